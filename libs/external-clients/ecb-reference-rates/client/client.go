@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	gorequest "libs/shared/go-request"
+	"net/http"
+	"time"
+
+	outputDTO "libs/services/acl/dtos/ecb-reference-rates/output"
+)
+
+// Client represents a client for the euro foreign exchange reference rates of the European Central Bank.
+type Client struct {
+	// ctx is the context for API requests.
+	ctx context.Context
+	// baseURL is the base URL for the API.
+	baseURL string
+	// httpClient is the client used to make HTTP requests.
+	httpClient *http.Client
+	// Timeout for the API requests.
+	Timeout time.Duration
+}
+
+// NewClient creates and returns a new Client instance with default settings.
+func NewClient() *Client {
+	return &Client{
+		ctx:        context.Background(),
+		baseURL:    "https://www.ecb.europa.eu",
+		httpClient: &http.Client{},
+		Timeout:    900 * time.Millisecond,
+	}
+}
+
+// SetBaseURL changes the base URL of the API, such as the URL of a stand-in server in tests.
+func (c *Client) SetBaseURL(baseURL string) {
+	c.baseURL = baseURL
+}
+
+// GetDailyRates fetches the reference rates of the last working day, published around 16:00 CET.
+func (c *Client) GetDailyRates() (outputDTO.EnvelopeDTO, error) {
+	return c.GetDailyRatesContext(c.ctx)
+}
+
+// GetDailyRatesContext is like GetDailyRates but the request is canceled with ctx.
+func (c *Client) GetDailyRatesContext(ctx context.Context) (outputDTO.EnvelopeDTO, error) {
+	pathParams := []string{"stats", "eurofxref", "eurofxref-daily.xml"}
+	headers := map[string]string{"Content-Type": "application/xml", "Accept": "application/xml"}
+	req, err := gorequest.CreateRequest(
+		ctx,
+		c.baseURL,
+		pathParams,
+		nil,
+		nil,
+		headers,
+		http.MethodGet,
+	)
+	if err != nil {
+		return outputDTO.EnvelopeDTO{}, err
+	}
+
+	var apiOutput outputDTO.EnvelopeDTO
+	err = gorequest.SendRequest(ctx, req, c.httpClient, &apiOutput, c.Timeout)
+	if err != nil {
+		return outputDTO.EnvelopeDTO{}, err
+	}
+	return apiOutput, nil
+}
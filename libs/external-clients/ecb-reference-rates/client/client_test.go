@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+const dailyRates = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<gesmes:Sender>
+		<gesmes:name>European Central Bank</gesmes:name>
+	</gesmes:Sender>
+	<Cube>
+		<Cube time='2024-05-21'>
+			<Cube currency='USD' rate='1.0857'/>
+			<Cube currency='BRL' rate='5.5701'/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+type ECBReferenceRatesClientTestSuite struct {
+	suite.Suite
+	path   string
+	server *httptest.Server
+	client *Client
+}
+
+func TestECBReferenceRatesClientTestSuite(t *testing.T) {
+	suite.Run(t, new(ECBReferenceRatesClientTestSuite))
+}
+
+func (suite *ECBReferenceRatesClientTestSuite) SetupTest() {
+	suite.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		suite.path = r.URL.Path
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write([]byte(dailyRates))
+	}))
+	suite.client = NewClient()
+	suite.client.SetBaseURL(suite.server.URL)
+}
+
+func (suite *ECBReferenceRatesClientTestSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+func (suite *ECBReferenceRatesClientTestSuite) TestGetDailyRates() {
+	envelope, err := suite.client.GetDailyRatesContext(context.Background())
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "/stats/eurofxref/eurofxref-daily.xml", suite.path)
+	assert.Equal(suite.T(), "European Central Bank", envelope.Sender)
+	if assert.Len(suite.T(), envelope.Days, 1) {
+		assert.Equal(suite.T(), "2024-05-21", envelope.Days[0].Time)
+		assert.Len(suite.T(), envelope.Days[0].Rates, 2)
+		assert.Equal(suite.T(), "BRL", envelope.Days[0].Rates[1].Currency)
+		assert.Equal(suite.T(), "5.5701", envelope.Days[0].Rates[1].Rate)
+	}
+}
+
+func (suite *ECBReferenceRatesClientTestSuite) TestGetDailyRatesFailure() {
+	suite.server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	})
+
+	_, err := suite.client.GetDailyRates()
+
+	assert.Error(suite.T(), err)
+}
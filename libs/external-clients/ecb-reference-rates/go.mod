@@ -0,0 +1,3 @@
+module libs/external-clients/ecb-reference-rates
+
+go 1.22
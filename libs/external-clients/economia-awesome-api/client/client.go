@@ -31,12 +31,23 @@ func NewClient() *Client {
 	}
 }
 
+// SetBaseURL changes the base URL of the API, such as the URL of a stand-in server in tests.
+func (c *Client) SetBaseURL(baseURL string) {
+	c.baseURL = baseURL
+}
+
 // GetExchangeRate fetches the exchange rate for the given exchangeRateName from the Economia Awesome API.
 func (c *Client) GetExchangeRate(exchangeRateName string) (outputDTO.CurrencyInfoMapDTO, error) {
+	return c.GetExchangeRateContext(c.ctx, exchangeRateName)
+}
+
+// GetExchangeRateContext is like GetExchangeRate but the request is canceled with ctx.
+// exchangeRateName is one pair, such as "USD-BRL", or several separated by commas.
+func (c *Client) GetExchangeRateContext(ctx context.Context, exchangeRateName string) (outputDTO.CurrencyInfoMapDTO, error) {
 	pathParams := []string{"json", "last", exchangeRateName}
 	headers := map[string]string{"Content-Type": "application/json"}
 	req, err := gorequest.CreateRequest(
-		c.ctx,
+		ctx,
 		c.baseURL,
 		pathParams,
 		nil,
@@ -49,7 +60,7 @@ func (c *Client) GetExchangeRate(exchangeRateName string) (outputDTO.CurrencyInf
 	}
 
 	var apiOutput outputDTO.CurrencyInfoMapDTO
-	err = gorequest.SendRequest(c.ctx, req, c.httpClient, &apiOutput, c.Timeout)
+	err = gorequest.SendRequest(ctx, req, c.httpClient, &apiOutput, c.Timeout)
 	if err != nil {
 		return outputDTO.CurrencyInfoMapDTO{}, err
 	}
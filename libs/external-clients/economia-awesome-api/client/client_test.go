@@ -1,7 +1,10 @@
 package client
 
 import (
+	"context"
 	outputDTO "libs/services/acl/dtos/economia-awesome-api/output"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -29,3 +32,22 @@ func (suite *EconomiaAwesomeAPIClientTestSuite) TestGetExchangeRate() {
 	assert.NotEmpty(suite.T(), result)
 	assert.IsType(suite.T(), outputDTO.CurrencyInfoMapDTO{}, result)
 }
+
+func (suite *EconomiaAwesomeAPIClientTestSuite) TestGetExchangeRateContextFromStandIn() {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"USDBRL":{"code":"USD","codein":"BRL","bid":"5.45"},"EURBRL":{"code":"EUR","codein":"BRL","bid":"6.01"}}`))
+	}))
+	defer server.Close()
+	client := NewClient()
+	client.SetBaseURL(server.URL)
+
+	result, err := client.GetExchangeRateContext(context.Background(), "USD-BRL,EUR-BRL")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "/json/last/USD-BRL,EUR-BRL", path)
+	assert.Equal(suite.T(), "5.45", result["USDBRL"].Bid)
+	assert.Equal(suite.T(), "EUR", result["EURBRL"].Code)
+}
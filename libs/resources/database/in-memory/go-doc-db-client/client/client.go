@@ -18,9 +18,9 @@ func NewClient(db *database.InMemoryDocBD) *Client {
 	}
 }
 
-// getCollection retrieves a collection by its name. Returns an error if the collection does not exist.
-func (c *Client) getCollection(collectionName string) (*database.Collection, error) {
-	collection, err := c.db.GetCollection(collectionName)
+// getCollection retrieves a collection or a view by its name. Returns an error if neither exists.
+func (c *Client) getCollection(collectionName string) (database.DocumentStore, error) {
+	collection, err := c.db.GetStore(collectionName)
 	if err != nil {
 		return nil, fmt.Errorf("collection %s does not exist", collectionName)
 	}
@@ -38,7 +38,7 @@ func (c *Client) CreateCollection(collectionName string) error {
 
 // DropCollection drops a collection by its name. Returns an error if the collection does not exist.
 func (c *Client) DropCollection(collectionName string) error {
-	if _, exists := c.db.Collections[collectionName]; !exists {
+	if _, err := c.db.GetCollection(collectionName); err != nil {
 		return fmt.Errorf("collection %s does not exist", collectionName)
 	}
 	err := c.db.DropCollection(collectionName)
@@ -53,6 +53,30 @@ func (c *Client) ListCollections() []string {
 	return c.db.ListCollections()
 }
 
+// ListCollectionInfo lists the collections and the views of the database, sorted by name, with their kind.
+func (c *Client) ListCollectionInfo() []database.CollectionInfo {
+	return c.db.ListCollectionInfo()
+}
+
+// CreateView creates a read-only view over the source collection. Reads on the view run the pipeline lazily,
+// and writes return a *database.ViewWriteError.
+func (c *Client) CreateView(viewName string, sourceCollection string, pipeline database.Pipeline) error {
+	return c.db.CreateView(viewName, sourceCollection, pipeline)
+}
+
+// DropView drops a view by its name. Returns an error if the view does not exist.
+func (c *Client) DropView(viewName string) error {
+	if _, err := c.db.GetView(viewName); err != nil {
+		return fmt.Errorf("view %s does not exist", viewName)
+	}
+	return c.db.DropView(viewName)
+}
+
+// ListViews lists the names of all views in the database.
+func (c *Client) ListViews() []string {
+	return c.db.ListViews()
+}
+
 // ConvertToDocument converts a map to a Document type. Returns an error if the document is nil or the _id field is missing.
 func (c *Client) ConvertToDocument(document map[string]interface{}) (database.Document, error) {
 	if document == nil {
@@ -104,12 +128,16 @@ func (c *Client) FindAll(collectionName string) ([]map[string]interface{}, error
 	return documents, nil
 }
 
-// Find returns documents matching the given query from the specified collection. Returns an error if the collection does not exist.
+// Find returns documents matching the given query from the specified collection. Returns an error if the collection does not exist
+// or the query is invalid, such as a $text nested in a logical operator.
 func (c *Client) Find(collectionName string, filter map[string]interface{}) ([]map[string]interface{}, error) {
 	collection, err := c.getCollection(collectionName)
 	if err != nil {
 		return nil, err
 	}
+	if err := database.ValidateQuery(filter); err != nil {
+		return nil, err
+	}
 	docs := collection.Find(filter)
 	documents := make([]map[string]interface{}, 0, len(docs))
 	for _, doc := range docs {
@@ -118,6 +146,33 @@ func (c *Client) Find(collectionName string, filter map[string]interface{}) ([]m
 	return documents, nil
 }
 
+// FindWithOptions returns documents matching the given query from the specified collection, ordered and limited by the options.
+// Returns an error if the collection does not exist or is a view, or if the query is invalid.
+func (c *Client) FindWithOptions(collectionName string, filter map[string]interface{}, options database.FindOptions) ([]map[string]interface{}, error) {
+	collection, err := c.db.GetCollection(collectionName)
+	if err != nil {
+		return nil, fmt.Errorf("collection %s does not exist", collectionName)
+	}
+	if err := database.ValidateQuery(filter); err != nil {
+		return nil, err
+	}
+	docs := collection.FindWithOptions(filter, options)
+	documents := make([]map[string]interface{}, 0, len(docs))
+	for _, doc := range docs {
+		documents = append(documents, map[string]interface{}(doc))
+	}
+	return documents, nil
+}
+
+// CreateIndex creates an index on the specified collection. Returns an error if the collection does not exist or the index is invalid.
+func (c *Client) CreateIndex(collectionName string, model database.IndexModel) error {
+	collection, err := c.db.GetCollection(collectionName)
+	if err != nil {
+		return fmt.Errorf("collection %s does not exist", collectionName)
+	}
+	return collection.CreateIndex(model)
+}
+
 // UpdateOne updates a single document by its ID with the given update in the specified collection. Returns an error if the collection or document does not exist.
 func (c *Client) UpdateOne(collectionName string, id string, update map[string]interface{}) error {
 	collection, err := c.getCollection(collectionName)
@@ -399,3 +399,59 @@ func (suite *InMemoryDocDBClientTestSuite) TestClientDeleteAllError() {
 	err = suite.client.DeleteAll(suite.collectionName2)
 	assert.NotNil(suite.T(), err)
 }
+
+func (suite *InMemoryDocDBClientTestSuite) TestClientView() {
+	err := suite.client.CreateCollection(suite.collectionName1)
+	assert.Nil(suite.T(), err)
+	err = suite.client.InsertOne(suite.collectionName1, suite.document1)
+	assert.Nil(suite.T(), err)
+
+	err = suite.client.CreateView("view", suite.collectionName1, database.Pipeline{})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []string{"view"}, suite.client.ListViews())
+	assert.Equal(suite.T(), []string{suite.collectionName1}, suite.client.ListCollections())
+
+	documents, err := suite.client.FindAll("view")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 1, len(documents))
+
+	err = suite.client.InsertOne("view", suite.document2)
+	assert.ErrorIs(suite.T(), err, database.ErrReadOnlyView)
+
+	err = suite.client.DropView("view")
+	assert.Nil(suite.T(), err)
+	err = suite.client.DropView("view")
+	assert.Equal(suite.T(), "view view does not exist", err.Error())
+}
+
+func (suite *InMemoryDocDBClientTestSuite) TestClientTextSearch() {
+	err := suite.client.CreateCollection(suite.collectionName1)
+	assert.Nil(suite.T(), err)
+	err = suite.client.InsertOne(suite.collectionName1, map[string]interface{}{"_id": "1", "name": "Dólar Americano/Real Brasileiro"})
+	assert.Nil(suite.T(), err)
+	err = suite.client.InsertOne(suite.collectionName1, map[string]interface{}{"_id": "2", "name": "Euro/Real Brasileiro"})
+	assert.Nil(suite.T(), err)
+
+	err = suite.client.CreateIndex(suite.collectionName1, database.IndexModel{Name: "name_text", Kind: database.IndexKindText, Fields: []string{"name"}})
+	assert.Nil(suite.T(), err)
+
+	documents, err := suite.client.FindWithOptions(
+		suite.collectionName1,
+		map[string]interface{}{database.OpText: "dolar real"},
+		database.FindOptions{Sort: []database.SortField{database.Desc(database.TextScoreField)}},
+	)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 2, len(documents))
+	assert.Equal(suite.T(), "1", documents[0]["_id"])
+
+	nested := map[string]interface{}{database.OpOr: []interface{}{map[string]interface{}{database.OpText: "dolar"}}}
+	_, err = suite.client.FindWithOptions(suite.collectionName1, nested, database.FindOptions{})
+	assert.ErrorIs(suite.T(), err, database.ErrNestedText)
+	_, err = suite.client.Find(suite.collectionName1, nested)
+	assert.ErrorIs(suite.T(), err, database.ErrNestedText)
+
+	_, err = suite.client.FindWithOptions("nonExistentCollection", nil, database.FindOptions{})
+	assert.NotNil(suite.T(), err)
+	err = suite.client.CreateIndex("nonExistentCollection", database.IndexModel{})
+	assert.NotNil(suite.T(), err)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"libs/resources/database/in-memory/go-doc-db/database"
+	"libs/resources/database/in-memory/go-doc-db/shell"
+	"log"
+	"os"
+)
+
+var (
+	defaultDBName = "godocdb"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: godocdb shell [-dump path] [-name database]")
+}
+
+func runShell(args []string) error {
+	flags := flag.NewFlagSet("shell", flag.ExitOnError)
+	dumpPath := flags.String("dump", "", "JSON dump file to load into the database")
+	dbName := flags.String("name", defaultDBName, "name of the database when no dump is given")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	db := database.NewInMemoryDocBD(*dbName)
+	if *dumpPath != "" {
+		loaded, err := database.LoadFromFile(*dumpPath)
+		if err != nil {
+			return err
+		}
+		db = loaded
+	}
+
+	fmt.Printf("godocdb shell connected to %q, type help for the list of commands\n", db.Name)
+	return shell.NewShell(db, os.Stdin, os.Stdout).Run()
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "shell":
+		if err := runShell(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,107 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ArrayQueryTestSuite struct {
+	suite.Suite
+	collection *Collection
+}
+
+func TestArrayQueryTestSuite(t *testing.T) {
+	suite.Run(t, new(ArrayQueryTestSuite))
+}
+
+func (suite *ArrayQueryTestSuite) SetupTest() {
+	suite.collection = NewCollection()
+	documents := []Document{
+		{
+			"_id":  "USD-BRL",
+			"tags": []interface{}{"americas", "major"},
+			"bids": []float64{5.40, 5.45, 5.52},
+			"history": []interface{}{
+				map[string]interface{}{"bid": 5.40, "source": "api"},
+				map[string]interface{}{"bid": 5.45, "source": "cache"},
+			},
+			"meta": map[string]interface{}{"provider": map[string]interface{}{"name": "awesome"}},
+		},
+		{
+			"_id":  "EUR-BRL",
+			"tags": []string{"europe", "major"},
+			"bids": []interface{}{6.01},
+			"history": []interface{}{
+				map[string]interface{}{"bid": 6.01, "source": "api"},
+			},
+			"meta": map[string]interface{}{"provider": map[string]interface{}{"name": "ecb"}},
+		},
+		{
+			"_id":  "ARS-BRL",
+			"tags": []interface{}{},
+		},
+	}
+	for _, document := range documents {
+		err := suite.collection.InsertOne(document)
+		assert.Nil(suite.T(), err)
+	}
+}
+
+func (suite *ArrayQueryTestSuite) findIDs(query map[string]interface{}) []string {
+	ids := []string{}
+	for _, document := range suite.collection.Find(query) {
+		ids = append(ids, document["_id"].(string))
+	}
+	return ids
+}
+
+func (suite *ArrayQueryTestSuite) TestArrayQueries() {
+	testCases := []struct {
+		name     string
+		query    map[string]interface{}
+		expected []string
+	}{
+		{"scalar matches any element", map[string]interface{}{"tags": "major"}, []string{"USD-BRL", "EUR-BRL"}},
+		{"scalar matches typed slice", map[string]interface{}{"bids": 5.45}, []string{"USD-BRL"}},
+		{"whole array equality", map[string]interface{}{"tags": []interface{}{"americas", "major"}}, []string{"USD-BRL"}},
+		{"comparison on any element", map[string]interface{}{"bids": map[string]interface{}{OpGt: 5.5}}, []string{"USD-BRL", "EUR-BRL"}},
+		{"in on any element", map[string]interface{}{"tags": map[string]interface{}{OpIn: []interface{}{"europe", "asia"}}}, []string{"EUR-BRL"}},
+		{"ne excludes arrays holding the value", map[string]interface{}{"tags": map[string]interface{}{OpNe: "major"}}, []string{"ARS-BRL"}},
+		{"nin excludes arrays holding the values", map[string]interface{}{"tags": map[string]interface{}{OpNin: []interface{}{"americas"}}}, []string{"EUR-BRL", "ARS-BRL"}},
+		{"all", map[string]interface{}{"tags": map[string]interface{}{OpAll: []interface{}{"major", "americas"}}}, []string{"USD-BRL"}},
+		{"all with empty list", map[string]interface{}{"tags": map[string]interface{}{OpAll: []interface{}{}}}, []string{}},
+		{"size", map[string]interface{}{"tags": map[string]interface{}{OpSize: 2}}, []string{"USD-BRL", "EUR-BRL"}},
+		{"size zero", map[string]interface{}{"tags": map[string]interface{}{OpSize: 0}}, []string{"ARS-BRL"}},
+		{"elemMatch on documents", map[string]interface{}{"history": map[string]interface{}{OpElemMatch: map[string]interface{}{"source": "cache", "bid": map[string]interface{}{OpGte: 5.4}}}}, []string{"USD-BRL"}},
+		{"elemMatch on scalars", map[string]interface{}{"bids": map[string]interface{}{OpElemMatch: map[string]interface{}{OpGt: 5.41, OpLt: 5.5}}}, []string{"USD-BRL"}},
+		{"dotted path with position", map[string]interface{}{"history.0.bid": map[string]interface{}{OpGt: 6.0}}, []string{"EUR-BRL"}},
+		{"dotted path with position out of range", map[string]interface{}{"history.1.bid": map[string]interface{}{OpExists: true}}, []string{"USD-BRL"}},
+		{"dotted path across array elements", map[string]interface{}{"history.source": "cache"}, []string{"USD-BRL"}},
+		{"dotted path into nested documents", map[string]interface{}{"meta.provider.name": "ecb"}, []string{"EUR-BRL"}},
+		{"dotted path on missing field", map[string]interface{}{"meta.provider.name": map[string]interface{}{OpExists: false}}, []string{"ARS-BRL"}},
+		{"nested document inside array", map[string]interface{}{"history": map[string]interface{}{"source": "cache"}}, []string{"USD-BRL"}},
+	}
+
+	for _, tc := range testCases {
+		suite.T().Run(tc.name, func(t *testing.T) {
+			assert.ElementsMatch(t, tc.expected, suite.findIDs(tc.query))
+		})
+	}
+}
+
+func (suite *ArrayQueryTestSuite) TestParsedDottedPath() {
+	query, err := ParseQuery(`history.0.bid == 5.40 && tags == "major"`)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []string{"USD-BRL"}, suite.findIDs(query))
+}
+
+func (suite *ArrayQueryTestSuite) TestSortOnDottedPath() {
+	documents := Pipeline{
+		Match(map[string]interface{}{"history": map[string]interface{}{OpExists: true}}),
+		Sort(Desc("history.0.bid")),
+	}.Apply(suite.collection.FindAll())
+	assert.Equal(suite.T(), "EUR-BRL", documents[0]["_id"])
+	assert.Equal(suite.T(), "USD-BRL", documents[1]["_id"])
+}
@@ -2,9 +2,15 @@ package database
 
 import (
 	"errors"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// ErrDocumentNotFound is returned when no document has the requested ID.
+var ErrDocumentNotFound = errors.New("document not found")
+
 // DocumentID represents the unique identifier for a document.
 type DocumentID string
 
@@ -13,21 +19,25 @@ type Document map[string]interface{}
 
 // Collection represents a collection of documents with thread-safe operations.
 type Collection struct {
-	data map[string]Document
-	mu   sync.RWMutex
+	data            map[string]Document
+	indexes         map[string]index
+	instrumentation atomic.Value
+	mu              sync.RWMutex
 }
 
 // NewCollection creates and returns a new Collection instance.
 func NewCollection() *Collection {
 	return &Collection{
-		data: make(map[string]Document),
+		data:    make(map[string]Document),
+		indexes: make(map[string]index),
 	}
 }
 
 // InsertOne inserts a single document into the collection.
-func (c *Collection) InsertOne(document Document) error {
-	c.mu.Lock() // Lock for writing
-	defer c.mu.Unlock()
+func (c *Collection) InsertOne(document Document) (err error) {
+	defer c.observe(OperationInsertOne, time.Now(), &err)
+	unlock := c.lock() // Lock for writing
+	defer unlock()
 
 	documentID, ok := document["_id"]
 	if !ok {
@@ -42,25 +52,30 @@ func (c *Collection) InsertOne(document Document) error {
 		return errors.New("document already exists")
 	}
 	c.data[documentIDStr] = document
+	for _, idx := range c.indexes {
+		idx.add(documentIDStr, document)
+	}
 	return nil
 }
 
 // FindOne finds and returns a single document by its ID.
-func (c *Collection) FindOne(id string) (Document, error) {
-	c.mu.RLock() // Lock for reading
-	defer c.mu.RUnlock()
+func (c *Collection) FindOne(id string) (document Document, err error) {
+	defer c.observe(OperationFindOne, time.Now(), &err)
+	unlock := c.rlock() // Lock for reading
+	defer unlock()
 
 	document, ok := c.data[id]
 	if !ok {
-		return nil, errors.New("document not found")
+		return nil, ErrDocumentNotFound
 	}
 	return document, nil
 }
 
 // FindAll returns all documents in the collection.
 func (c *Collection) FindAll() []Document {
-	c.mu.RLock() // Lock for reading
-	defer c.mu.RUnlock()
+	defer c.observe(OperationFindAll, time.Now(), nil)
+	unlock := c.rlock() // Lock for reading
+	defer unlock()
 	documents := make([]Document, 0, len(c.data))
 	for _, document := range c.data {
 		documents = append(documents, document)
@@ -68,73 +83,232 @@ func (c *Collection) FindAll() []Document {
 	return documents
 }
 
-// matchesQuery checks if a document matches the query criteria.
-func matchesQuery(document, query map[string]interface{}) bool {
-	for key, value := range query {
-		docValue, exists := document[key]
-		if !exists {
-			return false
+// Find searches documents matching a given query.
+func (c *Collection) Find(query map[string]interface{}) []Document {
+	return c.FindWithOptions(query, FindOptions{})
+}
+
+// FindOptions controls the ordering and size of the result of FindWithOptions.
+type FindOptions struct {
+	// Sort orders the results. TextScoreField sorts by $text relevance.
+	Sort []SortField
+	// Skip drops the first results.
+	Skip int
+	// Limit caps the number of results when greater than zero.
+	Limit int
+	// IncludeTextScore returns copies of the matched documents holding their relevance under TextScoreField.
+	IncludeTextScore bool
+}
+
+// FindWithOptions searches documents matching a given query and applies the given options.
+// A query with a top-level $text operator requires a text index on the collection.
+func (c *Collection) FindWithOptions(query map[string]interface{}, options FindOptions) []Document {
+	defer c.observe(OperationFind, time.Now(), nil)
+	unlock := c.rlock() // Lock for reading
+	defer unlock()
+
+	var scores map[string]float64
+	candidates := c.data
+	if operand, ok := query[OpText]; ok {
+		search, ok := textSearchString(operand)
+		textIdx := c.textIndex()
+		if !ok || textIdx == nil {
+			return []Document{}
+		}
+		scores = textIdx.search(search)
+		candidates = make(map[string]Document, len(scores))
+		for id := range scores {
+			candidates[id] = c.data[id]
+		}
+		query = withoutKey(query, OpText)
+	}
+
+	ids := make([]string, 0, len(candidates))
+	for id, document := range candidates {
+		if matchesQuery(document, query) {
+			ids = append(ids, id)
 		}
+	}
 
-		// If the value is a map, recurse into it.
-		if queryMap, ok := value.(map[string]interface{}); ok {
-			docMap, ok := docValue.(map[string]interface{})
-			if !ok || !matchesQuery(docMap, queryMap) {
-				return false
+	if len(options.Sort) > 0 {
+		sort.SliceStable(ids, func(i, j int) bool {
+			for _, field := range options.Sort {
+				var cmp int
+				if field.Field == TextScoreField && scores != nil {
+					cmp = compareFloats(scores[ids[i]], scores[ids[j]])
+				} else {
+					cmp = compareFieldValues(c.data[ids[i]], c.data[ids[j]], field.Field)
+				}
+				if cmp == 0 {
+					continue
+				}
+				if field.Descending {
+					return cmp > 0
+				}
+				return cmp < 0
 			}
+			return false
+		})
+	}
+
+	if options.Skip > 0 {
+		if options.Skip >= len(ids) {
+			ids = ids[:0]
 		} else {
-			// Direct comparison for non-map values
-			if docValue != value {
-				return false
-			}
+			ids = ids[options.Skip:]
 		}
 	}
-	return true
-}
+	if options.Limit > 0 && options.Limit < len(ids) {
+		ids = ids[:options.Limit]
+	}
 
-// Find searches documents matching a given query.
-func (c *Collection) Find(query map[string]interface{}) []Document {
-	c.mu.RLock() // Lock for reading
-	defer c.mu.RUnlock()
-	documents := make([]Document, 0, len(c.data))
-	for _, document := range c.data {
-		if matchesQuery(document, query) {
-			documents = append(documents, document)
+	documents := make([]Document, 0, len(ids))
+	for _, id := range ids {
+		document := c.data[id]
+		if options.IncludeTextScore && scores != nil {
+			scored := make(Document, len(document)+1)
+			for key, value := range document {
+				scored[key] = value
+			}
+			scored[TextScoreField] = scores[id]
+			document = scored
 		}
+		documents = append(documents, document)
 	}
 	return documents
 }
 
 // DeleteOne deletes a single document by its ID.
-func (c *Collection) DeleteOne(id string) error {
-	c.mu.Lock() // Lock for writing
-	defer c.mu.Unlock()
+func (c *Collection) DeleteOne(id string) (err error) {
+	defer c.observe(OperationDeleteOne, time.Now(), &err)
+	unlock := c.lock() // Lock for writing
+	defer unlock()
 	_, ok := c.data[id]
 	if !ok {
-		return errors.New("document not found")
+		return ErrDocumentNotFound
 	}
 	delete(c.data, id)
+	for _, idx := range c.indexes {
+		idx.remove(id)
+	}
 	return nil
 }
 
 // UpdateOne updates a single document by its ID with the given update.
-func (c *Collection) UpdateOne(id string, update Document) error {
-	c.mu.Lock() // Lock for writing
-	defer c.mu.Unlock()
+func (c *Collection) UpdateOne(id string, update Document) (err error) {
+	defer c.observe(OperationUpdateOne, time.Now(), &err)
+	unlock := c.lock() // Lock for writing
+	defer unlock()
 	_, ok := c.data[id]
 	if !ok {
-		return errors.New("document not found")
+		return ErrDocumentNotFound
 	}
 	for key, value := range update {
 		c.data[id][key] = value
 	}
+	for _, idx := range c.indexes {
+		idx.add(id, c.data[id])
+	}
 	return nil
 }
 
 // DeleteAll deletes all documents in the collection.
-func (c *Collection) DeleteAll() error {
-	c.mu.Lock() // Lock for writing
-	defer c.mu.Unlock()
+func (c *Collection) DeleteAll() (err error) {
+	defer c.observe(OperationDeleteAll, time.Now(), &err)
+	unlock := c.lock() // Lock for writing
+	defer unlock()
 	c.data = make(map[string]Document)
+	for _, idx := range c.indexes {
+		idx.clear()
+	}
 	return nil
 }
+
+// CreateIndex creates an index on the collection and indexes the existing documents.
+// A collection can hold at most one text index.
+func (c *Collection) CreateIndex(model IndexModel) error {
+	unlock := c.lock() // Lock for writing
+	defer unlock()
+
+	if model.Name == "" {
+		return errIndexNameRequired
+	}
+	if len(model.Fields) == 0 {
+		return errIndexFieldRequired
+	}
+	if _, ok := c.indexes[model.Name]; ok {
+		return errIndexExists
+	}
+
+	var idx index
+	switch model.Kind {
+	case IndexKindText:
+		if c.textIndex() != nil {
+			return errTextIndexExists
+		}
+		idx = newTextIndex(model)
+	default:
+		return errUnsupportedIndex
+	}
+
+	for id, document := range c.data {
+		idx.add(id, document)
+	}
+	c.indexes[model.Name] = idx
+	return nil
+}
+
+// DropIndex removes an index by its name.
+func (c *Collection) DropIndex(name string) error {
+	unlock := c.lock() // Lock for writing
+	defer unlock()
+	if _, ok := c.indexes[name]; !ok {
+		return errIndexNotFound
+	}
+	delete(c.indexes, name)
+	return nil
+}
+
+// ListIndexes returns the models of all indexes on the collection.
+func (c *Collection) ListIndexes() []IndexModel {
+	unlock := c.rlock() // Lock for reading
+	defer unlock()
+	models := make([]IndexModel, 0, len(c.indexes))
+	for _, idx := range c.indexes {
+		models = append(models, idx.model())
+	}
+	return models
+}
+
+// textIndex returns the text index of the collection, or nil if there is none.
+func (c *Collection) textIndex() *textIndex {
+	for _, idx := range c.indexes {
+		if textIdx, ok := idx.(*textIndex); ok {
+			return textIdx
+		}
+	}
+	return nil
+}
+
+// withoutKey returns a copy of the query without the given key.
+func withoutKey(query map[string]interface{}, key string) map[string]interface{} {
+	rest := make(map[string]interface{}, len(query))
+	for k, v := range query {
+		if k != key {
+			rest[k] = v
+		}
+	}
+	return rest
+}
+
+// compareFloats orders two float64 values.
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
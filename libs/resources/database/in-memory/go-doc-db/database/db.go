@@ -1,11 +1,35 @@
 package database
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// CollectionKind tells a collection from a view in the entries of ListCollectionInfo.
+type CollectionKind string
+
+const (
+	KindCollection CollectionKind = "collection"
+	KindView       CollectionKind = "view"
+)
+
+// CollectionInfo describes a collection or a view of a database.
+type CollectionInfo struct {
+	Name string
+	Kind CollectionKind
+}
 
 // InMemoryDocBD represents an in-memory document database containing multiple collections.
+// Its methods are safe for concurrent use; the Collections and Views maps must not be changed directly while it is in use.
 type InMemoryDocBD struct {
 	Name        string
 	Collections map[string]*Collection
+	Views       map[string]*View
+
+	instrumentation Instrumentation
+	mu              sync.RWMutex
 }
 
 // NewInMemoryDocBD creates and returns a new InMemoryDocBD instance with the given name.
@@ -13,11 +37,14 @@ func NewInMemoryDocBD(name string) *InMemoryDocBD {
 	return &InMemoryDocBD{
 		Name:        name,
 		Collections: make(map[string]*Collection),
+		Views:       make(map[string]*View),
 	}
 }
 
 // GetCollection retrieves a collection by its name.
 func (d *InMemoryDocBD) GetCollection(collectionName string) (*Collection, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
 	collection, ok := d.Collections[collectionName]
 	if !ok {
 		return nil, errors.New("collection not found")
@@ -27,27 +54,138 @@ func (d *InMemoryDocBD) GetCollection(collectionName string) (*Collection, error
 
 // CreateCollection creates a new collection with the given name.
 func (d *InMemoryDocBD) CreateCollection(collectionName string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
 	if _, ok := d.Collections[collectionName]; ok {
 		return errors.New("collection already exists")
 	}
-	d.Collections[collectionName] = NewCollection()
+	if _, ok := d.Views[collectionName]; ok {
+		return errors.New("a view with this name already exists")
+	}
+	collection := NewCollection()
+	if d.instrumentation != nil {
+		collection.SetInstrumentation(collectionName, d.instrumentation)
+	}
+	d.Collections[collectionName] = collection
 	return nil
 }
 
 // DropCollection drops a collection by its name.
 func (d *InMemoryDocBD) DropCollection(collectionName string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
 	if _, ok := d.Collections[collectionName]; !ok {
 		return errors.New("collection not found")
 	}
 	delete(d.Collections, collectionName)
+	if d.instrumentation != nil {
+		d.instrumentation.SetDocumentCount(collectionName, 0)
+	}
 	return nil
 }
 
-// ListCollections lists the names of all collections in the database.
+// ListCollections lists the names of all collections in the database. Views are listed by ListViews.
 func (d *InMemoryDocBD) ListCollections() []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
 	collectionNames := make([]string, 0, len(d.Collections))
 	for collectionName := range d.Collections {
 		collectionNames = append(collectionNames, collectionName)
 	}
 	return collectionNames
 }
+
+// ListCollectionInfo lists the collections and the views of the database, sorted by name, with their kind.
+// The names can be passed to GetStore.
+func (d *InMemoryDocBD) ListCollectionInfo() []CollectionInfo {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	infos := make([]CollectionInfo, 0, len(d.Collections)+len(d.Views))
+	for collectionName := range d.Collections {
+		infos = append(infos, CollectionInfo{Name: collectionName, Kind: KindCollection})
+	}
+	for viewName := range d.Views {
+		infos = append(infos, CollectionInfo{Name: viewName, Kind: KindView})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// CreateView creates a read-only view that runs the pipeline over the source collection or view.
+// The source does not need to exist yet; the view is evaluated lazily on every read.
+func (d *InMemoryDocBD) CreateView(viewName string, sourceCollection string, pipeline Pipeline) error {
+	if viewName == "" {
+		return errors.New("view name is required")
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.Views[viewName]; ok {
+		return errors.New("view already exists")
+	}
+	if _, ok := d.Collections[viewName]; ok {
+		return errors.New("a collection with this name already exists")
+	}
+	for source := sourceCollection; ; {
+		if source == viewName {
+			return errors.New("a view cannot read from itself")
+		}
+		view, ok := d.Views[source]
+		if !ok {
+			break
+		}
+		source = view.source
+	}
+	d.Views[viewName] = &View{
+		name:     viewName,
+		source:   sourceCollection,
+		pipeline: pipeline,
+		db:       d,
+	}
+	return nil
+}
+
+// GetView retrieves a view by its name.
+func (d *InMemoryDocBD) GetView(viewName string) (*View, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	view, ok := d.Views[viewName]
+	if !ok {
+		return nil, errors.New("view not found")
+	}
+	return view, nil
+}
+
+// DropView drops a view by its name. The source collection is left untouched.
+func (d *InMemoryDocBD) DropView(viewName string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.Views[viewName]; !ok {
+		return errors.New("view not found")
+	}
+	delete(d.Views, viewName)
+	return nil
+}
+
+// ListViews lists the names of all views in the database.
+func (d *InMemoryDocBD) ListViews() []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	viewNames := make([]string, 0, len(d.Views))
+	for viewName := range d.Views {
+		viewNames = append(viewNames, viewName)
+	}
+	return viewNames
+}
+
+// GetStore retrieves a collection or a view by its name.
+func (d *InMemoryDocBD) GetStore(name string) (DocumentStore, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if collection, ok := d.Collections[name]; ok {
+		return collection, nil
+	}
+	if view, ok := d.Views[name]; ok {
+		return view, nil
+	}
+	return nil, fmt.Errorf("collection or view %s not found", name)
+}
@@ -1,6 +1,8 @@
 package database
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -102,3 +104,33 @@ func (suite *InMemoryDocDBTestSuite) TestDBGetCollectionError() {
 	_, err := suite.db.GetCollection(suite.collectionName1)
 	assert.NotNil(suite.T(), err)
 }
+
+func (suite *InMemoryDocDBTestSuite) TestDBDumpAndLoad() {
+	err := suite.db.CreateCollection(suite.collectionName1)
+	assert.Nil(suite.T(), err)
+	collection, err := suite.db.GetCollection(suite.collectionName1)
+	assert.Nil(suite.T(), err)
+	err = collection.InsertOne(Document{"_id": "1", "name": "Alice", "age": 30})
+	assert.Nil(suite.T(), err)
+
+	var buffer bytes.Buffer
+	err = suite.db.Dump(&buffer)
+	assert.Nil(suite.T(), err)
+
+	loaded, err := LoadDump(&buffer)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), suite.dbName, loaded.Name)
+	assert.Equal(suite.T(), []string{suite.collectionName1}, loaded.ListCollections())
+
+	loadedCollection, err := loaded.GetCollection(suite.collectionName1)
+	assert.Nil(suite.T(), err)
+	document, err := loadedCollection.FindOne("1")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "Alice", document["name"])
+	assert.Equal(suite.T(), float64(30), document["age"])
+}
+
+func (suite *InMemoryDocDBTestSuite) TestDBLoadDumpInvalid() {
+	_, err := LoadDump(strings.NewReader("not json"))
+	assert.NotNil(suite.T(), err)
+}
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// dumpFile is the JSON layout used to persist an InMemoryDocBD.
+type dumpFile struct {
+	Name        string                  `json:"name"`
+	Collections map[string][]Document   `json:"collections"`
+	Indexes     map[string][]IndexModel `json:"indexes,omitempty"`
+}
+
+// Dump writes every collection of the database to w as JSON. Views are skipped: their pipelines are functions,
+// so they must be created again after LoadDump.
+func (d *InMemoryDocBD) Dump(w io.Writer) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	dump := dumpFile{
+		Name:        d.Name,
+		Collections: make(map[string][]Document, len(d.Collections)),
+		Indexes:     make(map[string][]IndexModel),
+	}
+	for collectionName, collection := range d.Collections {
+		dump.Collections[collectionName] = collection.FindAll()
+		if indexes := collection.ListIndexes(); len(indexes) > 0 {
+			dump.Indexes[collectionName] = indexes
+		}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(dump)
+}
+
+// SaveToFile writes a JSON dump of the database to the given path.
+func (d *InMemoryDocBD) SaveToFile(path string) (err error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create dump file: %w", err)
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close dump file: %w", closeErr)
+		}
+	}()
+	return d.Dump(file)
+}
+
+// LoadDump reads a JSON dump produced by Dump and returns a new database holding its documents.
+func LoadDump(r io.Reader) (*InMemoryDocBD, error) {
+	var dump dumpFile
+	if err := json.NewDecoder(r).Decode(&dump); err != nil {
+		return nil, fmt.Errorf("failed to decode dump: %w", err)
+	}
+	db := NewInMemoryDocBD(dump.Name)
+	for collectionName, documents := range dump.Collections {
+		if err := db.CreateCollection(collectionName); err != nil {
+			return nil, err
+		}
+		collection := db.Collections[collectionName]
+		for _, document := range documents {
+			if err := collection.InsertOne(document); err != nil {
+				return nil, fmt.Errorf("failed to load document into %s: %w", collectionName, err)
+			}
+		}
+		for _, model := range dump.Indexes[collectionName] {
+			if err := collection.CreateIndex(model); err != nil {
+				return nil, fmt.Errorf("failed to create index %s on %s: %w", model.Name, collectionName, err)
+			}
+		}
+	}
+	return db, nil
+}
+
+// LoadFromFile reads a JSON dump from the given path and returns a new database holding its documents.
+func LoadFromFile(path string) (*InMemoryDocBD, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dump file: %w", err)
+	}
+	defer file.Close()
+	return LoadDump(file)
+}
@@ -0,0 +1,172 @@
+package database
+
+import (
+	"errors"
+	"math"
+)
+
+// OpText is the top-level query operator for full-text search.
+// Its operand is either the search string or a map holding it under OpSearch.
+const (
+	OpText   = "$text"
+	OpSearch = "$search"
+)
+
+// TextScoreField is the pseudo field holding the relevance score of a $text query.
+// Use it in FindOptions.Sort to order results by relevance.
+const TextScoreField = "_score"
+
+// IndexKind identifies the type of an index.
+type IndexKind string
+
+// IndexKindText is a full-text index over string fields.
+const IndexKindText IndexKind = "text"
+
+var (
+	errIndexNameRequired  = errors.New("index name is required")
+	errIndexFieldRequired = errors.New("index requires at least one field")
+	errIndexExists        = errors.New("index already exists")
+	errIndexNotFound      = errors.New("index not found")
+	errTextIndexExists    = errors.New("collection already has a text index")
+	errUnsupportedIndex   = errors.New("unsupported index kind")
+)
+
+// IndexModel describes an index to create on a collection.
+type IndexModel struct {
+	Name   string    `json:"name"`
+	Kind   IndexKind `json:"kind"`
+	Fields []string  `json:"fields"`
+	// Weights optionally scales the contribution of each field to the text score. Fields default to 1.
+	Weights map[string]float64 `json:"weights,omitempty"`
+}
+
+// index is maintained by a collection as documents are written.
+type index interface {
+	model() IndexModel
+	add(id string, document Document)
+	remove(id string)
+	clear()
+}
+
+// textIndex is an inverted index from normalized terms to the documents that contain them.
+type textIndex struct {
+	spec IndexModel
+	// postings maps a term to the weighted frequency of that term in each document.
+	postings map[string]map[string]float64
+	// documents maps a document ID to its weighted term frequencies, so it can be removed.
+	documents map[string]map[string]float64
+}
+
+// newTextIndex creates and returns a new, empty textIndex.
+func newTextIndex(spec IndexModel) *textIndex {
+	return &textIndex{
+		spec:      spec,
+		postings:  make(map[string]map[string]float64),
+		documents: make(map[string]map[string]float64),
+	}
+}
+
+func (t *textIndex) model() IndexModel {
+	return t.spec
+}
+
+// add indexes the configured string fields of the document.
+func (t *textIndex) add(id string, document Document) {
+	t.remove(id)
+	frequencies := make(map[string]float64)
+	for _, field := range t.spec.Fields {
+		weight := 1.0
+		if w, ok := t.spec.Weights[field]; ok {
+			weight = w
+		}
+		values, _ := resolvePath(document, field)
+		for _, value := range values {
+			for _, text := range textValues(value) {
+				for _, term := range Tokenize(text) {
+					frequencies[term] += weight
+				}
+			}
+		}
+	}
+	if len(frequencies) == 0 {
+		return
+	}
+	t.documents[id] = frequencies
+	for term, frequency := range frequencies {
+		if t.postings[term] == nil {
+			t.postings[term] = make(map[string]float64)
+		}
+		t.postings[term][id] = frequency
+	}
+}
+
+// remove drops the document from the index.
+func (t *textIndex) remove(id string) {
+	for term := range t.documents[id] {
+		delete(t.postings[term], id)
+		if len(t.postings[term]) == 0 {
+			delete(t.postings, term)
+		}
+	}
+	delete(t.documents, id)
+}
+
+func (t *textIndex) clear() {
+	t.postings = make(map[string]map[string]float64)
+	t.documents = make(map[string]map[string]float64)
+}
+
+// search returns the relevance score of every document containing at least one of the search terms.
+// Scores are the sum, over matched terms, of the weighted term frequency times the inverse document frequency.
+func (t *textIndex) search(search string) map[string]float64 {
+	scores := make(map[string]float64)
+	total := float64(len(t.documents))
+	seen := make(map[string]struct{})
+	for _, term := range Tokenize(search) {
+		if _, ok := seen[term]; ok {
+			continue
+		}
+		seen[term] = struct{}{}
+		postings := t.postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+		idf := math.Log(1 + total/float64(len(postings)))
+		for id, frequency := range postings {
+			scores[id] += frequency * idf
+		}
+	}
+	return scores
+}
+
+// textValues extracts the strings held by a field value.
+func textValues(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+// textSearchString extracts the search string from the operand of a $text query.
+func textSearchString(operand interface{}) (string, bool) {
+	if search, ok := operand.(string); ok {
+		return search, true
+	}
+	if m, ok := asMap(operand); ok {
+		search, ok := m[OpSearch].(string)
+		return search, ok
+	}
+	return "", false
+}
@@ -0,0 +1,115 @@
+package database
+
+import "time"
+
+// Operation names reported to an Instrumentation.
+const (
+	OperationInsertOne = "insert_one"
+	OperationFindOne   = "find_one"
+	OperationFindAll   = "find_all"
+	OperationFind      = "find"
+	OperationUpdateOne = "update_one"
+	OperationDeleteOne = "delete_one"
+	OperationDeleteAll = "delete_all"
+)
+
+// LockMode identifies the kind of collection lock a caller waited for.
+type LockMode string
+
+const (
+	LockRead  LockMode = "read"
+	LockWrite LockMode = "write"
+)
+
+// Instrumentation receives measurements from collections. Implementations must be safe for concurrent use.
+type Instrumentation interface {
+	// ObserveOperation is called once per collection operation with its total latency and resulting error.
+	ObserveOperation(collection string, operation string, duration time.Duration, err error)
+	// ObserveLockWait is called with the time spent waiting to acquire the collection lock.
+	ObserveLockWait(collection string, mode LockMode, wait time.Duration)
+	// SetDocumentCount is called with the number of documents after every change to a collection.
+	SetDocumentCount(collection string, count int)
+}
+
+// NoopInstrumentation discards every measurement. It is the default for new collections.
+type NoopInstrumentation struct{}
+
+func (NoopInstrumentation) ObserveOperation(string, string, time.Duration, error) {}
+func (NoopInstrumentation) ObserveLockWait(string, LockMode, time.Duration)       {}
+func (NoopInstrumentation) SetDocumentCount(string, int)                          {}
+
+// instrumentationHolder lets an Instrumentation interface be stored in an atomic.Value together with the name the
+// collection is reported under, so both are read at once.
+type instrumentationHolder struct {
+	name            string
+	instrumentation Instrumentation
+}
+
+// SetInstrumentation names the collection in reported measurements and starts sending them to instrumentation.
+// A nil instrumentation disables reporting.
+func (c *Collection) SetInstrumentation(name string, instrumentation Instrumentation) {
+	if instrumentation == nil {
+		instrumentation = NoopInstrumentation{}
+	}
+	c.mu.Lock()
+	c.instrumentation.Store(instrumentationHolder{name: name, instrumentation: instrumentation})
+	count := len(c.data)
+	c.mu.Unlock()
+	instrumentation.SetDocumentCount(name, count)
+}
+
+// SetInstrumentation sends the measurements of every current and future collection to instrumentation.
+func (d *InMemoryDocBD) SetInstrumentation(instrumentation Instrumentation) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.instrumentation = instrumentation
+	for collectionName, collection := range d.Collections {
+		collection.SetInstrumentation(collectionName, instrumentation)
+	}
+}
+
+// instrument returns the name the collection is reported under and its instrumentation.
+func (c *Collection) instrument() (string, Instrumentation) {
+	holder, ok := c.instrumentation.Load().(instrumentationHolder)
+	if !ok {
+		return "", NoopInstrumentation{}
+	}
+	return holder.name, holder.instrumentation
+}
+
+// lock acquires the write lock. The returned unlock releases it and only then reports how long it waited and the
+// document count, so the instrumentation never runs while the collection is locked.
+func (c *Collection) lock() (unlock func()) {
+	start := time.Now()
+	c.mu.Lock()
+	wait := time.Since(start)
+	return func() {
+		count := len(c.data)
+		c.mu.Unlock()
+		name, instrumentation := c.instrument()
+		instrumentation.ObserveLockWait(name, LockWrite, wait)
+		instrumentation.SetDocumentCount(name, count)
+	}
+}
+
+// rlock acquires the read lock. The returned unlock releases it and then reports how long it waited.
+func (c *Collection) rlock() (unlock func()) {
+	start := time.Now()
+	c.mu.RLock()
+	wait := time.Since(start)
+	return func() {
+		c.mu.RUnlock()
+		name, instrumentation := c.instrument()
+		instrumentation.ObserveLockWait(name, LockRead, wait)
+	}
+}
+
+// observe reports an operation that started at start. It is meant to be deferred.
+func (c *Collection) observe(operation string, start time.Time, err *error) {
+	var opErr error
+	if err != nil {
+		opErr = *err
+	}
+	name, instrumentation := c.instrument()
+	instrumentation.ObserveOperation(name, operation, time.Since(start), opErr)
+}
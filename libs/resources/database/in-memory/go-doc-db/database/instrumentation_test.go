@@ -0,0 +1,186 @@
+package database
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// recordingInstrumentation keeps every measurement it receives.
+type recordingInstrumentation struct {
+	mu         sync.Mutex
+	operations []string
+	errors     map[string]int
+	lockWaits  map[LockMode]int
+	documents  map[string]int
+}
+
+func newRecordingInstrumentation() *recordingInstrumentation {
+	return &recordingInstrumentation{
+		errors:    make(map[string]int),
+		lockWaits: make(map[LockMode]int),
+		documents: make(map[string]int),
+	}
+}
+
+func (r *recordingInstrumentation) ObserveOperation(collection string, operation string, duration time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.operations = append(r.operations, collection+"."+operation)
+	if err != nil {
+		r.errors[operation]++
+	}
+}
+
+func (r *recordingInstrumentation) ObserveLockWait(collection string, mode LockMode, wait time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lockWaits[mode]++
+}
+
+func (r *recordingInstrumentation) SetDocumentCount(collection string, count int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.documents[collection] = count
+}
+
+type InstrumentationTestSuite struct {
+	suite.Suite
+	db              *InMemoryDocBD
+	instrumentation *recordingInstrumentation
+}
+
+func TestInstrumentationTestSuite(t *testing.T) {
+	suite.Run(t, new(InstrumentationTestSuite))
+}
+
+func (suite *InstrumentationTestSuite) SetupTest() {
+	suite.db = NewInMemoryDocBD("test-db")
+	suite.instrumentation = newRecordingInstrumentation()
+}
+
+func (suite *InstrumentationTestSuite) TearDownTest() {
+	suite.db = nil
+	suite.instrumentation = nil
+}
+
+func (suite *InstrumentationTestSuite) TestOperationsAreReported() {
+	suite.db.SetInstrumentation(suite.instrumentation)
+	err := suite.db.CreateCollection("rates")
+	assert.Nil(suite.T(), err)
+	collection, _ := suite.db.GetCollection("rates")
+
+	assert.Nil(suite.T(), collection.InsertOne(Document{"_id": "1", "code": "USD"}))
+	assert.NotNil(suite.T(), collection.InsertOne(Document{"_id": "1", "code": "USD"}))
+	_, err = collection.FindOne("1")
+	assert.Nil(suite.T(), err)
+	collection.Find(map[string]interface{}{"code": "USD"})
+	assert.Nil(suite.T(), collection.UpdateOne("1", Document{"code": "EUR"}))
+	assert.Nil(suite.T(), collection.DeleteOne("1"))
+
+	assert.Equal(suite.T(), []string{
+		"rates.insert_one",
+		"rates.insert_one",
+		"rates.find_one",
+		"rates.find",
+		"rates.update_one",
+		"rates.delete_one",
+	}, suite.instrumentation.operations)
+	assert.Equal(suite.T(), 1, suite.instrumentation.errors[OperationInsertOne])
+	assert.Equal(suite.T(), 2, suite.instrumentation.lockWaits[LockRead])
+	assert.Equal(suite.T(), 4, suite.instrumentation.lockWaits[LockWrite])
+	assert.Equal(suite.T(), 0, suite.instrumentation.documents["rates"])
+}
+
+func (suite *InstrumentationTestSuite) TestExistingCollectionsReportDocumentCount() {
+	err := suite.db.CreateCollection("rates")
+	assert.Nil(suite.T(), err)
+	collection, _ := suite.db.GetCollection("rates")
+	assert.Nil(suite.T(), collection.InsertOne(Document{"_id": "1"}))
+	assert.Nil(suite.T(), collection.InsertOne(Document{"_id": "2"}))
+
+	suite.db.SetInstrumentation(suite.instrumentation)
+	assert.Equal(suite.T(), 2, suite.instrumentation.documents["rates"])
+
+	assert.Nil(suite.T(), collection.DeleteAll())
+	assert.Equal(suite.T(), 0, suite.instrumentation.documents["rates"])
+}
+
+func (suite *InstrumentationTestSuite) TestDropCollectionResetsDocumentCount() {
+	suite.db.SetInstrumentation(suite.instrumentation)
+	err := suite.db.CreateCollection("rates")
+	assert.Nil(suite.T(), err)
+	collection, _ := suite.db.GetCollection("rates")
+	assert.Nil(suite.T(), collection.InsertOne(Document{"_id": "1"}))
+
+	assert.Nil(suite.T(), suite.db.DropCollection("rates"))
+	assert.Equal(suite.T(), 0, suite.instrumentation.documents["rates"])
+}
+
+func (suite *InstrumentationTestSuite) TestNilInstrumentationDisablesReporting() {
+	collection := NewCollection()
+	collection.SetInstrumentation("rates", nil)
+	assert.Nil(suite.T(), collection.InsertOne(Document{"_id": "1"}))
+	_, instrumentation := collection.instrument()
+	assert.IsType(suite.T(), NoopInstrumentation{}, instrumentation)
+}
+
+func (suite *InstrumentationTestSuite) TestSetInstrumentationDuringOperations() {
+	collection := NewCollection()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				collection.FindAll()
+			}
+		}()
+	}
+	for i := 0; i < 50; i++ {
+		collection.SetInstrumentation("rates", suite.instrumentation)
+	}
+	wg.Wait()
+
+	name, _ := collection.instrument()
+	assert.Equal(suite.T(), "rates", name)
+}
+
+// lockCheckingInstrumentation records whether the collection was still locked when a measurement arrived.
+type lockCheckingInstrumentation struct {
+	NoopInstrumentation
+	collection *Collection
+	locked     int
+}
+
+func (l *lockCheckingInstrumentation) ObserveLockWait(collection string, mode LockMode, wait time.Duration) {
+	l.check()
+}
+
+func (l *lockCheckingInstrumentation) SetDocumentCount(collection string, count int) {
+	l.check()
+}
+
+func (l *lockCheckingInstrumentation) check() {
+	if !l.collection.mu.TryLock() {
+		l.locked++
+		return
+	}
+	l.collection.mu.Unlock()
+}
+
+func (suite *InstrumentationTestSuite) TestMeasurementsAreReportedAfterUnlock() {
+	collection := NewCollection()
+	instrumentation := &lockCheckingInstrumentation{collection: collection}
+	collection.SetInstrumentation("rates", instrumentation)
+
+	assert.Nil(suite.T(), collection.InsertOne(Document{"_id": "1"}))
+	_, err := collection.FindOne("1")
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), collection.DeleteOne("1"))
+
+	assert.Equal(suite.T(), 0, instrumentation.locked)
+}
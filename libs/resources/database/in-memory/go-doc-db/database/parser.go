@@ -0,0 +1,501 @@
+package database
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SyntaxError describes a malformed filter expression and where it was found.
+type SyntaxError struct {
+	// Offset is the byte offset of the offending token in the input.
+	Offset int
+	// Line is the 1-based line of the offending token.
+	Line int
+	// Column is the 1-based column (in runes) of the offending token.
+	Column int
+	// Msg describes what was wrong.
+	Msg string
+}
+
+// Error implements the error interface.
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenTrue
+	tokenFalse
+	tokenNull
+	tokenIn
+	tokenNot
+	tokenEq
+	tokenNe
+	tokenGt
+	tokenGte
+	tokenLt
+	tokenLte
+	tokenAnd
+	tokenOr
+	tokenBang
+	tokenLParen
+	tokenRParen
+	tokenLBracket
+	tokenRBracket
+	tokenComma
+)
+
+// token is a lexical unit of a filter expression.
+type token struct {
+	kind   tokenKind
+	text   string
+	offset int
+}
+
+// describe returns a human readable representation of the token for error messages.
+func (t token) describe() string {
+	if t.kind == tokenEOF {
+		return "end of input"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+var keywords = map[string]tokenKind{
+	"true":  tokenTrue,
+	"false": tokenFalse,
+	"null":  tokenNull,
+	"in":    tokenIn,
+	"not":   tokenNot,
+}
+
+var comparisonOperators = map[tokenKind]string{
+	tokenNe:  OpNe,
+	tokenGt:  OpGt,
+	tokenGte: OpGte,
+	tokenLt:  OpLt,
+	tokenLte: OpLte,
+}
+
+// ParseQuery parses a filter expression such as
+//
+//	code == "USD" && bid > 5.2 && codeIn in ["BRL", "EUR"]
+//
+// into the query representation accepted by Collection.Find.
+// An empty expression yields an empty query that matches every document.
+func ParseQuery(input string) (map[string]interface{}, error) {
+	p := &parser{input: input}
+	if err := p.tokenize(); err != nil {
+		return nil, err
+	}
+	if p.peek().kind == tokenEOF {
+		return map[string]interface{}{}, nil
+	}
+	query, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.errorAt(tok, fmt.Sprintf("unexpected %s", tok.describe()))
+	}
+	return query, nil
+}
+
+// parser is a recursive descent parser for filter expressions.
+type parser struct {
+	input  string
+	tokens []token
+	pos    int
+}
+
+// tokenize splits the input into tokens.
+func (p *parser) tokenize() error {
+	i := 0
+	for i < len(p.input) {
+		r, size := utf8.DecodeRuneInString(p.input[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+			continue
+		case r == '"':
+			end, err := p.scanString(i)
+			if err != nil {
+				return err
+			}
+			p.tokens = append(p.tokens, token{kind: tokenString, text: p.input[i:end], offset: i})
+			i = end
+			continue
+		case r == '-' || r == '+' || unicode.IsDigit(r):
+			end := p.scanNumber(i)
+			p.tokens = append(p.tokens, token{kind: tokenNumber, text: p.input[i:end], offset: i})
+			i = end
+			continue
+		case r == '_' || r == '$' || unicode.IsLetter(r):
+			end := p.scanIdent(i)
+			text := p.input[i:end]
+			kind, ok := keywords[text]
+			if !ok {
+				kind = tokenIdent
+			}
+			p.tokens = append(p.tokens, token{kind: kind, text: text, offset: i})
+			i = end
+			continue
+		}
+
+		kind, width := p.scanSymbol(i)
+		if width == 0 {
+			return p.errorAtOffset(i, fmt.Sprintf("unexpected character %q", r))
+		}
+		p.tokens = append(p.tokens, token{kind: kind, text: p.input[i : i+width], offset: i})
+		i += width
+	}
+	p.tokens = append(p.tokens, token{kind: tokenEOF, offset: len(p.input)})
+	return nil
+}
+
+// scanString returns the offset just past the closing quote of the string starting at start.
+func (p *parser) scanString(start int) (int, error) {
+	i := start + 1
+	for i < len(p.input) {
+		switch p.input[i] {
+		case '\\':
+			i += 2
+		case '"':
+			return i + 1, nil
+		default:
+			i++
+		}
+	}
+	return 0, p.errorAtOffset(start, "unterminated string")
+}
+
+// scanNumber returns the offset just past the number starting at start.
+func (p *parser) scanNumber(start int) int {
+	i := start
+	if p.input[i] == '-' || p.input[i] == '+' {
+		i++
+	}
+	for i < len(p.input) {
+		c := p.input[i]
+		if (c >= '0' && c <= '9') || c == '.' || c == 'e' || c == 'E' {
+			i++
+			continue
+		}
+		if (c == '-' || c == '+') && (p.input[i-1] == 'e' || p.input[i-1] == 'E') {
+			i++
+			continue
+		}
+		break
+	}
+	return i
+}
+
+// scanIdent returns the offset just past the identifier starting at start.
+// Identifiers may contain dots to address nested fields.
+func (p *parser) scanIdent(start int) int {
+	i := start
+	for i < len(p.input) {
+		r, size := utf8.DecodeRuneInString(p.input[i:])
+		if r != '_' && r != '$' && r != '.' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			break
+		}
+		i += size
+	}
+	return i
+}
+
+// scanSymbol recognizes operators and punctuation at the given offset.
+func (p *parser) scanSymbol(i int) (tokenKind, int) {
+	rest := p.input[i:]
+	twoChar := map[string]tokenKind{
+		"==": tokenEq,
+		"!=": tokenNe,
+		">=": tokenGte,
+		"<=": tokenLte,
+		"&&": tokenAnd,
+		"||": tokenOr,
+	}
+	if len(rest) >= 2 {
+		if kind, ok := twoChar[rest[:2]]; ok {
+			return kind, 2
+		}
+	}
+	switch rest[0] {
+	case '>':
+		return tokenGt, 1
+	case '<':
+		return tokenLt, 1
+	case '!':
+		return tokenBang, 1
+	case '(':
+		return tokenLParen, 1
+	case ')':
+		return tokenRParen, 1
+	case '[':
+		return tokenLBracket, 1
+	case ']':
+		return tokenRBracket, 1
+	case ',':
+		return tokenComma, 1
+	}
+	return tokenEOF, 0
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// expect consumes the next token if it has the given kind, otherwise it returns a syntax error.
+func (p *parser) expect(kind tokenKind, what string) (token, error) {
+	tok := p.next()
+	if tok.kind != kind {
+		return tok, p.errorAt(tok, fmt.Sprintf("expected %s, found %s", what, tok.describe()))
+	}
+	return tok, nil
+}
+
+// parseOr parses: and ("||" and)*
+func (p *parser) parseOr() (map[string]interface{}, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenOr {
+		return left, nil
+	}
+	clauses := []interface{}{left}
+	for p.peek().kind == tokenOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, right)
+	}
+	return map[string]interface{}{OpOr: clauses}, nil
+}
+
+// parseAnd parses: unary ("&&" unary)*
+func (p *parser) parseAnd() (map[string]interface{}, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	clauses := []map[string]interface{}{left}
+	for p.peek().kind == tokenAnd {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, right)
+	}
+	return mergeAnd(clauses), nil
+}
+
+// parseUnary parses: "!" unary | primary
+func (p *parser) parseUnary() (map[string]interface{}, error) {
+	if p.peek().kind == tokenBang {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{OpNor: []interface{}{operand}}, nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary parses: "(" or ")" | comparison
+func (p *parser) parsePrimary() (map[string]interface{}, error) {
+	if p.peek().kind == tokenLParen {
+		p.next()
+		query, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenRParen, `")"`); err != nil {
+			return nil, err
+		}
+		return query, nil
+	}
+	return p.parseComparison()
+}
+
+// parseComparison parses: field operator value
+func (p *parser) parseComparison() (map[string]interface{}, error) {
+	fieldTok, err := p.expect(tokenIdent, "field name")
+	if err != nil {
+		return nil, err
+	}
+	for _, segment := range strings.Split(fieldTok.text, ".") {
+		if segment == "" {
+			return nil, p.errorAt(fieldTok, fmt.Sprintf("invalid field name %q", fieldTok.text))
+		}
+	}
+
+	opTok := p.next()
+	var condition interface{}
+	switch opTok.kind {
+	case tokenEq:
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			// A field equals null when it is missing or holds null.
+			return map[string]interface{}{OpOr: []interface{}{
+				map[string]interface{}{fieldTok.text: map[string]interface{}{OpExists: false}},
+				map[string]interface{}{fieldTok.text: nil},
+			}}, nil
+		}
+		condition = value
+	case tokenNe, tokenGt, tokenGte, tokenLt, tokenLte:
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		if value == nil && opTok.kind == tokenNe {
+			// A field differs from null when it exists and does not hold null.
+			condition = map[string]interface{}{OpExists: true, OpNe: nil}
+			break
+		}
+		condition = map[string]interface{}{comparisonOperators[opTok.kind]: value}
+	case tokenIn:
+		list, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		condition = map[string]interface{}{OpIn: list}
+	case tokenNot:
+		if _, err := p.expect(tokenIn, `"in"`); err != nil {
+			return nil, err
+		}
+		list, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		condition = map[string]interface{}{OpNin: list}
+	default:
+		return nil, p.errorAt(opTok, fmt.Sprintf("expected comparison operator after %q, found %s", fieldTok.text, opTok.describe()))
+	}
+
+	return map[string]interface{}{fieldTok.text: condition}, nil
+}
+
+// parseValue parses a literal or a list of literals.
+func (p *parser) parseValue() (interface{}, error) {
+	tok := p.peek()
+	switch tok.kind {
+	case tokenLBracket:
+		return p.parseList()
+	case tokenString:
+		p.next()
+		value, err := strconv.Unquote(tok.text)
+		if err != nil {
+			return nil, p.errorAt(tok, fmt.Sprintf("invalid string literal %s", tok.text))
+		}
+		return value, nil
+	case tokenNumber:
+		p.next()
+		value, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, p.errorAt(tok, fmt.Sprintf("invalid number %q", tok.text))
+		}
+		return value, nil
+	case tokenTrue:
+		p.next()
+		return true, nil
+	case tokenFalse:
+		p.next()
+		return false, nil
+	case tokenNull:
+		p.next()
+		return nil, nil
+	default:
+		p.next()
+		return nil, p.errorAt(tok, fmt.Sprintf("expected value, found %s", tok.describe()))
+	}
+}
+
+// parseList parses: "[" (value ("," value)*)? "]"
+func (p *parser) parseList() ([]interface{}, error) {
+	if _, err := p.expect(tokenLBracket, `"["`); err != nil {
+		return nil, err
+	}
+	list := []interface{}{}
+	if p.peek().kind == tokenRBracket {
+		p.next()
+		return list, nil
+	}
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, value)
+		tok := p.next()
+		if tok.kind == tokenRBracket {
+			return list, nil
+		}
+		if tok.kind != tokenComma {
+			return nil, p.errorAt(tok, fmt.Sprintf(`expected "," or "]", found %s`, tok.describe()))
+		}
+	}
+}
+
+// errorAt builds a SyntaxError pointing at the given token.
+func (p *parser) errorAt(tok token, msg string) *SyntaxError {
+	return p.errorAtOffset(tok.offset, msg)
+}
+
+// errorAtOffset builds a SyntaxError pointing at the given byte offset.
+func (p *parser) errorAtOffset(offset int, msg string) *SyntaxError {
+	line, column := 1, 1
+	for _, r := range p.input[:offset] {
+		if r == '\n' {
+			line++
+			column = 1
+			continue
+		}
+		column++
+	}
+	return &SyntaxError{Offset: offset, Line: line, Column: column, Msg: msg}
+}
+
+// mergeAnd combines conjunctive clauses into a single query map.
+// Clauses are merged into one map when their keys do not overlap, otherwise they are wrapped in $and.
+func mergeAnd(clauses []map[string]interface{}) map[string]interface{} {
+	if len(clauses) == 1 {
+		return clauses[0]
+	}
+	merged := make(map[string]interface{})
+	for _, clause := range clauses {
+		for key := range clause {
+			if _, ok := merged[key]; ok {
+				list := make([]interface{}, len(clauses))
+				for i, c := range clauses {
+					list[i] = c
+				}
+				return map[string]interface{}{OpAnd: list}
+			}
+			merged[key] = clause[key]
+		}
+	}
+	return merged
+}
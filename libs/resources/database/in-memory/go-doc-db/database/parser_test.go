@@ -0,0 +1,149 @@
+package database
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type QueryParserTestSuite struct {
+	suite.Suite
+	collection *Collection
+}
+
+func TestQueryParserTestSuite(t *testing.T) {
+	suite.Run(t, new(QueryParserTestSuite))
+}
+
+func (suite *QueryParserTestSuite) SetupTest() {
+	suite.collection = NewCollection()
+	documents := []Document{
+		{"_id": "1", "code": "USD", "codeIn": "BRL", "bid": 5.45, "active": true},
+		{"_id": "2", "code": "USD", "codeIn": "EUR", "bid": 0.92, "active": true},
+		{"_id": "3", "code": "EUR", "codeIn": "BRL", "bid": 6.01, "active": false},
+		{"_id": "4", "code": "USD", "codeIn": "JPY", "bid": 155, "meta": map[string]interface{}{"source": "api"}},
+	}
+	for _, document := range documents {
+		err := suite.collection.InsertOne(document)
+		assert.Nil(suite.T(), err)
+	}
+}
+
+func (suite *QueryParserTestSuite) findIDs(expression string) []string {
+	query, err := ParseQuery(expression)
+	assert.Nil(suite.T(), err)
+	ids := []string{}
+	for _, document := range suite.collection.Find(query) {
+		ids = append(ids, document["_id"].(string))
+	}
+	return ids
+}
+
+func (suite *QueryParserTestSuite) TestParseQueryMergesConjunction() {
+	query, err := ParseQuery(`code == "USD" && bid > 5.2 && codeIn in ["BRL","EUR"]`)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), map[string]interface{}{
+		"code":   "USD",
+		"bid":    map[string]interface{}{OpGt: 5.2},
+		"codeIn": map[string]interface{}{OpIn: []interface{}{"BRL", "EUR"}},
+	}, query)
+}
+
+func (suite *QueryParserTestSuite) TestParseQueryUsesAndOnRepeatedField() {
+	query, err := ParseQuery(`bid >= 1 && bid < 6`)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), map[string]interface{}{
+		OpAnd: []interface{}{
+			map[string]interface{}{"bid": map[string]interface{}{OpGte: 1.0}},
+			map[string]interface{}{"bid": map[string]interface{}{OpLt: 6.0}},
+		},
+	}, query)
+}
+
+func (suite *QueryParserTestSuite) TestParseQueryNestedField() {
+	query, err := ParseQuery(`meta.source == "api" && history.0.bid > 5`)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), map[string]interface{}{
+		"meta.source":   "api",
+		"history.0.bid": map[string]interface{}{OpGt: 5.0},
+	}, query)
+}
+
+func (suite *QueryParserTestSuite) TestParseQueryNullMatchesMissingFields() {
+	err := suite.collection.InsertOne(Document{"_id": "5", "code": "GBP", "meta": nil})
+	assert.Nil(suite.T(), err)
+
+	assert.ElementsMatch(suite.T(), []string{"1", "2", "3", "5"}, suite.findIDs(`meta == null`))
+	assert.ElementsMatch(suite.T(), []string{"4"}, suite.findIDs(`meta != null`))
+}
+
+func (suite *QueryParserTestSuite) TestParseQueryEmpty() {
+	query, err := ParseQuery("   ")
+	assert.Nil(suite.T(), err)
+	assert.Empty(suite.T(), query)
+	assert.Equal(suite.T(), 4, len(suite.collection.Find(query)))
+}
+
+func (suite *QueryParserTestSuite) TestFindWithParsedQueries() {
+	testCases := []struct {
+		expression string
+		expected   []string
+	}{
+		{`code == "USD" && bid > 5.2 && codeIn in ["BRL","EUR"]`, []string{"1"}},
+		{`code == "USD"`, []string{"1", "2", "4"}},
+		{`code != "USD"`, []string{"3"}},
+		{`bid >= 5.45 && bid <= 6.01`, []string{"1", "3"}},
+		{`bid == 155`, []string{"4"}},
+		{`codeIn not in ["BRL", "EUR"]`, []string{"4"}},
+		{`code == "EUR" || codeIn == "JPY"`, []string{"3", "4"}},
+		{`!(code == "USD")`, []string{"3"}},
+		{`(code == "EUR" || codeIn == "EUR") && active == true`, []string{"2"}},
+		{`active == false`, []string{"3"}},
+		{`meta.source == "api"`, []string{"4"}},
+		{`bid > "5"`, []string{}},
+		{`meta == null`, []string{"1", "2", "3"}},
+		{`meta != null`, []string{"4"}},
+		{`active == null`, []string{"4"}},
+		{`active == null || code == "EUR"`, []string{"3", "4"}},
+	}
+
+	for _, tc := range testCases {
+		suite.T().Run(tc.expression, func(t *testing.T) {
+			assert.ElementsMatch(t, tc.expected, suite.findIDs(tc.expression))
+		})
+	}
+}
+
+func (suite *QueryParserTestSuite) TestParseQuerySyntaxErrors() {
+	testCases := []struct {
+		expression string
+		line       int
+		column     int
+		message    string
+	}{
+		{`code == `, 1, 9, "expected value, found end of input"},
+		{`code = "USD"`, 1, 6, `unexpected character '='`},
+		{`code == "USD" &&`, 1, 17, "expected field name, found end of input"},
+		{`code "USD"`, 1, 6, `expected comparison operator after "code", found "\"USD\""`},
+		{`(code == "USD"`, 1, 15, `expected ")", found end of input`},
+		{`codeIn in "BRL"`, 1, 11, `expected "[", found "\"BRL\""`},
+		{`codeIn in ["BRL" "EUR"]`, 1, 18, `expected "," or "]", found "\"EUR\""`},
+		{`code == "USD"` + "\n" + `&& name == "Dollar`, 2, 12, "unterminated string"},
+		{`code == "USD" )`, 1, 15, `unexpected ")"`},
+	}
+
+	for _, tc := range testCases {
+		suite.T().Run(tc.expression, func(t *testing.T) {
+			query, err := ParseQuery(tc.expression)
+			assert.Nil(t, query)
+			var syntaxErr *SyntaxError
+			if assert.True(t, errors.As(err, &syntaxErr)) {
+				assert.Equal(t, tc.line, syntaxErr.Line)
+				assert.Equal(t, tc.column, syntaxErr.Column)
+				assert.Equal(t, tc.message, syntaxErr.Msg)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Stage transforms the documents flowing through a pipeline.
+// Stages must not modify the documents they receive.
+type Stage func(documents []Document) []Document
+
+// Pipeline is an ordered list of stages applied to the documents of a source collection.
+type Pipeline []Stage
+
+// Apply runs every stage of the pipeline in order over the given documents.
+func (p Pipeline) Apply(documents []Document) []Document {
+	for _, stage := range p {
+		documents = stage(documents)
+	}
+	return documents
+}
+
+// SortField describes a field to sort on and its direction.
+type SortField struct {
+	Field      string
+	Descending bool
+}
+
+// Asc returns a SortField ordering by field in ascending order.
+func Asc(field string) SortField {
+	return SortField{Field: field}
+}
+
+// Desc returns a SortField ordering by field in descending order.
+func Desc(field string) SortField {
+	return SortField{Field: field, Descending: true}
+}
+
+// Match returns a stage that keeps the documents matching the query.
+func Match(query map[string]interface{}) Stage {
+	return func(documents []Document) []Document {
+		matched := make([]Document, 0, len(documents))
+		for _, document := range documents {
+			if matchesQuery(document, query) {
+				matched = append(matched, document)
+			}
+		}
+		return matched
+	}
+}
+
+// Sort returns a stage that orders documents by the given fields. Fields may be dotted paths.
+// Documents missing a field sort before documents that have it.
+func Sort(fields ...SortField) Stage {
+	return func(documents []Document) []Document {
+		sorted := make([]Document, len(documents))
+		copy(sorted, documents)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			for _, field := range fields {
+				cmp := compareFieldValues(sorted[i], sorted[j], field.Field)
+				if cmp == 0 {
+					continue
+				}
+				if field.Descending {
+					return cmp > 0
+				}
+				return cmp < 0
+			}
+			return false
+		})
+		return sorted
+	}
+}
+
+// Skip returns a stage that drops the first n documents.
+func Skip(n int) Stage {
+	return func(documents []Document) []Document {
+		if n >= len(documents) {
+			return []Document{}
+		}
+		return documents[n:]
+	}
+}
+
+// Limit returns a stage that keeps at most n documents.
+func Limit(n int) Stage {
+	return func(documents []Document) []Document {
+		if n < len(documents) {
+			return documents[:n]
+		}
+		return documents
+	}
+}
+
+// Project returns a stage that keeps only the given fields, plus _id, of each document.
+func Project(fields ...string) Stage {
+	return func(documents []Document) []Document {
+		projected := make([]Document, 0, len(documents))
+		for _, document := range documents {
+			out := Document{"_id": document["_id"]}
+			for _, field := range fields {
+				if value, ok := document[field]; ok {
+					out[field] = value
+				}
+			}
+			projected = append(projected, out)
+		}
+		return projected
+	}
+}
+
+// DistinctOn returns a stage that keeps the first document for each distinct combination of the given fields.
+// Combined with Sort it selects, for example, the latest document per group.
+func DistinctOn(fields ...string) Stage {
+	return func(documents []Document) []Document {
+		seen := make(map[string]struct{})
+		distinct := make([]Document, 0, len(documents))
+		for _, document := range documents {
+			key := make([]interface{}, len(fields))
+			for i, field := range fields {
+				key[i], _ = lookupPath(document, field)
+			}
+			keyStr := fmt.Sprintf("%#v", key)
+			if _, ok := seen[keyStr]; ok {
+				continue
+			}
+			seen[keyStr] = struct{}{}
+			distinct = append(distinct, document)
+		}
+		return distinct
+	}
+}
+
+// compareFieldValues orders two documents by a single field, which may be a dotted path.
+func compareFieldValues(a, b Document, field string) int {
+	av, aok := lookupPath(a, field)
+	bv, bok := lookupPath(b, field)
+	switch {
+	case !aok && !bok:
+		return 0
+	case !aok:
+		return -1
+	case !bok:
+		return 1
+	}
+	if cmp, ok := compareValues(av, bv); ok {
+		return cmp
+	}
+	as, bs := fmt.Sprint(av), fmt.Sprint(bv)
+	switch {
+	case as < bs:
+		return -1
+	case as > bs:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,505 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Logical operators combine several sub-queries into one.
+const (
+	OpAnd = "$and"
+	OpOr  = "$or"
+	OpNor = "$nor"
+)
+
+// Comparison operators apply to the value of a single field.
+const (
+	OpEq     = "$eq"
+	OpNe     = "$ne"
+	OpGt     = "$gt"
+	OpGte    = "$gte"
+	OpLt     = "$lt"
+	OpLte    = "$lte"
+	OpIn     = "$in"
+	OpNin    = "$nin"
+	OpExists = "$exists"
+)
+
+// Array operators apply to fields holding arrays.
+const (
+	OpAll       = "$all"
+	OpSize      = "$size"
+	OpElemMatch = "$elemMatch"
+)
+
+// ErrNestedText is returned by ValidateQuery for a $text operator that is not at the top level of a query.
+var ErrNestedText = errors.New("$text is only supported at the top level of a query")
+
+// ValidateQuery checks the query for operators that cannot be evaluated where they are, such as a $text nested in
+// $and, $or or $nor, which would never match.
+func ValidateQuery(query map[string]interface{}) error {
+	for _, operator := range []string{OpAnd, OpOr, OpNor} {
+		for _, subQuery := range asQueryList(query[operator]) {
+			if _, ok := subQuery[OpText]; ok {
+				return fmt.Errorf("%w: found in %s", ErrNestedText, operator)
+			}
+			if err := ValidateQuery(subQuery); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// matchesQuery checks if a document matches the query criteria.
+func matchesQuery(document, query map[string]interface{}) bool {
+	for key, value := range query {
+		switch key {
+		case OpAnd:
+			for _, subQuery := range asQueryList(value) {
+				if !matchesQuery(document, subQuery) {
+					return false
+				}
+			}
+			continue
+		case OpOr:
+			matched := false
+			for _, subQuery := range asQueryList(value) {
+				if matchesQuery(document, subQuery) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return false
+			}
+			continue
+		case OpNor:
+			for _, subQuery := range asQueryList(value) {
+				if matchesQuery(document, subQuery) {
+					return false
+				}
+			}
+			continue
+		case OpText:
+			// $text is answered by the text index of a collection, see Collection.FindWithOptions. Nested in a logical
+			// operator it is rejected by ValidateQuery.
+			return false
+		}
+
+		values, exists := resolvePath(document, key)
+
+		// Operator maps such as {"$gt": 5} are evaluated against the field value.
+		if operators, ok := asOperatorMap(value); ok {
+			if !matchesOperators(values, exists, operators) {
+				return false
+			}
+			continue
+		}
+
+		if !exists {
+			return false
+		}
+
+		// If the value is a map, recurse into it.
+		if queryMap, ok := asMap(value); ok {
+			matched := false
+			for _, candidate := range expandArrays(values) {
+				if docMap, ok := asMap(candidate); ok && matchesQuery(docMap, queryMap) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return false
+			}
+		} else {
+			// Direct comparison for non-map values. A scalar matches any element of an array field.
+			if !anyValueEqual(expandArrays(values), value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// matchesOperators checks the values found at a field path against every operator in the map.
+// Unknown operators never match.
+func matchesOperators(values []interface{}, exists bool, operators map[string]interface{}) bool {
+	expanded := expandArrays(values)
+	for operator, operand := range operators {
+		switch operator {
+		case OpExists:
+			want, _ := operand.(bool)
+			if exists != want {
+				return false
+			}
+		case OpEq:
+			if !exists || !anyValueEqual(expanded, operand) {
+				return false
+			}
+		case OpNe:
+			if exists && anyValueEqual(expanded, operand) {
+				return false
+			}
+		case OpGt, OpGte, OpLt, OpLte:
+			if !exists || !anyValueCompares(expanded, operator, operand) {
+				return false
+			}
+		case OpIn:
+			if !exists || !anyValueIn(expanded, asList(operand)) {
+				return false
+			}
+		case OpNin:
+			if exists && anyValueIn(expanded, asList(operand)) {
+				return false
+			}
+		case OpAll:
+			required := asList(operand)
+			if !exists || len(required) == 0 {
+				return false
+			}
+			for _, item := range required {
+				if !anyValueEqual(expanded, item) {
+					return false
+				}
+			}
+		case OpSize:
+			if !exists || !anyArrayOfSize(values, operand) {
+				return false
+			}
+		case OpElemMatch:
+			if !exists || !anyElementMatches(values, operand) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// resolvePath returns the values found at a dotted path such as "history.0.bid".
+// Numeric segments index into arrays, other segments applied to an array are resolved on each of its elements,
+// so a path can yield several values. The boolean reports whether any value was found.
+func resolvePath(document map[string]interface{}, path string) ([]interface{}, bool) {
+	if value, ok := document[path]; ok {
+		return []interface{}{value}, true
+	}
+	if !strings.Contains(path, ".") {
+		return nil, false
+	}
+	return resolveSegments(document, strings.Split(path, "."))
+}
+
+func resolveSegments(value interface{}, segments []string) ([]interface{}, bool) {
+	if len(segments) == 0 {
+		return []interface{}{value}, true
+	}
+	segment, rest := segments[0], segments[1:]
+	if m, ok := asMap(value); ok {
+		child, ok := m[segment]
+		if !ok {
+			return nil, false
+		}
+		return resolveSegments(child, rest)
+	}
+	list, ok := asArray(value)
+	if !ok {
+		return nil, false
+	}
+	if position, err := strconv.Atoi(segment); err == nil {
+		if position < 0 || position >= len(list) {
+			return nil, false
+		}
+		return resolveSegments(list[position], rest)
+	}
+	var values []interface{}
+	for _, element := range list {
+		if found, ok := resolveSegments(element, segments); ok {
+			values = append(values, found...)
+		}
+	}
+	return values, len(values) > 0
+}
+
+// lookupPath returns the first value found at a dotted path.
+func lookupPath(document map[string]interface{}, path string) (interface{}, bool) {
+	values, ok := resolvePath(document, path)
+	if !ok {
+		return nil, false
+	}
+	return values[0], true
+}
+
+// expandArrays returns the values followed by the elements of those values that are arrays.
+func expandArrays(values []interface{}) []interface{} {
+	expanded := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		expanded = append(expanded, value)
+		if list, ok := asArray(value); ok {
+			expanded = append(expanded, list...)
+		}
+	}
+	return expanded
+}
+
+func anyValueEqual(values []interface{}, target interface{}) bool {
+	for _, value := range values {
+		if valuesEqual(value, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func anyValueIn(values []interface{}, list []interface{}) bool {
+	for _, value := range values {
+		if containsValue(list, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func anyValueCompares(values []interface{}, operator string, operand interface{}) bool {
+	for _, value := range values {
+		cmp, ok := compareValues(value, operand)
+		if !ok {
+			continue
+		}
+		switch {
+		case operator == OpGt && cmp > 0,
+			operator == OpGte && cmp >= 0,
+			operator == OpLt && cmp < 0,
+			operator == OpLte && cmp <= 0:
+			return true
+		}
+	}
+	return false
+}
+
+func anyArrayOfSize(values []interface{}, operand interface{}) bool {
+	size, ok := toFloat64(operand)
+	if !ok {
+		return false
+	}
+	for _, value := range values {
+		if list, ok := asArray(value); ok && float64(len(list)) == size {
+			return true
+		}
+	}
+	return false
+}
+
+// anyElementMatches reports whether an array value holds an element matching the $elemMatch operand.
+// Operator maps apply to scalar elements, other maps are queries on document elements.
+func anyElementMatches(values []interface{}, operand interface{}) bool {
+	subQuery, ok := asMap(operand)
+	if !ok {
+		return false
+	}
+	operators, isOperatorMap := asOperatorMap(subQuery)
+	for _, value := range values {
+		list, ok := asArray(value)
+		if !ok {
+			continue
+		}
+		for _, element := range list {
+			if isOperatorMap {
+				if _, isDoc := asMap(element); !isDoc && matchesOperators([]interface{}{element}, true, operators) {
+					return true
+				}
+				continue
+			}
+			if elementDoc, ok := asMap(element); ok && matchesQuery(elementDoc, subQuery) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// asArray returns the value as a slice of interfaces when it is an array.
+func asArray(value interface{}) ([]interface{}, bool) {
+	if value == nil {
+		return nil, false
+	}
+	kind := reflect.TypeOf(value).Kind()
+	if kind != reflect.Slice && kind != reflect.Array {
+		return nil, false
+	}
+	return asList(value), true
+}
+
+// asOperatorMap returns the value as a map when all of its keys are operators.
+func asOperatorMap(value interface{}) (map[string]interface{}, bool) {
+	m, ok := asMap(value)
+	if !ok || len(m) == 0 {
+		return nil, false
+	}
+	for key := range m {
+		if !strings.HasPrefix(key, "$") {
+			return nil, false
+		}
+	}
+	return m, true
+}
+
+// asMap returns the value as a plain map, accepting both maps and Documents.
+func asMap(value interface{}) (map[string]interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v, true
+	case Document:
+		return map[string]interface{}(v), true
+	default:
+		return nil, false
+	}
+}
+
+// asList returns the value as a slice of interfaces, or nil if it is not a slice.
+func asList(value interface{}) []interface{} {
+	if list, ok := value.([]interface{}); ok {
+		return list
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil
+	}
+	list := make([]interface{}, rv.Len())
+	for i := range list {
+		list[i] = rv.Index(i).Interface()
+	}
+	return list
+}
+
+// asQueryList returns the operand of a logical operator as a list of sub-queries.
+func asQueryList(value interface{}) []map[string]interface{} {
+	if queries, ok := value.([]map[string]interface{}); ok {
+		return queries
+	}
+	items := asList(value)
+	queries := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		if query, ok := asMap(item); ok {
+			queries = append(queries, query)
+		}
+	}
+	return queries
+}
+
+// containsValue reports whether the list holds a value equal to the given one.
+func containsValue(list []interface{}, value interface{}) bool {
+	for _, item := range list {
+		if valuesEqual(value, item) {
+			return true
+		}
+	}
+	return false
+}
+
+// valuesEqual compares two values, treating all numeric types as comparable.
+func valuesEqual(a, b interface{}) bool {
+	if af, ok := toFloat64(a); ok {
+		bf, ok := toFloat64(b)
+		return ok && af == bf
+	}
+	if cmp, ok := compareTimes(a, b); ok {
+		return cmp == 0
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// compareValues orders two values of compatible types.
+// It returns -1, 0 or 1 and false when the values cannot be ordered.
+func compareValues(a, b interface{}) (int, bool) {
+	if af, ok := toFloat64(a); ok {
+		bf, ok := toFloat64(b)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case af < bf:
+			return -1, true
+		case af > bf:
+			return 1, true
+		default:
+			return 0, true
+		}
+	}
+	if cmp, ok := compareTimes(a, b); ok {
+		return cmp, true
+	}
+	as, aok := a.(string)
+	bs, bok := b.(string)
+	if aok && bok {
+		return strings.Compare(as, bs), true
+	}
+	return 0, false
+}
+
+// compareTimes orders two time values. A string operand is parsed as RFC 3339.
+func compareTimes(a, b interface{}) (int, bool) {
+	at, aok := toTime(a)
+	bt, bok := toTime(b)
+	if !aok || !bok {
+		return 0, false
+	}
+	// Only compare strings as times when at least one side is a real time.Time.
+	_, aIsTime := a.(time.Time)
+	_, bIsTime := b.(time.Time)
+	if !aIsTime && !bIsTime {
+		return 0, false
+	}
+	return at.Compare(bt), true
+}
+
+// toTime converts a time.Time or an RFC 3339 string to a time.Time.
+func toTime(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case string:
+		t, err := time.Parse(time.RFC3339, v)
+		return t, err == nil
+	default:
+		return time.Time{}, false
+	}
+}
+
+// toFloat64 converts any Go numeric value to a float64.
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
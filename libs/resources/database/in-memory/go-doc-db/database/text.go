@@ -0,0 +1,62 @@
+package database
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// letterFolding maps the lowercase Latin letters that have no decomposition to their unaccented form.
+var letterFolding = map[rune]string{
+	'ð': "d", 'đ': "d",
+	'ħ': "h",
+	'ı': "i",
+	'ł': "l",
+	'ø': "o",
+	'ß': "ss",
+	'þ': "th",
+	'æ': "ae", 'œ': "oe",
+}
+
+// stopWords are frequent Portuguese and English words that are not indexed.
+var stopWords = map[string]struct{}{
+	"a": {}, "o": {}, "e": {}, "as": {}, "os": {}, "de": {}, "da": {}, "do": {}, "das": {}, "dos": {},
+	"em": {}, "na": {}, "no": {}, "um": {}, "uma": {}, "para": {}, "por": {}, "com": {},
+	"an": {}, "and": {}, "of": {}, "the": {}, "to": {}, "in": {}, "on": {}, "for": {},
+}
+
+// NormalizeText lowercases the text and removes accents, so "Dólar" becomes "dolar". The text is decomposed to the
+// Unicode NFD form first, so precomposed and combining accents are both removed.
+func NormalizeText(text string) string {
+	var builder strings.Builder
+	builder.Grow(len(text))
+	for _, r := range norm.NFD.String(text) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		r = unicode.ToLower(r)
+		if folded, ok := letterFolding[r]; ok {
+			builder.WriteString(folded)
+			continue
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
+
+// Tokenize splits the text into normalized terms, dropping punctuation and stop words.
+// "Dólar Americano/Real Brasileiro" yields [dolar americano real brasileiro].
+func Tokenize(text string) []string {
+	fields := strings.FieldsFunc(NormalizeText(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	terms := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if _, ok := stopWords[field]; ok {
+			continue
+		}
+		terms = append(terms, field)
+	}
+	return terms
+}
@@ -0,0 +1,146 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type TextSearchTestSuite struct {
+	suite.Suite
+	collection *Collection
+}
+
+func TestTextSearchTestSuite(t *testing.T) {
+	suite.Run(t, new(TextSearchTestSuite))
+}
+
+func (suite *TextSearchTestSuite) SetupTest() {
+	suite.collection = NewCollection()
+	documents := []Document{
+		{"_id": "USD-BRL", "code": "USD", "name": "Dólar Americano/Real Brasileiro"},
+		{"_id": "EUR-BRL", "code": "EUR", "name": "Euro/Real Brasileiro"},
+		{"_id": "USD-EUR", "code": "USD", "name": "Dólar Americano/Euro"},
+		{"_id": "CAD-BRL", "code": "CAD", "name": "Dólar Canadense/Real Brasileiro"},
+	}
+	for _, document := range documents {
+		err := suite.collection.InsertOne(document)
+		assert.Nil(suite.T(), err)
+	}
+	err := suite.collection.CreateIndex(IndexModel{Name: "name_text", Kind: IndexKindText, Fields: []string{"name"}})
+	assert.Nil(suite.T(), err)
+}
+
+func (suite *TextSearchTestSuite) ids(documents []Document) []string {
+	ids := make([]string, 0, len(documents))
+	for _, document := range documents {
+		ids = append(ids, document["_id"].(string))
+	}
+	return ids
+}
+
+func (suite *TextSearchTestSuite) TestNormalizeText() {
+	assert.Equal(suite.T(), "dolar americano/real brasileiro", NormalizeText("Dólar Americano/Real Brasileiro"))
+	assert.Equal(suite.T(), "acao cambio coracao", NormalizeText("Ação Câmbio CORAÇÃO"))
+	assert.Equal(suite.T(), "dolar", NormalizeText("Do\u0301lar"), "combining accents are removed")
+	assert.Equal(suite.T(), "zloty krone strasse", NormalizeText("Złoty Krøne Straße"))
+}
+
+func (suite *TextSearchTestSuite) TestTokenize() {
+	assert.Equal(suite.T(), []string{"dolar", "americano", "real", "brasileiro"}, Tokenize("Dólar Americano/Real Brasileiro"))
+	assert.Equal(suite.T(), []string{"peso", "argentino"}, Tokenize("Peso da Argentino"))
+	assert.Empty(suite.T(), Tokenize(" / - "))
+}
+
+func (suite *TextSearchTestSuite) TestTextSearchFoldsAccentsAndCase() {
+	documents := suite.collection.Find(map[string]interface{}{OpText: map[string]interface{}{OpSearch: "DOLAR"}})
+	assert.ElementsMatch(suite.T(), []string{"USD-BRL", "USD-EUR", "CAD-BRL"}, suite.ids(documents))
+}
+
+func (suite *TextSearchTestSuite) TestTextSearchMatchesDecomposedText() {
+	err := suite.collection.InsertOne(Document{"_id": "ARS-BRL", "code": "ARS", "name": "Do\u0301lar Argentino"})
+	assert.Nil(suite.T(), err)
+
+	documents := suite.collection.Find(map[string]interface{}{OpText: map[string]interface{}{OpSearch: "dólar argentino"}})
+	assert.Contains(suite.T(), suite.ids(documents), "ARS-BRL")
+}
+
+func (suite *TextSearchTestSuite) TestValidateQueryRejectsNestedText() {
+	text := map[string]interface{}{OpText: map[string]interface{}{OpSearch: "dolar"}}
+	for _, operator := range []string{OpAnd, OpOr, OpNor} {
+		err := ValidateQuery(map[string]interface{}{operator: []interface{}{text, map[string]interface{}{"code": "USD"}}})
+		assert.ErrorIs(suite.T(), err, ErrNestedText, operator)
+	}
+	err := ValidateQuery(map[string]interface{}{OpOr: []interface{}{map[string]interface{}{OpAnd: []interface{}{text}}}})
+	assert.ErrorIs(suite.T(), err, ErrNestedText)
+	assert.Nil(suite.T(), ValidateQuery(map[string]interface{}{OpText: "dolar", OpOr: []interface{}{map[string]interface{}{"code": "USD"}}}))
+}
+
+func (suite *TextSearchTestSuite) TestTextSearchSortedByScore() {
+	documents := suite.collection.FindWithOptions(
+		map[string]interface{}{OpText: "dólar americano"},
+		FindOptions{Sort: []SortField{Desc(TextScoreField)}, IncludeTextScore: true},
+	)
+	assert.Equal(suite.T(), 3, len(documents))
+	// Both USD pairs match two terms and rank above the Canadian dollar.
+	assert.ElementsMatch(suite.T(), []string{"USD-BRL", "USD-EUR"}, suite.ids(documents[:2]))
+	assert.Equal(suite.T(), "CAD-BRL", documents[2]["_id"])
+	assert.Greater(suite.T(), documents[0][TextScoreField].(float64), documents[2][TextScoreField].(float64))
+
+	// Scores are only added to copies of the stored documents.
+	stored, err := suite.collection.FindOne("CAD-BRL")
+	assert.Nil(suite.T(), err)
+	assert.NotContains(suite.T(), stored, TextScoreField)
+}
+
+func (suite *TextSearchTestSuite) TestTextSearchCombinedWithFilterAndLimit() {
+	documents := suite.collection.FindWithOptions(
+		map[string]interface{}{OpText: "real", "code": map[string]interface{}{OpNe: "USD"}},
+		FindOptions{Sort: []SortField{Asc("code")}, Limit: 1},
+	)
+	assert.Equal(suite.T(), []string{"CAD-BRL"}, suite.ids(documents))
+}
+
+func (suite *TextSearchTestSuite) TestIndexFollowsWrites() {
+	err := suite.collection.InsertOne(Document{"_id": "ARS-BRL", "name": "Peso Argentino/Real Brasileiro"})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 1, len(suite.collection.Find(map[string]interface{}{OpText: "argentino"})))
+
+	err = suite.collection.UpdateOne("ARS-BRL", Document{"name": "Peso Chileno/Real Brasileiro"})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 0, len(suite.collection.Find(map[string]interface{}{OpText: "argentino"})))
+	assert.Equal(suite.T(), 1, len(suite.collection.Find(map[string]interface{}{OpText: "chileno"})))
+
+	err = suite.collection.DeleteOne("ARS-BRL")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 0, len(suite.collection.Find(map[string]interface{}{OpText: "chileno"})))
+
+	err = suite.collection.DeleteAll()
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 0, len(suite.collection.Find(map[string]interface{}{OpText: "real"})))
+}
+
+func (suite *TextSearchTestSuite) TestTextSearchWithoutIndexMatchesNothing() {
+	err := suite.collection.DropIndex("name_text")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 0, len(suite.collection.Find(map[string]interface{}{OpText: "dolar"})))
+	assert.Empty(suite.T(), suite.collection.ListIndexes())
+}
+
+func (suite *TextSearchTestSuite) TestCreateIndexErrors() {
+	err := suite.collection.CreateIndex(IndexModel{Name: "other", Kind: IndexKindText, Fields: []string{"code"}})
+	assert.Equal(suite.T(), errTextIndexExists, err)
+
+	err = suite.collection.CreateIndex(IndexModel{Name: "name_text", Kind: IndexKindText, Fields: []string{"name"}})
+	assert.Equal(suite.T(), errIndexExists, err)
+
+	err = suite.collection.CreateIndex(IndexModel{Name: "hash", Kind: "hash", Fields: []string{"code"}})
+	assert.Equal(suite.T(), errUnsupportedIndex, err)
+
+	err = suite.collection.CreateIndex(IndexModel{Name: "empty", Kind: IndexKindText})
+	assert.Equal(suite.T(), errIndexFieldRequired, err)
+
+	err = suite.collection.DropIndex("missing")
+	assert.Equal(suite.T(), errIndexNotFound, err)
+}
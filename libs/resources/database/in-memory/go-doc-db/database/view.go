@@ -0,0 +1,104 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrReadOnlyView is returned, wrapped in a *ViewWriteError, by every write on a view.
+var ErrReadOnlyView = errors.New("view is read-only")
+
+// ViewWriteError reports an attempt to modify a view.
+type ViewWriteError struct {
+	View      string
+	Operation string
+}
+
+// Error implements the error interface.
+func (e *ViewWriteError) Error() string {
+	return fmt.Sprintf("%s on view %s: %v", e.Operation, e.View, ErrReadOnlyView)
+}
+
+// Unwrap allows errors.Is(err, ErrReadOnlyView).
+func (e *ViewWriteError) Unwrap() error {
+	return ErrReadOnlyView
+}
+
+// DocumentStore is the set of operations shared by collections and views.
+type DocumentStore interface {
+	InsertOne(document Document) error
+	FindOne(id string) (Document, error)
+	FindAll() []Document
+	Find(query map[string]interface{}) []Document
+	DeleteOne(id string) error
+	UpdateOne(id string, update Document) error
+	DeleteAll() error
+}
+
+// View is a read-only collection computed lazily by running a pipeline over a source collection or view.
+type View struct {
+	name     string
+	source   string
+	pipeline Pipeline
+	db       *InMemoryDocBD
+}
+
+// Name returns the name of the view.
+func (v *View) Name() string {
+	return v.name
+}
+
+// Source returns the name of the collection or view the view reads from.
+func (v *View) Source() string {
+	return v.source
+}
+
+// evaluate runs the pipeline over the current documents of the source.
+// A source that does not exist yet yields an empty view.
+func (v *View) evaluate() []Document {
+	source, err := v.db.GetStore(v.source)
+	if err != nil {
+		return []Document{}
+	}
+	return v.pipeline.Apply(source.FindAll())
+}
+
+// FindOne finds and returns a single document of the view by its ID.
+func (v *View) FindOne(id string) (Document, error) {
+	for _, document := range v.evaluate() {
+		if documentID, ok := document["_id"].(string); ok && documentID == id {
+			return document, nil
+		}
+	}
+	return nil, ErrDocumentNotFound
+}
+
+// FindAll returns all documents of the view.
+func (v *View) FindAll() []Document {
+	return v.evaluate()
+}
+
+// Find searches the documents of the view matching a given query.
+func (v *View) Find(query map[string]interface{}) []Document {
+	return Match(query)(v.evaluate())
+}
+
+// InsertOne always fails because views are read-only.
+func (v *View) InsertOne(document Document) error {
+	return &ViewWriteError{View: v.name, Operation: "insert"}
+}
+
+// DeleteOne always fails because views are read-only.
+func (v *View) DeleteOne(id string) error {
+	return &ViewWriteError{View: v.name, Operation: "delete"}
+}
+
+// UpdateOne always fails because views are read-only.
+func (v *View) UpdateOne(id string, update Document) error {
+	return &ViewWriteError{View: v.name, Operation: "update"}
+}
+
+// DeleteAll always fails because views are read-only.
+func (v *View) DeleteAll() error {
+	return &ViewWriteError{View: v.name, Operation: "delete"}
+}
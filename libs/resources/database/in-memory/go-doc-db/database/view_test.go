@@ -0,0 +1,178 @@
+package database
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ViewTestSuite struct {
+	suite.Suite
+	db     *InMemoryDocBD
+	source *Collection
+}
+
+func TestViewTestSuite(t *testing.T) {
+	suite.Run(t, new(ViewTestSuite))
+}
+
+func (suite *ViewTestSuite) SetupTest() {
+	suite.db = NewInMemoryDocBD("test-db")
+	err := suite.db.CreateCollection("currency-info")
+	assert.Nil(suite.T(), err)
+	suite.source, err = suite.db.GetCollection("currency-info")
+	assert.Nil(suite.T(), err)
+
+	documents := []Document{
+		{"_id": "1", "code": "USD", "codeIn": "BRL", "bid": 5.40, "timestamp": int64(100)},
+		{"_id": "2", "code": "USD", "codeIn": "BRL", "bid": 5.45, "timestamp": int64(200)},
+		{"_id": "3", "code": "EUR", "codeIn": "BRL", "bid": 6.01, "timestamp": int64(150)},
+		{"_id": "4", "code": "EUR", "codeIn": "BRL", "bid": 5.98, "timestamp": int64(50)},
+	}
+	for _, document := range documents {
+		err := suite.source.InsertOne(document)
+		assert.Nil(suite.T(), err)
+	}
+
+	err = suite.db.CreateView("latest-quotes", "currency-info", Pipeline{
+		Sort(Desc("timestamp")),
+		DistinctOn("code", "codeIn"),
+	})
+	assert.Nil(suite.T(), err)
+}
+
+func (suite *ViewTestSuite) TestLatestQuotePerPair() {
+	view, err := suite.db.GetView("latest-quotes")
+	assert.Nil(suite.T(), err)
+
+	documents := view.FindAll()
+	ids := []string{}
+	for _, document := range documents {
+		ids = append(ids, document["_id"].(string))
+	}
+	assert.Equal(suite.T(), []string{"2", "3"}, ids)
+}
+
+func (suite *ViewTestSuite) TestViewIsEvaluatedLazily() {
+	err := suite.source.InsertOne(Document{"_id": "5", "code": "USD", "codeIn": "BRL", "bid": 5.50, "timestamp": int64(300)})
+	assert.Nil(suite.T(), err)
+
+	view, err := suite.db.GetView("latest-quotes")
+	assert.Nil(suite.T(), err)
+	documents := view.Find(map[string]interface{}{"code": "USD"})
+	assert.Equal(suite.T(), 1, len(documents))
+	assert.Equal(suite.T(), "5", documents[0]["_id"])
+}
+
+func (suite *ViewTestSuite) TestViewFindOne() {
+	view, err := suite.db.GetView("latest-quotes")
+	assert.Nil(suite.T(), err)
+
+	document, err := view.FindOne("3")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "EUR", document["code"])
+
+	// Document 1 exists in the source but is not part of the view.
+	_, err = view.FindOne("1")
+	assert.NotNil(suite.T(), err)
+}
+
+func (suite *ViewTestSuite) TestViewWritesReturnTypedError() {
+	store, err := suite.db.GetStore("latest-quotes")
+	assert.Nil(suite.T(), err)
+
+	writes := map[string]error{
+		"insert": store.InsertOne(Document{"_id": "9"}),
+		"update": store.UpdateOne("2", Document{"bid": 1.0}),
+		"delete": store.DeleteOne("2"),
+	}
+	for operation, err := range writes {
+		var viewErr *ViewWriteError
+		assert.True(suite.T(), errors.As(err, &viewErr), operation)
+		assert.Equal(suite.T(), "latest-quotes", viewErr.View)
+		assert.Equal(suite.T(), operation, viewErr.Operation)
+		assert.ErrorIs(suite.T(), err, ErrReadOnlyView)
+	}
+	assert.ErrorIs(suite.T(), store.DeleteAll(), ErrReadOnlyView)
+	assert.Equal(suite.T(), 4, len(suite.source.FindAll()))
+}
+
+func (suite *ViewTestSuite) TestViewsAreListedDistinctly() {
+	assert.Equal(suite.T(), []string{"currency-info"}, suite.db.ListCollections())
+	assert.Equal(suite.T(), []string{"latest-quotes"}, suite.db.ListViews())
+
+	infos := suite.db.ListCollectionInfo()
+	assert.Equal(suite.T(), []CollectionInfo{
+		{Name: "currency-info", Kind: KindCollection},
+		{Name: "latest-quotes", Kind: KindView},
+	}, infos)
+	for _, info := range infos {
+		_, err := suite.db.GetStore(info.Name)
+		assert.Nil(suite.T(), err, info.Name)
+	}
+}
+
+func (suite *ViewTestSuite) TestDumpSkipsViews() {
+	var dump bytes.Buffer
+	assert.Nil(suite.T(), suite.db.Dump(&dump))
+
+	loaded, err := LoadDump(&dump)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []string{"currency-info"}, loaded.ListCollections())
+	assert.Empty(suite.T(), loaded.ListViews())
+}
+
+func (suite *ViewTestSuite) TestViewOverView() {
+	err := suite.db.CreateView("latest-usd", "latest-quotes", Pipeline{
+		Match(map[string]interface{}{"code": "USD"}),
+		Project("bid"),
+	})
+	assert.Nil(suite.T(), err)
+
+	view, err := suite.db.GetView("latest-usd")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []Document{{"_id": "2", "bid": 5.45}}, view.FindAll())
+}
+
+func (suite *ViewTestSuite) TestCreateViewErrors() {
+	err := suite.db.CreateView("latest-quotes", "currency-info", nil)
+	assert.NotNil(suite.T(), err)
+
+	err = suite.db.CreateView("currency-info", "latest-quotes", nil)
+	assert.NotNil(suite.T(), err)
+
+	err = suite.db.CreateView("loop", "loop", nil)
+	assert.NotNil(suite.T(), err)
+
+	err = suite.db.CreateCollection("latest-quotes")
+	assert.NotNil(suite.T(), err)
+}
+
+func (suite *ViewTestSuite) TestViewOverMissingSourceIsEmpty() {
+	err := suite.db.CreateView("empty", "missing", nil)
+	assert.Nil(suite.T(), err)
+
+	view, err := suite.db.GetView("empty")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 0, len(view.FindAll()))
+}
+
+func (suite *ViewTestSuite) TestDropView() {
+	err := suite.db.DropView("latest-quotes")
+	assert.Nil(suite.T(), err)
+	_, err = suite.db.GetView("latest-quotes")
+	assert.NotNil(suite.T(), err)
+
+	err = suite.db.DropView("latest-quotes")
+	assert.NotNil(suite.T(), err)
+}
+
+func (suite *ViewTestSuite) TestPipelineSkipAndLimit() {
+	documents := Pipeline{Sort(Asc("timestamp")), Skip(1), Limit(2)}.Apply(suite.source.FindAll())
+	assert.Equal(suite.T(), 2, len(documents))
+	assert.Equal(suite.T(), "1", documents[0]["_id"])
+	assert.Equal(suite.T(), "3", documents[1]["_id"])
+}
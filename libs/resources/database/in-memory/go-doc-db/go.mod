@@ -1,3 +1,5 @@
 module libs/resources/database/in-memory/go-doc-db
 
 go 1.22
+
+require golang.org/x/text v0.22.0
@@ -0,0 +1,269 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"libs/resources/database/in-memory/go-doc-db/database"
+)
+
+// ContentType is the content type of the Prometheus text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets are the upper bounds, in seconds, of the latency histograms.
+// They are finer than the usual Prometheus defaults because in-memory operations take microseconds.
+var DefaultBuckets = []float64{0.00001, 0.00005, 0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1}
+
+// operationKey identifies an operation series.
+type operationKey struct {
+	collection string
+	operation  string
+}
+
+// lockKey identifies a lock wait series.
+type lockKey struct {
+	collection string
+	mode       database.LockMode
+}
+
+// histogram is a cumulative Prometheus histogram.
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// Collector is a database.Instrumentation that aggregates measurements and exposes them in the Prometheus text format.
+type Collector struct {
+	buckets    []float64
+	operations map[operationKey]uint64
+	errors     map[operationKey]uint64
+	latencies  map[operationKey]*histogram
+	lockWaits  map[lockKey]*histogram
+	documents  map[string]int
+	mu         sync.Mutex
+}
+
+// NewCollector creates and returns a new Collector. Without buckets, DefaultBuckets is used.
+func NewCollector(buckets ...float64) *Collector {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	sorted := make([]float64, len(buckets))
+	copy(sorted, buckets)
+	sort.Float64s(sorted)
+	return &Collector{
+		buckets:    sorted,
+		operations: make(map[operationKey]uint64),
+		errors:     make(map[operationKey]uint64),
+		latencies:  make(map[operationKey]*histogram),
+		lockWaits:  make(map[lockKey]*histogram),
+		documents:  make(map[string]int),
+	}
+}
+
+// ObserveOperation counts the operation and records its latency.
+func (c *Collector) ObserveOperation(collection string, operation string, duration time.Duration, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := operationKey{collection: collection, operation: operation}
+	c.operations[key]++
+	if err != nil {
+		c.errors[key]++
+	}
+	if c.latencies[key] == nil {
+		c.latencies[key] = c.newHistogram()
+	}
+	c.observe(c.latencies[key], duration)
+}
+
+// ObserveLockWait records the time spent waiting for a collection lock.
+func (c *Collector) ObserveLockWait(collection string, mode database.LockMode, wait time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := lockKey{collection: collection, mode: mode}
+	if c.lockWaits[key] == nil {
+		c.lockWaits[key] = c.newHistogram()
+	}
+	c.observe(c.lockWaits[key], wait)
+}
+
+// SetDocumentCount records the number of documents in a collection.
+func (c *Collector) SetDocumentCount(collection string, count int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.documents[collection] = count
+}
+
+// ServeHTTP writes the collected metrics, so the Collector can be mounted on /metrics.
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	if _, err := c.WriteTo(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// WriteTo writes the collected metrics to w in the Prometheus text format. Series are sorted by their labels.
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+
+	operationKeys := make([]operationKey, 0, len(c.operations))
+	for key := range c.operations {
+		operationKeys = append(operationKeys, key)
+	}
+	sort.Slice(operationKeys, func(i, j int) bool {
+		if operationKeys[i].collection != operationKeys[j].collection {
+			return operationKeys[i].collection < operationKeys[j].collection
+		}
+		return operationKeys[i].operation < operationKeys[j].operation
+	})
+
+	writeHeader(cw, "godocdb_operations_total", "counter", "Total number of collection operations.")
+	for _, key := range operationKeys {
+		fmt.Fprintf(cw, "godocdb_operations_total%s %d\n", labels("collection", key.collection, "operation", key.operation), c.operations[key])
+	}
+
+	writeHeader(cw, "godocdb_operation_errors_total", "counter", "Total number of collection operations that returned an error.")
+	for _, key := range operationKeys {
+		fmt.Fprintf(cw, "godocdb_operation_errors_total%s %d\n", labels("collection", key.collection, "operation", key.operation), c.errors[key])
+	}
+
+	writeHeader(cw, "godocdb_operation_duration_seconds", "histogram", "Latency of collection operations, including lock wait time.")
+	for _, key := range operationKeys {
+		c.writeHistogram(cw, "godocdb_operation_duration_seconds", c.latencies[key], "collection", key.collection, "operation", key.operation)
+	}
+
+	lockKeys := make([]lockKey, 0, len(c.lockWaits))
+	for key := range c.lockWaits {
+		lockKeys = append(lockKeys, key)
+	}
+	sort.Slice(lockKeys, func(i, j int) bool {
+		if lockKeys[i].collection != lockKeys[j].collection {
+			return lockKeys[i].collection < lockKeys[j].collection
+		}
+		return lockKeys[i].mode < lockKeys[j].mode
+	})
+
+	writeHeader(cw, "godocdb_lock_wait_seconds", "histogram", "Time spent waiting to acquire collection locks.")
+	for _, key := range lockKeys {
+		c.writeHistogram(cw, "godocdb_lock_wait_seconds", c.lockWaits[key], "collection", key.collection, "mode", string(key.mode))
+	}
+
+	collections := make([]string, 0, len(c.documents))
+	for collection := range c.documents {
+		collections = append(collections, collection)
+	}
+	sort.Strings(collections)
+
+	writeHeader(cw, "godocdb_documents", "gauge", "Number of documents in each collection.")
+	for _, collection := range collections {
+		fmt.Fprintf(cw, "godocdb_documents%s %d\n", labels("collection", collection), c.documents[collection])
+	}
+
+	if cw.err != nil {
+		return cw.n, cw.err
+	}
+	return cw.n, cw.w.Flush()
+}
+
+// newHistogram creates an empty histogram using the buckets of the collector.
+func (c *Collector) newHistogram() *histogram {
+	return &histogram{counts: make([]uint64, len(c.buckets))}
+}
+
+// observe adds a duration to a histogram. Bucket counts are cumulative.
+func (c *Collector) observe(h *histogram, duration time.Duration) {
+	seconds := duration.Seconds()
+	for i, bound := range c.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+// writeHistogram writes the bucket, sum and count series of a histogram.
+func (c *Collector) writeHistogram(w io.Writer, name string, h *histogram, labelPairs ...string) {
+	for i, bound := range c.buckets {
+		fmt.Fprintf(w, "%s_bucket%s %d\n", name, labels(append(labelPairs, "le", formatFloat(bound))...), h.counts[i])
+	}
+	fmt.Fprintf(w, "%s_bucket%s %d\n", name, labels(append(labelPairs, "le", "+Inf")...), h.count)
+	fmt.Fprintf(w, "%s_sum%s %s\n", name, labels(labelPairs...), formatFloat(h.sum))
+	fmt.Fprintf(w, "%s_count%s %d\n", name, labels(labelPairs...), h.count)
+}
+
+// writeHeader writes the HELP and TYPE lines of a metric family.
+func writeHeader(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, kind)
+}
+
+// labels formats name/value pairs as a Prometheus label set.
+func labels(pairs ...string) string {
+	if len(pairs) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(pairs[i])
+		b.WriteString(`="`)
+		b.WriteString(escapeLabelValue(pairs[i+1]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// escapeLabelValue escapes backslashes, double quotes and line feeds as required by the text format.
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// formatFloat formats a sample value the way Prometheus expects.
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+// countingWriter counts the bytes written and keeps the first error.
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	if cw.err != nil {
+		return 0, cw.err
+	}
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	cw.err = err
+	return n, err
+}
+
+var _ database.Instrumentation = (*Collector)(nil)
@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"bytes"
+	"errors"
+	"libs/resources/database/in-memory/go-doc-db/database"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type CollectorTestSuite struct {
+	suite.Suite
+	collector *Collector
+}
+
+func TestCollectorTestSuite(t *testing.T) {
+	suite.Run(t, new(CollectorTestSuite))
+}
+
+func (suite *CollectorTestSuite) SetupTest() {
+	suite.collector = NewCollector(0.001, 0.01)
+}
+
+func (suite *CollectorTestSuite) TearDownTest() {
+	suite.collector = nil
+}
+
+func (suite *CollectorTestSuite) TestWriteTo() {
+	suite.collector.ObserveOperation("rates", database.OperationInsertOne, 500*time.Microsecond, nil)
+	suite.collector.ObserveOperation("rates", database.OperationInsertOne, 5*time.Millisecond, errors.New("document already exists"))
+	suite.collector.ObserveLockWait("rates", database.LockWrite, 20*time.Millisecond)
+	suite.collector.SetDocumentCount("rates", 1)
+
+	var out bytes.Buffer
+	n, err := suite.collector.WriteTo(&out)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), int64(out.Len()), n)
+
+	text := out.String()
+	for _, line := range []string{
+		"# TYPE godocdb_operations_total counter",
+		`godocdb_operations_total{collection="rates",operation="insert_one"} 2`,
+		`godocdb_operation_errors_total{collection="rates",operation="insert_one"} 1`,
+		"# TYPE godocdb_operation_duration_seconds histogram",
+		`godocdb_operation_duration_seconds_bucket{collection="rates",operation="insert_one",le="0.001"} 1`,
+		`godocdb_operation_duration_seconds_bucket{collection="rates",operation="insert_one",le="0.01"} 2`,
+		`godocdb_operation_duration_seconds_bucket{collection="rates",operation="insert_one",le="+Inf"} 2`,
+		`godocdb_operation_duration_seconds_sum{collection="rates",operation="insert_one"} 0.0055`,
+		`godocdb_operation_duration_seconds_count{collection="rates",operation="insert_one"} 2`,
+		`godocdb_lock_wait_seconds_bucket{collection="rates",mode="write",le="0.01"} 0`,
+		`godocdb_lock_wait_seconds_bucket{collection="rates",mode="write",le="+Inf"} 1`,
+		"# TYPE godocdb_documents gauge",
+		`godocdb_documents{collection="rates"} 1`,
+	} {
+		assert.Contains(suite.T(), text, line+"\n")
+	}
+}
+
+func (suite *CollectorTestSuite) TestLabelValuesAreEscaped() {
+	suite.collector.SetDocumentCount("a\"b\\c\nd", 3)
+	var out bytes.Buffer
+	_, err := suite.collector.WriteTo(&out)
+	assert.Nil(suite.T(), err)
+	assert.Contains(suite.T(), out.String(), `godocdb_documents{collection="a\"b\\c\nd"} 3`)
+}
+
+func (suite *CollectorTestSuite) TestServeHTTP() {
+	db := database.NewInMemoryDocBD("test-db")
+	db.SetInstrumentation(suite.collector)
+	err := db.CreateCollection("rates")
+	assert.Nil(suite.T(), err)
+	collection, _ := db.GetCollection("rates")
+	assert.Nil(suite.T(), collection.InsertOne(database.Document{"_id": "1"}))
+	collection.FindAll()
+
+	recorder := httptest.NewRecorder()
+	suite.collector.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Equal(suite.T(), ContentType, recorder.Header().Get("Content-Type"))
+	body := recorder.Body.String()
+	assert.True(suite.T(), strings.Contains(body, `godocdb_operations_total{collection="rates",operation="find_all"} 1`))
+	assert.True(suite.T(), strings.Contains(body, `godocdb_documents{collection="rates"} 1`))
+}
@@ -0,0 +1,264 @@
+package shell
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"libs/resources/database/in-memory/go-doc-db/database"
+	"sort"
+	"strings"
+)
+
+var (
+	errNoCollectionSelected = errors.New("no collection selected, run: use <collection>")
+	errExit                 = errors.New("exit")
+)
+
+const helpText = `Commands:
+  collections          list the collections and views in the database
+  use <collection>     select the collection or view used by the query commands
+  find [expression]    list the documents matching the filter expression
+  findone <id>         show the document with the given _id
+  count [expression]   count the documents matching the filter expression
+  search <terms>       full-text search on the text index, best matches first
+  parse <expression>   show the query a filter expression compiles to
+  save <path>          write a JSON dump of the database to path
+  help                 show this message
+  exit                 leave the shell
+
+Filter expressions:
+  code == "USD" && bid > 5.2 && codeIn in ["BRL", "EUR"]
+  operators: == != > >= < <= in, not in, &&, ||, !, ( )
+`
+
+// Shell is an interactive read-eval-print loop over an in-memory document database.
+type Shell struct {
+	db         *database.InMemoryDocBD
+	in         io.Reader
+	out        io.Writer
+	collection string
+}
+
+// NewShell creates and returns a new Shell reading commands from in and writing results to out.
+func NewShell(db *database.InMemoryDocBD, in io.Reader, out io.Writer) *Shell {
+	return &Shell{
+		db:  db,
+		in:  in,
+		out: out,
+	}
+}
+
+// Run reads and executes commands until the input is exhausted or the exit command is given.
+// Command errors are reported to the output and do not stop the loop.
+func (s *Shell) Run() error {
+	scanner := bufio.NewScanner(s.in)
+	s.prompt()
+	for scanner.Scan() {
+		err := s.Execute(scanner.Text())
+		if errors.Is(err, errExit) {
+			return nil
+		}
+		if err != nil {
+			s.reportError(scanner.Text(), err)
+		}
+		s.prompt()
+	}
+	fmt.Fprintln(s.out)
+	return scanner.Err()
+}
+
+// Execute runs a single shell command line.
+func (s *Shell) Execute(line string) error {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return nil
+	}
+	command, args, _ := strings.Cut(line, " ")
+	args = strings.TrimSpace(args)
+
+	switch command {
+	case "help":
+		fmt.Fprint(s.out, helpText)
+		return nil
+	case "exit", "quit":
+		return errExit
+	case "collections":
+		return s.listCollections()
+	case "use":
+		return s.useCollection(args)
+	case "find":
+		return s.find(args)
+	case "findone":
+		return s.findOne(args)
+	case "count":
+		return s.count(args)
+	case "search":
+		return s.search(args)
+	case "parse":
+		return s.parse(args)
+	case "save":
+		return s.save(args)
+	default:
+		return fmt.Errorf("unknown command %q, type help for the list of commands", command)
+	}
+}
+
+func (s *Shell) prompt() {
+	if s.collection != "" {
+		fmt.Fprintf(s.out, "godocdb:%s> ", s.collection)
+		return
+	}
+	fmt.Fprint(s.out, "godocdb> ")
+}
+
+// reportError prints an error, pointing at the offending column for syntax errors.
+func (s *Shell) reportError(line string, err error) {
+	var syntaxErr *database.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		line = strings.TrimSpace(line)
+		_, args, _ := strings.Cut(line, " ")
+		fmt.Fprintf(s.out, "  %s\n", strings.TrimSpace(args))
+		fmt.Fprintf(s.out, "  %s^\n", strings.Repeat(" ", syntaxErr.Column-1))
+	}
+	fmt.Fprintf(s.out, "error: %v\n", err)
+}
+
+func (s *Shell) listCollections() error {
+	for _, info := range s.db.ListCollectionInfo() {
+		if info.Kind == database.KindView {
+			fmt.Fprintf(s.out, "%s (view)\n", info.Name)
+			continue
+		}
+		fmt.Fprintln(s.out, info.Name)
+	}
+	return nil
+}
+
+func (s *Shell) useCollection(name string) error {
+	if name == "" {
+		return errors.New("usage: use <collection>")
+	}
+	if _, err := s.db.GetStore(name); err != nil {
+		return fmt.Errorf("collection %s does not exist", name)
+	}
+	s.collection = name
+	fmt.Fprintf(s.out, "switched to collection %s\n", name)
+	return nil
+}
+
+func (s *Shell) currentCollection() (database.DocumentStore, error) {
+	if s.collection == "" {
+		return nil, errNoCollectionSelected
+	}
+	return s.db.GetStore(s.collection)
+}
+
+func (s *Shell) find(expression string) error {
+	collection, err := s.currentCollection()
+	if err != nil {
+		return err
+	}
+	query, err := database.ParseQuery(expression)
+	if err != nil {
+		return err
+	}
+	documents := collection.Find(query)
+	sortDocuments(documents)
+	for _, document := range documents {
+		if err := s.writeJSON(document, ""); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(s.out, "(%d documents)\n", len(documents))
+	return nil
+}
+
+func (s *Shell) findOne(id string) error {
+	collection, err := s.currentCollection()
+	if err != nil {
+		return err
+	}
+	if id == "" {
+		return errors.New("usage: findone <id>")
+	}
+	document, err := collection.FindOne(strings.Trim(id, `"`))
+	if err != nil {
+		return err
+	}
+	return s.writeJSON(document, "  ")
+}
+
+func (s *Shell) count(expression string) error {
+	collection, err := s.currentCollection()
+	if err != nil {
+		return err
+	}
+	query, err := database.ParseQuery(expression)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(s.out, len(collection.Find(query)))
+	return nil
+}
+
+func (s *Shell) search(terms string) error {
+	store, err := s.currentCollection()
+	if err != nil {
+		return err
+	}
+	if terms == "" {
+		return errors.New("usage: search <terms>")
+	}
+	collection, ok := store.(*database.Collection)
+	if !ok {
+		return fmt.Errorf("%s is a view, full-text search needs a collection with a text index", s.collection)
+	}
+	documents := collection.FindWithOptions(
+		map[string]interface{}{database.OpText: terms},
+		database.FindOptions{
+			Sort:             []database.SortField{database.Desc(database.TextScoreField)},
+			IncludeTextScore: true,
+		},
+	)
+	for _, document := range documents {
+		if err := s.writeJSON(document, ""); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(s.out, "(%d documents)\n", len(documents))
+	return nil
+}
+
+func (s *Shell) parse(expression string) error {
+	query, err := database.ParseQuery(expression)
+	if err != nil {
+		return err
+	}
+	return s.writeJSON(query, "  ")
+}
+
+func (s *Shell) save(path string) error {
+	if path == "" {
+		return errors.New("usage: save <path>")
+	}
+	if err := s.db.SaveToFile(path); err != nil {
+		return err
+	}
+	fmt.Fprintf(s.out, "saved database to %s\n", path)
+	return nil
+}
+
+func (s *Shell) writeJSON(v interface{}, indent string) error {
+	encoder := json.NewEncoder(s.out)
+	encoder.SetIndent("", indent)
+	return encoder.Encode(v)
+}
+
+// sortDocuments orders documents by _id so results are stable between runs.
+func sortDocuments(documents []database.Document) {
+	sort.Slice(documents, func(i, j int) bool {
+		return fmt.Sprint(documents[i]["_id"]) < fmt.Sprint(documents[j]["_id"])
+	})
+}
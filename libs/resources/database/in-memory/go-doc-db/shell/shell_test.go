@@ -0,0 +1,115 @@
+package shell
+
+import (
+	"bytes"
+	"libs/resources/database/in-memory/go-doc-db/database"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ShellTestSuite struct {
+	suite.Suite
+	db  *database.InMemoryDocBD
+	out *bytes.Buffer
+}
+
+func TestShellTestSuite(t *testing.T) {
+	suite.Run(t, new(ShellTestSuite))
+}
+
+func (suite *ShellTestSuite) SetupTest() {
+	suite.db = database.NewInMemoryDocBD("test-db")
+	suite.out = &bytes.Buffer{}
+	err := suite.db.CreateCollection("currency-info")
+	assert.Nil(suite.T(), err)
+	collection, err := suite.db.GetCollection("currency-info")
+	assert.Nil(suite.T(), err)
+	err = collection.InsertOne(database.Document{"_id": "1", "code": "USD", "codeIn": "BRL", "bid": 5.45})
+	assert.Nil(suite.T(), err)
+	err = collection.InsertOne(database.Document{"_id": "2", "code": "EUR", "codeIn": "BRL", "bid": 6.01})
+	assert.Nil(suite.T(), err)
+}
+
+func (suite *ShellTestSuite) run(commands ...string) string {
+	input := strings.NewReader(strings.Join(commands, "\n") + "\n")
+	err := NewShell(suite.db, input, suite.out).Run()
+	assert.Nil(suite.T(), err)
+	return suite.out.String()
+}
+
+func (suite *ShellTestSuite) TestFind() {
+	output := suite.run("use currency-info", `find code == "USD" && bid > 5.2`)
+	assert.Contains(suite.T(), output, "switched to collection currency-info")
+	assert.Contains(suite.T(), output, `{"_id":"1","bid":5.45,"code":"USD","codeIn":"BRL"}`)
+	assert.NotContains(suite.T(), output, `"_id":"2"`)
+	assert.Contains(suite.T(), output, "(1 documents)")
+}
+
+func (suite *ShellTestSuite) TestCount() {
+	output := suite.run("use currency-info", `count codeIn == "BRL"`)
+	assert.Contains(suite.T(), output, "godocdb:currency-info> 2\n")
+}
+
+func (suite *ShellTestSuite) TestCollections() {
+	err := suite.db.CreateView("latest", "currency-info", database.Pipeline{})
+	assert.Nil(suite.T(), err)
+
+	output := suite.run("collections", "use latest", "count")
+	assert.Contains(suite.T(), output, "currency-info\n")
+	assert.Contains(suite.T(), output, "latest (view)\n")
+	assert.Contains(suite.T(), output, "godocdb:latest> 2\n")
+}
+
+func (suite *ShellTestSuite) TestFindRequiresCollection() {
+	output := suite.run("find")
+	assert.Contains(suite.T(), output, "error: no collection selected")
+}
+
+func (suite *ShellTestSuite) TestSyntaxErrorPointsAtColumn() {
+	output := suite.run("use currency-info", `find code == "USD" && > 5`)
+	assert.Contains(suite.T(), output, "  code == \"USD\" && > 5\n")
+	assert.Contains(suite.T(), output, "  "+strings.Repeat(" ", 17)+"^\n")
+	assert.Contains(suite.T(), output, "error: syntax error at line 1, column 18: expected field name")
+}
+
+func (suite *ShellTestSuite) TestExitStopsReading() {
+	output := suite.run("exit", "collections")
+	assert.NotContains(suite.T(), output, "currency-info")
+}
+
+func (suite *ShellTestSuite) TestUnknownCommand() {
+	output := suite.run("drop")
+	assert.Contains(suite.T(), output, `error: unknown command "drop"`)
+}
+
+func (suite *ShellTestSuite) TestSaveAndLoadDump() {
+	path := filepath.Join(suite.T().TempDir(), "dump.json")
+	suite.run("save " + path)
+
+	loaded, err := database.LoadFromFile(path)
+	assert.Nil(suite.T(), err)
+
+	out := &bytes.Buffer{}
+	input := strings.NewReader("use currency-info\ncount\n")
+	err = NewShell(loaded, input, out).Run()
+	assert.Nil(suite.T(), err)
+	assert.Contains(suite.T(), out.String(), "godocdb:currency-info> 2\n")
+}
+
+func (suite *ShellTestSuite) TestSearch() {
+	collection, err := suite.db.GetCollection("currency-info")
+	assert.Nil(suite.T(), err)
+	err = collection.UpdateOne("1", database.Document{"name": "Dólar Americano/Real Brasileiro"})
+	assert.Nil(suite.T(), err)
+	err = collection.CreateIndex(database.IndexModel{Name: "name_text", Kind: database.IndexKindText, Fields: []string{"name"}})
+	assert.Nil(suite.T(), err)
+
+	output := suite.run("use currency-info", "search dolar")
+	assert.Contains(suite.T(), output, `"_id":"1"`)
+	assert.Contains(suite.T(), output, `"_score":`)
+	assert.Contains(suite.T(), output, "(1 documents)")
+}
@@ -1,45 +1,163 @@
 package client
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"slices"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
 type Client struct {
-	db *sql.DB
+	db             *sql.DB
+	readDB         *sql.DB
+	statements     *stmtCache
+	defaultTimeout time.Duration
+	txRetries      int
 }
 
 // NewSQLiteClient creates a new SQLite client and initializes the database.
+// It uses DefaultOptions, without the read pool when the database is in memory.
 func NewClient(dbPath string) (*Client, error) {
-	db, err := sql.Open("sqlite3", dbPath)
+	options := DefaultOptions()
+	if isInMemory(dbPath) {
+		options.ReadPool = false
+	}
+	return NewClientWithOptions(dbPath, options)
+}
+
+// NewClientWithOptions validates the options, opens the database with them and checks it with Ping.
+func NewClientWithOptions(dbPath string, options Options) (*Client, error) {
+	if err := options.Validate(dbPath); err != nil {
+		return nil, err
+	}
+	db, err := openPool(options.dsn(dbPath, false), options.MaxOpenConns, options.MaxIdleConns, options.ConnMaxLifetime)
+	if err != nil {
+		return nil, err
+	}
+	if isInMemory(dbPath) {
+		// Every connection to :memory: opens a database of its own, so the pool must keep a single one.
+		db.SetMaxOpenConns(1)
+		db.SetMaxIdleConns(1)
+		db.SetConnMaxLifetime(0)
+	}
+	c := &Client{
+		db:             db,
+		statements:     newStmtCache(options.StatementCacheSize),
+		defaultTimeout: options.DefaultTimeout,
+		txRetries:      options.TxRetries,
+	}
+	if options.ReadPool {
+		c.readDB, err = openPool(options.dsn(dbPath, true), options.MaxReadOpenConns, options.MaxReadIdleConns, options.ConnMaxLifetime)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+
+	ctx := context.Background()
+	if options.PingTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.PingTimeout)
+		defer cancel()
+	}
+	if err := c.Ping(ctx); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("failed to connect to %s: %w", dbPath, err)
+	}
+	return c, nil
+}
+
+// openPool opens a connection pool with the given limits.
+func openPool(dsn string, maxOpen, maxIdle int, maxLifetime time.Duration) (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
 		return nil, err
 	}
-	return &Client{
-		db: db,
-	}, nil
+	db.SetMaxOpenConns(maxOpen)
+	db.SetMaxIdleConns(maxIdle)
+	db.SetConnMaxLifetime(maxLifetime)
+	return db, nil
 }
 
-// Close closes the database connection.
+// Ping checks that a connection of every pool can be opened and used.
+func (c *Client) Ping(ctx context.Context) error {
+	if err := c.db.PingContext(ctx); err != nil {
+		return err
+	}
+	if c.readDB != nil {
+		return c.readDB.PingContext(ctx)
+	}
+	return nil
+}
+
+// Close closes the database connections.
 func (c *Client) Close() error {
-	return c.db.Close()
+	c.InvalidateStatements()
+	err := c.db.Close()
+	if c.readDB != nil {
+		if readErr := c.readDB.Close(); err == nil {
+			err = readErr
+		}
+	}
+	return err
 }
 
 // Exec executes a query without returning any rows.
 func (c *Client) Exec(query string, args ...interface{}) error {
 	_, err := c.db.Exec(query, args...)
+	if err == nil && changesSchema(query) {
+		c.InvalidateStatements()
+	}
 	return err
 }
 
 // QueryRow executes a query that is expected to return at most one row.
 func (c *Client) QueryRow(query string, args ...interface{}) *sql.Row {
-	return c.db.QueryRow(query, args...)
+	return c.reader(query).QueryRow(query, args...)
 }
 
 // Query executes a query that returns rows.
 func (c *Client) Query(query string, args ...interface{}) (*sql.Rows, error) {
-	return c.db.Query(query, args...)
+	return c.reader(query).Query(query, args...)
+}
+
+// reader returns the read pool for SELECT statements, when there is one, and the write pool otherwise.
+// Statements such as DELETE ... RETURNING return rows but must run on the write pool.
+func (c *Client) reader(query string) *sql.DB {
+	if c.readDB != nil && isSelect(query) {
+		return c.readDB
+	}
+	return c.db
+}
+
+// isSelect reports whether the query is a single read-only SELECT, VALUES or WITH statement.
+// Leading comments are skipped, and a WITH statement is read-only when its common table expressions feed a SELECT
+// rather than an INSERT, UPDATE, DELETE or REPLACE.
+func isSelect(query string) bool {
+	words := topLevelWords(query)
+	for len(words) > 0 && words[len(words)-1] == ";" {
+		words = words[:len(words)-1]
+	}
+	if len(words) == 0 || slices.Contains(words, ";") {
+		return false
+	}
+	switch words[0] {
+	case "SELECT", "VALUES":
+		return true
+	case "WITH":
+		for _, word := range words[1:] {
+			switch word {
+			case "SELECT", "VALUES":
+				return true
+			case "INSERT", "UPDATE", "DELETE", "REPLACE":
+				return false
+			}
+		}
+	}
+	return false
 }
 
 // func (c *Client) CreateCollection(name string) error {
@@ -0,0 +1,195 @@
+package client
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// TimeoutError is returned when a query does not finish before its deadline.
+// It unwraps to context.DeadlineExceeded.
+type TimeoutError struct {
+	Query   string
+	Elapsed time.Duration
+	Err     error
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("query exceeded its deadline after %s: %v", e.Elapsed, e.Err)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// SetDefaultTimeout bounds every *Context call whose context has no deadline. Zero disables the default.
+func (c *Client) SetDefaultTimeout(timeout time.Duration) {
+	c.defaultTimeout = timeout
+}
+
+// executor is implemented by both *sql.DB and *sql.Tx.
+type executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// ExecContext executes a query without returning any rows.
+// Inside WithTx, it runs in the transaction carried by ctx.
+func (c *Client) ExecContext(ctx context.Context, query string, args ...interface{}) error {
+	return c.execContext(ctx, c.executor(ctx), query, args...)
+}
+
+// QueryRowContext executes a query that is expected to return at most one row.
+// The default timeout, if any, covers the query and the Scan of the returned Row.
+// Inside WithTx, it runs in the transaction carried by ctx.
+func (c *Client) QueryRowContext(ctx context.Context, query string, args ...interface{}) *Row {
+	return c.queryRowContext(ctx, c.readExecutor(ctx, query), query, args...)
+}
+
+// QueryContext executes a query that returns rows.
+// The default timeout, if any, covers the query and the iteration until the returned Rows are closed.
+// Inside WithTx, it runs in the transaction carried by ctx.
+func (c *Client) QueryContext(ctx context.Context, query string, args ...interface{}) (*Rows, error) {
+	return c.queryContext(ctx, c.readExecutor(ctx, query), query, args...)
+}
+
+// executor returns the transaction carried by ctx, if it belongs to the client, or the database.
+func (c *Client) executor(ctx context.Context) executor {
+	if tx, ok := TxFromContext(ctx); ok && tx.client == c {
+		return tx.tx
+	}
+	return c.db
+}
+
+// readExecutor is executor for queries that return rows: outside a transaction, SELECT statements use the read pool.
+func (c *Client) readExecutor(ctx context.Context, query string) executor {
+	if tx, ok := TxFromContext(ctx); ok && tx.client == c {
+		return tx.tx
+	}
+	return c.reader(query)
+}
+
+func (c *Client) execContext(ctx context.Context, exec executor, query string, args ...interface{}) error {
+	ctx, cancel := c.withDefaultTimeout(ctx)
+	defer cancel()
+	start := time.Now()
+	var err error
+	if stmt, release, ok := c.statement(ctx, exec, query); ok {
+		defer release()
+		_, err = stmt.ExecContext(ctx, args...)
+	} else {
+		_, err = exec.ExecContext(ctx, query, args...)
+	}
+	if err == nil && changesSchema(query) {
+		c.InvalidateStatements()
+	}
+	return mapContextError(ctx, query, start, err)
+}
+
+func (c *Client) queryRowContext(ctx context.Context, exec executor, query string, args ...interface{}) *Row {
+	ctx, cancel := c.withDefaultTimeout(ctx)
+	start := time.Now()
+	var row *sql.Row
+	if stmt, release, ok := c.statement(ctx, exec, query); ok {
+		row = stmt.QueryRowContext(ctx, args...)
+		cancel = releaseAndCancel(release, cancel)
+	} else {
+		row = exec.QueryRowContext(ctx, query, args...)
+	}
+	return &Row{
+		row:    row,
+		ctx:    ctx,
+		cancel: cancel,
+		query:  query,
+		start:  start,
+	}
+}
+
+func (c *Client) queryContext(ctx context.Context, exec executor, query string, args ...interface{}) (*Rows, error) {
+	ctx, cancel := c.withDefaultTimeout(ctx)
+	start := time.Now()
+	var rows *sql.Rows
+	var err error
+	if stmt, release, ok := c.statement(ctx, exec, query); ok {
+		rows, err = stmt.QueryContext(ctx, args...)
+		cancel = releaseAndCancel(release, cancel)
+	} else {
+		rows, err = exec.QueryContext(ctx, query, args...)
+	}
+	if err != nil {
+		cancel()
+		return nil, mapContextError(ctx, query, start, err)
+	}
+	return &Rows{Rows: rows, ctx: ctx, cancel: cancel, query: query, start: start}, nil
+}
+
+// releaseAndCancel releases a cached statement along with the timeout of the query using it.
+// The returned func may be called more than once, as Rows.Close may.
+func releaseAndCancel(release func(), cancel context.CancelFunc) context.CancelFunc {
+	var once sync.Once
+	return func() {
+		once.Do(release)
+		cancel()
+	}
+}
+
+// Row is the result of QueryRowContext.
+type Row struct {
+	row    *sql.Row
+	ctx    context.Context
+	cancel context.CancelFunc
+	query  string
+	start  time.Time
+}
+
+// Scan copies the columns of the row into dest. It returns sql.ErrNoRows when the query matched nothing.
+func (r *Row) Scan(dest ...interface{}) error {
+	defer r.cancel()
+	return mapContextError(r.ctx, r.query, r.start, r.row.Scan(dest...))
+}
+
+// Rows is the result of QueryContext. It must be closed.
+type Rows struct {
+	*sql.Rows
+	ctx    context.Context
+	cancel context.CancelFunc
+	query  string
+	start  time.Time
+}
+
+// Err returns the error met during iteration, if any.
+func (r *Rows) Err() error {
+	return mapContextError(r.ctx, r.query, r.start, r.Rows.Err())
+}
+
+// Close closes the rows and releases the timeout of the query.
+func (r *Rows) Close() error {
+	defer r.cancel()
+	return r.Rows.Close()
+}
+
+// withDefaultTimeout applies the default timeout of the client when ctx has no deadline.
+func (c *Client) withDefaultTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || c.defaultTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.defaultTimeout)
+}
+
+// mapContextError turns a failure caused by an expired deadline into a *TimeoutError and logs it.
+func mapContextError(ctx context.Context, query string, start time.Time, err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		timeoutErr := &TimeoutError{Query: query, Elapsed: time.Since(start), Err: context.DeadlineExceeded}
+		log.Printf("Query deadline exceeded after %s: %s", timeoutErr.Elapsed, query)
+		return timeoutErr
+	}
+	return err
+}
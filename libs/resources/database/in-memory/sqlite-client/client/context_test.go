@@ -0,0 +1,111 @@
+package client
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// slowQuery counts to a large number, so it only finishes if it is not interrupted.
+const slowQuery = "WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c WHERE x < 1000000000) SELECT COUNT(*) FROM c"
+
+type SQLiteClientContextTestSuite struct {
+	suite.Suite
+	client *Client
+}
+
+func TestSQLiteClientContextTestSuite(t *testing.T) {
+	suite.Run(t, new(SQLiteClientContextTestSuite))
+}
+
+func (suite *SQLiteClientContextTestSuite) SetupTest() {
+	client, err := NewClient(":memory:")
+	assert.NoError(suite.T(), err)
+	suite.client = client
+	suite.client.db.SetMaxOpenConns(1)
+	err = suite.client.ExecContext(context.Background(), "CREATE TABLE test (id INTEGER PRIMARY KEY, name TEXT)")
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *SQLiteClientContextTestSuite) TearDownTest() {
+	suite.client.Close()
+}
+
+func (suite *SQLiteClientContextTestSuite) TestExecQueryAndQueryRowContext() {
+	ctx := context.Background()
+	assert.NoError(suite.T(), suite.client.ExecContext(ctx, "INSERT INTO test (name) VALUES (?)", "Alice"))
+	assert.NoError(suite.T(), suite.client.ExecContext(ctx, "INSERT INTO test (name) VALUES (?)", "Bob"))
+
+	var name string
+	err := suite.client.QueryRowContext(ctx, "SELECT name FROM test WHERE id = ?", 1).Scan(&name)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Alice", name)
+
+	err = suite.client.QueryRowContext(ctx, "SELECT name FROM test WHERE id = ?", 3).Scan(&name)
+	assert.ErrorIs(suite.T(), err, sql.ErrNoRows)
+
+	rows, err := suite.client.QueryContext(ctx, "SELECT name FROM test ORDER BY id")
+	assert.NoError(suite.T(), err)
+	var names []string
+	for rows.Next() {
+		assert.NoError(suite.T(), rows.Scan(&name))
+		names = append(names, name)
+	}
+	assert.NoError(suite.T(), rows.Err())
+	assert.NoError(suite.T(), rows.Close())
+	assert.Equal(suite.T(), []string{"Alice", "Bob"}, names)
+}
+
+func (suite *SQLiteClientContextTestSuite) TestCallerDeadline() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	var count int
+	err := suite.client.QueryRowContext(ctx, slowQuery).Scan(&count)
+
+	var timeoutErr *TimeoutError
+	assert.True(suite.T(), errors.As(err, &timeoutErr), "unexpected error: %v", err)
+	assert.ErrorIs(suite.T(), err, context.DeadlineExceeded)
+	assert.Equal(suite.T(), slowQuery, timeoutErr.Query)
+}
+
+func (suite *SQLiteClientContextTestSuite) TestDefaultTimeout() {
+	suite.client.SetDefaultTimeout(10 * time.Millisecond)
+
+	err := suite.client.ExecContext(context.Background(), "INSERT INTO test (name) SELECT 'x' FROM ("+slowQuery+")")
+	assert.ErrorIs(suite.T(), err, context.DeadlineExceeded)
+
+	rows, err := suite.client.QueryContext(context.Background(), slowQuery)
+	if err == nil {
+		for rows.Next() {
+		}
+		err = rows.Err()
+		rows.Close()
+	}
+	var timeoutErr *TimeoutError
+	assert.True(suite.T(), errors.As(err, &timeoutErr), "unexpected error: %v", err)
+}
+
+func (suite *SQLiteClientContextTestSuite) TestDefaultTimeoutDoesNotOverrideCallerDeadline() {
+	suite.client.SetDefaultTimeout(time.Nanosecond)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	err := suite.client.ExecContext(ctx, "INSERT INTO test (name) VALUES (?)", "Alice")
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *SQLiteClientContextTestSuite) TestCanceledContextIsNotATimeout() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := suite.client.ExecContext(ctx, "INSERT INTO test (name) VALUES (?)", "Alice")
+	assert.ErrorIs(suite.T(), err, context.Canceled)
+	var timeoutErr *TimeoutError
+	assert.False(suite.T(), errors.As(err, &timeoutErr))
+}
@@ -0,0 +1,365 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// DefaultMigrationLockTimeout is how long a Migrator waits for another process to release the migration lock.
+const DefaultMigrationLockTimeout = 30 * time.Second
+
+// staleMigrationLockAge is the age after which a lock is assumed to belong to a process that crashed while migrating.
+const staleMigrationLockAge = 10 * time.Minute
+
+var (
+	// ErrMigrationLocked is returned when the migration lock could not be acquired before the lock timeout.
+	ErrMigrationLocked = errors.New("migrations are locked by another process")
+
+	errMigrationVersionRequired = errors.New("migration version must be greater than zero")
+	errMigrationUpRequired      = errors.New("migration has no up step")
+	errMigrationDownRequired    = errors.New("migration has no down step")
+	errMigrationDuplicate       = errors.New("duplicate migration version")
+)
+
+// migrationFilePattern matches embedded migration files such as 0001_create_exchange_rates.up.sql.
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Migration is a versioned schema change. Each step is either SQL or a Go func; the SQL form wins when both are set.
+type Migration struct {
+	Version int64
+	Name    string
+	UpSQL   string
+	DownSQL string
+	Up      func(tx *sql.Tx) error
+	Down    func(tx *sql.Tx) error
+}
+
+// Checksum identifies the up step of the migration, so edits to an applied migration can be detected.
+// Go func migrations are identified by their name only.
+func (m Migration) Checksum() string {
+	source := m.UpSQL
+	if source == "" {
+		source = "func:" + m.Name
+	}
+	sum := sha256.Sum256([]byte(source))
+	return hex.EncodeToString(sum[:])
+}
+
+// ChecksumMismatchError is returned when an applied migration no longer matches its definition.
+type ChecksumMismatchError struct {
+	Version int64
+	Name    string
+	Applied string
+	Current string
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("migration %d (%s) was changed after being applied: checksum %s, expected %s", e.Version, e.Name, e.Current, e.Applied)
+}
+
+// LoadMigrations reads migrations from the root of fsys, typically an embed.FS.
+// Files are named <version>_<name>.up.sql and <version>_<name>.down.sql; the down file is optional.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		matches := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || matches == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", entry.Name(), err)
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: matches[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != matches[2] {
+			return nil, fmt.Errorf("%w: %d", errMigrationDuplicate, version)
+		}
+		if matches[3] == "up" {
+			migration.UpSQL = string(content)
+		} else {
+			migration.DownSQL = string(content)
+		}
+	}
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Migrator applies and reverts migrations, recording them in the schema_migrations table.
+type Migrator struct {
+	client     *Client
+	migrations []Migration
+	owner      string
+	// LockTimeout bounds the wait for the migration lock held by another process.
+	LockTimeout time.Duration
+}
+
+// NewMigrator validates the migrations and returns a Migrator that applies them in version order.
+func NewMigrator(client *Client, migrations []Migration) (*Migrator, error) {
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	for i, migration := range sorted {
+		if migration.Version <= 0 {
+			return nil, fmt.Errorf("%w: %s", errMigrationVersionRequired, migration.Name)
+		}
+		if migration.UpSQL == "" && migration.Up == nil {
+			return nil, fmt.Errorf("%w: %d", errMigrationUpRequired, migration.Version)
+		}
+		if i > 0 && sorted[i-1].Version == migration.Version {
+			return nil, fmt.Errorf("%w: %d", errMigrationDuplicate, migration.Version)
+		}
+	}
+	return &Migrator{
+		client:      client,
+		migrations:  sorted,
+		owner:       fmt.Sprintf("%d-%d", os.Getpid(), time.Now().UnixNano()),
+		LockTimeout: DefaultMigrationLockTimeout,
+	}, nil
+}
+
+// Migrate applies every pending migration. It is a shortcut for NewMigrator followed by Up.
+func (c *Client) Migrate(migrations []Migration) error {
+	return c.MigrateContext(context.Background(), migrations)
+}
+
+// MigrateContext is Migrate bounded by ctx. It is a shortcut for NewMigrator followed by UpContext.
+func (c *Client) MigrateContext(ctx context.Context, migrations []Migration) error {
+	migrator, err := NewMigrator(c, migrations)
+	if err != nil {
+		return err
+	}
+	return migrator.UpContext(ctx)
+}
+
+// Up applies every pending migration in version order, each in its own transaction.
+// It fails without applying anything if an applied migration has a different checksum.
+func (m *Migrator) Up() error {
+	return m.UpContext(context.Background())
+}
+
+// UpContext is Up bounded by ctx. Once ctx is done, the running migration is rolled back and no other one is applied.
+func (m *Migrator) UpContext(ctx context.Context) error {
+	return m.withLock(ctx, func() error {
+		// Migrations run in their own transactions, outside Exec, so the cached statements are dropped here.
+		defer m.client.InvalidateStatements()
+		applied, err := m.applied(ctx)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			checksum, ok := applied[migration.Version]
+			if ok && checksum != migration.Checksum() {
+				return &ChecksumMismatchError{
+					Version: migration.Version,
+					Name:    migration.Name,
+					Applied: checksum,
+					Current: migration.Checksum(),
+				}
+			}
+		}
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			log.Printf("Applying migration %d: %s", migration.Version, migration.Name)
+			err := m.inTx(ctx, func(tx *sql.Tx) error {
+				if err := runStep(ctx, tx, migration.UpSQL, migration.Up); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx, 
+					"INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)",
+					migration.Version, migration.Name, migration.Checksum(), time.Now().UTC(),
+				)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("failed to apply migration %d (%s): %w", migration.Version, migration.Name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Down reverts applied migrations newer than targetVersion, newest first. Down(0) reverts everything.
+func (m *Migrator) Down(targetVersion int64) error {
+	ctx := context.Background()
+	return m.withLock(ctx, func() error {
+		defer m.client.InvalidateStatements()
+		applied, err := m.applied(ctx)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if migration.Version <= targetVersion {
+				break
+			}
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			if migration.DownSQL == "" && migration.Down == nil {
+				return fmt.Errorf("%w: %d", errMigrationDownRequired, migration.Version)
+			}
+			log.Printf("Reverting migration %d: %s", migration.Version, migration.Name)
+			err := m.inTx(ctx, func(tx *sql.Tx) error {
+				if err := runStep(ctx, tx, migration.DownSQL, migration.Down); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = ?", migration.Version)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("failed to revert migration %d (%s): %w", migration.Version, migration.Name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Version returns the highest applied migration version, or 0 when none is applied.
+func (m *Migrator) Version() (int64, error) {
+	if err := m.createTables(context.Background()); err != nil {
+		return 0, err
+	}
+	var version int64
+	err := m.client.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+	return version, err
+}
+
+// createTables creates the bookkeeping tables of the migrator.
+func (m *Migrator) createTables(ctx context.Context) error {
+	_, err := m.client.db.ExecContext(ctx, `
+    CREATE TABLE IF NOT EXISTS schema_migrations (
+        version INTEGER PRIMARY KEY,
+        name TEXT NOT NULL,
+        checksum TEXT NOT NULL,
+        applied_at DATETIME NOT NULL
+    );
+    CREATE TABLE IF NOT EXISTS schema_migrations_lock (
+        id INTEGER PRIMARY KEY CHECK (id = 1),
+        owner TEXT NOT NULL,
+        locked_at DATETIME NOT NULL
+    )`)
+	return err
+}
+
+// applied returns the checksum of every applied migration by version.
+func (m *Migrator) applied(ctx context.Context) (map[int64]string, error) {
+	rows, err := m.client.db.QueryContext(ctx, "SELECT version, checksum FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	applied := make(map[int64]string)
+	for rows.Next() {
+		var version int64
+		var checksum string
+		if err := rows.Scan(&version, &checksum); err != nil {
+			return nil, err
+		}
+		applied[version] = checksum
+	}
+	return applied, rows.Err()
+}
+
+// withLock runs fn while holding the migration lock, a single row in schema_migrations_lock.
+// The row is visible to every process using the database file, so only one of them migrates at a time.
+// Waiting for the lock stops when ctx is done.
+func (m *Migrator) withLock(ctx context.Context, fn func() error) error {
+	if err := m.createTables(ctx); err != nil {
+		return fmt.Errorf("failed to create migration tables: %w", err)
+	}
+	deadline := time.Now().Add(m.LockTimeout)
+	for {
+		_, err := m.client.db.ExecContext(ctx,
+			"INSERT INTO schema_migrations_lock (id, owner, locked_at) VALUES (1, ?, ?)",
+			m.owner, time.Now().UTC(),
+		)
+		if err == nil {
+			break
+		}
+		if !isLockConflict(err) {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		if time.Now().After(deadline) {
+			return ErrMigrationLocked
+		}
+		if _, err := m.client.db.ExecContext(ctx, "DELETE FROM schema_migrations_lock WHERE locked_at < ?", time.Now().UTC().Add(-staleMigrationLockAge)); err != nil && !isLockConflict(err) {
+			return fmt.Errorf("failed to clear stale migration lock: %w", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+	defer func() {
+		// The lock is released even when ctx is done, so the next run does not wait for it to go stale.
+		if _, err := m.client.db.ExecContext(context.WithoutCancel(ctx), "DELETE FROM schema_migrations_lock WHERE id = 1 AND owner = ?", m.owner); err != nil {
+			log.Printf("Error releasing migration lock: %v", err)
+		}
+	}()
+	return fn()
+}
+
+// inTx runs fn in a transaction, committing on success and rolling back on error.
+func (m *Migrator) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := m.client.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			log.Printf("Error rolling back migration: %v", rollbackErr)
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+// runStep executes the SQL of a step, or its func when there is no SQL.
+func runStep(ctx context.Context, tx *sql.Tx, statements string, fn func(tx *sql.Tx) error) error {
+	if statements != "" {
+		_, err := tx.ExecContext(ctx, statements)
+		return err
+	}
+	return fn(tx)
+}
+
+// isLockConflict reports whether err means another process holds the lock or the database file.
+func isLockConflict(err error) bool {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	return sqliteErr.Code == sqlite3.ErrConstraint || isBusy(err)
+}
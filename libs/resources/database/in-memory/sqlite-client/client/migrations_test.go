@@ -0,0 +1,266 @@
+package client
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type MigrationsTestSuite struct {
+	suite.Suite
+	client       *Client
+	databasePath string
+	migrations   []Migration
+}
+
+func TestMigrationsTestSuite(t *testing.T) {
+	suite.Run(t, new(MigrationsTestSuite))
+}
+
+func (suite *MigrationsTestSuite) SetupTest() {
+	suite.databasePath = filepath.Join(suite.T().TempDir(), "test.db")
+	client, err := NewClient(suite.databasePath)
+	assert.NoError(suite.T(), err)
+	suite.client = client
+	suite.migrations = []Migration{
+		{
+			Version: 1,
+			Name:    "create_test",
+			UpSQL:   "CREATE TABLE test (id INTEGER PRIMARY KEY, name TEXT)",
+			DownSQL: "DROP TABLE test",
+		},
+		{
+			Version: 2,
+			Name:    "index_test_name",
+			UpSQL:   "CREATE INDEX idx_test_name ON test (name)",
+			DownSQL: "DROP INDEX idx_test_name",
+		},
+	}
+}
+
+func (suite *MigrationsTestSuite) TearDownTest() {
+	suite.client.Close()
+}
+
+func (suite *MigrationsTestSuite) objectExists(kind, name string) bool {
+	var count int
+	err := suite.client.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = ? AND name = ?", kind, name).Scan(&count)
+	assert.NoError(suite.T(), err)
+	return count == 1
+}
+
+func (suite *MigrationsTestSuite) TestUp() {
+	migrator, err := NewMigrator(suite.client, suite.migrations)
+	assert.NoError(suite.T(), err)
+
+	assert.NoError(suite.T(), migrator.Up())
+	assert.True(suite.T(), suite.objectExists("table", "test"))
+	assert.True(suite.T(), suite.objectExists("index", "idx_test_name"))
+
+	version, err := migrator.Version()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(2), version)
+
+	// Running again is a no-op.
+	assert.NoError(suite.T(), migrator.Up())
+	var count int
+	err = suite.client.QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&count)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, count)
+}
+
+func (suite *MigrationsTestSuite) TestDown() {
+	migrator, err := NewMigrator(suite.client, suite.migrations)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), migrator.Up())
+
+	assert.NoError(suite.T(), migrator.Down(1))
+	assert.True(suite.T(), suite.objectExists("table", "test"))
+	assert.False(suite.T(), suite.objectExists("index", "idx_test_name"))
+
+	assert.NoError(suite.T(), migrator.Down(0))
+	assert.False(suite.T(), suite.objectExists("table", "test"))
+
+	version, err := migrator.Version()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(0), version)
+}
+
+func (suite *MigrationsTestSuite) TestDownWithoutDownStep() {
+	suite.migrations[1].DownSQL = ""
+	migrator, err := NewMigrator(suite.client, suite.migrations)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), migrator.Up())
+
+	err = migrator.Down(0)
+	assert.ErrorIs(suite.T(), err, errMigrationDownRequired)
+	assert.True(suite.T(), suite.objectExists("index", "idx_test_name"))
+}
+
+func (suite *MigrationsTestSuite) TestChecksumMismatch() {
+	assert.NoError(suite.T(), suite.client.Migrate(suite.migrations[:1]))
+
+	changed := []Migration{suite.migrations[0], suite.migrations[1]}
+	changed[0].UpSQL = "CREATE TABLE test (id INTEGER PRIMARY KEY, name TEXT, email TEXT)"
+	err := suite.client.Migrate(changed)
+
+	var mismatch *ChecksumMismatchError
+	assert.True(suite.T(), errors.As(err, &mismatch))
+	assert.Equal(suite.T(), int64(1), mismatch.Version)
+	// Nothing after the mismatch is applied.
+	assert.False(suite.T(), suite.objectExists("index", "idx_test_name"))
+}
+
+func (suite *MigrationsTestSuite) TestFailedMigrationIsRolledBack() {
+	migrations := append(suite.migrations, Migration{
+		Version: 3,
+		Name:    "broken",
+		Up: func(tx *sql.Tx) error {
+			if _, err := tx.Exec("CREATE TABLE partial (id INTEGER)"); err != nil {
+				return err
+			}
+			return errors.New("boom")
+		},
+	})
+
+	err := suite.client.Migrate(migrations)
+	assert.Error(suite.T(), err)
+	assert.False(suite.T(), suite.objectExists("table", "partial"))
+
+	migrator, err := NewMigrator(suite.client, migrations)
+	assert.NoError(suite.T(), err)
+	version, err := migrator.Version()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(2), version)
+}
+
+func (suite *MigrationsTestSuite) TestFuncMigration() {
+	migrations := append(suite.migrations, Migration{
+		Version: 3,
+		Name:    "seed_test",
+		Up: func(tx *sql.Tx) error {
+			_, err := tx.Exec("INSERT INTO test (name) VALUES (?)", "Alice")
+			return err
+		},
+	})
+	assert.NoError(suite.T(), suite.client.Migrate(migrations))
+
+	var name string
+	assert.NoError(suite.T(), suite.client.QueryRow("SELECT name FROM test").Scan(&name))
+	assert.Equal(suite.T(), "Alice", name)
+}
+
+func (suite *MigrationsTestSuite) TestNewMigratorValidation() {
+	testCases := []struct {
+		name       string
+		migrations []Migration
+		err        error
+	}{
+		{"missing version", []Migration{{Name: "a", UpSQL: "SELECT 1"}}, errMigrationVersionRequired},
+		{"missing up", []Migration{{Version: 1, Name: "a"}}, errMigrationUpRequired},
+		{"duplicate", []Migration{{Version: 1, Name: "a", UpSQL: "SELECT 1"}, {Version: 1, Name: "b", UpSQL: "SELECT 1"}}, errMigrationDuplicate},
+	}
+	for _, tc := range testCases {
+		suite.T().Run(tc.name, func(t *testing.T) {
+			_, err := NewMigrator(suite.client, tc.migrations)
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+}
+
+func (suite *MigrationsTestSuite) TestLoadMigrations() {
+	fsys := fstest.MapFS{
+		"0002_index_test_name.up.sql":   {Data: []byte("CREATE INDEX idx_test_name ON test (name)")},
+		"0002_index_test_name.down.sql": {Data: []byte("DROP INDEX idx_test_name")},
+		"0001_create_test.up.sql":       {Data: []byte("CREATE TABLE test (id INTEGER PRIMARY KEY, name TEXT)")},
+		"README.md":                     {Data: []byte("ignored")},
+	}
+	migrations, err := LoadMigrations(fsys)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, len(migrations))
+	assert.Equal(suite.T(), int64(1), migrations[0].Version)
+	assert.Equal(suite.T(), "create_test", migrations[0].Name)
+	assert.Equal(suite.T(), "", migrations[0].DownSQL)
+	assert.Equal(suite.T(), "DROP INDEX idx_test_name", migrations[1].DownSQL)
+
+	assert.NoError(suite.T(), suite.client.Migrate(migrations))
+	assert.True(suite.T(), suite.objectExists("index", "idx_test_name"))
+}
+
+func (suite *MigrationsTestSuite) TestLockHeldByAnotherProcess() {
+	migrator, err := NewMigrator(suite.client, suite.migrations)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), migrator.createTables(context.Background()))
+	err = suite.client.Exec("INSERT INTO schema_migrations_lock (id, owner, locked_at) VALUES (1, 'other', ?)", time.Now().UTC())
+	assert.NoError(suite.T(), err)
+
+	migrator.LockTimeout = 100 * time.Millisecond
+	assert.ErrorIs(suite.T(), migrator.Up(), ErrMigrationLocked)
+	assert.False(suite.T(), suite.objectExists("table", "test"))
+}
+
+func (suite *MigrationsTestSuite) TestUpContextStopsWaitingForLock() {
+	migrator, err := NewMigrator(suite.client, suite.migrations)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), migrator.createTables(context.Background()))
+	err = suite.client.Exec("INSERT INTO schema_migrations_lock (id, owner, locked_at) VALUES (1, 'other', ?)", time.Now().UTC())
+	assert.NoError(suite.T(), err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(suite.T(), migrator.UpContext(ctx), context.DeadlineExceeded)
+	assert.False(suite.T(), suite.objectExists("table", "test"))
+}
+
+func (suite *MigrationsTestSuite) TestMigrateContextCanceled() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(suite.T(), suite.client.MigrateContext(ctx, suite.migrations), context.Canceled)
+	assert.False(suite.T(), suite.objectExists("table", "test"))
+}
+
+func (suite *MigrationsTestSuite) TestStaleLockIsCleared() {
+	migrator, err := NewMigrator(suite.client, suite.migrations)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), migrator.createTables(context.Background()))
+	err = suite.client.Exec("INSERT INTO schema_migrations_lock (id, owner, locked_at) VALUES (1, 'crashed', ?)", time.Now().UTC().Add(-time.Hour))
+	assert.NoError(suite.T(), err)
+
+	assert.NoError(suite.T(), migrator.Up())
+	assert.True(suite.T(), suite.objectExists("table", "test"))
+}
+
+func (suite *MigrationsTestSuite) TestConcurrentMigrators() {
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			client, err := NewClient(suite.databasePath + "?_busy_timeout=5000")
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			defer client.Close()
+			errs[i] = client.Migrate(suite.migrations)
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		assert.NoError(suite.T(), err)
+	}
+
+	var count int
+	err := suite.client.QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&count)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, count)
+}
@@ -0,0 +1,180 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrInvalidOptions is wrapped by the errors returned by Options.Validate.
+var ErrInvalidOptions = errors.New("invalid sqlite client options")
+
+// Journal modes accepted by Options.JournalMode. See https://www.sqlite.org/pragma.html#pragma_journal_mode.
+const (
+	JournalModeWAL      = "WAL"
+	JournalModeDelete   = "DELETE"
+	JournalModeTruncate = "TRUNCATE"
+	JournalModePersist  = "PERSIST"
+	JournalModeMemory   = "MEMORY"
+	JournalModeOff      = "OFF"
+)
+
+// Synchronous levels accepted by Options.Synchronous. See https://www.sqlite.org/pragma.html#pragma_synchronous.
+const (
+	SynchronousOff    = "OFF"
+	SynchronousNormal = "NORMAL"
+	SynchronousFull   = "FULL"
+	SynchronousExtra  = "EXTRA"
+)
+
+// Transaction locking modes accepted by Options.TxLock. See https://www.sqlite.org/lang_transaction.html.
+const (
+	TxLockDeferred  = "deferred"
+	TxLockImmediate = "immediate"
+	TxLockExclusive = "exclusive"
+)
+
+// Options configures the connections opened by NewClientWithOptions.
+type Options struct {
+	// JournalMode is applied to every connection. WAL lets readers run while a writer commits.
+	JournalMode string
+	// Synchronous is the durability level. NORMAL is safe with WAL and much faster than FULL.
+	Synchronous string
+	// BusyTimeout is how long a connection waits for a lock held by another connection before failing with SQLITE_BUSY.
+	BusyTimeout time.Duration
+	// ForeignKeys enforces foreign key constraints.
+	ForeignKeys bool
+	// TxLock is the locking mode of BEGIN. Immediate takes the write lock up front, so a transaction never
+	// fails halfway when it upgrades from reading to writing.
+	TxLock string
+
+	// MaxOpenConns caps the connections of the write pool. Zero means unlimited.
+	MaxOpenConns int
+	// MaxIdleConns caps the idle connections kept by the write pool.
+	MaxIdleConns int
+	// ConnMaxLifetime closes connections older than this. Zero keeps them forever.
+	ConnMaxLifetime time.Duration
+
+	// ReadPool opens a second, query-only pool used by SELECT statements outside transactions.
+	// It requires a database file.
+	ReadPool bool
+	// MaxReadOpenConns caps the connections of the read pool. Zero means unlimited.
+	MaxReadOpenConns int
+	// MaxReadIdleConns caps the idle connections kept by the read pool.
+	MaxReadIdleConns int
+
+	// StatementCacheSize is the number of prepared statements kept for reuse. Zero disables the cache.
+	StatementCacheSize int
+
+	// DefaultTimeout bounds every *Context call whose context has no deadline. Zero disables it.
+	DefaultTimeout time.Duration
+	// TxRetries is how many times WithTx retries a transaction that failed with SQLITE_BUSY.
+	TxRetries int
+	// PingTimeout bounds the health check run by NewClientWithOptions.
+	PingTimeout time.Duration
+}
+
+// DefaultOptions returns options suited to a service with concurrent readers and writers:
+// WAL, synchronous NORMAL, a five second busy timeout, foreign keys, immediate transactions,
+// a single writer connection, a separate read pool and a cache of DefaultStatementCacheSize prepared statements.
+func DefaultOptions() Options {
+	return Options{
+		JournalMode:        JournalModeWAL,
+		Synchronous:        SynchronousNormal,
+		BusyTimeout:        5 * time.Second,
+		ForeignKeys:        true,
+		TxLock:             TxLockImmediate,
+		MaxOpenConns:       1,
+		MaxIdleConns:       1,
+		ReadPool:           true,
+		MaxReadOpenConns:   4,
+		MaxReadIdleConns:   4,
+		StatementCacheSize: DefaultStatementCacheSize,
+		TxRetries:          DefaultTxRetries,
+		PingTimeout:        5 * time.Second,
+	}
+}
+
+// Validate checks the options against the database path they will be used with.
+func (o Options) Validate(dbPath string) error {
+	if !oneOf(strings.ToUpper(o.JournalMode), "", JournalModeWAL, JournalModeDelete, JournalModeTruncate, JournalModePersist, JournalModeMemory, JournalModeOff) {
+		return fmt.Errorf("%w: unknown journal mode %q", ErrInvalidOptions, o.JournalMode)
+	}
+	if !oneOf(strings.ToUpper(o.Synchronous), "", SynchronousOff, SynchronousNormal, SynchronousFull, SynchronousExtra) {
+		return fmt.Errorf("%w: unknown synchronous level %q", ErrInvalidOptions, o.Synchronous)
+	}
+	if !oneOf(strings.ToLower(o.TxLock), "", TxLockDeferred, TxLockImmediate, TxLockExclusive) {
+		return fmt.Errorf("%w: unknown transaction lock %q", ErrInvalidOptions, o.TxLock)
+	}
+	if o.BusyTimeout < 0 || o.ConnMaxLifetime < 0 || o.DefaultTimeout < 0 || o.PingTimeout < 0 {
+		return fmt.Errorf("%w: durations cannot be negative", ErrInvalidOptions)
+	}
+	if o.MaxOpenConns < 0 || o.MaxIdleConns < 0 || o.MaxReadOpenConns < 0 || o.MaxReadIdleConns < 0 {
+		return fmt.Errorf("%w: connection limits cannot be negative", ErrInvalidOptions)
+	}
+	if o.MaxOpenConns > 0 && o.MaxIdleConns > o.MaxOpenConns {
+		return fmt.Errorf("%w: max idle connections %d exceed max open connections %d", ErrInvalidOptions, o.MaxIdleConns, o.MaxOpenConns)
+	}
+	if o.MaxReadOpenConns > 0 && o.MaxReadIdleConns > o.MaxReadOpenConns {
+		return fmt.Errorf("%w: max idle read connections %d exceed max open read connections %d", ErrInvalidOptions, o.MaxReadIdleConns, o.MaxReadOpenConns)
+	}
+	if o.StatementCacheSize < 0 {
+		return fmt.Errorf("%w: statement cache size cannot be negative", ErrInvalidOptions)
+	}
+	if o.TxRetries < 0 {
+		return fmt.Errorf("%w: transaction retries cannot be negative", ErrInvalidOptions)
+	}
+	if o.ReadPool && isInMemory(dbPath) {
+		return fmt.Errorf("%w: a read pool needs a database file, %q is in memory", ErrInvalidOptions, dbPath)
+	}
+	return nil
+}
+
+// dsn appends the pragmas of the options to the database path as go-sqlite3 parameters.
+// Parameters already present in dbPath take precedence.
+func (o Options) dsn(dbPath string, queryOnly bool) string {
+	params := url.Values{}
+	if o.JournalMode != "" {
+		params.Set("_journal_mode", strings.ToUpper(o.JournalMode))
+	}
+	if o.Synchronous != "" {
+		params.Set("_synchronous", strings.ToUpper(o.Synchronous))
+	}
+	if o.BusyTimeout > 0 {
+		params.Set("_busy_timeout", fmt.Sprint(o.BusyTimeout.Milliseconds()))
+	}
+	if o.ForeignKeys {
+		params.Set("_foreign_keys", "1")
+	}
+	if o.TxLock != "" && !queryOnly {
+		params.Set("_txlock", strings.ToLower(o.TxLock))
+	}
+	if queryOnly {
+		params.Set("_query_only", "1")
+	}
+	if len(params) == 0 {
+		return dbPath
+	}
+	separator := "?"
+	if strings.Contains(dbPath, "?") {
+		separator = "&"
+	}
+	return dbPath + separator + params.Encode()
+}
+
+// isInMemory reports whether the database path names an in-memory database, which lives in a single connection.
+func isInMemory(dbPath string) bool {
+	return dbPath == ":memory:" || strings.HasPrefix(dbPath, "file::memory:") || strings.Contains(dbPath, "mode=memory")
+}
+
+// oneOf reports whether value is one of the allowed values.
+func oneOf(value string, allowed ...string) bool {
+	for _, candidate := range allowed {
+		if value == candidate {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,208 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type SQLiteClientOptionsTestSuite struct {
+	suite.Suite
+	databasePath string
+}
+
+func TestSQLiteClientOptionsTestSuite(t *testing.T) {
+	suite.Run(t, new(SQLiteClientOptionsTestSuite))
+}
+
+func (suite *SQLiteClientOptionsTestSuite) SetupTest() {
+	suite.databasePath = filepath.Join(suite.T().TempDir(), "test.db")
+}
+
+// pragma returns the value of a pragma as seen by a connection of the given pool.
+func (suite *SQLiteClientOptionsTestSuite) pragma(client *Client, query string) string {
+	var value string
+	assert.NoError(suite.T(), client.db.QueryRow(query).Scan(&value))
+	return strings.ToLower(value)
+}
+
+func (suite *SQLiteClientOptionsTestSuite) TestValidate() {
+	tests := []struct {
+		name    string
+		path    string
+		options func(o *Options)
+		valid   bool
+	}{
+		{name: "defaults", path: "test.db", options: func(o *Options) {}, valid: true},
+		{name: "lower case values", path: "test.db", options: func(o *Options) { o.JournalMode, o.Synchronous = "wal", "full" }, valid: true},
+		{name: "unknown journal mode", path: "test.db", options: func(o *Options) { o.JournalMode = "FAST" }},
+		{name: "unknown synchronous level", path: "test.db", options: func(o *Options) { o.Synchronous = "SOMETIMES" }},
+		{name: "unknown transaction lock", path: "test.db", options: func(o *Options) { o.TxLock = "eager" }},
+		{name: "negative busy timeout", path: "test.db", options: func(o *Options) { o.BusyTimeout = -time.Second }},
+		{name: "negative connections", path: "test.db", options: func(o *Options) { o.MaxReadOpenConns = -1 }},
+		{name: "more idle than open", path: "test.db", options: func(o *Options) { o.MaxIdleConns = 2 }},
+		{name: "negative retries", path: "test.db", options: func(o *Options) { o.TxRetries = -1 }},
+		{name: "read pool in memory", path: ":memory:", options: func(o *Options) {}},
+		{name: "no read pool in memory", path: ":memory:", options: func(o *Options) { o.ReadPool = false }, valid: true},
+	}
+	for _, tt := range tests {
+		suite.T().Run(tt.name, func(t *testing.T) {
+			options := DefaultOptions()
+			tt.options(&options)
+
+			err := options.Validate(tt.path)
+
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, ErrInvalidOptions), "unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func (suite *SQLiteClientOptionsTestSuite) TestNewClientWithInvalidOptions() {
+	options := DefaultOptions()
+	options.Synchronous = "SOMETIMES"
+
+	client, err := NewClientWithOptions(suite.databasePath, options)
+
+	assert.Nil(suite.T(), client)
+	assert.ErrorIs(suite.T(), err, ErrInvalidOptions)
+}
+
+func (suite *SQLiteClientOptionsTestSuite) TestNewClientPingFails() {
+	client, err := NewClientWithOptions(filepath.Join(suite.databasePath, "missing", "test.db"), DefaultOptions())
+
+	assert.Nil(suite.T(), client)
+	assert.Error(suite.T(), err)
+}
+
+func (suite *SQLiteClientOptionsTestSuite) TestPragmasAreApplied() {
+	options := DefaultOptions()
+	options.Synchronous = SynchronousFull
+	options.BusyTimeout = 1500 * time.Millisecond
+	client, err := NewClientWithOptions(suite.databasePath, options)
+	suite.Require().NoError(err)
+	defer client.Close()
+
+	assert.Equal(suite.T(), "wal", suite.pragma(client, "PRAGMA journal_mode"))
+	assert.Equal(suite.T(), "2", suite.pragma(client, "PRAGMA synchronous"))
+	assert.Equal(suite.T(), "1500", suite.pragma(client, "PRAGMA busy_timeout"))
+	assert.Equal(suite.T(), "1", suite.pragma(client, "PRAGMA foreign_keys"))
+	assert.Equal(suite.T(), 1, client.db.Stats().MaxOpenConnections)
+}
+
+func (suite *SQLiteClientOptionsTestSuite) TestParametersInPathWin() {
+	client, err := NewClient(suite.databasePath + "?_busy_timeout=250")
+	suite.Require().NoError(err)
+	defer client.Close()
+
+	assert.Equal(suite.T(), "250", suite.pragma(client, "PRAGMA busy_timeout"))
+}
+
+func (suite *SQLiteClientOptionsTestSuite) TestInMemoryUsesASingleConnection() {
+	options := DefaultOptions()
+	options.ReadPool = false
+	options.MaxOpenConns = 0
+	client, err := NewClientWithOptions(":memory:", options)
+	suite.Require().NoError(err)
+	defer client.Close()
+
+	assert.Equal(suite.T(), 1, client.db.Stats().MaxOpenConnections)
+	assert.Nil(suite.T(), client.readDB)
+}
+
+func (suite *SQLiteClientOptionsTestSuite) TestReadPool() {
+	client, err := NewClient(suite.databasePath)
+	suite.Require().NoError(err)
+	defer client.Close()
+	suite.Require().NotNil(client.readDB)
+	ctx := context.Background()
+	assert.NoError(suite.T(), client.ExecContext(ctx, "CREATE TABLE test (id INTEGER PRIMARY KEY, name TEXT)"))
+	assert.NoError(suite.T(), client.ExecContext(ctx, "INSERT INTO test (name) VALUES (?)", "Alice"))
+
+	// SELECT statements read from the query-only pool.
+	var name string
+	assert.NoError(suite.T(), client.QueryRowContext(ctx, "SELECT name FROM test WHERE id = 1").Scan(&name))
+	assert.Equal(suite.T(), "Alice", name)
+	var queryOnly string
+	assert.NoError(suite.T(), client.QueryRowContext(ctx, "SELECT * FROM pragma_query_only").Scan(&queryOnly))
+	assert.Equal(suite.T(), "1", queryOnly)
+	_, err = client.readDB.Exec("INSERT INTO test (name) VALUES (?)", "Mallory")
+	assert.Error(suite.T(), err)
+
+	// Statements that write and return rows stay on the write pool.
+	var id int64
+	assert.NoError(suite.T(), client.QueryRowContext(ctx, "DELETE FROM test WHERE name = ? RETURNING id", "Alice").Scan(&id))
+	assert.Equal(suite.T(), int64(1), id)
+
+	// Inside a transaction, reads see the uncommitted writes of the transaction.
+	err = client.WithTx(ctx, func(tx *Tx) error {
+		if err := client.ExecContext(tx.Context(), "INSERT INTO test (name) VALUES (?)", "Bob"); err != nil {
+			return err
+		}
+		return client.QueryRowContext(tx.Context(), "SELECT name FROM test").Scan(&name)
+	})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Bob", name)
+}
+
+func (suite *SQLiteClientOptionsTestSuite) TestImmediateTransactionsFailBeforeRunning() {
+	client, err := NewClient(suite.databasePath)
+	suite.Require().NoError(err)
+	defer client.Close()
+	assert.NoError(suite.T(), client.Exec("CREATE TABLE test (id INTEGER PRIMARY KEY, name TEXT)"))
+	other, err := NewClient(suite.databasePath + "?_busy_timeout=0")
+	suite.Require().NoError(err)
+	defer other.Close()
+	other.SetTxRetries(0)
+
+	attempts := 0
+	err = client.WithTx(context.Background(), func(tx *Tx) error {
+		otherErr := other.WithTx(context.Background(), func(otherTx *Tx) error {
+			attempts++
+			return nil
+		})
+		assert.True(suite.T(), isBusy(otherErr), "unexpected error: %v", otherErr)
+		return nil
+	})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, attempts)
+}
+
+func (suite *SQLiteClientOptionsTestSuite) TestIsSelect() {
+	assert.True(suite.T(), isSelect("SELECT 1"))
+	assert.True(suite.T(), isSelect("\n    select id FROM test"))
+	assert.True(suite.T(), isSelect("WITH c AS (SELECT 1) SELECT * FROM c"))
+	assert.False(suite.T(), isSelect("WITH c AS (SELECT 1) DELETE FROM test WHERE id IN c"))
+	assert.False(suite.T(), isSelect("DELETE FROM test RETURNING id"))
+	assert.False(suite.T(), isSelect(""))
+	assert.True(suite.T(), isSelect("-- latest quotes\n/* by pair */ SELECT * FROM test;"))
+	assert.True(suite.T(), isSelect("VALUES (1), (2)"))
+	assert.True(suite.T(), isSelect("WITH c(id) AS (SELECT id FROM test WHERE name = 'insert') SELECT updated_at FROM c"))
+	assert.False(suite.T(), isSelect("/* SELECT */ INSERT INTO test (name) SELECT name FROM other"))
+	assert.False(suite.T(), isSelect("WITH c AS (SELECT 1) INSERT INTO test (id) SELECT * FROM c"))
+	assert.False(suite.T(), isSelect("with c as materialized (select 1) update test set name = 'x'"))
+	assert.False(suite.T(), isSelect("SELECT 1; DELETE FROM test"))
+	assert.False(suite.T(), isSelect("-- SELECT\nDELETE FROM test"))
+}
+
+func (suite *SQLiteClientOptionsTestSuite) TestWriteAfterCommentUsesWritePool() {
+	client, err := NewClient(suite.databasePath)
+	suite.Require().NoError(err)
+	defer client.Close()
+	suite.Require().NotNil(client.readDB)
+	assert.NoError(suite.T(), client.Exec("CREATE TABLE test (id INTEGER PRIMARY KEY, name TEXT)"))
+
+	rows, err := client.Query("/* copy */ WITH c AS (SELECT 'Alice' AS name) INSERT INTO test (name) SELECT name FROM c RETURNING id")
+	assert.NoError(suite.T(), err, "the read-only pool would reject the insert")
+	assert.True(suite.T(), rows.Next())
+	assert.NoError(suite.T(), rows.Close())
+}
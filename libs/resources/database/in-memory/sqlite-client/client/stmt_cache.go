@@ -0,0 +1,337 @@
+package client
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultStatementCacheSize is the number of prepared statements kept per client by DefaultOptions.
+const DefaultStatementCacheSize = 64
+
+// StatementCacheStats reports the activity of the prepared statement cache of a client.
+type StatementCacheStats struct {
+	// Hits counts queries that reused a prepared statement.
+	Hits uint64
+	// Misses counts queries that had to prepare their statement.
+	Misses uint64
+	// Evictions counts statements dropped to keep the cache within its size.
+	Evictions uint64
+	// Invalidations counts the times the cache was cleared after a schema change.
+	Invalidations uint64
+	// Size is the number of statements currently cached.
+	Size int
+}
+
+// stmtKey identifies a prepared statement: statements belong to the pool that prepared them.
+type stmtKey struct {
+	pool  *sql.DB
+	query string
+}
+
+// cachedStmt is a prepared statement shared by concurrent queries. It is closed once it has left the cache
+// and the last query using it has released it.
+type cachedStmt struct {
+	key     stmtKey
+	stmt    *sql.Stmt
+	element *list.Element
+	refs    int
+	evicted bool
+}
+
+// stmtCache is a least recently used cache of prepared statements, keyed by pool and query text.
+type stmtCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[stmtKey]*cachedStmt
+	order   *list.List
+
+	hits          atomic.Uint64
+	misses        atomic.Uint64
+	evictions     atomic.Uint64
+	invalidations atomic.Uint64
+}
+
+// newStmtCache returns a cache holding at most size statements, or nil when size is zero, which disables caching.
+func newStmtCache(size int) *stmtCache {
+	if size <= 0 {
+		return nil
+	}
+	return &stmtCache{
+		size:    size,
+		entries: make(map[stmtKey]*cachedStmt),
+		order:   list.New(),
+	}
+}
+
+// acquire returns the statement of query on pool, preparing it on a miss. The statement must be released.
+func (s *stmtCache) acquire(ctx context.Context, pool *sql.DB, query string) (*cachedStmt, error) {
+	key := stmtKey{pool: pool, query: query}
+	if entry := s.lookup(key); entry != nil {
+		s.hits.Add(1)
+		return entry, nil
+	}
+	s.misses.Add(1)
+	// Prepare outside the lock, so a slow prepare does not hold up hits on other statements.
+	stmt, err := pool.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if entry, ok := s.entries[key]; ok {
+		// Another query prepared the same statement in the meantime.
+		entry.refs++
+		s.order.MoveToFront(entry.element)
+		s.mu.Unlock()
+		closeStmt(stmt)
+		return entry, nil
+	}
+	entry := &cachedStmt{key: key, stmt: stmt, refs: 1}
+	entry.element = s.order.PushFront(entry)
+	s.entries[key] = entry
+	var evicted []*sql.Stmt
+	for s.order.Len() > s.size {
+		oldest := s.order.Back().Value.(*cachedStmt)
+		if stmt := s.remove(oldest); stmt != nil {
+			evicted = append(evicted, stmt)
+		}
+		s.evictions.Add(1)
+	}
+	s.mu.Unlock()
+	closeStmts(evicted)
+	return entry, nil
+}
+
+// peek returns the statement of query on pool when it is cached, without preparing it. The statement must be released.
+func (s *stmtCache) peek(pool *sql.DB, query string) *cachedStmt {
+	entry := s.lookup(stmtKey{pool: pool, query: query})
+	if entry != nil {
+		s.hits.Add(1)
+	}
+	return entry
+}
+
+// lookup returns the cached statement of key with a reference taken, or nil.
+func (s *stmtCache) lookup(key stmtKey) *cachedStmt {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil
+	}
+	entry.refs++
+	s.order.MoveToFront(entry.element)
+	return entry
+}
+
+// release gives back a statement returned by acquire or peek, closing it if it left the cache meanwhile.
+func (s *stmtCache) release(entry *cachedStmt) {
+	s.mu.Lock()
+	entry.refs--
+	closable := entry.evicted && entry.refs == 0
+	s.mu.Unlock()
+	if closable {
+		closeStmt(entry.stmt)
+	}
+}
+
+// invalidate drops every statement. Statements in use are closed when released.
+func (s *stmtCache) invalidate() {
+	s.mu.Lock()
+	var evicted []*sql.Stmt
+	for _, entry := range s.entries {
+		if stmt := s.remove(entry); stmt != nil {
+			evicted = append(evicted, stmt)
+		}
+	}
+	s.mu.Unlock()
+	s.invalidations.Add(1)
+	closeStmts(evicted)
+}
+
+// remove drops entry from the cache and returns its statement when nobody uses it, so the caller closes it
+// after unlocking. It must be called with the lock held.
+func (s *stmtCache) remove(entry *cachedStmt) *sql.Stmt {
+	s.order.Remove(entry.element)
+	delete(s.entries, entry.key)
+	entry.evicted = true
+	if entry.refs == 0 {
+		return entry.stmt
+	}
+	return nil
+}
+
+// stats returns a snapshot of the counters of the cache.
+func (s *stmtCache) stats() StatementCacheStats {
+	s.mu.Lock()
+	size := len(s.entries)
+	s.mu.Unlock()
+	return StatementCacheStats{
+		Hits:          s.hits.Load(),
+		Misses:        s.misses.Load(),
+		Evictions:     s.evictions.Load(),
+		Invalidations: s.invalidations.Load(),
+		Size:          size,
+	}
+}
+
+func closeStmt(stmt *sql.Stmt) {
+	if err := stmt.Close(); err != nil {
+		log.Printf("Error closing prepared statement: %v", err)
+	}
+}
+
+func closeStmts(stmts []*sql.Stmt) {
+	for _, stmt := range stmts {
+		closeStmt(stmt)
+	}
+}
+
+// StatementCacheStats returns the hit, miss, eviction and invalidation counters of the prepared statement cache.
+// They are all zero when the cache is disabled.
+func (c *Client) StatementCacheStats() StatementCacheStats {
+	if c.statements == nil {
+		return StatementCacheStats{}
+	}
+	return c.statements.stats()
+}
+
+// InvalidateStatements drops every cached prepared statement. The client calls it after the statements it runs
+// change the schema; call it after changing the schema through another client.
+func (c *Client) InvalidateStatements() {
+	if c.statements != nil {
+		c.statements.invalidate()
+	}
+}
+
+// statement returns a cached prepared statement running query on exec, and the func that releases it.
+// It returns ok false when the query is not cached, in which case the caller runs the query on exec directly.
+// Inside a transaction, only statements already in the cache are used: preparing one would need a connection
+// of the pool while the transaction may hold the only one.
+func (c *Client) statement(ctx context.Context, exec executor, query string) (stmt *sql.Stmt, release func(), ok bool) {
+	if c.statements == nil || !isCacheable(query) {
+		return nil, nil, false
+	}
+	switch exec := exec.(type) {
+	case *sql.DB:
+		entry, err := c.statements.acquire(ctx, exec, query)
+		if err != nil {
+			// Running the query unprepared reports the same error to the caller.
+			return nil, nil, false
+		}
+		return entry.stmt, func() { c.statements.release(entry) }, true
+	case *sql.Tx:
+		entry := c.statements.peek(c.db, query)
+		if entry == nil {
+			return nil, nil, false
+		}
+		txStmt := exec.StmtContext(ctx, entry.stmt)
+		return txStmt, func() {
+			txStmt.Close()
+			c.statements.release(entry)
+		}, true
+	}
+	return nil, nil, false
+}
+
+// cacheableKeywords are the first keywords of the statements worth preparing once.
+var cacheableKeywords = []string{"SELECT", "INSERT", "UPDATE", "DELETE", "REPLACE", "WITH"}
+
+// isCacheable reports whether query is a single data statement. A prepared statement only runs the first
+// statement of its query, so queries holding several statements always run unprepared.
+func isCacheable(query string) bool {
+	trimmed := strings.TrimRight(strings.TrimSpace(query), ";")
+	if strings.Contains(trimmed, ";") {
+		return false
+	}
+	return oneOf(firstKeyword(trimmed), cacheableKeywords...)
+}
+
+// schemaKeywords are the first keywords of the statements that change the schema.
+var schemaKeywords = []string{"CREATE", "DROP", "ALTER"}
+
+// changesSchema reports whether one of the statements of query creates, drops or alters a table, index or view.
+func changesSchema(query string) bool {
+	for _, statement := range strings.Split(query, ";") {
+		if oneOf(firstKeyword(statement), schemaKeywords...) {
+			return true
+		}
+	}
+	return false
+}
+
+// firstKeyword returns the first word of a statement in upper case, after any leading comments.
+func firstKeyword(statement string) string {
+	words := topLevelWords(statement)
+	if len(words) == 0 {
+		return ""
+	}
+	return words[0]
+}
+
+// topLevelWords returns the words of query outside parentheses, in upper case. Comments, string literals and
+// quoted identifiers are skipped, and every ";" is returned as a word of its own.
+func topLevelWords(query string) []string {
+	var words []string
+	depth := 0
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				return words
+			}
+			i += end + 1
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return words
+			}
+			i += end + 4
+		case c == '\'' || c == '"' || c == '`' || c == '[':
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+			end := strings.IndexByte(query[i+1:], closing)
+			if end < 0 {
+				return words
+			}
+			// A doubled quote inside a literal is skipped as two adjacent literals.
+			i += end + 2
+		case c == '(':
+			depth++
+			i++
+		case c == ')':
+			depth--
+			i++
+		case c == ';':
+			if depth == 0 {
+				words = append(words, ";")
+			}
+			i++
+		case isWordByte(c):
+			start := i
+			for i < len(query) && isWordByte(query[i]) {
+				i++
+			}
+			if depth == 0 {
+				words = append(words, strings.ToUpper(query[start:i]))
+			}
+		default:
+			i++
+		}
+	}
+	return words
+}
+
+// isWordByte reports whether c can be part of a keyword or an unquoted identifier.
+func isWordByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
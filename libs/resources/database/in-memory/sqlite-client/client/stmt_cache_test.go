@@ -0,0 +1,232 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+const upsertQuery = `
+    INSERT INTO test (id, name) VALUES (?, ?)
+    ON CONFLICT(id) DO UPDATE SET name = excluded.name
+    `
+
+type SQLiteClientStatementCacheTestSuite struct {
+	suite.Suite
+	client *Client
+}
+
+func TestSQLiteClientStatementCacheTestSuite(t *testing.T) {
+	suite.Run(t, new(SQLiteClientStatementCacheTestSuite))
+}
+
+func (suite *SQLiteClientStatementCacheTestSuite) SetupTest() {
+	suite.client = suite.newClient(filepath.Join(suite.T().TempDir(), "test.db"), 4)
+}
+
+func (suite *SQLiteClientStatementCacheTestSuite) TearDownTest() {
+	suite.client.Close()
+}
+
+// newClient opens a client caching size statements, with a test table.
+func (suite *SQLiteClientStatementCacheTestSuite) newClient(path string, size int) *Client {
+	options := DefaultOptions()
+	options.StatementCacheSize = size
+	options.ReadPool = !isInMemory(path)
+	client, err := NewClientWithOptions(path, options)
+	suite.Require().NoError(err)
+	suite.Require().NoError(client.ExecContext(context.Background(), "CREATE TABLE test (id INTEGER PRIMARY KEY, name TEXT)"))
+	return client
+}
+
+func (suite *SQLiteClientStatementCacheTestSuite) name(id int) string {
+	var name string
+	assert.NoError(suite.T(), suite.client.QueryRowContext(context.Background(), "SELECT name FROM test WHERE id = ?", id).Scan(&name))
+	return name
+}
+
+func (suite *SQLiteClientStatementCacheTestSuite) TestHitsAndMisses() {
+	ctx := context.Background()
+	assert.NoError(suite.T(), suite.client.ExecContext(ctx, upsertQuery, 1, "Alice"))
+	assert.NoError(suite.T(), suite.client.ExecContext(ctx, upsertQuery, 1, "Bob"))
+	assert.NoError(suite.T(), suite.client.ExecContext(ctx, upsertQuery, 2, "Carol"))
+
+	stats := suite.client.StatementCacheStats()
+	assert.Equal(suite.T(), uint64(1), stats.Misses)
+	assert.Equal(suite.T(), uint64(2), stats.Hits)
+	assert.Equal(suite.T(), 1, stats.Size)
+	assert.Equal(suite.T(), "Bob", suite.name(1))
+}
+
+func (suite *SQLiteClientStatementCacheTestSuite) TestSizeIsBounded() {
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		var value int
+		assert.NoError(suite.T(), suite.client.QueryRowContext(ctx, fmt.Sprintf("SELECT %d", i)).Scan(&value))
+		assert.Equal(suite.T(), i, value)
+	}
+
+	stats := suite.client.StatementCacheStats()
+	assert.Equal(suite.T(), 4, stats.Size)
+	assert.Equal(suite.T(), uint64(6), stats.Evictions)
+
+	// The most recently used statements are kept.
+	var value int
+	assert.NoError(suite.T(), suite.client.QueryRowContext(ctx, "SELECT 9").Scan(&value))
+	assert.Equal(suite.T(), uint64(1), suite.client.StatementCacheStats().Hits)
+}
+
+func (suite *SQLiteClientStatementCacheTestSuite) TestStatementInUseSurvivesEviction() {
+	ctx := context.Background()
+	assert.NoError(suite.T(), suite.client.ExecContext(ctx, upsertQuery, 1, "Alice"))
+	assert.NoError(suite.T(), suite.client.ExecContext(ctx, upsertQuery, 2, "Bob"))
+	rows, err := suite.client.QueryContext(ctx, "SELECT name FROM test ORDER BY id")
+	suite.Require().NoError(err)
+
+	suite.client.InvalidateStatements()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		assert.NoError(suite.T(), rows.Scan(&name))
+		names = append(names, name)
+	}
+	assert.NoError(suite.T(), rows.Err())
+	assert.NoError(suite.T(), rows.Close())
+	assert.NoError(suite.T(), rows.Close())
+	assert.Equal(suite.T(), []string{"Alice", "Bob"}, names)
+	assert.Equal(suite.T(), 0, suite.client.StatementCacheStats().Size)
+}
+
+func (suite *SQLiteClientStatementCacheTestSuite) TestSchemaChangeInvalidates() {
+	ctx := context.Background()
+	assert.NoError(suite.T(), suite.client.ExecContext(ctx, upsertQuery, 1, "Alice"))
+	before := suite.client.StatementCacheStats()
+	assert.Equal(suite.T(), 1, before.Size)
+
+	assert.NoError(suite.T(), suite.client.ExecContext(ctx, "DROP TABLE test"))
+	assert.NoError(suite.T(), suite.client.Exec("CREATE TABLE test (id INTEGER PRIMARY KEY, name TEXT, extra TEXT DEFAULT 'x')"))
+
+	stats := suite.client.StatementCacheStats()
+	assert.Equal(suite.T(), 0, stats.Size)
+	assert.Equal(suite.T(), before.Invalidations+2, stats.Invalidations)
+	assert.NoError(suite.T(), suite.client.ExecContext(ctx, upsertQuery, 1, "Bob"))
+	assert.Equal(suite.T(), "Bob", suite.name(1))
+}
+
+func (suite *SQLiteClientStatementCacheTestSuite) TestMigrationsInvalidate() {
+	assert.NoError(suite.T(), suite.client.ExecContext(context.Background(), upsertQuery, 1, "Alice"))
+
+	err := suite.client.Migrate([]Migration{{Version: 1, Name: "add_extra", UpSQL: "ALTER TABLE test ADD COLUMN extra TEXT"}})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, suite.client.StatementCacheStats().Size)
+}
+
+func (suite *SQLiteClientStatementCacheTestSuite) TestMultipleStatementsAreNotCached() {
+	ctx := context.Background()
+	err := suite.client.ExecContext(ctx, "INSERT INTO test (name) VALUES ('Alice'); INSERT INTO test (name) VALUES ('Bob');")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, suite.client.StatementCacheStats().Size)
+	assert.Equal(suite.T(), "Bob", suite.name(2))
+}
+
+func (suite *SQLiteClientStatementCacheTestSuite) TestTransactionsReuseCachedStatements() {
+	ctx := context.Background()
+	assert.NoError(suite.T(), suite.client.ExecContext(ctx, upsertQuery, 1, "Alice"))
+
+	err := suite.client.WithTx(ctx, func(tx *Tx) error {
+		if err := tx.ExecContext(tx.Context(), upsertQuery, 1, "Bob"); err != nil {
+			return err
+		}
+		return tx.ExecContext(tx.Context(), upsertQuery, 2, "Carol")
+	})
+
+	assert.NoError(suite.T(), err)
+	stats := suite.client.StatementCacheStats()
+	assert.Equal(suite.T(), uint64(1), stats.Misses)
+	assert.Equal(suite.T(), uint64(2), stats.Hits)
+	assert.Equal(suite.T(), "Bob", suite.name(1))
+	assert.Equal(suite.T(), "Carol", suite.name(2))
+}
+
+func (suite *SQLiteClientStatementCacheTestSuite) TestConcurrentReuse() {
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				id := worker*100 + i
+				assert.NoError(suite.T(), suite.client.ExecContext(ctx, upsertQuery, id, "name"))
+				var name string
+				assert.NoError(suite.T(), suite.client.QueryRowContext(ctx, "SELECT name FROM test WHERE id = ?", id).Scan(&name))
+				// Distinct queries force evictions while other workers use the statements.
+				var value int
+				assert.NoError(suite.T(), suite.client.QueryRowContext(ctx, fmt.Sprintf("SELECT %d", i%6)).Scan(&value))
+			}
+		}(worker)
+	}
+	wg.Wait()
+
+	var count int
+	assert.NoError(suite.T(), suite.client.QueryRowContext(ctx, "SELECT COUNT(*) FROM test").Scan(&count))
+	assert.Equal(suite.T(), 160, count)
+	assert.LessOrEqual(suite.T(), suite.client.StatementCacheStats().Size, 4)
+}
+
+func (suite *SQLiteClientStatementCacheTestSuite) TestDisabled() {
+	client := suite.newClient(":memory:", 0)
+	defer client.Close()
+
+	assert.NoError(suite.T(), client.ExecContext(context.Background(), upsertQuery, 1, "Alice"))
+	assert.Equal(suite.T(), StatementCacheStats{}, client.StatementCacheStats())
+}
+
+func TestIsCacheable(t *testing.T) {
+	assert.True(t, isCacheable(upsertQuery))
+	assert.True(t, isCacheable("SELECT 1;"))
+	assert.False(t, isCacheable("SELECT 1; SELECT 2"))
+	assert.False(t, isCacheable("CREATE TABLE test (id INTEGER)"))
+	assert.False(t, isCacheable("SAVEPOINT sp_1"))
+	assert.True(t, changesSchema("INSERT INTO test VALUES (1); DROP INDEX idx_test"))
+	assert.False(t, changesSchema(upsertQuery))
+}
+
+// benchmarkUpsert runs the same upsert repeatedly on a client caching size statements.
+func benchmarkUpsert(b *testing.B, size int) {
+	options := DefaultOptions()
+	options.StatementCacheSize = size
+	options.Synchronous = SynchronousOff
+	client, err := NewClientWithOptions(filepath.Join(b.TempDir(), "bench.db"), options)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+	if err := client.ExecContext(ctx, "CREATE TABLE test (id INTEGER PRIMARY KEY, name TEXT)"); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := client.ExecContext(ctx, upsertQuery, i%100, "name"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUpsertCached(b *testing.B) {
+	benchmarkUpsert(b, DefaultStatementCacheSize)
+}
+
+func BenchmarkUpsertUncached(b *testing.B) {
+	benchmarkUpsert(b, 0)
+}
@@ -0,0 +1,162 @@
+package client
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// DefaultTxRetries is how many times WithTx retries a transaction that failed because the database was busy.
+const DefaultTxRetries = 3
+
+// txRetryBackoff is the wait before the first retry of a busy transaction. It doubles on every retry.
+const txRetryBackoff = 10 * time.Millisecond
+
+// txContextKey is the context key holding the current *Tx.
+type txContextKey struct{}
+
+// Tx is a transaction started by WithTx. It is not safe for concurrent use.
+type Tx struct {
+	client     *Client
+	tx         *sql.Tx
+	ctx        context.Context
+	savepoints int
+}
+
+// TxFromContext returns the transaction carried by a context passed down from WithTx.
+func TxFromContext(ctx context.Context) (*Tx, bool) {
+	tx, ok := ctx.Value(txContextKey{}).(*Tx)
+	return tx, ok
+}
+
+// SetTxRetries changes how many times WithTx retries a transaction that failed with SQLITE_BUSY.
+func (c *Client) SetTxRetries(retries int) {
+	c.txRetries = retries
+}
+
+// WithTx runs fn in a transaction, committing when fn returns nil and rolling back when it returns an error or panics.
+// When ctx already carries a transaction of the client, fn runs in a savepoint of it instead.
+// A transaction that fails because the database is busy is retried from the start, so fn may run more than once.
+// Calls made inside fn must use tx.Context(): the transaction holds a connection of the write pool, which has a
+// single connection by default, so a call made with another context, or with Exec, waits for it until that
+// context is done and never returns without a deadline.
+func (c *Client) WithTx(ctx context.Context, fn func(tx *Tx) error) error {
+	if tx, ok := TxFromContext(ctx); ok && tx.client == c {
+		return tx.WithTx(fn)
+	}
+	backoff := txRetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := c.runTx(ctx, fn)
+		if err == nil || !isBusy(err) || attempt >= c.txRetries {
+			return err
+		}
+		log.Printf("Transaction failed because the database is busy, retrying in %s: %v", backoff, err)
+		select {
+		case <-ctx.Done():
+			return mapContextError(ctx, "BEGIN", time.Now(), ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// runTx runs a single attempt of a transaction.
+func (c *Client) runTx(ctx context.Context, fn func(tx *Tx) error) (err error) {
+	start := time.Now()
+	sqlTx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return mapContextError(ctx, "BEGIN", start, err)
+	}
+	tx := &Tx{client: c, tx: sqlTx}
+	tx.ctx = context.WithValue(ctx, txContextKey{}, tx)
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			if rollbackErr := sqlTx.Rollback(); rollbackErr != nil {
+				log.Printf("Error rolling back transaction: %v", rollbackErr)
+			}
+			panic(recovered)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		if rollbackErr := sqlTx.Rollback(); rollbackErr != nil && !errors.Is(rollbackErr, sql.ErrTxDone) {
+			log.Printf("Error rolling back transaction: %v", rollbackErr)
+		}
+		return err
+	}
+	if err := sqlTx.Commit(); err != nil {
+		return mapContextError(ctx, "COMMIT", start, err)
+	}
+	return nil
+}
+
+// Context returns a context carrying the transaction. Client calls made with it, and repositories using
+// the client, run inside the transaction.
+func (t *Tx) Context() context.Context {
+	return t.ctx
+}
+
+// WithTx runs fn in a savepoint of the transaction, releasing it when fn returns nil and
+// rolling back to it when fn returns an error or panics. The outer transaction stays usable.
+func (t *Tx) WithTx(fn func(tx *Tx) error) (err error) {
+	t.savepoints++
+	name := fmt.Sprintf("sp_%d", t.savepoints)
+	defer func() { t.savepoints-- }()
+
+	if err := t.ExecContext(t.ctx, "SAVEPOINT "+name); err != nil {
+		return err
+	}
+	// ROLLBACK TO keeps the savepoint open, so it is released afterwards. The statements run one at a time,
+	// and a failed rollback is not followed by a release that would keep the writes of fn.
+	rollback := func() {
+		if err := t.ExecContext(t.ctx, "ROLLBACK TO "+name); err != nil {
+			log.Printf("Error rolling back savepoint %s: %v", name, err)
+			return
+		}
+		if err := t.ExecContext(t.ctx, "RELEASE "+name); err != nil {
+			log.Printf("Error releasing savepoint %s: %v", name, err)
+		}
+	}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			rollback()
+			panic(recovered)
+		}
+	}()
+
+	if err := fn(t); err != nil {
+		rollback()
+		return err
+	}
+	return t.ExecContext(t.ctx, "RELEASE "+name)
+}
+
+// ExecContext executes a query without returning any rows inside the transaction.
+func (t *Tx) ExecContext(ctx context.Context, query string, args ...interface{}) error {
+	return t.client.execContext(ctx, t.tx, query, args...)
+}
+
+// QueryRowContext executes a query that is expected to return at most one row inside the transaction.
+func (t *Tx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *Row {
+	return t.client.queryRowContext(ctx, t.tx, query, args...)
+}
+
+// QueryContext executes a query that returns rows inside the transaction.
+func (t *Tx) QueryContext(ctx context.Context, query string, args ...interface{}) (*Rows, error) {
+	return t.client.queryContext(ctx, t.tx, query, args...)
+}
+
+// isBusy reports whether err means the database was locked by another connection.
+func isBusy(err error) bool {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+}
@@ -0,0 +1,200 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type SQLiteClientTxTestSuite struct {
+	suite.Suite
+	client       *Client
+	databasePath string
+}
+
+func TestSQLiteClientTxTestSuite(t *testing.T) {
+	suite.Run(t, new(SQLiteClientTxTestSuite))
+}
+
+func (suite *SQLiteClientTxTestSuite) SetupTest() {
+	suite.databasePath = filepath.Join(suite.T().TempDir(), "test.db")
+	client, err := NewClient(suite.databasePath)
+	assert.NoError(suite.T(), err)
+	suite.client = client
+	err = suite.client.Exec("CREATE TABLE test (id INTEGER PRIMARY KEY, name TEXT)")
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *SQLiteClientTxTestSuite) TearDownTest() {
+	suite.client.Close()
+}
+
+func (suite *SQLiteClientTxTestSuite) names() []string {
+	rows, err := suite.client.QueryContext(context.Background(), "SELECT name FROM test ORDER BY id")
+	assert.NoError(suite.T(), err)
+	defer rows.Close()
+	names := []string{}
+	for rows.Next() {
+		var name string
+		assert.NoError(suite.T(), rows.Scan(&name))
+		names = append(names, name)
+	}
+	return names
+}
+
+func (suite *SQLiteClientTxTestSuite) TestCommit() {
+	err := suite.client.WithTx(context.Background(), func(tx *Tx) error {
+		if err := tx.ExecContext(tx.Context(), "INSERT INTO test (name) VALUES (?)", "Alice"); err != nil {
+			return err
+		}
+		// Client calls made with the transaction context run inside the transaction.
+		return suite.client.ExecContext(tx.Context(), "INSERT INTO test (name) VALUES (?)", "Bob")
+	})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"Alice", "Bob"}, suite.names())
+}
+
+func (suite *SQLiteClientTxTestSuite) TestRollbackOnError() {
+	boom := errors.New("boom")
+	err := suite.client.WithTx(context.Background(), func(tx *Tx) error {
+		if err := suite.client.ExecContext(tx.Context(), "INSERT INTO test (name) VALUES (?)", "Alice"); err != nil {
+			return err
+		}
+		return boom
+	})
+	assert.ErrorIs(suite.T(), err, boom)
+	assert.Empty(suite.T(), suite.names())
+}
+
+func (suite *SQLiteClientTxTestSuite) TestRollbackOnPanic() {
+	assert.Panics(suite.T(), func() {
+		_ = suite.client.WithTx(context.Background(), func(tx *Tx) error {
+			if err := tx.ExecContext(tx.Context(), "INSERT INTO test (name) VALUES (?)", "Alice"); err != nil {
+				return err
+			}
+			panic("boom")
+		})
+	})
+	assert.Empty(suite.T(), suite.names())
+}
+
+func (suite *SQLiteClientTxTestSuite) TestNestedSavepoints() {
+	err := suite.client.WithTx(context.Background(), func(tx *Tx) error {
+		if err := tx.ExecContext(tx.Context(), "INSERT INTO test (name) VALUES (?)", "Alice"); err != nil {
+			return err
+		}
+		nestedErr := suite.client.WithTx(tx.Context(), func(nested *Tx) error {
+			assert.Same(suite.T(), tx, nested)
+			if err := nested.ExecContext(nested.Context(), "INSERT INTO test (name) VALUES (?)", "Bob"); err != nil {
+				return err
+			}
+			return errors.New("discard Bob")
+		})
+		assert.Error(suite.T(), nestedErr)
+
+		return tx.WithTx(func(nested *Tx) error {
+			return nested.ExecContext(nested.Context(), "INSERT INTO test (name) VALUES (?)", "Carol")
+		})
+	})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"Alice", "Carol"}, suite.names())
+}
+
+func (suite *SQLiteClientTxTestSuite) TestRolledBackSavepointIsReleased() {
+	err := suite.client.WithTx(context.Background(), func(tx *Tx) error {
+		for _, name := range []string{"Alice", "Bob"} {
+			nestedErr := tx.WithTx(func(nested *Tx) error {
+				if err := nested.ExecContext(nested.Context(), "INSERT INTO test (name) VALUES (?)", name); err != nil {
+					return err
+				}
+				return errors.New("discard " + name)
+			})
+			assert.Error(suite.T(), nestedErr)
+		}
+		// A savepoint left open after ROLLBACK TO would make this RELEASE succeed.
+		assert.Error(suite.T(), tx.ExecContext(tx.Context(), "RELEASE sp_1"), "no savepoint is left open")
+		return tx.ExecContext(tx.Context(), "INSERT INTO test (name) VALUES (?)", "Carol")
+	})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"Carol"}, suite.names())
+}
+
+func (suite *SQLiteClientTxTestSuite) TestClientCallsInsideTxOnSingleConnectionPool() {
+	assert.Equal(suite.T(), 1, suite.client.db.Stats().MaxOpenConnections)
+	err := suite.client.WithTx(context.Background(), func(tx *Tx) error {
+		if err := suite.client.ExecContext(tx.Context(), "INSERT INTO test (name) VALUES (?)", "Alice"); err != nil {
+			return err
+		}
+		var count int
+		if err := suite.client.QueryRowContext(tx.Context(), "SELECT COUNT(*) FROM test").Scan(&count); err != nil {
+			return err
+		}
+		assert.Equal(suite.T(), 1, count, "the transaction context reads its own writes")
+
+		// A call made without the transaction context waits for the connection held by the transaction.
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		var timeoutErr *TimeoutError
+		assert.ErrorAs(suite.T(), suite.client.ExecContext(ctx, "INSERT INTO test (name) VALUES (?)", "Bob"), &timeoutErr)
+		return nil
+	})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"Alice"}, suite.names())
+}
+
+func (suite *SQLiteClientTxTestSuite) TestRetryOnBusy() {
+	// With deferred transactions the lock is only requested by the INSERT, so fn runs on every attempt.
+	other, err := NewClient(suite.databasePath + "?_busy_timeout=0&_txlock=deferred")
+	assert.NoError(suite.T(), err)
+	defer other.Close()
+
+	locked := make(chan struct{})
+	released := make(chan error, 1)
+	go func() {
+		released <- suite.client.WithTx(context.Background(), func(tx *Tx) error {
+			if err := tx.ExecContext(tx.Context(), "INSERT INTO test (name) VALUES (?)", "Alice"); err != nil {
+				close(locked)
+				return err
+			}
+			close(locked)
+			time.Sleep(30 * time.Millisecond)
+			return nil
+		})
+	}()
+	<-locked
+
+	attempts := 0
+	err = other.WithTx(context.Background(), func(tx *Tx) error {
+		attempts++
+		return tx.ExecContext(tx.Context(), "INSERT INTO test (name) VALUES (?)", "Bob")
+	})
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), <-released)
+	assert.Greater(suite.T(), attempts, 1)
+	assert.Equal(suite.T(), []string{"Alice", "Bob"}, suite.names())
+}
+
+func (suite *SQLiteClientTxTestSuite) TestBusyWithoutRetries() {
+	other, err := NewClient(suite.databasePath + "?_busy_timeout=0")
+	assert.NoError(suite.T(), err)
+	defer other.Close()
+	other.SetTxRetries(0)
+
+	err = suite.client.WithTx(context.Background(), func(tx *Tx) error {
+		if err := tx.ExecContext(tx.Context(), "INSERT INTO test (name) VALUES (?)", "Alice"); err != nil {
+			return err
+		}
+		otherErr := other.WithTx(context.Background(), func(otherTx *Tx) error {
+			return otherTx.ExecContext(otherTx.Context(), "INSERT INTO test (name) VALUES (?)", "Bob")
+		})
+		assert.True(suite.T(), isBusy(otherErr), "unexpected error: %v", otherErr)
+		return nil
+	})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"Alice"}, suite.names())
+}
@@ -0,0 +1,94 @@
+// Package dialect abstracts the parts of SQL that differ between the relational databases used by the repositories.
+package dialect
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Dialect describes how a database spells the non-portable parts of a query.
+// Queries are written with ? placeholders and rewritten for the database with Rebind.
+type Dialect interface {
+	// Name returns the name of the database, for logs.
+	Name() string
+	// Placeholder returns the placeholder of the n-th argument of a query, counting from 1.
+	Placeholder(n int) string
+	// NoLimit returns the LIMIT argument that keeps every row.
+	NoLimit() interface{}
+}
+
+var (
+	// SQLite is the dialect of SQLite: ? placeholders and a negative LIMIT for no limit.
+	SQLite Dialect = sqliteDialect{}
+	// Postgres is the dialect of PostgreSQL: $n placeholders and the largest bigint for no limit.
+	Postgres Dialect = postgresDialect{}
+)
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string { return "sqlite" }
+
+func (sqliteDialect) Placeholder(int) string { return "?" }
+
+func (sqliteDialect) NoLimit() interface{} { return int64(-1) }
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string { return "postgres" }
+
+func (postgresDialect) Placeholder(n int) string { return "$" + strconv.Itoa(n) }
+
+// NoLimit returns math.MaxInt64 rather than NULL so the same query also runs on SQLite, which rejects LIMIT NULL.
+func (postgresDialect) NoLimit() interface{} { return int64(math.MaxInt64) }
+
+// Rebind rewrites the ? placeholders of query into the placeholders of d, numbered in order of appearance.
+// Question marks inside quoted strings and identifiers are left alone.
+func Rebind(d Dialect, query string) string {
+	var builder strings.Builder
+	builder.Grow(len(query))
+	var quote rune
+	n := 0
+	for _, r := range query {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '?':
+			n++
+			builder.WriteString(d.Placeholder(n))
+			continue
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
+
+// Upsert returns an INSERT of columns into table, with ? placeholders, that updates every other column
+// of the existing row when key conflicts. Both SQLite and PostgreSQL accept ON CONFLICT ... DO UPDATE.
+func Upsert(table string, key string, columns ...string) string {
+	placeholders := make([]string, len(columns))
+	updates := make([]string, 0, len(columns))
+	for i, column := range columns {
+		placeholders[i] = "?"
+		if column != key {
+			updates = append(updates, column+" = excluded."+column)
+		}
+	}
+	return "INSERT INTO " + table + " (" + strings.Join(columns, ", ") + ") VALUES (" + strings.Join(placeholders, ", ") + ")" +
+		" ON CONFLICT(" + key + ") DO UPDATE SET " + strings.Join(updates, ", ")
+}
+
+// InsertIfAbsent returns an INSERT of columns into table, with ? placeholders, that does nothing when key conflicts.
+// It returns the key of the inserted row, so no row means the key was already stored.
+func InsertIfAbsent(table string, key string, columns ...string) string {
+	placeholders := make([]string, len(columns))
+	for i := range columns {
+		placeholders[i] = "?"
+	}
+	return "INSERT INTO " + table + " (" + strings.Join(columns, ", ") + ") VALUES (" + strings.Join(placeholders, ", ") + ")" +
+		" ON CONFLICT(" + key + ") DO NOTHING RETURNING " + key
+}
@@ -0,0 +1,45 @@
+package dialect
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type DialectTestSuite struct {
+	suite.Suite
+}
+
+func TestDialectTestSuite(t *testing.T) {
+	suite.Run(t, new(DialectTestSuite))
+}
+
+func (suite *DialectTestSuite) TestRebind() {
+	query := "SELECT id FROM t WHERE a = ? AND b = '?' AND \"c?\" = ? LIMIT ?"
+
+	assert.Equal(suite.T(), query, Rebind(SQLite, query))
+	assert.Equal(suite.T(), "SELECT id FROM t WHERE a = $1 AND b = '?' AND \"c?\" = $2 LIMIT $3", Rebind(Postgres, query))
+	assert.Equal(suite.T(), "SELECT 'it''s?' || $1", Rebind(Postgres, "SELECT 'it''s?' || ?"))
+}
+
+func (suite *DialectTestSuite) TestUpsert() {
+	query := Upsert("rates", "id", "id", "code", "bid")
+
+	assert.Equal(suite.T(), "INSERT INTO rates (id, code, bid) VALUES (?, ?, ?) ON CONFLICT(id) DO UPDATE SET code = excluded.code, bid = excluded.bid", query)
+	assert.Equal(suite.T(), "INSERT INTO rates (id, code, bid) VALUES ($1, $2, $3) ON CONFLICT(id) DO UPDATE SET code = excluded.code, bid = excluded.bid", Rebind(Postgres, query))
+}
+
+func (suite *DialectTestSuite) TestInsertIfAbsent() {
+	query := InsertIfAbsent("rates", "id", "id", "code", "bid")
+	assert.Equal(suite.T(), "INSERT INTO rates (id, code, bid) VALUES (?, ?, ?) ON CONFLICT(id) DO NOTHING RETURNING id", query)
+	assert.Equal(suite.T(), "INSERT INTO rates (id, code, bid) VALUES ($1, $2, $3) ON CONFLICT(id) DO NOTHING RETURNING id", Rebind(Postgres, query))
+}
+
+func (suite *DialectTestSuite) TestNoLimit() {
+	assert.Equal(suite.T(), int64(-1), SQLite.NoLimit())
+	assert.Equal(suite.T(), int64(math.MaxInt64), Postgres.NoLimit())
+	assert.Equal(suite.T(), "sqlite", SQLite.Name())
+	assert.Equal(suite.T(), "postgres", Postgres.Name())
+}
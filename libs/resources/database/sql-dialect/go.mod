@@ -0,0 +1,3 @@
+module libs/resources/database/sql-dialect
+
+go 1.22
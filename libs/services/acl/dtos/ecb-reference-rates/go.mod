@@ -0,0 +1,3 @@
+module libs/services/acl/dtos/ecb-reference-rates
+
+go 1.22
@@ -0,0 +1,20 @@
+package outputdto
+
+// EnvelopeDTO is the daily euro foreign exchange reference rates document of the European Central Bank.
+type EnvelopeDTO struct {
+	Subject string        `xml:"subject"`
+	Sender  string        `xml:"Sender>name"`
+	Days    []RatesDayDTO `xml:"Cube>Cube"`
+}
+
+// RatesDayDTO holds the reference rates of one day, written as "2006-01-02".
+type RatesDayDTO struct {
+	Time  string    `xml:"time,attr"`
+	Rates []RateDTO `xml:"Cube"`
+}
+
+// RateDTO is the amount of a currency one euro buys, such as "1.0857" USD.
+type RateDTO struct {
+	Currency string `xml:"currency,attr"`
+	Rate     string `xml:"rate,attr"`
+}
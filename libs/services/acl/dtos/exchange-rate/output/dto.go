@@ -1,19 +1,82 @@
 package outputdto
 
+import (
+	entity "libs/services/entities/exchange-rate/entity"
+)
+
+const (
+	// SourceProvider is the Source of an exchange rate fetched from the exchange rate provider.
+	SourceProvider = "provider"
+	// SourceCache is the Source of a stored exchange rate served because the provider failed.
+	SourceCache = "cache"
+)
+
 // ExchangeRateDTO is a data transfer object that represents the exchange rate api output.
+// Prices are exact Decimals, written as JSON numbers like the float64 fields they replaced.
+// Source tells whether the rate was just fetched or served from the repository; a cached rate also has the AgeSeconds
+// elapsed since its Timestamp.
 type ExchangeRateDTO struct {
-	Code       string  `json:"code"`
-	CodeIn     string  `json:"codein"`
-	Name       string  `json:"name"`
-	High       float64 `json:"high"`
-	Low        float64 `json:"low"`
-	VarBid     float64 `json:"varBid"`
-	PctChange  float64 `json:"pctChange"`
-	Bid        float64 `json:"bid"`
-	Ask        float64 `json:"ask"`
-	Timestamp  int64   `json:"timestamp"`
-	CreateDate string  `json:"create_date"`
+	Code       string         `json:"code"`
+	CodeIn     string         `json:"codein"`
+	Name       string         `json:"name"`
+	High       entity.Decimal `json:"high"`
+	Low        entity.Decimal `json:"low"`
+	VarBid     entity.Decimal `json:"varBid"`
+	PctChange  float64        `json:"pctChange"`
+	Bid        entity.Decimal `json:"bid"`
+	Ask        entity.Decimal `json:"ask"`
+	Timestamp  int64          `json:"timestamp"`
+	CreateDate string         `json:"create_date"`
+	Source     string         `json:"source,omitempty"`
+	AgeSeconds int64          `json:"age_seconds,omitempty"`
 }
 
 // ExchangeRatesDTO is a data transfer object that represents a map of exchange rates.
 type ExchangeRatesDTO map[string]ExchangeRateDTO
+
+// BatchExchangeRatesDTO is a data transfer object that represents the exchange rates of several pairs, keyed by pair
+// such as "USD-BRL", and the reason each pair that could not be fetched or saved failed.
+type BatchExchangeRatesDTO struct {
+	Rates    ExchangeRatesDTO  `json:"rates"`
+	Failures map[string]string `json:"failures,omitempty"`
+}
+
+// CandleDTO is a data transfer object that represents the open, high, low and close bid of one time bucket.
+type CandleDTO struct {
+	Timestamp int64          `json:"timestamp"`
+	Open      entity.Decimal `json:"open"`
+	High      entity.Decimal `json:"high"`
+	Low       entity.Decimal `json:"low"`
+	Close     entity.Decimal `json:"close"`
+	Count     int            `json:"count"`
+}
+
+// CandlesDTO is a data transfer object that represents the candles of a currency pair, oldest first.
+type CandlesDTO struct {
+	Code     string      `json:"code"`
+	CodeIn   string      `json:"codein"`
+	Interval string      `json:"interval"`
+	Candles  []CandleDTO `json:"candles"`
+}
+
+// ConversionQuoteDTO is a data transfer object that represents a quote used by a conversion, and the side it was traded at.
+type ConversionQuoteDTO struct {
+	ID        string         `json:"id"`
+	Code      string         `json:"code"`
+	CodeIn    string         `json:"codein"`
+	Side      string         `json:"side"`
+	Price     entity.Decimal `json:"price"`
+	Timestamp int64          `json:"timestamp"`
+}
+
+// ConversionDTO is a data transfer object that represents an amount converted between two currencies.
+// Path lists the currencies the conversion went through, from first, and Quotes the quote of each leg.
+type ConversionDTO struct {
+	From   string               `json:"from"`
+	To     string               `json:"to"`
+	Amount entity.Decimal       `json:"amount"`
+	Result entity.Decimal       `json:"result"`
+	Rate   entity.Decimal       `json:"rate"`
+	Path   []string             `json:"path"`
+	Quotes []ConversionQuoteDTO `json:"quotes"`
+}
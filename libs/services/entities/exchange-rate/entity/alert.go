@@ -0,0 +1,223 @@
+package exchangerateentity
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	gouuid "libs/shared/go-uuid"
+	"log"
+	"math"
+	"strconv"
+	"time"
+)
+
+var (
+	// ErrInvalidAlertRule is wrapped by the errors of alert rules that cannot be evaluated.
+	ErrInvalidAlertRule = errors.New("invalid alert rule")
+	// ErrAlertRuleNotFound is returned when an alert rule does not exist in the repository.
+	ErrAlertRuleNotFound = errors.New("alert rule not found")
+)
+
+// AlertKind is the condition an AlertRule watches.
+type AlertKind string
+
+const (
+	// AlertAbove holds while the bid is at or above the Level of the rule.
+	AlertAbove AlertKind = "above"
+	// AlertBelow holds while the bid is at or below the Level of the rule.
+	AlertBelow AlertKind = "below"
+	// AlertPercentChange holds while the bid moved, up or down, by at least the Percent of the rule.
+	AlertPercentChange AlertKind = "pct_change"
+)
+
+// AlertRule watches the quotes of a currency pair. An alert is raised when the condition of the rule starts holding:
+// it holds for a new quote but did not for the previous quote of the pair, so a level is reported once when it is
+// crossed rather than on every quote beyond it.
+type AlertRule struct {
+	ID     string    `json:"_id"`
+	Code   string    `json:"code"`
+	CodeIn string    `json:"codeIn"`
+	Kind   AlertKind `json:"kind"`
+	// Level is the bid watched by AlertAbove and AlertBelow rules.
+	Level Decimal `json:"level"`
+	// Percent is the change watched by AlertPercentChange rules, as a positive percentage.
+	Percent float64 `json:"percent"`
+	// Window is the period AlertPercentChange rules measure the change over, against the oldest stored quote of the
+	// window. Zero uses the PctChange of the quote instead.
+	Window time.Duration `json:"window"`
+}
+
+// NewThresholdAlertRule creates an AlertAbove or AlertBelow rule on the bid of the pair.
+func NewThresholdAlertRule(code string, codeIn string, kind AlertKind, level Decimal) (*AlertRule, error) {
+	return newAlertRule(AlertRule{Code: code, CodeIn: codeIn, Kind: kind, Level: level})
+}
+
+// NewPercentChangeAlertRule creates an AlertPercentChange rule on the bid of the pair. A zero window uses the PctChange
+// of each quote.
+func NewPercentChangeAlertRule(code string, codeIn string, percent float64, window time.Duration) (*AlertRule, error) {
+	return newAlertRule(AlertRule{Code: code, CodeIn: codeIn, Kind: AlertPercentChange, Percent: percent, Window: window})
+}
+
+// newAlertRule validates the rule, uppercases its codes and derives its ID from its definition, so the same rule
+// saved twice is stored once.
+func newAlertRule(rule AlertRule) (*AlertRule, error) {
+	pair, err := NewCurrencyPair(rule.Code, rule.CodeIn)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidAlertRule, err)
+	}
+	rule.Code, rule.CodeIn = pair.Code(), pair.CodeIn()
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+	rule.ID, err = gouuid.GetID(map[string]interface{}{
+		"code":    rule.Code,
+		"codeIn":  rule.CodeIn,
+		"kind":    string(rule.Kind),
+		"level":   rule.Level.String(),
+		"percent": strconv.FormatFloat(rule.Percent, 'f', -1, 64),
+		"window":  rule.Window.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// Validate checks that the rule can be evaluated. Errors wrap ErrInvalidAlertRule.
+func (r *AlertRule) Validate() error {
+	if _, err := NewCurrencyPair(r.Code, r.CodeIn); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidAlertRule, err)
+	}
+	switch r.Kind {
+	case AlertAbove, AlertBelow:
+		if r.Level.Sign() <= 0 {
+			return fmt.Errorf("%w: level must be positive", ErrInvalidAlertRule)
+		}
+	case AlertPercentChange:
+		if !(r.Percent > 0) || math.IsInf(r.Percent, 0) {
+			return fmt.Errorf("%w: percent must be positive", ErrInvalidAlertRule)
+		}
+		if r.Window < 0 {
+			return fmt.Errorf("%w: window must not be negative", ErrInvalidAlertRule)
+		}
+	default:
+		return fmt.Errorf("%w: unknown kind %q", ErrInvalidAlertRule, r.Kind)
+	}
+	return nil
+}
+
+// Change returns the change of the bid of quote, in percent, that an AlertPercentChange rule compares to Percent:
+// the PctChange of the quote without a Window, or the change from the bid of baseline otherwise.
+// It returns false when the rule has a Window and baseline is nil.
+func (r *AlertRule) Change(quote *CurrencyInfo, baseline *CurrencyInfo) (float64, bool) {
+	if r.Window == 0 {
+		return quote.PctChange, true
+	}
+	if baseline == nil || baseline.Bid.IsZero() {
+		return 0, false
+	}
+	change, err := quote.Bid.Sub(baseline.Bid).Div(baseline.Bid, RoundHalfEven)
+	if err != nil {
+		return 0, false
+	}
+	return change.Float64() * 100, true
+}
+
+// Holds reports whether the condition of the rule holds for quote. baseline is the oldest quote of the Window of an
+// AlertPercentChange rule, and is ignored by the other kinds.
+func (r *AlertRule) Holds(quote *CurrencyInfo, baseline *CurrencyInfo) bool {
+	switch r.Kind {
+	case AlertAbove:
+		return quote.Bid.Cmp(r.Level) >= 0
+	case AlertBelow:
+		return quote.Bid.Cmp(r.Level) <= 0
+	case AlertPercentChange:
+		change, ok := r.Change(quote, baseline)
+		return ok && math.Abs(change) >= r.Percent
+	}
+	return false
+}
+
+// String describes the rule, such as "USD-BRL above 5.5" or "USD-BRL moves 2% in 1h0m0s".
+func (r *AlertRule) String() string {
+	pair := r.Code + "-" + r.CodeIn
+	switch r.Kind {
+	case AlertPercentChange:
+		if r.Window == 0 {
+			return fmt.Sprintf("%s moves %g%% on the day", pair, r.Percent)
+		}
+		return fmt.Sprintf("%s moves %g%% in %s", pair, r.Percent, r.Window)
+	default:
+		return fmt.Sprintf("%s %s %s", pair, r.Kind, r.Level)
+	}
+}
+
+// ToMap converts the AlertRule object to a map representation. The level is stored as a float64, like the prices
+// of CurrencyInfo.ToMap.
+func (r *AlertRule) ToMap() map[string]interface{} {
+	return map[string]interface{}{
+		"_id":     r.ID,
+		"code":    r.Code,
+		"codeIn":  r.CodeIn,
+		"kind":    string(r.Kind),
+		"level":   r.Level.Float64(),
+		"percent": r.Percent,
+		"window":  int64(r.Window),
+	}
+}
+
+// MapToAlertRule converts a map representation of an AlertRule object back to a valid AlertRule.
+func MapToAlertRule(document map[string]interface{}) (*AlertRule, error) {
+	var rule AlertRule
+	documentBytes, err := json.Marshal(document)
+	if err != nil {
+		log.Printf("Error marshalling document: %v", err)
+		return nil, err
+	}
+	if err := json.Unmarshal(documentBytes, &rule); err != nil {
+		log.Printf("Error unmarshalling document: %v", err)
+		return nil, err
+	}
+	if rule.ID == "" {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidAlertRule, errIDRequired)
+	}
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// Alert is raised when the condition of an AlertRule starts holding for a quote.
+type Alert struct {
+	Rule      AlertRule `json:"rule"`
+	Code      string    `json:"code"`
+	CodeIn    string    `json:"codeIn"`
+	Bid       Decimal   `json:"bid"`
+	Timestamp int64     `json:"timestamp"`
+	// Change is the change in percent measured by an AlertPercentChange rule.
+	Change  float64 `json:"change,omitempty"`
+	Message string  `json:"message"`
+}
+
+// NewAlert returns the alert of the rule for quote, with the change measured against baseline.
+func NewAlert(rule AlertRule, quote *CurrencyInfo, baseline *CurrencyInfo) Alert {
+	alert := Alert{
+		Rule:      rule,
+		Code:      quote.Code,
+		CodeIn:    quote.CodeIn,
+		Bid:       quote.Bid,
+		Timestamp: quote.Timestamp,
+	}
+	alert.Message = fmt.Sprintf("%s: bid %s", rule.String(), quote.Bid)
+	if rule.Kind == AlertPercentChange {
+		alert.Change, _ = rule.Change(quote, baseline)
+		alert.Message = fmt.Sprintf("%s: bid %s changed %.2f%%", rule.String(), quote.Bid, alert.Change)
+	}
+	return alert
+}
+
+// AlertSink delivers alerts, such as to a webhook or a log.
+type AlertSink interface {
+	Notify(ctx context.Context, alert Alert) error
+}
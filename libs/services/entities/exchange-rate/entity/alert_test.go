@@ -0,0 +1,111 @@
+package exchangerateentity
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type AlertRuleTestSuite struct {
+	suite.Suite
+}
+
+func TestAlertRuleTestSuite(t *testing.T) {
+	suite.Run(t, new(AlertRuleTestSuite))
+}
+
+// quote returns a USD-BRL quote with the bid and pctChange.
+func (suite *AlertRuleTestSuite) quote(bid string, pctChange string) *CurrencyInfo {
+	quote, err := NewExchangeRate("USD", "BRL", "Dollar", bid, bid, "0", pctChange, bid, bid, "1626889200", "2021-07-21 17:40:00")
+	suite.Require().NoError(err)
+	return quote
+}
+
+func (suite *AlertRuleTestSuite) TestNewAlertRules() {
+	above, err := NewThresholdAlertRule("usd", "brl", AlertAbove, MustParseDecimal("5.5"))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "USD", above.Code)
+	assert.NotEmpty(suite.T(), above.ID)
+	assert.Equal(suite.T(), "USD-BRL above 5.5", above.String())
+
+	again, err := NewThresholdAlertRule("USD", "BRL", AlertAbove, MustParseDecimal("5.50"))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), above.ID, again.ID, "the same rule has the same ID")
+
+	moves, err := NewPercentChangeAlertRule("USD", "BRL", 2, time.Hour)
+	assert.NoError(suite.T(), err)
+	assert.NotEqual(suite.T(), above.ID, moves.ID)
+	assert.Equal(suite.T(), "USD-BRL moves 2% in 1h0m0s", moves.String())
+}
+
+func (suite *AlertRuleTestSuite) TestNewAlertRulesRejectInvalidRules() {
+	for name, err := range map[string]error{
+		"unknown currency": func() error {
+			_, err := NewThresholdAlertRule("BTC", "BRL", AlertAbove, MustParseDecimal("5.5"))
+			return err
+		}(),
+		"unknown kind": func() error {
+			_, err := NewThresholdAlertRule("USD", "BRL", "crosses", MustParseDecimal("5.5"))
+			return err
+		}(),
+		"zero level": func() error {
+			_, err := NewThresholdAlertRule("USD", "BRL", AlertBelow, Decimal{})
+			return err
+		}(),
+		"negative percent": func() error {
+			_, err := NewPercentChangeAlertRule("USD", "BRL", -1, 0)
+			return err
+		}(),
+		"negative window": func() error {
+			_, err := NewPercentChangeAlertRule("USD", "BRL", 1, -time.Minute)
+			return err
+		}(),
+	} {
+		assert.True(suite.T(), errors.Is(err, ErrInvalidAlertRule), "%s: unexpected error: %v", name, err)
+	}
+}
+
+func (suite *AlertRuleTestSuite) TestHolds() {
+	above, _ := NewThresholdAlertRule("USD", "BRL", AlertAbove, MustParseDecimal("5.5"))
+	below, _ := NewThresholdAlertRule("USD", "BRL", AlertBelow, MustParseDecimal("5.0"))
+	daily, _ := NewPercentChangeAlertRule("USD", "BRL", 1.5, 0)
+	hourly, _ := NewPercentChangeAlertRule("USD", "BRL", 2, time.Hour)
+	baseline := suite.quote("5.00", "0")
+
+	assert.True(suite.T(), above.Holds(suite.quote("5.5", "0"), nil))
+	assert.False(suite.T(), above.Holds(suite.quote("5.49", "0"), nil))
+	assert.True(suite.T(), below.Holds(suite.quote("4.99", "0"), nil))
+	assert.False(suite.T(), below.Holds(suite.quote("5.01", "0"), nil))
+	assert.True(suite.T(), daily.Holds(suite.quote("5.1", "-1.5"), nil))
+	assert.False(suite.T(), daily.Holds(suite.quote("5.1", "1.49"), nil))
+	assert.True(suite.T(), hourly.Holds(suite.quote("5.10", "0"), baseline))
+	assert.True(suite.T(), hourly.Holds(suite.quote("4.90", "0"), baseline))
+	assert.False(suite.T(), hourly.Holds(suite.quote("5.09", "0"), baseline))
+	assert.False(suite.T(), hourly.Holds(suite.quote("6", "0"), nil), "no history in the window")
+}
+
+func (suite *AlertRuleTestSuite) TestNewAlert() {
+	hourly, _ := NewPercentChangeAlertRule("USD", "BRL", 2, time.Hour)
+
+	alert := NewAlert(*hourly, suite.quote("5.15", "0"), suite.quote("5.00", "0"))
+
+	assert.Equal(suite.T(), "USD", alert.Code)
+	assert.Equal(suite.T(), MustParseDecimal("5.15"), alert.Bid)
+	assert.InDelta(suite.T(), 3, alert.Change, 1e-9)
+	assert.Equal(suite.T(), "USD-BRL moves 2% in 1h0m0s: bid 5.15 changed 3.00%", alert.Message)
+}
+
+func (suite *AlertRuleTestSuite) TestMapRoundTrip() {
+	rule, _ := NewPercentChangeAlertRule("USD", "BRL", 2.5, 30*time.Minute)
+
+	mapped, err := MapToAlertRule(rule.ToMap())
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), rule, mapped)
+
+	_, err = MapToAlertRule(map[string]interface{}{"code": "USD", "codeIn": "BRL", "kind": "above", "level": 5})
+	assert.True(suite.T(), errors.Is(err, ErrInvalidAlertRule), "a rule without ID: unexpected error: %v", err)
+}
@@ -0,0 +1,130 @@
+package exchangerateentity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ErrInvalidCandleInterval is returned for an interval other than the supported CandleIntervals.
+var ErrInvalidCandleInterval = errors.New("invalid candle interval")
+
+// CandleInterval is the width of the time buckets of a candle series.
+type CandleInterval time.Duration
+
+// Supported candle intervals. Buckets are aligned to the Unix epoch, so daily candles start at midnight UTC.
+const (
+	CandleInterval1m CandleInterval = CandleInterval(time.Minute)
+	CandleInterval5m CandleInterval = CandleInterval(5 * time.Minute)
+	CandleInterval1h CandleInterval = CandleInterval(time.Hour)
+	CandleInterval1d CandleInterval = CandleInterval(24 * time.Hour)
+)
+
+// candleIntervalNames maps the names accepted by ParseCandleInterval to intervals.
+var candleIntervalNames = map[string]CandleInterval{
+	"1m": CandleInterval1m,
+	"5m": CandleInterval5m,
+	"1h": CandleInterval1h,
+	"1d": CandleInterval1d,
+}
+
+// ParseCandleInterval parses one of 1m, 5m, 1h or 1d.
+func ParseCandleInterval(name string) (CandleInterval, error) {
+	interval, ok := candleIntervalNames[name]
+	if !ok {
+		return 0, fmt.Errorf("%w: %q, expected one of 1m, 5m, 1h or 1d", ErrInvalidCandleInterval, name)
+	}
+	return interval, nil
+}
+
+// String returns the name of the interval, as accepted by ParseCandleInterval.
+func (i CandleInterval) String() string {
+	for name, interval := range candleIntervalNames {
+		if interval == i {
+			return name
+		}
+	}
+	return time.Duration(i).String()
+}
+
+// Seconds returns the width of the interval in seconds, the unit of CurrencyInfo.Timestamp.
+func (i CandleInterval) Seconds() int64 {
+	return int64(time.Duration(i) / time.Second)
+}
+
+// Valid reports whether the interval is one of the supported intervals.
+func (i CandleInterval) Valid() bool {
+	for _, interval := range candleIntervalNames {
+		if interval == i {
+			return true
+		}
+	}
+	return false
+}
+
+// BucketStart returns the start, in Unix seconds, of the bucket holding timestamp.
+func (i CandleInterval) BucketStart(timestamp int64) int64 {
+	seconds := i.Seconds()
+	return timestamp - timestamp%seconds
+}
+
+// Candle is the open, high, low and close Bid of a currency pair over one bucket of a CandleInterval.
+type Candle struct {
+	// Start is the first second of the bucket, in Unix seconds.
+	Start int64
+	// Open is the Bid of the earliest quote of the bucket.
+	Open Decimal
+	// High is the highest Bid of the bucket.
+	High Decimal
+	// Low is the lowest Bid of the bucket.
+	Low Decimal
+	// Close is the Bid of the latest quote of the bucket.
+	Close Decimal
+	// Count is the number of quotes in the bucket.
+	Count int
+}
+
+// CandleRepository is implemented by repositories that aggregate candles in storage.
+type CandleRepository interface {
+	// FindCandles returns the candles of the pair for the quotes with from <= Timestamp < to, oldest first.
+	// Buckets without quotes are left out.
+	FindCandles(ctx context.Context, code string, codeIn string, interval CandleInterval, from time.Time, to time.Time) ([]Candle, error)
+}
+
+// AggregateCandles groups quotes into candles of the given interval, oldest first.
+// Quotes with the same Timestamp are ordered by ID to pick the open and close.
+func AggregateCandles(quotes []*CurrencyInfo, interval CandleInterval) []Candle {
+	sorted := make([]*CurrencyInfo, len(quotes))
+	copy(sorted, quotes)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Timestamp != sorted[j].Timestamp {
+			return sorted[i].Timestamp < sorted[j].Timestamp
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	candles := make([]Candle, 0)
+	for _, quote := range sorted {
+		start := interval.BucketStart(quote.Timestamp)
+		if len(candles) == 0 || candles[len(candles)-1].Start != start {
+			candles = append(candles, Candle{
+				Start: start,
+				Open:  quote.Bid,
+				High:  quote.Bid,
+				Low:   quote.Bid,
+			})
+		}
+		candle := &candles[len(candles)-1]
+		if quote.Bid.Cmp(candle.High) > 0 {
+			candle.High = quote.Bid
+		}
+		if quote.Bid.Cmp(candle.Low) < 0 {
+			candle.Low = quote.Bid
+		}
+		candle.Close = quote.Bid
+		candle.Count++
+	}
+	return candles
+}
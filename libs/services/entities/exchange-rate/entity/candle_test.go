@@ -0,0 +1,60 @@
+package exchangerateentity
+
+import (
+	"errors"
+	gouuid "libs/shared/go-uuid"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type CandleTestSuite struct {
+	suite.Suite
+}
+
+func TestCandleTestSuite(t *testing.T) {
+	suite.Run(t, new(CandleTestSuite))
+}
+
+func (suite *CandleTestSuite) TestParseCandleInterval() {
+	for name, expected := range map[string]CandleInterval{
+		"1m": CandleInterval1m,
+		"5m": CandleInterval5m,
+		"1h": CandleInterval1h,
+		"1d": CandleInterval1d,
+	} {
+		interval, err := ParseCandleInterval(name)
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), expected, interval)
+		assert.Equal(suite.T(), name, interval.String())
+		assert.True(suite.T(), interval.Valid())
+	}
+
+	_, err := ParseCandleInterval("15m")
+	assert.True(suite.T(), errors.Is(err, ErrInvalidCandleInterval), "unexpected error: %v", err)
+	assert.Equal(suite.T(), int64(86400), CandleInterval1d.Seconds())
+}
+
+func (suite *CandleTestSuite) TestAggregateCandles() {
+	quote := func(id string, timestamp int64, bid string) *CurrencyInfo {
+		return &CurrencyInfo{ID: gouuid.ID(id), Code: "USD", CodeIn: "BRL", Timestamp: timestamp, Bid: MustParseDecimal(bid)}
+	}
+	quotes := []*CurrencyInfo{
+		quote("c", 1626889250, "5.50"),
+		quote("a", 1626889200, "5.40"),
+		quote("b", 1626889230, "5.60"),
+		quote("e", 1626889320, "5.30"),
+		// Same timestamp as "e": the ID orders them, so "f" closes the bucket.
+		quote("f", 1626889320, "5.35"),
+	}
+
+	candles := AggregateCandles(quotes, CandleInterval1m)
+
+	assert.Equal(suite.T(), []Candle{
+		{Start: 1626889200, Open: MustParseDecimal("5.40"), High: MustParseDecimal("5.60"), Low: MustParseDecimal("5.40"), Close: MustParseDecimal("5.50"), Count: 3},
+		{Start: 1626889320, Open: MustParseDecimal("5.30"), High: MustParseDecimal("5.35"), Low: MustParseDecimal("5.30"), Close: MustParseDecimal("5.35"), Count: 2},
+	}, candles)
+	assert.Equal(suite.T(), "c", string(quotes[0].ID), "the input must not be reordered")
+	assert.Empty(suite.T(), AggregateCandles(nil, CandleInterval1h))
+}
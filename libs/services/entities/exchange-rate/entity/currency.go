@@ -0,0 +1,86 @@
+package exchangerateentity
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnknownCurrency is returned when a code is not an active ISO 4217 currency.
+var ErrUnknownCurrency = errors.New("unknown currency code")
+
+const (
+	// LocaleEnglish selects the English ISO 4217 name of a currency.
+	LocaleEnglish = "en"
+	// LocalePortuguese selects the Brazilian Portuguese name of a currency.
+	LocalePortuguese = "pt-BR"
+)
+
+// NoMinorUnits is the MinorUnits of currencies without a minor unit, such as gold (XAU) or the SDR (XDR).
+const NoMinorUnits = -1
+
+// Currency is an active ISO 4217 currency.
+type Currency struct {
+	// Code is the alphabetic code, such as "BRL".
+	Code string `json:"code"`
+	// Numeric is the three-digit numeric code, such as "986".
+	Numeric string `json:"numeric"`
+	// MinorUnits is the number of decimal places of the currency, or NoMinorUnits.
+	MinorUnits int `json:"minorUnits"`
+	// Names holds the name of the currency by locale: LocaleEnglish and LocalePortuguese.
+	Names map[string]string `json:"names"`
+}
+
+// Name returns the name of the currency in locale, or its English name when the locale is not known.
+func (c Currency) Name(locale string) string {
+	if name, ok := c.Names[locale]; ok {
+		return name
+	}
+	return c.Names[LocaleEnglish]
+}
+
+// currenciesJSON is the ISO 4217 list of active currencies, sorted by code.
+//
+//go:embed currencies.json
+var currenciesJSON []byte
+
+var (
+	// currencies holds the registry, sorted by code.
+	currencies []Currency
+	// currenciesByCode indexes currencies by alphabetic code.
+	currenciesByCode map[string]Currency
+)
+
+func init() {
+	if err := json.Unmarshal(currenciesJSON, &currencies); err != nil {
+		panic(fmt.Sprintf("exchangerateentity: invalid currencies.json: %v", err))
+	}
+	currenciesByCode = make(map[string]Currency, len(currencies))
+	for _, currency := range currencies {
+		currenciesByCode[currency.Code] = currency
+	}
+}
+
+// LookupCurrency returns the currency with the given alphabetic code, ignoring case and surrounding spaces.
+func LookupCurrency(code string) (Currency, bool) {
+	currency, ok := currenciesByCode[strings.ToUpper(strings.TrimSpace(code))]
+	return currency, ok
+}
+
+// ParseCurrency is like LookupCurrency but returns an error wrapping ErrUnknownCurrency for an unknown code.
+func ParseCurrency(code string) (Currency, error) {
+	currency, ok := LookupCurrency(code)
+	if !ok {
+		return Currency{}, fmt.Errorf("%w: %q", ErrUnknownCurrency, code)
+	}
+	return currency, nil
+}
+
+// Currencies returns the registry, sorted by code.
+func Currencies() []Currency {
+	result := make([]Currency, len(currencies))
+	copy(result, currencies)
+	return result
+}
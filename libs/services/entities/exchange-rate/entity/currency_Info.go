@@ -3,7 +3,6 @@ package exchangerateentity
 import (
 	"encoding/json"
 	"errors"
-	"fmt"
 	gouuid "libs/shared/go-uuid"
 	"log"
 	"time"
@@ -17,25 +16,32 @@ var (
 	errTimestampRequired = errors.New("timestamp is required")
 )
 
+// createDateLayout is the layout of the create date of the quote APIs.
+const createDateLayout = "2006-01-02 15:04:05"
+
 // CurrencyInfo represents the exchange rate information for a currency pair.
+// Prices are exact Decimals; PctChange is a percentage and stays a float64.
 type CurrencyInfo struct {
 	ID         gouuid.ID `json:"_id"`
 	Code       string    `json:"code"`
 	CodeIn     string    `json:"codeIn"`
 	Name       string    `json:"name"`
-	High       float64   `json:"high"`
-	Low        float64   `json:"low"`
-	VarBid     float64   `json:"varBid"`
+	High       Decimal   `json:"high"`
+	Low        Decimal   `json:"low"`
+	VarBid     Decimal   `json:"varBid"`
 	PctChange  float64   `json:"pctChange"`
-	Bid        float64   `json:"bid"`
-	Ask        float64   `json:"ask"`
+	Bid        Decimal   `json:"bid"`
+	Ask        Decimal   `json:"ask"`
 	Timestamp  int64     `json:"timestamp"`
 	CreateDate time.Time `json:"create_date"`
 }
 
 // NewExchangeRate creates a new CurrencyInfo entity from string inputs.
 // It converts string inputs to appropriate data types, validates them,
-// and initializes a new CurrencyInfo object.
+// and initializes a new CurrencyInfo object. code and codeIn must form a CurrencyPair;
+// they are stored uppercased. Bid must be positive and the prices must satisfy Low <= Bid <= High and Ask >= Bid.
+// Every invalid field is reported in a *ValidationError; errors.Is matches ErrValidation and, for invalid codes,
+// ErrInvalidCurrencyPair.
 func NewExchangeRate(
 	code string,
 	codeIn string,
@@ -49,62 +55,32 @@ func NewExchangeRate(
 	timestamp string,
 	createDate string,
 ) (*CurrencyInfo, error) {
-	highFloat, err := StringToFloat64(high)
-	if err != nil {
-		return nil, err
-	}
-	lowFloat, err := StringToFloat64(low)
-	if err != nil {
-		return nil, err
-	}
-	varBidFloat, err := StringToFloat64(varBid)
-	if err != nil {
-		return nil, err
-	}
-	pctChangeFloat, err := StringToFloat64(pctChange)
-	if err != nil {
-		return nil, err
-	}
-	bidFloat, err := StringToFloat64(bid)
-	if err != nil {
-		return nil, err
-	}
-	askFloat, err := StringToFloat64(ask)
-	if err != nil {
-		return nil, err
-	}
-	timestampInt, err := StringToInt64(timestamp)
-	if err != nil {
-		return nil, err
-	}
-
+	v := &validator{}
+	pair := v.currencyPair(code, codeIn)
 	currencyInfo := &CurrencyInfo{
-		Code:      code,
-		CodeIn:    codeIn,
-		Name:      name,
-		High:      highFloat,
-		Low:       lowFloat,
-		VarBid:    varBidFloat,
-		PctChange: pctChangeFloat,
-		Bid:       bidFloat,
-		Ask:       askFloat,
-		Timestamp: timestampInt,
-	}
-
-	err = currencyInfo.setEntityID(code, codeIn, timestamp)
-	if err != nil {
+		Code:       pair.Code(),
+		CodeIn:     pair.CodeIn(),
+		Name:       name,
+		High:       v.price("high", high),
+		Low:        v.price("low", low),
+		VarBid:     v.price("varBid", varBid),
+		PctChange:  v.float("pctChange", pctChange),
+		Bid:        v.positiveDecimal("bid", bid),
+		Ask:        v.price("ask", ask),
+		Timestamp:  v.timestamp("timestamp", timestamp),
+		CreateDate: v.dateTime("create_date", createDate),
+	}
+	v.prices(currencyInfo, high, low, ask)
+	if err := v.err(); err != nil {
 		return nil, err
 	}
 
-	if err := currencyInfo.isValid(); err != nil {
+	if err := currencyInfo.setEntityID(pair.Code(), pair.CodeIn(), timestamp); err != nil {
 		return nil, err
 	}
-
-	err = currencyInfo.setCreateDate(createDate)
-	if err != nil {
+	if err := currencyInfo.isValid(); err != nil {
 		return nil, err
 	}
-
 	return currencyInfo, nil
 }
 
@@ -112,18 +88,6 @@ func (e *CurrencyInfo) GetEntityID() string {
 	return string(e.ID)
 }
 
-// setCreateDate sets the create date of the CurrencyInfo object
-// by parsing the input string to a time.Time object.
-func (e *CurrencyInfo) setCreateDate(createDate string) error {
-	layout := "2006-01-02 15:04:05"
-	parsedTime, err := time.Parse(layout, createDate)
-	if err != nil {
-		return fmt.Errorf("error parsing create date: %w", err)
-	}
-	e.CreateDate = parsedTime
-	return nil
-}
-
 // setEntityID sets the ID of the CurrencyInfo object
 // using the code, codeIn, and timestamp properties.
 func (e *CurrencyInfo) setEntityID(code string, codeIn string, timestamp string) error {
@@ -154,25 +118,27 @@ func (e *CurrencyInfo) isValid() error {
 	if e.Timestamp == 0 {
 		return errTimestampRequired
 	}
-	if e.Bid == 0 {
+	if e.Bid.IsZero() {
 		return errBidRequired
 	}
 	return nil
 }
 
-// ToMap converts the CurrencyInfo object to a map representation.
+// ToMap converts the CurrencyInfo object to a map representation. Prices are stored as float64, so document
+// queries such as {"bid": {"$gt": 5.2}} compare them as numbers. MapToCurrencyInfoEntity reads them back exactly
+// for prices of up to 15 significant digits.
 func (e *CurrencyInfo) ToMap() map[string]interface{} {
 	return map[string]interface{}{
 		"_id":         string(e.ID),
 		"code":        e.Code,
 		"codeIn":      e.CodeIn,
 		"name":        e.Name,
-		"high":        e.High,
-		"low":         e.Low,
-		"varBid":      e.VarBid,
+		"high":        e.High.Float64(),
+		"low":         e.Low.Float64(),
+		"varBid":      e.VarBid.Float64(),
 		"pctChange":   e.PctChange,
-		"bid":         e.Bid,
-		"ask":         e.Ask,
+		"bid":         e.Bid.Float64(),
+		"ask":         e.Ask.Float64(),
 		"timestamp":   e.Timestamp,
 		"create_date": e.CreateDate,
 	}
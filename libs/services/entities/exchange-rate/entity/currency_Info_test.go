@@ -2,6 +2,7 @@ package exchangerateentity
 
 import (
 	"encoding/json"
+	"errors"
 	"testing"
 	"time"
 
@@ -53,12 +54,12 @@ func (suite *CurrencyInfoEntityTestSuite) TestNewExchangeRate() {
 	assert.Equal(suite.T(), code, currencyInfo.Code)
 	assert.Equal(suite.T(), codeIn, currencyInfo.CodeIn)
 	assert.Equal(suite.T(), name, currencyInfo.Name)
-	assert.Equal(suite.T(), 5.5, currencyInfo.High)
-	assert.Equal(suite.T(), 5.4, currencyInfo.Low)
-	assert.Equal(suite.T(), 5.45, currencyInfo.VarBid)
+	assert.Equal(suite.T(), MustParseDecimal("5.5"), currencyInfo.High)
+	assert.Equal(suite.T(), MustParseDecimal("5.4"), currencyInfo.Low)
+	assert.Equal(suite.T(), MustParseDecimal("5.45"), currencyInfo.VarBid)
 	assert.Equal(suite.T(), 0.01, currencyInfo.PctChange)
-	assert.Equal(suite.T(), 5.45, currencyInfo.Bid)
-	assert.Equal(suite.T(), 5.46, currencyInfo.Ask)
+	assert.Equal(suite.T(), MustParseDecimal("5.45"), currencyInfo.Bid)
+	assert.Equal(suite.T(), MustParseDecimal("5.46"), currencyInfo.Ask)
 	assert.Equal(suite.T(), int64(1626889200), currencyInfo.Timestamp)
 	suite.Equal(createDate, currencyInfo.CreateDate.Format("2006-01-02 15:04:05"))
 	suite.Equal("83da6030-ab1f-5b6c-8b07-7bac10f85dbc", currencyInfo.ID)
@@ -126,6 +127,20 @@ func (suite *CurrencyInfoEntityTestSuite) TestNewExchangeRateInvalidWhenCodeInIs
 	assert.Nil(suite.T(), currencyInfo)
 }
 
+func (suite *CurrencyInfoEntityTestSuite) TestNewExchangeRateValidatesCurrencyPair() {
+	currencyInfo, err := NewExchangeRate("usd", "brl", "Dollar", "5.5", "5.4", "5.45", "0.01", "5.45", "5.46", "1626889200", "2021-07-21 00:00:00")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "USD", currencyInfo.Code)
+	assert.Equal(suite.T(), "BRL", currencyInfo.CodeIn)
+	assert.Equal(suite.T(), "83da6030-ab1f-5b6c-8b07-7bac10f85dbc", currencyInfo.GetEntityID())
+
+	for _, codes := range [][2]string{{"XYZ", "BRL"}, {"BRL", "BRL"}} {
+		currencyInfo, err := NewExchangeRate(codes[0], codes[1], "Dollar", "5.5", "5.4", "5.45", "0.01", "5.45", "5.46", "1626889200", "2021-07-21 00:00:00")
+		assert.True(suite.T(), errors.Is(err, ErrInvalidCurrencyPair), "%v: unexpected error: %v", codes, err)
+		assert.Nil(suite.T(), currencyInfo)
+	}
+}
+
 func (suite *CurrencyInfoEntityTestSuite) TestNewExchangeRateInvalidWhenBidIsZero() {
 	code := "USD"
 	codeIn := "BRL"
@@ -192,12 +207,12 @@ func (suite *CurrencyInfoEntityTestSuite) TestMapToCurrencyInfoEntity() {
 	code := "USD"
 	codeIn := "BRL"
 	name := "Dollar"
-	high := 5.5
-	low := 5.4
-	varBid := 5.45
+	high := MustParseDecimal("5.5")
+	low := MustParseDecimal("5.4")
+	varBid := MustParseDecimal("5.45")
 	pctChange := 0.01
-	bid := 5.45
-	ask := 5.46
+	bid := MustParseDecimal("5.45")
+	ask := MustParseDecimal("5.46")
 	timestamp := int64(1626889200)
 	createDate, _ := time.Parse(dateStringLayout, "2021-07-21 00:00:00")
 
@@ -231,6 +246,21 @@ func (suite *CurrencyInfoEntityTestSuite) TestMapToCurrencyInfoEntity() {
 	assert.Equal(suite.T(), createDate, result.CreateDate)
 }
 
+func (suite *CurrencyInfoEntityTestSuite) TestToMapStoresNumericPrices() {
+	currencyInfo, err := NewExchangeRate("USD", "BRL", "Dollar", "5.5", "5.4", "0.0563", "0.01", "5.4563", "5.4564", "1626889200", "2021-07-21 00:00:00")
+	assert.Nil(suite.T(), err)
+
+	document := currencyInfo.ToMap()
+	for _, field := range []string{"high", "low", "varBid", "bid", "ask"} {
+		assert.IsType(suite.T(), float64(0), document[field], field)
+	}
+	assert.Equal(suite.T(), 5.4563, document["bid"])
+
+	result, err := MapToCurrencyInfoEntity(document)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), currencyInfo, result, "prices are read back exactly")
+}
+
 func (suite *CurrencyInfoEntityTestSuite) TestMapToCurrencyInfoEntityEdgeCases() {
 	type testCase struct {
 		name           string
@@ -257,9 +287,9 @@ func (suite *CurrencyInfoEntityTestSuite) TestMapToCurrencyInfoEntityEdgeCases()
 				ID:        "83da6030-ab1f-5b6c-8b07-7bac10f85dbc",
 				Code:      "USD",
 				CodeIn:    "BRL",
-				High:      5.5,
-				Low:       5.4,
-				Bid:       5.45,
+				High:      MustParseDecimal("5.5"),
+				Low:       MustParseDecimal("5.4"),
+				Bid:       MustParseDecimal("5.45"),
 				Timestamp: int64(1626889200),
 			},
 			expectedErr: nil,
@@ -293,10 +323,10 @@ func (suite *CurrencyInfoEntityTestSuite) TestMapToCurrencyInfoEntityEdgeCases()
 				ID:        "83da6030-ab1f-5b6c-8b07-7bac10f85dbc",
 				Code:      "USD",
 				CodeIn:    "BRL",
-				High:      1e10,
-				Low:       -1e10,
-				Bid:       1e10,
-				Ask:       -1e10,
+				High:      MustParseDecimal("10000000000"),
+				Low:       MustParseDecimal("-10000000000"),
+				Bid:       MustParseDecimal("10000000000"),
+				Ask:       MustParseDecimal("-10000000000"),
 				Timestamp: int64(1626889200),
 			},
 			expectedErr: nil,
@@ -317,9 +347,9 @@ func (suite *CurrencyInfoEntityTestSuite) TestMapToCurrencyInfoEntityEdgeCases()
 				ID:        "83da6030-ab1f-5b6c-8b07-7bac10f85dbc",
 				Code:      "USD",
 				CodeIn:    "BRL",
-				High:      5.5,
-				Bid:       5.45,
-				Ask:       5.46,
+				High:      MustParseDecimal("5.5"),
+				Bid:       MustParseDecimal("5.45"),
+				Ask:       MustParseDecimal("5.46"),
 				Timestamp: int64(1626889200),
 			},
 			expectedErr: nil,
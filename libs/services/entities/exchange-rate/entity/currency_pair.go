@@ -0,0 +1,77 @@
+package exchangerateentity
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrInvalidCurrencyPair is wrapped by every error of NewCurrencyPair and ParseCurrencyPair.
+	ErrInvalidCurrencyPair = errors.New("invalid currency pair")
+	// ErrIdenticalCurrencies is returned for a pair whose two currencies are the same.
+	ErrIdenticalCurrencies = errors.New("currency pair must hold two different currencies")
+)
+
+// CurrencyPair is a validated pair of ISO 4217 currencies: the base currency, quoted in the quote currency.
+// The zero value is not a valid pair; build one with NewCurrencyPair or ParseCurrencyPair.
+type CurrencyPair struct {
+	base  Currency
+	quote Currency
+}
+
+// NewCurrencyPair returns the pair of code quoted in codeIn. Codes are case-insensitive.
+// It returns an error wrapping ErrInvalidCurrencyPair and ErrUnknownCurrency or ErrIdenticalCurrencies.
+func NewCurrencyPair(code string, codeIn string) (CurrencyPair, error) {
+	base, err := ParseCurrency(code)
+	if err != nil {
+		return CurrencyPair{}, fmt.Errorf("%w: %w", ErrInvalidCurrencyPair, err)
+	}
+	quote, err := ParseCurrency(codeIn)
+	if err != nil {
+		return CurrencyPair{}, fmt.Errorf("%w: %w", ErrInvalidCurrencyPair, err)
+	}
+	if base.Code == quote.Code {
+		return CurrencyPair{}, fmt.Errorf("%w: %w: %s", ErrInvalidCurrencyPair, ErrIdenticalCurrencies, base.Code)
+	}
+	return CurrencyPair{base: base, quote: quote}, nil
+}
+
+// ParseCurrencyPair parses a pair written as "USD-BRL", the format of String.
+func ParseCurrencyPair(s string) (CurrencyPair, error) {
+	code, codeIn, ok := strings.Cut(s, "-")
+	if !ok {
+		return CurrencyPair{}, fmt.Errorf("%w: %q is not written as CODE-CODEIN", ErrInvalidCurrencyPair, s)
+	}
+	return NewCurrencyPair(code, codeIn)
+}
+
+// Base returns the base currency of the pair.
+func (p CurrencyPair) Base() Currency {
+	return p.base
+}
+
+// Quote returns the currency the base currency is quoted in.
+func (p CurrencyPair) Quote() Currency {
+	return p.quote
+}
+
+// Code returns the code of the base currency, the Code of a CurrencyInfo.
+func (p CurrencyPair) Code() string {
+	return p.base.Code
+}
+
+// CodeIn returns the code of the quote currency, the CodeIn of a CurrencyInfo.
+func (p CurrencyPair) CodeIn() string {
+	return p.quote.Code
+}
+
+// Inverse returns the pair with the currencies swapped.
+func (p CurrencyPair) Inverse() CurrencyPair {
+	return CurrencyPair{base: p.quote, quote: p.base}
+}
+
+// String returns the pair as "USD-BRL", the search key of the quote APIs.
+func (p CurrencyPair) String() string {
+	return p.base.Code + "-" + p.quote.Code
+}
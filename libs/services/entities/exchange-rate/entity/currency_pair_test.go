@@ -0,0 +1,57 @@
+package exchangerateentity
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type CurrencyPairTestSuite struct {
+	suite.Suite
+}
+
+func TestCurrencyPairTestSuite(t *testing.T) {
+	suite.Run(t, new(CurrencyPairTestSuite))
+}
+
+func (suite *CurrencyPairTestSuite) TestNewCurrencyPair() {
+	pair, err := NewCurrencyPair("usd", "Brl")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "USD", pair.Code())
+	assert.Equal(suite.T(), "BRL", pair.CodeIn())
+	assert.Equal(suite.T(), "840", pair.Base().Numeric)
+	assert.Equal(suite.T(), "986", pair.Quote().Numeric)
+	assert.Equal(suite.T(), "USD-BRL", pair.String())
+	assert.Equal(suite.T(), "BRL-USD", pair.Inverse().String())
+}
+
+func (suite *CurrencyPairTestSuite) TestNewCurrencyPairErrors() {
+	for _, test := range []struct {
+		code   string
+		codeIn string
+		err    error
+	}{
+		{"", "BRL", ErrUnknownCurrency},
+		{"USD", "", ErrUnknownCurrency},
+		{"BTC", "BRL", ErrUnknownCurrency},
+		{"USD", "usd", ErrIdenticalCurrencies},
+	} {
+		_, err := NewCurrencyPair(test.code, test.codeIn)
+		assert.True(suite.T(), errors.Is(err, ErrInvalidCurrencyPair), "%s-%s: unexpected error: %v", test.code, test.codeIn, err)
+		assert.True(suite.T(), errors.Is(err, test.err), "%s-%s: unexpected error: %v", test.code, test.codeIn, err)
+	}
+}
+
+func (suite *CurrencyPairTestSuite) TestParseCurrencyPair() {
+	pair, err := ParseCurrencyPair("eur-usd")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "EUR-USD", pair.String())
+
+	for _, s := range []string{"EURUSD", "EUR-", "EUR-USD-BRL", "EUR-EUR"} {
+		_, err := ParseCurrencyPair(s)
+		assert.True(suite.T(), errors.Is(err, ErrInvalidCurrencyPair), "%q: unexpected error: %v", s, err)
+	}
+}
@@ -0,0 +1,66 @@
+package exchangerateentity
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type CurrencyTestSuite struct {
+	suite.Suite
+}
+
+func TestCurrencyTestSuite(t *testing.T) {
+	suite.Run(t, new(CurrencyTestSuite))
+}
+
+func (suite *CurrencyTestSuite) TestLookupCurrency() {
+	brl, ok := LookupCurrency(" brl ")
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), "BRL", brl.Code)
+	assert.Equal(suite.T(), "986", brl.Numeric)
+	assert.Equal(suite.T(), 2, brl.MinorUnits)
+	assert.Equal(suite.T(), "Brazilian Real", brl.Name(LocaleEnglish))
+	assert.Equal(suite.T(), "Real brasileiro", brl.Name(LocalePortuguese))
+	assert.Equal(suite.T(), "Brazilian Real", brl.Name("fr"))
+
+	jpy, _ := LookupCurrency("JPY")
+	assert.Equal(suite.T(), 0, jpy.MinorUnits)
+	kwd, _ := LookupCurrency("KWD")
+	assert.Equal(suite.T(), 3, kwd.MinorUnits)
+	xau, _ := LookupCurrency("XAU")
+	assert.Equal(suite.T(), NoMinorUnits, xau.MinorUnits)
+
+	for _, code := range []string{"", "US", "USDX", "BTC", "XYZ"} {
+		_, ok := LookupCurrency(code)
+		assert.False(suite.T(), ok, code)
+		_, err := ParseCurrency(code)
+		assert.True(suite.T(), errors.Is(err, ErrUnknownCurrency), "%q: unexpected error: %v", code, err)
+	}
+}
+
+func (suite *CurrencyTestSuite) TestCurrenciesRegistry() {
+	currencies := Currencies()
+	assert.Greater(suite.T(), len(currencies), 150)
+
+	numerics := make(map[string]string)
+	for i, currency := range currencies {
+		if i > 0 {
+			assert.Less(suite.T(), currencies[i-1].Code, currency.Code, "codes are sorted and unique")
+		}
+		assert.Regexp(suite.T(), `^[A-Z]{3}$`, currency.Code)
+		assert.Regexp(suite.T(), `^[0-9]{3}$`, currency.Numeric, currency.Code)
+		assert.Contains(suite.T(), []int{NoMinorUnits, 0, 2, 3, 4}, currency.MinorUnits, currency.Code)
+		assert.NotEmpty(suite.T(), currency.Names[LocaleEnglish], currency.Code)
+		assert.NotEmpty(suite.T(), currency.Names[LocalePortuguese], currency.Code)
+		if other, ok := numerics[currency.Numeric]; ok {
+			suite.Failf("duplicate numeric code", "%s and %s share %s", other, currency.Code, currency.Numeric)
+		}
+		numerics[currency.Numeric] = currency.Code
+	}
+
+	currencies[0].Code = "changed"
+	assert.NotEqual(suite.T(), "changed", Currencies()[0].Code, "Currencies returns a copy")
+}
@@ -0,0 +1,375 @@
+package exchangerateentity
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// DecimalScale is the number of decimal places held by a Decimal.
+const DecimalScale = 8
+
+// decimalUnit is the number of units in 1: 10^DecimalScale.
+const decimalUnit = 100000000
+
+// maxDecimalExponent bounds the exponent ParseDecimal accepts, far beyond what a Decimal can hold, so that the
+// arithmetic on it cannot overflow.
+const maxDecimalExponent = 40
+
+var (
+	// ErrInvalidDecimal is returned when a value cannot be read as a Decimal.
+	ErrInvalidDecimal = errors.New("invalid decimal")
+	// ErrDecimalOverflow is returned, or panicked with by the arithmetic methods, when a result does not fit in a Decimal.
+	ErrDecimalOverflow = errors.New("decimal overflow")
+	// ErrDivisionByZero is returned by Div when dividing by zero.
+	ErrDivisionByZero = errors.New("decimal division by zero")
+)
+
+// RoundingMode tells how a result with more than the wanted decimal places is rounded.
+type RoundingMode int
+
+const (
+	// RoundHalfEven rounds to the nearest value, and ties to the even one. It is the default of the arithmetic of the package.
+	RoundHalfEven RoundingMode = iota
+	// RoundHalfUp rounds to the nearest value, and ties away from zero.
+	RoundHalfUp
+	// RoundDown rounds towards zero.
+	RoundDown
+	// RoundUp rounds away from zero.
+	RoundUp
+	// RoundFloor rounds towards negative infinity.
+	RoundFloor
+	// RoundCeiling rounds towards positive infinity.
+	RoundCeiling
+)
+
+// Decimal is an exact fixed-point number with DecimalScale decimal places, stored as an int64 count of 10^-8 units.
+// It holds values up to about ±92 billion. The zero value is 0.
+//
+// Add, Sub, Mul and Round panic with ErrDecimalOverflow when the result does not fit, like an index out of range:
+// prices are many orders of magnitude below the limit.
+type Decimal struct {
+	units int64
+}
+
+// DecimalFromUnits returns the Decimal holding units × 10^-8.
+func DecimalFromUnits(units int64) Decimal {
+	return Decimal{units: units}
+}
+
+// DecimalFromInt returns n as a Decimal. It panics with ErrDecimalOverflow when n is out of range.
+func DecimalFromInt(n int64) Decimal {
+	if n > math.MaxInt64/decimalUnit || n < math.MinInt64/decimalUnit {
+		panic(fmt.Errorf("%w: %d", ErrDecimalOverflow, n))
+	}
+	return Decimal{units: n * decimalUnit}
+}
+
+// DecimalFromFloat64 returns f rounded half to even to DecimalScale decimal places.
+// Use it to read values that were stored as floats; prices should be parsed from their text with ParseDecimal.
+func DecimalFromFloat64(f float64) (Decimal, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Decimal{}, fmt.Errorf("%w: %v", ErrInvalidDecimal, f)
+	}
+	return ParseDecimal(strconv.FormatFloat(f, 'f', DecimalScale, 64))
+}
+
+// ParseDecimal parses a decimal number such as "5.4563", "-0.01", "+3" or "1.5e-3".
+// It returns an error wrapping ErrInvalidDecimal for malformed text, an exponent beyond ±40 or more than
+// DecimalScale significant decimal places, and ErrDecimalOverflow for a value out of range. It never rounds.
+func ParseDecimal(s string) (Decimal, error) {
+	mantissa, exponent := s, 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.Atoi(s[i+1:])
+		if err != nil || e > maxDecimalExponent || e < -maxDecimalExponent {
+			return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+		}
+		mantissa, exponent = s[:i], e
+	}
+	negative := false
+	if mantissa != "" && (mantissa[0] == '-' || mantissa[0] == '+') {
+		negative = mantissa[0] == '-'
+		mantissa = mantissa[1:]
+	}
+	integer, fraction, _ := strings.Cut(mantissa, ".")
+	if integer == "" && fraction == "" || !isDigits(integer) || !isDigits(fraction) {
+		return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+	}
+
+	// The value is digits × 10^-places.
+	digits := strings.TrimLeft(integer+fraction, "0")
+	places := len(fraction) - exponent
+	if digits == "" {
+		return Decimal{}, nil
+	}
+	if places > DecimalScale {
+		extra := places - DecimalScale
+		if extra > len(digits) || strings.Trim(digits[len(digits)-extra:], "0") != "" {
+			return Decimal{}, fmt.Errorf("%w: %q has more than %d decimal places", ErrInvalidDecimal, s, DecimalScale)
+		}
+		digits = digits[:len(digits)-extra]
+		places = DecimalScale
+	}
+	if len(digits)+DecimalScale-places > 19 {
+		return Decimal{}, fmt.Errorf("%w: %q", ErrDecimalOverflow, s)
+	}
+	magnitude, err := strconv.ParseUint(digits+strings.Repeat("0", DecimalScale-places), 10, 64)
+	if err != nil || magnitude > math.MaxInt64 {
+		return Decimal{}, fmt.Errorf("%w: %q", ErrDecimalOverflow, s)
+	}
+	return fromMagnitude(magnitude, negative), nil
+}
+
+// MustParseDecimal is like ParseDecimal but panics on error. It is meant for constants.
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// isDigits reports whether s only holds ASCII digits.
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Units returns the value as a count of 10^-8 units, for storage as an integer.
+func (d Decimal) Units() int64 {
+	return d.units
+}
+
+// String returns the shortest exact text of d, without an exponent or trailing zeros: "5.4563", "-0.01", "3".
+func (d Decimal) String() string {
+	magnitude := d.magnitude()
+	text := strconv.FormatUint(magnitude/decimalUnit, 10)
+	if fraction := magnitude % decimalUnit; fraction != 0 {
+		text += "." + strings.TrimRight(fmt.Sprintf("%0*d", DecimalScale, fraction), "0")
+	}
+	if d.units < 0 {
+		return "-" + text
+	}
+	return text
+}
+
+// Float64 returns the float64 nearest to d, for display and statistics. Compare and compute with Decimal instead.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// IsZero reports whether d is 0.
+func (d Decimal) IsZero() bool {
+	return d.units == 0
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of d.
+func (d Decimal) Sign() int {
+	switch {
+	case d.units < 0:
+		return -1
+	case d.units > 0:
+		return 1
+	}
+	return 0
+}
+
+// Cmp returns -1, 0 or +1 when d is less than, equal to or greater than o.
+func (d Decimal) Cmp(o Decimal) int {
+	switch {
+	case d.units < o.units:
+		return -1
+	case d.units > o.units:
+		return 1
+	}
+	return 0
+}
+
+// Neg returns -d.
+func (d Decimal) Neg() Decimal {
+	if d.units == math.MinInt64 {
+		panic(fmt.Errorf("%w: -(%s)", ErrDecimalOverflow, d))
+	}
+	return Decimal{units: -d.units}
+}
+
+// Abs returns the absolute value of d.
+func (d Decimal) Abs() Decimal {
+	if d.units < 0 {
+		return d.Neg()
+	}
+	return d
+}
+
+// Add returns d + o.
+func (d Decimal) Add(o Decimal) Decimal {
+	sum := d.units + o.units
+	if (o.units > 0 && sum < d.units) || (o.units < 0 && sum > d.units) {
+		panic(fmt.Errorf("%w: %s + %s", ErrDecimalOverflow, d, o))
+	}
+	return Decimal{units: sum}
+}
+
+// Sub returns d - o.
+func (d Decimal) Sub(o Decimal) Decimal {
+	difference := d.units - o.units
+	if (o.units < 0 && difference < d.units) || (o.units > 0 && difference > d.units) {
+		panic(fmt.Errorf("%w: %s - %s", ErrDecimalOverflow, d, o))
+	}
+	return Decimal{units: difference}
+}
+
+// Mul returns d × o rounded to DecimalScale decimal places with mode.
+func (d Decimal) Mul(o Decimal, mode RoundingMode) Decimal {
+	negative := (d.units < 0) != (o.units < 0)
+	hi, lo := bits.Mul64(d.magnitude(), o.magnitude())
+	if hi >= decimalUnit {
+		panic(fmt.Errorf("%w: %s × %s", ErrDecimalOverflow, d, o))
+	}
+	quotient, remainder := bits.Div64(hi, lo, decimalUnit)
+	return fromMagnitude(round(quotient, remainder, decimalUnit, negative, mode), negative)
+}
+
+// Div returns d ÷ o rounded to DecimalScale decimal places with mode.
+// It returns ErrDivisionByZero when o is 0 and ErrDecimalOverflow when the quotient is out of range.
+func (d Decimal) Div(o Decimal, mode RoundingMode) (Decimal, error) {
+	if o.units == 0 {
+		return Decimal{}, ErrDivisionByZero
+	}
+	negative := (d.units < 0) != (o.units < 0)
+	divisor := o.magnitude()
+	hi, lo := bits.Mul64(d.magnitude(), decimalUnit)
+	if hi >= divisor {
+		return Decimal{}, fmt.Errorf("%w: %s ÷ %s", ErrDecimalOverflow, d, o)
+	}
+	quotient, remainder := bits.Div64(hi, lo, divisor)
+	magnitude := round(quotient, remainder, divisor, negative, mode)
+	if magnitude > math.MaxInt64 {
+		return Decimal{}, fmt.Errorf("%w: %s ÷ %s", ErrDecimalOverflow, d, o)
+	}
+	return fromMagnitude(magnitude, negative), nil
+}
+
+// Round returns d rounded to places decimal places, between 0 and DecimalScale, with mode.
+func (d Decimal) Round(places int, mode RoundingMode) Decimal {
+	if places >= DecimalScale {
+		return d
+	}
+	if places < 0 {
+		places = 0
+	}
+	step := uint64(math.Pow10(DecimalScale - places))
+	negative := d.units < 0
+	magnitude := d.magnitude()
+	quotient := round(magnitude/step, magnitude%step, step, negative, mode)
+	if quotient > math.MaxInt64/step {
+		panic(fmt.Errorf("%w: rounding %s", ErrDecimalOverflow, d))
+	}
+	return fromMagnitude(quotient*step, negative)
+}
+
+// magnitude returns the absolute value of the units of d.
+func (d Decimal) magnitude() uint64 {
+	if d.units < 0 {
+		return -uint64(d.units)
+	}
+	return uint64(d.units)
+}
+
+// fromMagnitude returns the Decimal with the given absolute units and sign.
+func fromMagnitude(magnitude uint64, negative bool) Decimal {
+	if magnitude > math.MaxInt64 {
+		panic(ErrDecimalOverflow)
+	}
+	if negative {
+		return Decimal{units: -int64(magnitude)}
+	}
+	return Decimal{units: int64(magnitude)}
+}
+
+// round rounds quotient + remainder/divisor, the magnitude of a value of the given sign, to an integer with mode.
+func round(quotient uint64, remainder uint64, divisor uint64, negative bool, mode RoundingMode) uint64 {
+	if remainder == 0 {
+		return quotient
+	}
+	var up bool
+	switch mode {
+	case RoundHalfEven:
+		up = remainder > divisor-remainder || (remainder == divisor-remainder && quotient%2 == 1)
+	case RoundHalfUp:
+		up = remainder >= divisor-remainder
+	case RoundDown:
+		up = false
+	case RoundUp:
+		up = true
+	case RoundFloor:
+		up = negative
+	case RoundCeiling:
+		up = !negative
+	}
+	if !up {
+		return quotient
+	}
+	if quotient == math.MaxUint64 {
+		panic(ErrDecimalOverflow)
+	}
+	return quotient + 1
+}
+
+// MarshalJSON writes d as a JSON number, so a Decimal field has the same JSON shape as a float64 one.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON reads a JSON number or a string holding one, the format of the quote APIs. null leaves d unchanged.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	text := string(data)
+	if text == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(text); err == nil {
+		text = unquoted
+	}
+	parsed, err := ParseDecimal(text)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// Value implements driver.Valuer. It returns the exact text of d, which NUMERIC columns store without loss.
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Scan implements sql.Scanner. It reads the text of NUMERIC and TEXT columns, integers as whole numbers,
+// and floats rounded to DecimalScale decimal places.
+func (d *Decimal) Scan(value interface{}) error {
+	var err error
+	switch v := value.(type) {
+	case string:
+		*d, err = ParseDecimal(v)
+	case []byte:
+		*d, err = ParseDecimal(string(v))
+	case int64:
+		if v > math.MaxInt64/decimalUnit || v < math.MinInt64/decimalUnit {
+			return fmt.Errorf("%w: %d", ErrDecimalOverflow, v)
+		}
+		*d = DecimalFromInt(v)
+	case float64:
+		*d, err = DecimalFromFloat64(v)
+	default:
+		return fmt.Errorf("%w: cannot scan %T into a Decimal", ErrInvalidDecimal, value)
+	}
+	return err
+}
@@ -0,0 +1,196 @@
+package exchangerateentity
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type DecimalTestSuite struct {
+	suite.Suite
+}
+
+func TestDecimalTestSuite(t *testing.T) {
+	suite.Run(t, new(DecimalTestSuite))
+}
+
+func (suite *DecimalTestSuite) TestParseDecimal() {
+	for text, expected := range map[string]int64{
+		"5.4563":        545630000,
+		"-0.01":         -1000000,
+		"+3":            300000000,
+		"0003.100":      310000000,
+		".5":            50000000,
+		"7.":            700000000,
+		"1.5e-3":        150000,
+		"2E2":           20000000000,
+		"0.123456789e1": 123456789,
+		"0.000000010":   1,
+		"0":             0,
+		"-0":            0,
+	} {
+		d, err := ParseDecimal(text)
+		assert.NoError(suite.T(), err, text)
+		assert.Equal(suite.T(), expected, d.Units(), text)
+	}
+
+	for _, text := range []string{"", "-", ".", "abc", "1.2.3", "1e", "1ex", "0x10", "1,5", " 1", "0.000000001", "5.123456789",
+		"1e9223372036854775807", "1e-9223372036854775808", "1e41", "0e-41"} {
+		_, err := ParseDecimal(text)
+		assert.True(suite.T(), errors.Is(err, ErrInvalidDecimal), "%q: unexpected error: %v", text, err)
+	}
+
+	for _, text := range []string{"92233720369", "1e20", "-100000000000"} {
+		_, err := ParseDecimal(text)
+		assert.True(suite.T(), errors.Is(err, ErrDecimalOverflow), "%q: unexpected error: %v", text, err)
+	}
+}
+
+func (suite *DecimalTestSuite) TestString() {
+	for text, expected := range map[string]string{
+		"5.4500":     "5.45",
+		"-0.01":      "-0.01",
+		"3.0":        "3",
+		"0.00000001": "0.00000001",
+		"-12.5":      "-12.5",
+		"0":          "0",
+	} {
+		assert.Equal(suite.T(), expected, MustParseDecimal(text).String())
+	}
+	assert.Equal(suite.T(), "-92233720368.54775808", DecimalFromUnits(math.MinInt64).String())
+}
+
+func (suite *DecimalTestSuite) TestFromIntAndFloat() {
+	assert.Equal(suite.T(), MustParseDecimal("42"), DecimalFromInt(42))
+	assert.Panics(suite.T(), func() { DecimalFromInt(math.MaxInt64) })
+
+	d, err := DecimalFromFloat64(5.45)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), MustParseDecimal("5.45"), d)
+	d, err = DecimalFromFloat64(0.1 + 0.2)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), MustParseDecimal("0.3"), d)
+	assert.Equal(suite.T(), 5.45, MustParseDecimal("5.45").Float64())
+
+	_, err = DecimalFromFloat64(math.NaN())
+	assert.True(suite.T(), errors.Is(err, ErrInvalidDecimal), "unexpected error: %v", err)
+}
+
+func (suite *DecimalTestSuite) TestArithmetic() {
+	a, b := MustParseDecimal("0.1"), MustParseDecimal("0.2")
+	assert.Equal(suite.T(), MustParseDecimal("0.3"), a.Add(b))
+	assert.Equal(suite.T(), MustParseDecimal("-0.1"), a.Sub(b))
+	assert.Equal(suite.T(), MustParseDecimal("0.02"), a.Mul(b, RoundHalfEven))
+	assert.Equal(suite.T(), MustParseDecimal("-5.45"), MustParseDecimal("5.45").Neg())
+	assert.Equal(suite.T(), MustParseDecimal("5.45"), MustParseDecimal("-5.45").Abs())
+	assert.Equal(suite.T(), -1, a.Cmp(b))
+	assert.Equal(suite.T(), 1, b.Cmp(a))
+	assert.Equal(suite.T(), 0, a.Cmp(MustParseDecimal("0.10")))
+	assert.Equal(suite.T(), -1, a.Neg().Sign())
+	assert.True(suite.T(), a.Sub(a).IsZero())
+
+	quotient, err := MustParseDecimal("1").Div(MustParseDecimal("3"), RoundHalfEven)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), MustParseDecimal("0.33333333"), quotient)
+	quotient, err = MustParseDecimal("-2").Div(MustParseDecimal("3"), RoundHalfEven)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), MustParseDecimal("-0.66666667"), quotient)
+	quotient, err = MustParseDecimal("100").Div(MustParseDecimal("5.45"), RoundDown)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), MustParseDecimal("18.34862385"), quotient)
+
+	_, err = a.Div(Decimal{}, RoundHalfEven)
+	assert.True(suite.T(), errors.Is(err, ErrDivisionByZero), "unexpected error: %v", err)
+	_, err = MustParseDecimal("90000000000").Div(MustParseDecimal("0.5"), RoundHalfEven)
+	assert.True(suite.T(), errors.Is(err, ErrDecimalOverflow), "unexpected error: %v", err)
+
+	maximum := DecimalFromUnits(math.MaxInt64)
+	assert.Panics(suite.T(), func() { maximum.Add(DecimalFromUnits(1)) })
+	assert.Panics(suite.T(), func() { maximum.Neg().Sub(DecimalFromUnits(2)) })
+	assert.Panics(suite.T(), func() { maximum.Mul(MustParseDecimal("2"), RoundHalfEven) })
+}
+
+func (suite *DecimalTestSuite) TestRoundingModes() {
+	for _, test := range []struct {
+		value    string
+		mode     RoundingMode
+		expected string
+	}{
+		{"2.5", RoundHalfEven, "2"},
+		{"3.5", RoundHalfEven, "4"},
+		{"-2.5", RoundHalfEven, "-2"},
+		{"2.51", RoundHalfEven, "3"},
+		{"2.5", RoundHalfUp, "3"},
+		{"-2.5", RoundHalfUp, "-3"},
+		{"2.49", RoundHalfUp, "2"},
+		{"2.9", RoundDown, "2"},
+		{"-2.9", RoundDown, "-2"},
+		{"2.1", RoundUp, "3"},
+		{"-2.1", RoundUp, "-3"},
+		{"2.9", RoundFloor, "2"},
+		{"-2.1", RoundFloor, "-3"},
+		{"2.1", RoundCeiling, "3"},
+		{"-2.9", RoundCeiling, "-2"},
+		{"2", RoundUp, "2"},
+	} {
+		rounded := MustParseDecimal(test.value).Round(0, test.mode)
+		assert.Equal(suite.T(), MustParseDecimal(test.expected), rounded, "%s with mode %d", test.value, test.mode)
+	}
+
+	assert.Equal(suite.T(), MustParseDecimal("5.46"), MustParseDecimal("5.4563").Round(2, RoundHalfEven))
+	assert.Equal(suite.T(), MustParseDecimal("5.4563"), MustParseDecimal("5.4563").Round(DecimalScale, RoundUp))
+	assert.Equal(suite.T(), MustParseDecimal("0.00000002"), MustParseDecimal("0.00000001").Mul(MustParseDecimal("1.5"), RoundHalfEven))
+	assert.Equal(suite.T(), MustParseDecimal("0.00000001"), MustParseDecimal("0.00000001").Mul(MustParseDecimal("0.5"), RoundCeiling))
+	assert.Equal(suite.T(), MustParseDecimal("0"), MustParseDecimal("0.00000001").Mul(MustParseDecimal("0.5"), RoundHalfEven))
+}
+
+func (suite *DecimalTestSuite) TestJSON() {
+	type quote struct {
+		Bid Decimal `json:"bid"`
+		Ask Decimal `json:"ask"`
+	}
+	data, err := json.Marshal(quote{Bid: MustParseDecimal("5.4563"), Ask: MustParseDecimal("-0.10")})
+	assert.NoError(suite.T(), err)
+	assert.JSONEq(suite.T(), `{"bid": 5.4563, "ask": -0.1}`, string(data))
+
+	var decoded quote
+	assert.NoError(suite.T(), json.Unmarshal([]byte(`{"bid": 5.4563, "ask": "5.47"}`), &decoded))
+	assert.Equal(suite.T(), quote{Bid: MustParseDecimal("5.4563"), Ask: MustParseDecimal("5.47")}, decoded)
+
+	decoded = quote{Bid: MustParseDecimal("1")}
+	assert.NoError(suite.T(), json.Unmarshal([]byte(`{"bid": null}`), &decoded))
+	assert.Equal(suite.T(), MustParseDecimal("1"), decoded.Bid)
+
+	err = json.Unmarshal([]byte(`{"bid": 1e-9223372036854775808}`), &decoded)
+	assert.True(suite.T(), errors.Is(err, ErrInvalidDecimal), "unexpected error: %v", err)
+	err = json.Unmarshal([]byte(`{"bid": "abc"}`), &decoded)
+	assert.True(suite.T(), errors.Is(err, ErrInvalidDecimal), "unexpected error: %v", err)
+}
+
+func (suite *DecimalTestSuite) TestSQL() {
+	value, err := MustParseDecimal("5.4563").Value()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "5.4563", value)
+
+	for source, expected := range map[interface{}]string{
+		"5.45000000":  "5.45",
+		int64(7):      "7",
+		float64(5.45): "5.45",
+	} {
+		var d Decimal
+		assert.NoError(suite.T(), d.Scan(source), "%v", source)
+		assert.Equal(suite.T(), MustParseDecimal(expected), d, "%v", source)
+	}
+	var d Decimal
+	assert.NoError(suite.T(), d.Scan([]byte("-0.01")))
+	assert.Equal(suite.T(), MustParseDecimal("-0.01"), d)
+
+	err = d.Scan(int64(math.MaxInt64))
+	assert.True(suite.T(), errors.Is(err, ErrDecimalOverflow), "unexpected error: %v", err)
+	err = d.Scan(true)
+	assert.True(suite.T(), errors.Is(err, ErrInvalidDecimal), "unexpected error: %v", err)
+}
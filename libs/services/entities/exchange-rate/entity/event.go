@@ -0,0 +1,146 @@
+package exchangerateentity
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	goeventbus "libs/shared/go-eventbus"
+	gouuid "libs/shared/go-uuid"
+	"time"
+)
+
+// ErrUnknownEvent is returned when an outbox event has a name no event type is registered for.
+var ErrUnknownEvent = errors.New("unknown event")
+
+const (
+	// ExchangeRateFetchedEvent is the name of ExchangeRateFetched events.
+	ExchangeRateFetchedEvent = "exchange_rate.fetched"
+	// ExchangeRateSavedEvent is the name of ExchangeRateSaved events.
+	ExchangeRateSavedEvent = "exchange_rate.saved"
+	// UpstreamFetchFailedEvent is the name of UpstreamFetchFailed events.
+	UpstreamFetchFailedEvent = "exchange_rate.upstream_fetch_failed"
+)
+
+// ExchangeRateFetched is published when a provider returns the exchange rate of a pair.
+type ExchangeRateFetched struct {
+	Pair         string        `json:"pair"`
+	Provider     string        `json:"provider"`
+	ExchangeRate *CurrencyInfo `json:"exchangeRate"`
+	OccurredAt   time.Time     `json:"occurredAt"`
+}
+
+// EventName implements goeventbus.Event.
+func (e ExchangeRateFetched) EventName() string { return ExchangeRateFetchedEvent }
+
+// EventKey implements goeventbus.Event, ordering the events of a pair.
+func (e ExchangeRateFetched) EventKey() string { return e.Pair }
+
+// ExchangeRateSaved is published once the exchange rate of a pair is stored.
+type ExchangeRateSaved struct {
+	Pair         string        `json:"pair"`
+	ExchangeRate *CurrencyInfo `json:"exchangeRate"`
+	OccurredAt   time.Time     `json:"occurredAt"`
+}
+
+// EventName implements goeventbus.Event.
+func (e ExchangeRateSaved) EventName() string { return ExchangeRateSavedEvent }
+
+// EventKey implements goeventbus.Event, ordering the events of a pair.
+func (e ExchangeRateSaved) EventKey() string { return e.Pair }
+
+// UpstreamFetchFailed is published when a provider fails to return the exchange rate of a pair.
+type UpstreamFetchFailed struct {
+	Pair       string    `json:"pair"`
+	Provider   string    `json:"provider"`
+	Error      string    `json:"error"`
+	OccurredAt time.Time `json:"occurredAt"`
+}
+
+// EventName implements goeventbus.Event.
+func (e UpstreamFetchFailed) EventName() string { return UpstreamFetchFailedEvent }
+
+// EventKey implements goeventbus.Event, ordering the events of a pair.
+func (e UpstreamFetchFailed) EventKey() string { return e.Pair }
+
+// OutboxEvent is an event stored in an EventOutbox until it is published, with its payload as JSON.
+type OutboxEvent struct {
+	ID      string
+	Name    string
+	Key     string
+	Payload []byte
+}
+
+// NewOutboxEvent encodes event for an EventOutbox. The ID is derived from the name, the key and the exchange rate of
+// the event, not from when it occurred, so the events of a quote saved again are stored once. Events without an
+// exchange rate, like UpstreamFetchFailed, are told apart by when they occurred.
+func NewOutboxEvent(event goeventbus.Event) (OutboxEvent, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return OutboxEvent{}, err
+	}
+	id, err := gouuid.GetID(map[string]interface{}{
+		"name":    event.EventName(),
+		"key":     event.EventKey(),
+		"subject": eventSubject(event),
+	})
+	if err != nil {
+		return OutboxEvent{}, err
+	}
+	return OutboxEvent{ID: id, Name: event.EventName(), Key: event.EventKey(), Payload: payload}, nil
+}
+
+// eventSubject returns the entity ID of the exchange rate of the event, or the time it occurred when it has none.
+func eventSubject(event goeventbus.Event) string {
+	var exchangeRate *CurrencyInfo
+	switch e := event.(type) {
+	case ExchangeRateFetched:
+		exchangeRate = e.ExchangeRate
+	case ExchangeRateSaved:
+		exchangeRate = e.ExchangeRate
+	case UpstreamFetchFailed:
+		return e.OccurredAt.UTC().Format(time.RFC3339Nano)
+	}
+	if exchangeRate == nil {
+		return ""
+	}
+	return exchangeRate.GetEntityID()
+}
+
+// Event decodes the event stored by NewOutboxEvent. It returns an error wrapping ErrUnknownEvent when the name is not
+// one of the events of this package.
+func (e OutboxEvent) Event() (goeventbus.Event, error) {
+	var event goeventbus.Event
+	var err error
+	switch e.Name {
+	case ExchangeRateFetchedEvent:
+		var fetched ExchangeRateFetched
+		err = json.Unmarshal(e.Payload, &fetched)
+		event = fetched
+	case ExchangeRateSavedEvent:
+		var saved ExchangeRateSaved
+		err = json.Unmarshal(e.Payload, &saved)
+		event = saved
+	case UpstreamFetchFailedEvent:
+		var failed UpstreamFetchFailed
+		err = json.Unmarshal(e.Payload, &failed)
+		event = failed
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownEvent, e.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// EventOutbox stores events until they are published. Repositories implementing it along with UnitOfWork append the
+// events in the transaction of the unit of work, so events of saved exchange rates survive a crash before publishing.
+type EventOutbox interface {
+	// AppendEvents stores the events, ignoring those already stored.
+	AppendEvents(ctx context.Context, events ...OutboxEvent) error
+	// PendingEvents returns up to limit stored events, oldest first.
+	PendingEvents(ctx context.Context, limit int) ([]OutboxEvent, error)
+	// DeleteEvents removes the published events.
+	DeleteEvents(ctx context.Context, ids ...string) error
+}
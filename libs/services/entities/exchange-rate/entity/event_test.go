@@ -0,0 +1,79 @@
+package exchangerateentity
+
+import (
+	"errors"
+	goeventbus "libs/shared/go-eventbus"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type EventTestSuite struct {
+	suite.Suite
+	exchangeRate *CurrencyInfo
+	at           time.Time
+}
+
+func TestEventTestSuite(t *testing.T) {
+	suite.Run(t, new(EventTestSuite))
+}
+
+func (suite *EventTestSuite) SetupTest() {
+	var err error
+	suite.exchangeRate, err = NewExchangeRate("USD", "BRL", "Dollar", "5.5", "5.4", "0.05", "0.01", "5.45", "5.46", "1626889200", "2021-07-21 17:40:00")
+	suite.Require().NoError(err)
+	suite.at = time.Date(2021, 7, 21, 17, 40, 1, 500, time.UTC)
+}
+
+func (suite *EventTestSuite) TestEventsAreKeyedByPair() {
+	fetched := ExchangeRateFetched{Pair: "USD-BRL", Provider: "ecb", ExchangeRate: suite.exchangeRate, OccurredAt: suite.at}
+	saved := ExchangeRateSaved{Pair: "USD-BRL", ExchangeRate: suite.exchangeRate, OccurredAt: suite.at}
+	failed := UpstreamFetchFailed{Pair: "USD-BRL", Provider: "ecb", Error: "timeout", OccurredAt: suite.at}
+
+	assert.Equal(suite.T(), ExchangeRateFetchedEvent, fetched.EventName())
+	assert.Equal(suite.T(), ExchangeRateSavedEvent, saved.EventName())
+	assert.Equal(suite.T(), UpstreamFetchFailedEvent, failed.EventName())
+	for _, key := range []string{fetched.EventKey(), saved.EventKey(), failed.EventKey()} {
+		assert.Equal(suite.T(), "USD-BRL", key)
+	}
+}
+
+func (suite *EventTestSuite) TestOutboxEventRoundTrip() {
+	saved := ExchangeRateSaved{Pair: "USD-BRL", ExchangeRate: suite.exchangeRate, OccurredAt: suite.at}
+	failed := UpstreamFetchFailed{Pair: "USD-BRL", Provider: "ecb", Error: "timeout", OccurredAt: suite.at}
+
+	for _, event := range []goeventbus.Event{saved, failed, ExchangeRateFetched{Pair: "USD-BRL", Provider: "ecb", ExchangeRate: suite.exchangeRate, OccurredAt: suite.at}} {
+		outboxEvent, err := NewOutboxEvent(event)
+		assert.NoError(suite.T(), err)
+		assert.NotEmpty(suite.T(), outboxEvent.ID)
+		assert.Equal(suite.T(), event.EventName(), outboxEvent.Name)
+		assert.Equal(suite.T(), "USD-BRL", outboxEvent.Key)
+
+		decoded, err := outboxEvent.Event()
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), event, decoded)
+	}
+
+	first, _ := NewOutboxEvent(saved)
+	later, _ := NewOutboxEvent(ExchangeRateSaved{Pair: "USD-BRL", ExchangeRate: suite.exchangeRate, OccurredAt: suite.at.Add(time.Second)})
+	assert.Equal(suite.T(), first.ID, later.ID, "the same quote saved again has the same ID")
+
+	newer, err := NewExchangeRate("USD", "BRL", "Dollar", "5.5", "5.4", "0.05", "0.01", "5.45", "5.46", "1626889260", "2021-07-21 17:41:00")
+	suite.Require().NoError(err)
+	other, _ := NewOutboxEvent(ExchangeRateSaved{Pair: "USD-BRL", ExchangeRate: newer, OccurredAt: suite.at})
+	assert.NotEqual(suite.T(), first.ID, other.ID, "another quote has another ID")
+	fetched, _ := NewOutboxEvent(ExchangeRateFetched{Pair: "USD-BRL", Provider: "ecb", ExchangeRate: suite.exchangeRate, OccurredAt: suite.at})
+	assert.NotEqual(suite.T(), first.ID, fetched.ID, "another event of the quote has another ID")
+
+	failed1, _ := NewOutboxEvent(failed)
+	failed2, _ := NewOutboxEvent(UpstreamFetchFailed{Pair: "USD-BRL", Provider: "ecb", Error: "timeout", OccurredAt: suite.at.Add(time.Second)})
+	assert.NotEqual(suite.T(), failed1.ID, failed2.ID, "failures without an exchange rate differ by when they occurred")
+}
+
+func (suite *EventTestSuite) TestOutboxEventRejectsUnknownNames() {
+	_, err := OutboxEvent{ID: "1", Name: "exchange_rate.deleted", Key: "USD-BRL", Payload: []byte("{}")}.Event()
+
+	assert.True(suite.T(), errors.Is(err, ErrUnknownEvent), "unexpected error: %v", err)
+}
@@ -0,0 +1,33 @@
+package exchangerateentity
+
+import "context"
+
+// RawQuote is a quote as an ExchangeRateProvider returns it, before validation: every field is the text written
+// by the source, in the format NewExchangeRate accepts.
+type RawQuote struct {
+	Code       string
+	CodeIn     string
+	Name       string
+	High       string
+	Low        string
+	VarBid     string
+	PctChange  string
+	Bid        string
+	Ask        string
+	Timestamp  string
+	CreateDate string
+}
+
+// ExchangeRate validates the quote with NewExchangeRate.
+func (q RawQuote) ExchangeRate() (*CurrencyInfo, error) {
+	return NewExchangeRate(q.Code, q.CodeIn, q.Name, q.High, q.Low, q.VarBid, q.PctChange, q.Bid, q.Ask, q.Timestamp, q.CreateDate)
+}
+
+// ExchangeRateProvider is a source of current exchange rates, such as a quote API.
+type ExchangeRateProvider interface {
+	// Name identifies the provider in logs and health reports.
+	Name() string
+	// GetExchangeRates returns the current quote of each pair the provider knows. Pairs it does not quote are left
+	// out rather than failing the call, so a caller asking for several pairs must check which were returned.
+	GetExchangeRates(ctx context.Context, pairs []CurrencyPair) ([]RawQuote, error)
+}
@@ -1,11 +1,132 @@
 package exchangerateentity
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var (
+	// ErrExchangeRateNotFound is returned by repositories when no exchange rate matches the requested ID,
+	// or by FindLatest when the pair has no quote.
+	ErrExchangeRateNotFound = errors.New("exchange rate not found")
+	// ErrInvalidHistoryQuery is returned by FindRange for a negative limit or offset, or a range ending before it starts.
+	ErrInvalidHistoryQuery = errors.New("invalid exchange rate history query")
+)
+
+// SortOrder orders the results of history queries by Timestamp.
+type SortOrder int
+
+const (
+	// SortAscending returns the oldest quotes first.
+	SortAscending SortOrder = iota
+	// SortDescending returns the newest quotes first.
+	SortDescending
+)
+
+// Page selects a window of the quotes returned by FindRange.
+// Quotes with the same Timestamp are ordered by ID, so consecutive pages neither repeat nor skip quotes.
+type Page struct {
+	// Limit caps the number of quotes when greater than zero.
+	Limit int
+	// Offset skips the first quotes.
+	Offset int
+	// Order sorts the quotes by Timestamp.
+	Order SortOrder
+}
+
+// ValidateHistoryQuery checks the arguments of FindRange. A zero from or to leaves that side of the range open.
+func ValidateHistoryQuery(from, to time.Time, page Page) error {
+	if page.Limit < 0 || page.Offset < 0 {
+		return fmt.Errorf("%w: limit %d and offset %d cannot be negative", ErrInvalidHistoryQuery, page.Limit, page.Offset)
+	}
+	if page.Order != SortAscending && page.Order != SortDescending {
+		return fmt.Errorf("%w: unknown sort order %d", ErrInvalidHistoryQuery, page.Order)
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return fmt.Errorf("%w: range ends at %s before it starts at %s", ErrInvalidHistoryQuery, to, from)
+	}
+	return nil
+}
+
+// TimestampBounds converts the range of FindRange into Unix second bounds, min inclusive and max exclusive,
+// matching the precision of CurrencyInfo.Timestamp. An open side becomes math.MinInt64 or math.MaxInt64.
+func TimestampBounds(from, to time.Time) (min int64, max int64) {
+	min, max = math.MinInt64, math.MaxInt64
+	if !from.IsZero() {
+		min = from.Unix()
+		if from.Nanosecond() > 0 {
+			min++
+		}
+	}
+	if !to.IsZero() {
+		max = to.Unix()
+		if to.Nanosecond() > 0 {
+			max++
+		}
+	}
+	return min, max
+}
+
+// RepositoryTimeoutError is returned by repositories when an operation does not finish before the deadline of its context.
+// It unwraps to context.DeadlineExceeded.
+type RepositoryTimeoutError struct {
+	Operation string
+	Err       error
+}
+
+func (e *RepositoryTimeoutError) Error() string {
+	return fmt.Sprintf("exchange rate repository %s exceeded its deadline: %v", e.Operation, e.Err)
+}
+
+func (e *RepositoryTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// MapDeadlineError wraps err in a *RepositoryTimeoutError when it was caused by an expired context deadline.
+// Other errors are returned unchanged.
+func MapDeadlineError(operation string, err error) error {
+	if err != nil && errors.Is(err, context.DeadlineExceeded) {
+		return &RepositoryTimeoutError{Operation: operation, Err: err}
+	}
+	return err
+}
+
 // ExchangeRateRepositoryInterface defines the methods that any repository implementation
 // of CurrencyInfo must implement.
 type ExchangeRateRepositoryInterface interface {
-	Save(currencyInfo *CurrencyInfo) error
-	FindAll() ([]*CurrencyInfo, error)
-	Find(code string, codeIn string) ([]*CurrencyInfo, error)
-	FindByID(id string) (*CurrencyInfo, error)
-	Delete(id string) error
+	Save(ctx context.Context, currencyInfo *CurrencyInfo) error
+	// Insert stores the quote unless one with the same ID is already stored, and reports whether it stored it.
+	// The check and the insert are a single operation, so of concurrent inserts of a quote only one reports true.
+	Insert(ctx context.Context, currencyInfo *CurrencyInfo) (bool, error)
+	FindAll(ctx context.Context) ([]*CurrencyInfo, error)
+	Find(ctx context.Context, code string, codeIn string) ([]*CurrencyInfo, error)
+	FindByID(ctx context.Context, id string) (*CurrencyInfo, error)
+	Delete(ctx context.Context, id string) error
+	// FindLatest returns the quote of the pair with the highest Timestamp, or ErrExchangeRateNotFound.
+	FindLatest(ctx context.Context, code string, codeIn string) (*CurrencyInfo, error)
+	// FindRange returns the quotes of the pair with from <= Timestamp < to, ordered and paginated by page.
+	FindRange(ctx context.Context, code string, codeIn string, from time.Time, to time.Time, page Page) ([]*CurrencyInfo, error)
+	// CountByPair returns the number of stored quotes of the pair.
+	CountByPair(ctx context.Context, code string, codeIn string) (int, error)
+}
+
+// UnitOfWork is implemented by repositories that can persist several calls atomically.
+type UnitOfWork interface {
+	// Do runs fn and keeps every repository call made with the context it receives, or none of them if fn returns an error.
+	// fn may run more than once if the storage asks for a retry, so it should only depend on its inputs.
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// AlertRuleRepositoryInterface defines the methods that any repository implementation of AlertRule must implement.
+type AlertRuleRepositoryInterface interface {
+	// SaveAlertRule inserts the rule, or replaces the rule with the same ID.
+	SaveAlertRule(ctx context.Context, rule *AlertRule) error
+	// FindAlertRules returns the rules of the pair.
+	FindAlertRules(ctx context.Context, code string, codeIn string) ([]*AlertRule, error)
+	FindAllAlertRules(ctx context.Context) ([]*AlertRule, error)
+	// DeleteAlertRule removes the rule, or returns ErrAlertRuleNotFound.
+	DeleteAlertRule(ctx context.Context, id string) error
 }
@@ -0,0 +1,64 @@
+package exchangerateentity
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type RepositoryInterfaceTestSuite struct {
+	suite.Suite
+}
+
+func TestRepositoryInterfaceTestSuite(t *testing.T) {
+	suite.Run(t, new(RepositoryInterfaceTestSuite))
+}
+
+func (suite *RepositoryInterfaceTestSuite) TestValidateHistoryQuery() {
+	from := time.Unix(1626889200, 0)
+	to := from.Add(time.Hour)
+	tests := []struct {
+		name  string
+		from  time.Time
+		to    time.Time
+		page  Page
+		valid bool
+	}{
+		{name: "open range", page: Page{}, valid: true},
+		{name: "closed range", from: from, to: to, page: Page{Limit: 10, Offset: 20, Order: SortDescending}, valid: true},
+		{name: "empty range", from: from, to: from, valid: true},
+		{name: "range ends before it starts", from: to, to: from},
+		{name: "negative limit", page: Page{Limit: -1}},
+		{name: "negative offset", page: Page{Offset: -1}},
+		{name: "unknown order", page: Page{Order: SortOrder(7)}},
+	}
+	for _, tt := range tests {
+		suite.T().Run(tt.name, func(t *testing.T) {
+			err := ValidateHistoryQuery(tt.from, tt.to, tt.page)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, ErrInvalidHistoryQuery), "unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func (suite *RepositoryInterfaceTestSuite) TestTimestampBounds() {
+	min, max := TimestampBounds(time.Time{}, time.Time{})
+	assert.Equal(suite.T(), int64(math.MinInt64), min)
+	assert.Equal(suite.T(), int64(math.MaxInt64), max)
+
+	min, max = TimestampBounds(time.Unix(100, 0), time.Unix(200, 0))
+	assert.Equal(suite.T(), int64(100), min)
+	assert.Equal(suite.T(), int64(200), max)
+
+	// Fractional seconds round inwards for the start and outwards for the end.
+	min, max = TimestampBounds(time.Unix(100, 500), time.Unix(200, 500))
+	assert.Equal(suite.T(), int64(101), min)
+	assert.Equal(suite.T(), int64(201), max)
+}
@@ -0,0 +1,323 @@
+// Package repositorytest holds the conformance suite every ExchangeRateRepositoryInterface implementation must pass.
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	entity "libs/services/entities/exchange-rate/entity"
+	"strconv"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// ExchangeRateRepositorySuite checks the behaviour shared by all exchange rate repositories.
+// Run it from the tests of an implementation with suite.Run, setting NewRepository.
+type ExchangeRateRepositorySuite struct {
+	suite.Suite
+	// NewRepository returns an empty repository. It is called before every test.
+	NewRepository func() entity.ExchangeRateRepositoryInterface
+	// Cleanup, when set, is called after every test.
+	Cleanup func()
+
+	repository entity.ExchangeRateRepositoryInterface
+}
+
+func (suite *ExchangeRateRepositorySuite) SetupTest() {
+	suite.repository = suite.NewRepository()
+}
+
+func (suite *ExchangeRateRepositorySuite) TearDownTest() {
+	if suite.Cleanup != nil {
+		suite.Cleanup()
+	}
+	suite.repository = nil
+}
+
+// newCurrencyInfo builds a valid entity for the given pair and timestamp, with every price equal to bid.
+func (suite *ExchangeRateRepositorySuite) newCurrencyInfo(code, codeIn, bid, timestamp string) *entity.CurrencyInfo {
+	currencyInfo, err := entity.NewExchangeRate(
+		code,
+		codeIn,
+		code+"/"+codeIn,
+		bid,
+		bid,
+		"0.05",
+		"0.01",
+		bid,
+		bid,
+		timestamp,
+		"2021-07-21 00:00:00",
+	)
+	suite.Require().NoError(err)
+	return currencyInfo
+}
+
+// ids returns the IDs of the given entities.
+func ids(currencyInfos []*entity.CurrencyInfo) []string {
+	result := make([]string, len(currencyInfos))
+	for i, currencyInfo := range currencyInfos {
+		result[i] = currencyInfo.GetEntityID()
+	}
+	return result
+}
+
+func (suite *ExchangeRateRepositorySuite) TestSaveAndFindByID() {
+	currencyInfo := suite.newCurrencyInfo("USD", "BRL", "5.45", "1626889200")
+
+	err := suite.repository.Save(context.Background(), currencyInfo)
+	assert.NoError(suite.T(), err)
+
+	result, err := suite.repository.FindByID(context.Background(), currencyInfo.GetEntityID())
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), currencyInfo, result)
+}
+
+func (suite *ExchangeRateRepositorySuite) TestFindByIDNotFound() {
+	result, err := suite.repository.FindByID(context.Background(), "missing")
+	assert.Nil(suite.T(), result)
+	assert.True(suite.T(), errors.Is(err, entity.ErrExchangeRateNotFound), "unexpected error: %v", err)
+}
+
+func (suite *ExchangeRateRepositorySuite) TestSaveTwiceKeepsOneEntity() {
+	currencyInfo := suite.newCurrencyInfo("USD", "BRL", "5.45", "1626889200")
+
+	assert.NoError(suite.T(), suite.repository.Save(context.Background(), currencyInfo))
+	assert.NoError(suite.T(), suite.repository.Save(context.Background(), currencyInfo))
+
+	results, err := suite.repository.FindAll(context.Background())
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, len(results))
+}
+
+func (suite *ExchangeRateRepositorySuite) TestFindAll() {
+	results, err := suite.repository.FindAll(context.Background())
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), results)
+
+	usd := suite.newCurrencyInfo("USD", "BRL", "5.45", "1626889200")
+	eur := suite.newCurrencyInfo("EUR", "BRL", "6.01", "1626889200")
+	assert.NoError(suite.T(), suite.repository.Save(context.Background(), usd))
+	assert.NoError(suite.T(), suite.repository.Save(context.Background(), eur))
+
+	results, err = suite.repository.FindAll(context.Background())
+	assert.NoError(suite.T(), err)
+	assert.ElementsMatch(suite.T(), ids([]*entity.CurrencyInfo{usd, eur}), ids(results))
+}
+
+func (suite *ExchangeRateRepositorySuite) TestFind() {
+	first := suite.newCurrencyInfo("USD", "BRL", "5.45", "1626889200")
+	second := suite.newCurrencyInfo("USD", "BRL", "5.47", "1626889260")
+	other := suite.newCurrencyInfo("USD", "EUR", "0.92", "1626889200")
+	for _, currencyInfo := range []*entity.CurrencyInfo{first, second, other} {
+		assert.NoError(suite.T(), suite.repository.Save(context.Background(), currencyInfo))
+	}
+
+	results, err := suite.repository.Find(context.Background(), "USD", "BRL")
+	assert.NoError(suite.T(), err)
+	assert.ElementsMatch(suite.T(), ids([]*entity.CurrencyInfo{first, second}), ids(results))
+
+	results, err = suite.repository.Find(context.Background(), "GBP", "BRL")
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), results)
+}
+
+func (suite *ExchangeRateRepositorySuite) TestDelete() {
+	currencyInfo := suite.newCurrencyInfo("USD", "BRL", "5.45", "1626889200")
+	assert.NoError(suite.T(), suite.repository.Save(context.Background(), currencyInfo))
+
+	err := suite.repository.Delete(context.Background(), currencyInfo.GetEntityID())
+	assert.NoError(suite.T(), err)
+
+	_, err = suite.repository.FindByID(context.Background(), currencyInfo.GetEntityID())
+	assert.True(suite.T(), errors.Is(err, entity.ErrExchangeRateNotFound), "unexpected error: %v", err)
+}
+
+func (suite *ExchangeRateRepositorySuite) TestDeleteNotFound() {
+	err := suite.repository.Delete(context.Background(), "missing")
+	assert.True(suite.T(), errors.Is(err, entity.ErrExchangeRateNotFound), "unexpected error: %v", err)
+}
+
+func (suite *ExchangeRateRepositorySuite) TestExpiredDeadline() {
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	currencyInfo := suite.newCurrencyInfo("USD", "BRL", "5.45", "1626889200")
+
+	err := suite.repository.Save(ctx, currencyInfo)
+
+	var timeoutErr *entity.RepositoryTimeoutError
+	assert.True(suite.T(), errors.As(err, &timeoutErr), "unexpected error: %v", err)
+	assert.ErrorIs(suite.T(), err, context.DeadlineExceeded)
+
+	_, err = suite.repository.FindAll(ctx)
+	assert.True(suite.T(), errors.As(err, &timeoutErr), "unexpected error: %v", err)
+}
+
+// unitOfWork returns the repository as a UnitOfWork, skipping the test when it does not implement one.
+func (suite *ExchangeRateRepositorySuite) unitOfWork() entity.UnitOfWork {
+	uow, ok := suite.repository.(entity.UnitOfWork)
+	if !ok {
+		suite.T().Skip("repository does not implement UnitOfWork")
+	}
+	return uow
+}
+
+func (suite *ExchangeRateRepositorySuite) TestUnitOfWorkCommit() {
+	uow := suite.unitOfWork()
+	usd := suite.newCurrencyInfo("USD", "BRL", "5.45", "1626889200")
+	eur := suite.newCurrencyInfo("EUR", "BRL", "6.01", "1626889200")
+
+	err := uow.Do(context.Background(), func(ctx context.Context) error {
+		if err := suite.repository.Save(ctx, usd); err != nil {
+			return err
+		}
+		return suite.repository.Save(ctx, eur)
+	})
+	assert.NoError(suite.T(), err)
+
+	results, err := suite.repository.FindAll(context.Background())
+	assert.NoError(suite.T(), err)
+	assert.ElementsMatch(suite.T(), ids([]*entity.CurrencyInfo{usd, eur}), ids(results))
+}
+
+func (suite *ExchangeRateRepositorySuite) TestUnitOfWorkRollback() {
+	uow := suite.unitOfWork()
+	kept := suite.newCurrencyInfo("GBP", "BRL", "7.01", "1626889200")
+	assert.NoError(suite.T(), suite.repository.Save(context.Background(), kept))
+	usd := suite.newCurrencyInfo("USD", "BRL", "5.45", "1626889200")
+	boom := errors.New("boom")
+
+	err := uow.Do(context.Background(), func(ctx context.Context) error {
+		if err := suite.repository.Save(ctx, usd); err != nil {
+			return err
+		}
+		if err := suite.repository.Delete(ctx, kept.GetEntityID()); err != nil {
+			return err
+		}
+		return boom
+	})
+	assert.ErrorIs(suite.T(), err, boom)
+
+	results, err := suite.repository.FindAll(context.Background())
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{kept.GetEntityID()}, ids(results))
+}
+
+// saveHistory saves quotes of USD-BRL at the given timestamps, and a quote of another pair, and returns the USD-BRL ones.
+func (suite *ExchangeRateRepositorySuite) saveHistory(timestamps ...int64) []*entity.CurrencyInfo {
+	other := suite.newCurrencyInfo("EUR", "BRL", "6.01", "1626889230")
+	suite.Require().NoError(suite.repository.Save(context.Background(), other))
+	saved := make([]*entity.CurrencyInfo, len(timestamps))
+	for i, timestamp := range timestamps {
+		saved[i] = suite.newCurrencyInfo("USD", "BRL", fmt.Sprintf("5.%02d", 40+i), strconv.FormatInt(timestamp, 10))
+		suite.Require().NoError(suite.repository.Save(context.Background(), saved[i]))
+	}
+	return saved
+}
+
+func (suite *ExchangeRateRepositorySuite) TestFindLatest() {
+	_, err := suite.repository.FindLatest(context.Background(), "USD", "BRL")
+	assert.True(suite.T(), errors.Is(err, entity.ErrExchangeRateNotFound), "unexpected error: %v", err)
+
+	saved := suite.saveHistory(1626889260, 1626889320, 1626889200)
+
+	result, err := suite.repository.FindLatest(context.Background(), "USD", "BRL")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), saved[1], result)
+}
+
+func (suite *ExchangeRateRepositorySuite) TestFindRange() {
+	saved := suite.saveHistory(1626889320, 1626889200, 1626889260, 1626889380)
+	from := time.Unix(1626889200, 0)
+	to := time.Unix(1626889380, 0)
+
+	results, err := suite.repository.FindRange(context.Background(), "USD", "BRL", from, to, entity.Page{})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), ids([]*entity.CurrencyInfo{saved[1], saved[2], saved[0]}), ids(results))
+
+	results, err = suite.repository.FindRange(context.Background(), "USD", "BRL", from, to, entity.Page{Order: entity.SortDescending})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), ids([]*entity.CurrencyInfo{saved[0], saved[2], saved[1]}), ids(results))
+
+	results, err = suite.repository.FindRange(context.Background(), "USD", "BRL", time.Time{}, time.Time{}, entity.Page{})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), ids([]*entity.CurrencyInfo{saved[1], saved[2], saved[0], saved[3]}), ids(results))
+
+	results, err = suite.repository.FindRange(context.Background(), "GBP", "BRL", from, to, entity.Page{})
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), results)
+}
+
+func (suite *ExchangeRateRepositorySuite) TestFindRangePagination() {
+	saved := suite.saveHistory(1626889200, 1626889260, 1626889320, 1626889380, 1626889440)
+	var pages [][]string
+	for offset := 0; offset < 6; offset += 2 {
+		results, err := suite.repository.FindRange(context.Background(), "USD", "BRL", time.Time{}, time.Time{}, entity.Page{
+			Limit:  2,
+			Offset: offset,
+			Order:  entity.SortDescending,
+		})
+		assert.NoError(suite.T(), err)
+		pages = append(pages, ids(results))
+	}
+
+	assert.Equal(suite.T(), [][]string{
+		ids([]*entity.CurrencyInfo{saved[4], saved[3]}),
+		ids([]*entity.CurrencyInfo{saved[2], saved[1]}),
+		ids([]*entity.CurrencyInfo{saved[0]}),
+	}, pages)
+}
+
+func (suite *ExchangeRateRepositorySuite) TestFindRangeInvalid() {
+	from := time.Unix(1626889200, 0)
+
+	_, err := suite.repository.FindRange(context.Background(), "USD", "BRL", from, from.Add(-time.Second), entity.Page{})
+	assert.True(suite.T(), errors.Is(err, entity.ErrInvalidHistoryQuery), "unexpected error: %v", err)
+
+	_, err = suite.repository.FindRange(context.Background(), "USD", "BRL", from, time.Time{}, entity.Page{Limit: -1})
+	assert.True(suite.T(), errors.Is(err, entity.ErrInvalidHistoryQuery), "unexpected error: %v", err)
+}
+
+func (suite *ExchangeRateRepositorySuite) TestCountByPair() {
+	count, err := suite.repository.CountByPair(context.Background(), "USD", "BRL")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, count)
+
+	suite.saveHistory(1626889200, 1626889260, 1626889320)
+
+	count, err = suite.repository.CountByPair(context.Background(), "USD", "BRL")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 3, count)
+	count, err = suite.repository.CountByPair(context.Background(), "EUR", "BRL")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, count)
+}
+
+func (suite *ExchangeRateRepositorySuite) TestFindCandles() {
+	candleRepository, ok := suite.repository.(entity.CandleRepository)
+	if !ok {
+		suite.T().Skip("repository does not implement CandleRepository")
+	}
+	// Two quotes in the first minute, one in the third; the last one is outside the range.
+	saved := suite.saveHistory(1626889200, 1626889230, 1626889330, 1626889400)
+	from := time.Unix(1626889200, 0)
+	to := time.Unix(1626889400, 0)
+
+	candles, err := candleRepository.FindCandles(context.Background(), "USD", "BRL", entity.CandleInterval1m, from, to)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), entity.AggregateCandles(saved[:3], entity.CandleInterval1m), candles)
+	assert.Equal(suite.T(), []entity.Candle{
+		{Start: 1626889200, Open: entity.MustParseDecimal("5.40"), High: entity.MustParseDecimal("5.41"), Low: entity.MustParseDecimal("5.40"), Close: entity.MustParseDecimal("5.41"), Count: 2},
+		{Start: 1626889320, Open: entity.MustParseDecimal("5.42"), High: entity.MustParseDecimal("5.42"), Low: entity.MustParseDecimal("5.42"), Close: entity.MustParseDecimal("5.42"), Count: 1},
+	}, candles)
+
+	candles, err = candleRepository.FindCandles(context.Background(), "USD", "BRL", entity.CandleInterval1d, time.Time{}, time.Time{})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []entity.Candle{{Start: 1626825600, Open: entity.MustParseDecimal("5.40"), High: entity.MustParseDecimal("5.43"), Low: entity.MustParseDecimal("5.40"), Close: entity.MustParseDecimal("5.43"), Count: 4}}, candles)
+
+	_, err = candleRepository.FindCandles(context.Background(), "USD", "BRL", entity.CandleInterval(time.Second), from, to)
+	assert.True(suite.T(), errors.Is(err, entity.ErrInvalidCandleInterval), "unexpected error: %v", err)
+}
@@ -0,0 +1,238 @@
+package exchangerateentity
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrValidation is matched by every *ValidationError with errors.Is.
+var ErrValidation = errors.New("invalid exchange rate")
+
+// Rules reported by a FieldError.
+const (
+	// RuleRequired is violated by an empty field.
+	RuleRequired = "required"
+	// RuleCurrency is violated by a code that is not an active ISO 4217 currency.
+	RuleCurrency = "currency"
+	// RuleDifferentCurrencies is violated by a pair whose two codes are the same currency.
+	RuleDifferentCurrencies = "different_currencies"
+	// RuleDecimal is violated by a price that is not a decimal number with at most DecimalScale decimal places.
+	RuleDecimal = "decimal"
+	// RuleNumber is violated by a value that is not a number.
+	RuleNumber = "number"
+	// RuleInteger is violated by a value that is not a whole number.
+	RuleInteger = "integer"
+	// RulePositive is violated by a value that is zero or negative.
+	RulePositive = "positive"
+	// RuleDateTime is violated by a date that is not written as "2006-01-02 15:04:05".
+	RuleDateTime = "datetime"
+	// RuleLowNotAboveBid is violated when Low > Bid.
+	RuleLowNotAboveBid = "low_lte_bid"
+	// RuleBidNotAboveHigh is violated when Bid > High.
+	RuleBidNotAboveHigh = "bid_lte_high"
+	// RuleAskNotBelowBid is violated when Ask < Bid.
+	RuleAskNotBelowBid = "ask_gte_bid"
+)
+
+// FieldError describes why a field of an exchange rate is invalid.
+type FieldError struct {
+	// Field is the JSON name of the field, such as "bid".
+	Field string `json:"field"`
+	// Value is the raw value that was rejected.
+	Value string `json:"value"`
+	// Rule is the rule the value violates, one of the Rule constants.
+	Rule string `json:"rule"`
+	// Message explains the violation.
+	Message string `json:"message"`
+	// Err is the underlying error, if any, such as one wrapping ErrInvalidCurrencyPair or ErrInvalidDecimal.
+	Err error `json:"-"`
+}
+
+// Error returns the field followed by the message.
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// Unwrap returns the underlying error.
+func (e FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError holds every FieldError found while building an exchange rate, in the order of the fields.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+// Error returns the messages of all field errors.
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, fieldErr := range e.Errors {
+		messages[i] = fieldErr.Error()
+	}
+	return ErrValidation.Error() + ": " + strings.Join(messages, "; ")
+}
+
+// Is reports whether target is ErrValidation.
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// Unwrap returns the field errors, so errors.Is also matches their underlying errors.
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, fieldErr := range e.Errors {
+		errs[i] = fieldErr
+	}
+	return errs
+}
+
+// Field returns the first error of the named field.
+func (e *ValidationError) Field(field string) (FieldError, bool) {
+	for _, fieldErr := range e.Errors {
+		if fieldErr.Field == field {
+			return fieldErr, true
+		}
+	}
+	return FieldError{}, false
+}
+
+// validator collects the field errors of an exchange rate.
+type validator struct {
+	errors []FieldError
+}
+
+// add records a field error.
+func (v *validator) add(field, value, rule, message string, err error) {
+	v.errors = append(v.errors, FieldError{Field: field, Value: value, Rule: rule, Message: message, Err: err})
+}
+
+// err returns a *ValidationError holding the collected errors, or nil.
+func (v *validator) err() error {
+	if len(v.errors) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: v.errors}
+}
+
+// currencyPair validates code and codeIn and returns the pair, or the zero CurrencyPair when they do not form one.
+func (v *validator) currencyPair(code, codeIn string) CurrencyPair {
+	valid := true
+	for _, field := range []struct{ name, value string }{{"code", code}, {"codeIn", codeIn}} {
+		if strings.TrimSpace(field.value) == "" {
+			v.add(field.name, field.value, RuleRequired, "is required",
+				fmt.Errorf("%w: %s is required", ErrInvalidCurrencyPair, field.name))
+			valid = false
+			continue
+		}
+		if _, err := ParseCurrency(field.value); err != nil {
+			v.add(field.name, field.value, RuleCurrency, "is not an active ISO 4217 currency",
+				fmt.Errorf("%w: %w", ErrInvalidCurrencyPair, err))
+			valid = false
+		}
+	}
+	if !valid {
+		return CurrencyPair{}
+	}
+	pair, err := NewCurrencyPair(code, codeIn)
+	if err != nil {
+		v.add("codeIn", codeIn, RuleDifferentCurrencies, "must differ from code", err)
+	}
+	return pair
+}
+
+// decimal parses a price, or records why it is not one.
+func (v *validator) decimal(field, value string) (Decimal, bool) {
+	if value == "" {
+		v.add(field, value, RuleRequired, "is required", nil)
+		return Decimal{}, false
+	}
+	d, err := ParseDecimal(value)
+	if err != nil {
+		v.add(field, value, RuleDecimal, fmt.Sprintf("must be a decimal number with at most %d decimal places", DecimalScale), err)
+		return Decimal{}, false
+	}
+	return d, true
+}
+
+// price is like decimal for a price whose validity is only checked through has.
+func (v *validator) price(field, value string) Decimal {
+	d, _ := v.decimal(field, value)
+	return d
+}
+
+// positiveDecimal is like decimal but also requires the price to be above zero.
+func (v *validator) positiveDecimal(field, value string) Decimal {
+	d, ok := v.decimal(field, value)
+	if ok && d.Sign() <= 0 {
+		v.add(field, value, RulePositive, "must be greater than zero", nil)
+	}
+	return d
+}
+
+// float parses a number, or records why it is not one.
+func (v *validator) float(field, value string) float64 {
+	if value == "" {
+		v.add(field, value, RuleRequired, "is required", nil)
+		return 0
+	}
+	f, err := StringToFloat64(value)
+	if err != nil {
+		v.add(field, value, RuleNumber, "must be a number", err)
+	}
+	return f
+}
+
+// timestamp parses positive Unix seconds, or records why they are not.
+func (v *validator) timestamp(field, value string) int64 {
+	if value == "" {
+		v.add(field, value, RuleRequired, "is required", nil)
+		return 0
+	}
+	n, err := StringToInt64(value)
+	if err != nil {
+		v.add(field, value, RuleInteger, "must be a whole number of Unix seconds", err)
+		return 0
+	}
+	if n <= 0 {
+		v.add(field, value, RulePositive, "must be greater than zero", nil)
+	}
+	return n
+}
+
+// dateTime parses a date written with createDateLayout, or records why it is not one.
+func (v *validator) dateTime(field, value string) time.Time {
+	t, err := time.Parse(createDateLayout, value)
+	if err != nil {
+		v.add(field, value, RuleDateTime, "must be written as "+createDateLayout, err)
+	}
+	return t
+}
+
+// has reports whether an error was recorded for the field.
+func (v *validator) has(field string) bool {
+	for _, fieldErr := range v.errors {
+		if fieldErr.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// prices checks the invariants between the valid prices of e: Low <= Bid <= High and Ask >= Bid.
+// high, low and ask are the raw values reported on violation.
+func (v *validator) prices(e *CurrencyInfo, high, low, ask string) {
+	if v.has("bid") {
+		return
+	}
+	if !v.has("low") && e.Low.Cmp(e.Bid) > 0 {
+		v.add("low", low, RuleLowNotAboveBid, "must not be greater than bid "+e.Bid.String(), nil)
+	}
+	if !v.has("high") && e.Bid.Cmp(e.High) > 0 {
+		v.add("high", high, RuleBidNotAboveHigh, "must not be less than bid "+e.Bid.String(), nil)
+	}
+	if !v.has("ask") && e.Ask.Cmp(e.Bid) < 0 {
+		v.add("ask", ask, RuleAskNotBelowBid, "must not be less than bid "+e.Bid.String(), nil)
+	}
+}
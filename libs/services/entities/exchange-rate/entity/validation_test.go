@@ -0,0 +1,92 @@
+package exchangerateentity
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ValidationTestSuite struct {
+	suite.Suite
+}
+
+func TestValidationTestSuite(t *testing.T) {
+	suite.Run(t, new(ValidationTestSuite))
+}
+
+// rules returns the field and rule of each error of err, which must be a *ValidationError.
+func (suite *ValidationTestSuite) rules(err error) [][2]string {
+	var validationErr *ValidationError
+	suite.Require().True(errors.As(err, &validationErr), "unexpected error: %v", err)
+	rules := make([][2]string, len(validationErr.Errors))
+	for i, fieldErr := range validationErr.Errors {
+		rules[i] = [2]string{fieldErr.Field, fieldErr.Rule}
+	}
+	return rules
+}
+
+func (suite *ValidationTestSuite) TestNewExchangeRateCollectsAllFieldErrors() {
+	currencyInfo, err := NewExchangeRate("USD", "BRL", "Dollar", "abc", "", "5.45", "1%", "0", "5.123456789", "yesterday", "21/07/2021")
+
+	assert.Nil(suite.T(), currencyInfo)
+	assert.True(suite.T(), errors.Is(err, ErrValidation))
+	assert.False(suite.T(), errors.Is(err, ErrInvalidCurrencyPair))
+	assert.True(suite.T(), errors.Is(err, ErrInvalidDecimal))
+	assert.Equal(suite.T(), [][2]string{
+		{"high", RuleDecimal},
+		{"low", RuleRequired},
+		{"pctChange", RuleNumber},
+		{"bid", RulePositive},
+		{"ask", RuleDecimal},
+		{"timestamp", RuleInteger},
+		{"create_date", RuleDateTime},
+	}, suite.rules(err))
+
+	var validationErr *ValidationError
+	suite.Require().True(errors.As(err, &validationErr))
+	high, ok := validationErr.Field("high")
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), "abc", high.Value)
+	assert.Contains(suite.T(), err.Error(), "high: must be a decimal number")
+}
+
+func (suite *ValidationTestSuite) TestNewExchangeRateChecksCurrencyPair() {
+	_, err := NewExchangeRate("", "XYZ", "Dollar", "5.5", "5.4", "0.05", "0.01", "5.45", "5.46", "1626889200", "2021-07-21 00:00:00")
+	assert.True(suite.T(), errors.Is(err, ErrInvalidCurrencyPair))
+	assert.True(suite.T(), errors.Is(err, ErrUnknownCurrency))
+	assert.Equal(suite.T(), [][2]string{{"code", RuleRequired}, {"codeIn", RuleCurrency}}, suite.rules(err))
+
+	_, err = NewExchangeRate("brl", "BRL", "Real", "5.5", "5.4", "0.05", "0.01", "5.45", "5.46", "1626889200", "2021-07-21 00:00:00")
+	assert.True(suite.T(), errors.Is(err, ErrIdenticalCurrencies))
+	assert.Equal(suite.T(), [][2]string{{"codeIn", RuleDifferentCurrencies}}, suite.rules(err))
+}
+
+func (suite *ValidationTestSuite) TestNewExchangeRateChecksPriceInvariants() {
+	_, err := NewExchangeRate("USD", "BRL", "Dollar", "5.44", "5.46", "0.05", "0.01", "5.45", "5.40", "1626889200", "2021-07-21 00:00:00")
+	assert.Equal(suite.T(), [][2]string{
+		{"low", RuleLowNotAboveBid},
+		{"high", RuleBidNotAboveHigh},
+		{"ask", RuleAskNotBelowBid},
+	}, suite.rules(err))
+
+	var validationErr *ValidationError
+	suite.Require().True(errors.As(err, &validationErr))
+	ask, _ := validationErr.Field("ask")
+	assert.Equal(suite.T(), "5.40", ask.Value)
+	assert.Equal(suite.T(), "must not be less than bid 5.45", ask.Message)
+}
+
+func (suite *ValidationTestSuite) TestNewExchangeRateAcceptsBoundaryPrices() {
+	currencyInfo, err := NewExchangeRate("USD", "BRL", "Dollar", "5.45", "5.45", "0", "0", "5.45", "5.45", "1626889200", "2021-07-21 00:00:00")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), MustParseDecimal("5.45"), currencyInfo.Ask)
+}
+
+func (suite *ValidationTestSuite) TestInvariantsSkipInvalidPrices() {
+	_, err := NewExchangeRate("USD", "BRL", "Dollar", "5.5", "x", "0.05", "0.01", "-1", "5.46", "1626889200", "2021-07-21 00:00:00")
+
+	assert.Equal(suite.T(), [][2]string{{"low", RuleDecimal}, {"bid", RulePositive}}, suite.rules(err))
+}
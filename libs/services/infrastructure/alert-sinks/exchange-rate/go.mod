@@ -0,0 +1,3 @@
+module libs/services/infrastructure/alert-sinks/exchange-rate
+
+go 1.22
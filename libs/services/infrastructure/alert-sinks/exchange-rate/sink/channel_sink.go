@@ -0,0 +1,33 @@
+package alertsink
+
+import (
+	"context"
+	entity "libs/services/entities/exchange-rate/entity"
+)
+
+// ChannelSink sends each alert to a channel, such as to observe alerts in tests.
+type ChannelSink struct {
+	alerts chan entity.Alert
+}
+
+// NewChannelSink creates and returns a new ChannelSink whose channel buffers size alerts.
+func NewChannelSink(size int) *ChannelSink {
+	return &ChannelSink{
+		alerts: make(chan entity.Alert, size),
+	}
+}
+
+// Alerts returns the channel the alerts are sent to.
+func (s *ChannelSink) Alerts() <-chan entity.Alert {
+	return s.alerts
+}
+
+// Notify sends the alert to the channel, waiting for room until ctx is done.
+func (s *ChannelSink) Notify(ctx context.Context, alert entity.Alert) error {
+	select {
+	case s.alerts <- alert:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,28 @@
+package alertsink
+
+import (
+	"context"
+	entity "libs/services/entities/exchange-rate/entity"
+	"log"
+)
+
+// LogSink writes each alert to a logger.
+type LogSink struct {
+	logger *log.Logger
+}
+
+// NewLogSink creates and returns a new LogSink writing to logger, or to the standard logger when logger is nil.
+func NewLogSink(logger *log.Logger) *LogSink {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &LogSink{
+		logger: logger,
+	}
+}
+
+// Notify writes the message of the alert.
+func (s *LogSink) Notify(ctx context.Context, alert entity.Alert) error {
+	s.logger.Printf("ALERT %s", alert.Message)
+	return nil
+}
@@ -0,0 +1,110 @@
+package alertsink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	entity "libs/services/entities/exchange-rate/entity"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type AlertSinkTestSuite struct {
+	suite.Suite
+	alert entity.Alert
+}
+
+func TestAlertSinkTestSuite(t *testing.T) {
+	suite.Run(t, new(AlertSinkTestSuite))
+}
+
+func (suite *AlertSinkTestSuite) SetupTest() {
+	rule, err := entity.NewThresholdAlertRule("USD", "BRL", entity.AlertAbove, entity.MustParseDecimal("5.5"))
+	suite.Require().NoError(err)
+	quote, err := entity.NewExchangeRate("USD", "BRL", "Dollar", "5.6", "5.6", "0", "0", "5.6", "5.6", "1626889200", "2021-07-21 17:40:00")
+	suite.Require().NoError(err)
+	suite.alert = entity.NewAlert(*rule, quote, nil)
+}
+
+func (suite *AlertSinkTestSuite) TestWebhookSinkPostsTheAlert() {
+	var received entity.Alert
+	var method, contentType, token string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, contentType, token = r.Method, r.Header.Get("Content-Type"), r.Header.Get("Authorization")
+		assert.NoError(suite.T(), json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	sink := NewWebhookSink(server.URL)
+	sink.SetHeader("Authorization", "Bearer token")
+
+	err := sink.Notify(context.Background(), suite.alert)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.MethodPost, method)
+	assert.Equal(suite.T(), "application/json", contentType)
+	assert.Equal(suite.T(), "Bearer token", token)
+	assert.Equal(suite.T(), suite.alert, received)
+}
+
+func (suite *AlertSinkTestSuite) TestWebhookSinkAcceptsJSONResponses() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	assert.NoError(suite.T(), NewWebhookSink(server.URL).Notify(context.Background(), suite.alert))
+}
+
+func (suite *AlertSinkTestSuite) TestWebhookSinkFailsOnErrorStatus() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	assert.Error(suite.T(), NewWebhookSink(server.URL).Notify(context.Background(), suite.alert))
+}
+
+func (suite *AlertSinkTestSuite) TestWebhookSinkTimesOut() {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+	sink := NewWebhookSink(server.URL)
+	sink.Timeout = 10 * time.Millisecond
+
+	assert.Error(suite.T(), sink.Notify(context.Background(), suite.alert))
+}
+
+func (suite *AlertSinkTestSuite) TestLogSinkWritesTheMessage() {
+	var output bytes.Buffer
+
+	err := NewLogSink(log.New(&output, "", 0)).Notify(context.Background(), suite.alert)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "ALERT USD-BRL above 5.5: bid 5.6\n", output.String())
+}
+
+func (suite *AlertSinkTestSuite) TestChannelSinkSendsTheAlert() {
+	sink := NewChannelSink(1)
+
+	assert.NoError(suite.T(), sink.Notify(context.Background(), suite.alert))
+	assert.Equal(suite.T(), suite.alert, <-sink.Alerts())
+}
+
+func (suite *AlertSinkTestSuite) TestChannelSinkStopsWaitingWithTheContext() {
+	sink := NewChannelSink(0)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.ErrorIs(suite.T(), sink.Notify(ctx, suite.alert), context.Canceled)
+}
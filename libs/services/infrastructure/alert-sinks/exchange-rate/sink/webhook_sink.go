@@ -0,0 +1,68 @@
+package alertsink
+
+import (
+	"context"
+	"errors"
+	"io"
+	entity "libs/services/entities/exchange-rate/entity"
+	gorequest "libs/shared/go-request"
+	"net/http"
+	"time"
+)
+
+// DefaultWebhookTimeout bounds each request of a WebhookSink.
+const DefaultWebhookTimeout = 2 * time.Second
+
+// WebhookSink posts each alert as JSON to a URL.
+type WebhookSink struct {
+	// url is the URL the alerts are posted to.
+	url string
+	// headers are added to each request, such as an Authorization header.
+	headers map[string]string
+	// httpClient is the client used to make HTTP requests.
+	httpClient *http.Client
+	// Timeout for each request.
+	Timeout time.Duration
+}
+
+// NewWebhookSink creates and returns a new WebhookSink posting to url.
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{
+		url:        url,
+		headers:    map[string]string{},
+		httpClient: &http.Client{},
+		Timeout:    DefaultWebhookTimeout,
+	}
+}
+
+// SetHeader adds a header to each request.
+func (s *WebhookSink) SetHeader(name string, value string) {
+	s.headers[name] = value
+}
+
+// Notify posts the alert to the URL. A 2xx response with an empty or JSON body is a success.
+func (s *WebhookSink) Notify(ctx context.Context, alert entity.Alert) error {
+	headers := map[string]string{"Content-Type": "application/json"}
+	for name, value := range s.headers {
+		headers[name] = value
+	}
+	req, err := gorequest.CreateRequest(
+		ctx,
+		s.url,
+		nil,
+		nil,
+		alert,
+		headers,
+		http.MethodPost,
+	)
+	if err != nil {
+		return err
+	}
+
+	var response interface{}
+	err = gorequest.SendRequest(ctx, req, s.httpClient, &response, s.Timeout)
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
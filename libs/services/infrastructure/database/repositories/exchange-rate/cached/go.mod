@@ -0,0 +1,3 @@
+module libs/services/infrastructure/database/repositories/exchange-rate/cached
+
+go 1.22
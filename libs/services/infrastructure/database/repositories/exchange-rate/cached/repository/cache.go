@@ -0,0 +1,180 @@
+package cachedrepository
+
+import (
+	"container/list"
+	entity "libs/services/entities/exchange-rate/entity"
+	"sync"
+	"time"
+)
+
+// keyKind tells which read a cache entry holds.
+type keyKind int
+
+const (
+	// byID holds the result of FindByID.
+	byID keyKind = iota
+	// byPair holds the result of Find.
+	byPair
+	// latestByPair holds the result of FindLatest.
+	latestByPair
+)
+
+// cacheKey identifies a cached read: an ID for byID, a (code, codeIn) pair for the others.
+type cacheKey struct {
+	kind   keyKind
+	id     string
+	code   string
+	codeIn string
+}
+
+// cacheEntry is a cached read. value is a *entity.CurrencyInfo or a []*entity.CurrencyInfo, never handed out directly.
+type cacheEntry struct {
+	key     cacheKey
+	value   interface{}
+	expires time.Time
+	element *list.Element
+}
+
+// cache is a least recently used cache of repository reads whose entries expire after a TTL.
+//
+// Every invalidation bumps a generation counter. A read records the generation before loading from the
+// wrapped repository and its result is only stored if no invalidation happened meanwhile, so a load that
+// raced a write never puts the value the write replaced back in the cache.
+type cache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+	entries    map[cacheKey]*cacheEntry
+	order      *list.List
+	generation uint64
+	stats      CacheStats
+}
+
+// newCache returns an empty cache of at most maxEntries entries living for ttl.
+func newCache(ttl time.Duration, maxEntries int) *cache {
+	return &cache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        time.Now,
+		entries:    make(map[cacheKey]*cacheEntry),
+		order:      list.New(),
+	}
+}
+
+// get returns the live value of key. It also returns the current generation, to pass to put after a miss.
+func (c *cache) get(key cacheKey) (interface{}, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if ok && !c.now().Before(entry.expires) {
+		c.remove(entry)
+		c.stats.Expirations++
+		ok = false
+	}
+	if !ok {
+		c.stats.Misses++
+		return nil, c.generation, false
+	}
+	c.stats.Hits++
+	c.order.MoveToFront(entry.element)
+	return entry.value, c.generation, true
+}
+
+// put stores value under key, unless the cache was invalidated since generation was read.
+func (c *cache) put(key cacheKey, value interface{}, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return
+	}
+	if entry, ok := c.entries[key]; ok {
+		c.remove(entry)
+	}
+	entry := &cacheEntry{key: key, value: value, expires: c.now().Add(c.ttl)}
+	entry.element = c.order.PushFront(entry)
+	c.entries[key] = entry
+	for c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back().Value.(*cacheEntry))
+		c.stats.Evictions++
+	}
+}
+
+// invalidateEntity drops the reads a write of the entity id of the pair (code, codeIn) may change: the entity
+// itself, the reads of the pair, and any read of another pair holding the entity, which a Save moving it
+// between pairs or a Delete changes. An empty code skips the pair, for a Delete of an entity that was not cached.
+func (c *cache) invalidateEntity(id string, code string, codeIn string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.stats.Invalidations++
+	if entry, ok := c.entries[cacheKey{kind: byID, id: id}]; ok {
+		cached := entry.value.(*entity.CurrencyInfo)
+		c.removePair(cached.Code, cached.CodeIn)
+		c.remove(entry)
+	}
+	if code != "" {
+		c.removePair(code, codeIn)
+	}
+	for _, entry := range c.entries {
+		if entry.key.kind != byID && holds(entry.value, id) {
+			c.remove(entry)
+		}
+	}
+}
+
+// invalidateAll drops every entry.
+func (c *cache) invalidateAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.stats.Invalidations++
+	c.entries = make(map[cacheKey]*cacheEntry)
+	c.order.Init()
+}
+
+// countSharedLoad records a miss answered by the load of another miss.
+func (c *cache) countSharedLoad() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.SharedLoads++
+}
+
+// snapshot returns the statistics of the cache.
+func (c *cache) snapshot() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Size = len(c.entries)
+	return stats
+}
+
+// removePair drops the reads of the pair. The caller holds c.mu.
+func (c *cache) removePair(code string, codeIn string) {
+	for _, kind := range []keyKind{byPair, latestByPair} {
+		if entry, ok := c.entries[cacheKey{kind: kind, code: code, codeIn: codeIn}]; ok {
+			c.remove(entry)
+		}
+	}
+}
+
+// remove drops entry. The caller holds c.mu.
+func (c *cache) remove(entry *cacheEntry) {
+	delete(c.entries, entry.key)
+	c.order.Remove(entry.element)
+}
+
+// holds reports whether a cached value contains the entity id.
+func holds(value interface{}, id string) bool {
+	switch v := value.(type) {
+	case *entity.CurrencyInfo:
+		return v.GetEntityID() == id
+	case []*entity.CurrencyInfo:
+		for _, currencyInfo := range v {
+			if currencyInfo.GetEntityID() == id {
+				return true
+			}
+		}
+	}
+	return false
+}
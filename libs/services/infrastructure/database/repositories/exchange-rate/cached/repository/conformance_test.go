@@ -0,0 +1,25 @@
+package cachedrepository
+
+import (
+	"testing"
+
+	"libs/resources/database/in-memory/go-doc-db-client/client"
+	"libs/resources/database/in-memory/go-doc-db/database"
+	entity "libs/services/entities/exchange-rate/entity"
+	"libs/services/entities/exchange-rate/entity/repositorytest"
+	godocdbrepository "libs/services/infrastructure/database/repositories/exchange-rate/in-memory/go-doc-db/repository"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestCachedExchangeRateRepositoryConformance(t *testing.T) {
+	suite.Run(t, &repositorytest.ExchangeRateRepositorySuite{
+		NewRepository: func() entity.ExchangeRateRepositoryInterface {
+			inner := godocdbrepository.NewExchangeRateRepository("test", client.NewClient(database.NewInMemoryDocBD("test")))
+			repository, err := Wrap(inner, DefaultOptions())
+			require.NoError(t, err)
+			return repository
+		},
+	})
+}
@@ -0,0 +1,324 @@
+package cachedrepository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	entity "libs/services/entities/exchange-rate/entity"
+	"log"
+	"sync"
+	"time"
+)
+
+// ErrInvalidOptions is returned by the constructors when Options.Validate fails.
+var ErrInvalidOptions = errors.New("invalid cache options")
+
+const (
+	// DefaultTTL is how long DefaultOptions keeps a read.
+	DefaultTTL = 30 * time.Second
+	// DefaultMaxEntries is the number of reads DefaultOptions keeps.
+	DefaultMaxEntries = 1024
+)
+
+// Options configures the cache of an ExchangeRateRepository.
+type Options struct {
+	// TTL is how long a read is served from the cache.
+	TTL time.Duration
+	// MaxEntries bounds the number of cached reads. The least recently used one is evicted first.
+	MaxEntries int
+}
+
+// DefaultOptions returns the options used when nothing else is configured.
+func DefaultOptions() Options {
+	return Options{
+		TTL:        DefaultTTL,
+		MaxEntries: DefaultMaxEntries,
+	}
+}
+
+// Validate checks that the TTL and the size of the cache are positive.
+func (o Options) Validate() error {
+	if o.TTL <= 0 {
+		return fmt.Errorf("%w: TTL must be positive, got %s", ErrInvalidOptions, o.TTL)
+	}
+	if o.MaxEntries <= 0 {
+		return fmt.Errorf("%w: MaxEntries must be positive, got %d", ErrInvalidOptions, o.MaxEntries)
+	}
+	return nil
+}
+
+// CacheStats reports the activity of the cache of an ExchangeRateRepository.
+type CacheStats struct {
+	// Hits counts reads served from the cache.
+	Hits uint64
+	// Misses counts reads that went to the wrapped repository.
+	Misses uint64
+	// SharedLoads counts misses answered by the load of a concurrent miss of the same read.
+	SharedLoads uint64
+	// Expirations counts reads dropped because their TTL elapsed.
+	Expirations uint64
+	// Evictions counts reads dropped to keep the cache within MaxEntries.
+	Evictions uint64
+	// Invalidations counts the writes that dropped cached reads.
+	Invalidations uint64
+	// Size is the number of reads currently cached.
+	Size int
+}
+
+// ExchangeRateRepository wraps an exchange rate repository with a read-through cache.
+// FindByID is cached by ID, Find and FindLatest by (code, codeIn); the other reads go to the wrapped repository.
+// Save and Delete go through to the wrapped repository and then drop the cached reads they may change.
+type ExchangeRateRepository struct {
+	repository entity.ExchangeRateRepositoryInterface
+	cache      *cache
+	flights    flightGroup
+}
+
+// NewExchangeRateRepository creates and returns a new ExchangeRateRepository caching the reads of repository.
+func NewExchangeRateRepository(
+	repository entity.ExchangeRateRepositoryInterface,
+	options Options,
+) (*ExchangeRateRepository, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+	return &ExchangeRateRepository{
+		repository: repository,
+		cache:      newCache(options.TTL, options.MaxEntries),
+	}, nil
+}
+
+// TransactionalRepository is a repository that also implements entity.UnitOfWork.
+type TransactionalRepository interface {
+	entity.ExchangeRateRepositoryInterface
+	entity.UnitOfWork
+}
+
+// TransactionalExchangeRateRepository is an ExchangeRateRepository over a repository implementing entity.UnitOfWork.
+// It implements entity.UnitOfWork too.
+type TransactionalExchangeRateRepository struct {
+	*ExchangeRateRepository
+	unitOfWork entity.UnitOfWork
+}
+
+// NewTransactionalExchangeRateRepository creates and returns a new TransactionalExchangeRateRepository caching the reads of repository.
+func NewTransactionalExchangeRateRepository(
+	repository TransactionalRepository,
+	options Options,
+) (*TransactionalExchangeRateRepository, error) {
+	cached, err := NewExchangeRateRepository(repository, options)
+	if err != nil {
+		return nil, err
+	}
+	return &TransactionalExchangeRateRepository{
+		ExchangeRateRepository: cached,
+		unitOfWork:             repository,
+	}, nil
+}
+
+// Wrap caches the reads of repository. The result implements entity.UnitOfWork when repository does.
+func Wrap(repository entity.ExchangeRateRepositoryInterface, options Options) (entity.ExchangeRateRepositoryInterface, error) {
+	if transactional, ok := repository.(TransactionalRepository); ok {
+		return NewTransactionalExchangeRateRepository(transactional, options)
+	}
+	return NewExchangeRateRepository(repository, options)
+}
+
+// Stats returns the statistics of the cache.
+func (r *ExchangeRateRepository) Stats() CacheStats {
+	return r.cache.snapshot()
+}
+
+// Invalidate drops every cached read.
+func (r *ExchangeRateRepository) Invalidate() {
+	r.cache.invalidateAll()
+}
+
+// Save saves the entity in the wrapped repository and drops the cached reads of its ID and pair.
+func (r *ExchangeRateRepository) Save(ctx context.Context, currencyInfo *entity.CurrencyInfo) error {
+	err := r.repository.Save(ctx, currencyInfo)
+	// A failed save may still have reached the storage, so the reads are dropped either way.
+	r.invalidate(ctx, currencyInfo.GetEntityID(), currencyInfo.Code, currencyInfo.CodeIn)
+	return err
+}
+
+// Insert inserts the entity in the wrapped repository unless it is stored, dropping the cached reads of its ID and
+// pair when it may have been inserted.
+func (r *ExchangeRateRepository) Insert(ctx context.Context, currencyInfo *entity.CurrencyInfo) (bool, error) {
+	inserted, err := r.repository.Insert(ctx, currencyInfo)
+	if inserted || err != nil {
+		r.invalidate(ctx, currencyInfo.GetEntityID(), currencyInfo.Code, currencyInfo.CodeIn)
+	}
+	return inserted, err
+}
+
+// Delete deletes the entity from the wrapped repository and drops the cached reads holding it.
+func (r *ExchangeRateRepository) Delete(ctx context.Context, id string) error {
+	err := r.repository.Delete(ctx, id)
+	r.invalidate(ctx, id, "", "")
+	return err
+}
+
+// FindByID returns the entity with the given ID, from the cache when it holds a live copy.
+func (r *ExchangeRateRepository) FindByID(ctx context.Context, id string) (*entity.CurrencyInfo, error) {
+	value, err := r.read(ctx, "find by id", cacheKey{kind: byID, id: id}, func(ctx context.Context) (interface{}, error) {
+		return r.repository.FindByID(ctx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return clone(value.(*entity.CurrencyInfo)), nil
+}
+
+// Find returns the entities of the pair, from the cache when it holds a live copy.
+func (r *ExchangeRateRepository) Find(ctx context.Context, code string, codeIn string) ([]*entity.CurrencyInfo, error) {
+	value, err := r.read(ctx, "find", cacheKey{kind: byPair, code: code, codeIn: codeIn}, func(ctx context.Context) (interface{}, error) {
+		return r.repository.Find(ctx, code, codeIn)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cloneAll(value.([]*entity.CurrencyInfo)), nil
+}
+
+// FindLatest returns the most recent entity of the pair, from the cache when it holds a live copy.
+func (r *ExchangeRateRepository) FindLatest(ctx context.Context, code string, codeIn string) (*entity.CurrencyInfo, error) {
+	value, err := r.read(ctx, "find latest", cacheKey{kind: latestByPair, code: code, codeIn: codeIn}, func(ctx context.Context) (interface{}, error) {
+		return r.repository.FindLatest(ctx, code, codeIn)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return clone(value.(*entity.CurrencyInfo)), nil
+}
+
+// FindAll returns every entity of the wrapped repository. It is not cached.
+func (r *ExchangeRateRepository) FindAll(ctx context.Context) ([]*entity.CurrencyInfo, error) {
+	return r.repository.FindAll(ctx)
+}
+
+// FindRange returns a page of the history of the pair from the wrapped repository. It is not cached.
+func (r *ExchangeRateRepository) FindRange(ctx context.Context, code string, codeIn string, from time.Time, to time.Time, page entity.Page) ([]*entity.CurrencyInfo, error) {
+	return r.repository.FindRange(ctx, code, codeIn, from, to, page)
+}
+
+// CountByPair counts the entities of the pair in the wrapped repository. It is not cached.
+func (r *ExchangeRateRepository) CountByPair(ctx context.Context, code string, codeIn string) (int, error) {
+	return r.repository.CountByPair(ctx, code, codeIn)
+}
+
+// FindCandles returns the candles of the pair. It uses the wrapped repository when it implements entity.CandleRepository,
+// and aggregates the result of its FindRange otherwise. It is not cached.
+func (r *ExchangeRateRepository) FindCandles(ctx context.Context, code string, codeIn string, interval entity.CandleInterval, from time.Time, to time.Time) ([]entity.Candle, error) {
+	if candleRepository, ok := r.repository.(entity.CandleRepository); ok {
+		return candleRepository.FindCandles(ctx, code, codeIn, interval, from, to)
+	}
+	if !interval.Valid() {
+		return nil, fmt.Errorf("%w: %s", entity.ErrInvalidCandleInterval, interval)
+	}
+	quotes, err := r.repository.FindRange(ctx, code, codeIn, from, to, entity.Page{})
+	if err != nil {
+		return nil, err
+	}
+	return entity.AggregateCandles(quotes, interval), nil
+}
+
+// read returns the cached value of key, or loads it once for all concurrent misses and caches it.
+// Inside a unit of work the cache is bypassed, so the reads see the writes of the unit of work.
+func (r *ExchangeRateRepository) read(ctx context.Context, operation string, key cacheKey, load func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	if r.writesFromContext(ctx) != nil {
+		return load(ctx)
+	}
+	value, generation, ok := r.cache.get(key)
+	if ok {
+		return value, nil
+	}
+	value, err, shared := r.flights.do(ctx, flightKey{key: key, generation: generation}, func() (interface{}, error) {
+		value, err := load(ctx)
+		if err == nil {
+			r.cache.put(key, value, generation)
+		}
+		return value, err
+	})
+	if !shared {
+		return value, err
+	}
+	r.cache.countSharedLoad()
+	if err != nil && ctx.Err() != nil {
+		return nil, entity.MapDeadlineError(operation, ctx.Err())
+	}
+	// The deadline of the caller that ran the load is not ours: load again with our own context.
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		log.Printf("Shared exchange rate load failed with the context of another caller, retrying: %v", err)
+		return load(ctx)
+	}
+	return value, err
+}
+
+// unitOfWorkKey is the context key of the writes of the unit of work of a repository.
+type unitOfWorkKey struct {
+	repository *ExchangeRateRepository
+}
+
+// writeSet records the entities written in a unit of work, to drop their reads again once it is over.
+type writeSet struct {
+	mu     sync.Mutex
+	writes []write
+}
+
+// write is an entity written in a unit of work.
+type write struct {
+	id     string
+	code   string
+	codeIn string
+}
+
+// writesFromContext returns the writes of the unit of work carried by ctx, if any.
+func (r *ExchangeRateRepository) writesFromContext(ctx context.Context) *writeSet {
+	writes, _ := ctx.Value(unitOfWorkKey{r}).(*writeSet)
+	return writes
+}
+
+// invalidate drops the cached reads an entity write may change. Inside a unit of work the write is also recorded:
+// readers outside it may cache the old value again until it commits.
+func (r *ExchangeRateRepository) invalidate(ctx context.Context, id string, code string, codeIn string) {
+	r.cache.invalidateEntity(id, code, codeIn)
+	if writes := r.writesFromContext(ctx); writes != nil {
+		writes.mu.Lock()
+		writes.writes = append(writes.writes, write{id: id, code: code, codeIn: codeIn})
+		writes.mu.Unlock()
+	}
+}
+
+// Do runs fn as a unit of work of the wrapped repository. Reads made with the context passed to fn bypass the cache,
+// and the reads of the entities written are dropped again once the unit of work is over.
+func (r *TransactionalExchangeRateRepository) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if r.writesFromContext(ctx) != nil {
+		return r.unitOfWork.Do(ctx, fn)
+	}
+	writes := &writeSet{}
+	err := r.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		return fn(context.WithValue(ctx, unitOfWorkKey{r.ExchangeRateRepository}, writes))
+	})
+	writes.mu.Lock()
+	defer writes.mu.Unlock()
+	for _, w := range writes.writes {
+		r.cache.invalidateEntity(w.id, w.code, w.codeIn)
+	}
+	return err
+}
+
+// clone returns a copy of the entity, so callers cannot change the cached one.
+func clone(currencyInfo *entity.CurrencyInfo) *entity.CurrencyInfo {
+	copied := *currencyInfo
+	return &copied
+}
+
+// cloneAll returns copies of the entities.
+func cloneAll(currencyInfos []*entity.CurrencyInfo) []*entity.CurrencyInfo {
+	copied := make([]*entity.CurrencyInfo, len(currencyInfos))
+	for i, currencyInfo := range currencyInfos {
+		copied[i] = clone(currencyInfo)
+	}
+	return copied
+}
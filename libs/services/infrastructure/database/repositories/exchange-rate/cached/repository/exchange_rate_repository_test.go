@@ -0,0 +1,476 @@
+package cachedrepository
+
+import (
+	"context"
+	"errors"
+	entity "libs/services/entities/exchange-rate/entity"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// countingRepository is an in-memory repository counting the reads that reach it.
+// When gate is set, FindByID reads the entity, then waits for gate to be closed before returning it.
+type countingRepository struct {
+	mu       sync.Mutex
+	rates    map[string]entity.CurrencyInfo
+	findByID atomic.Int64
+	find     atomic.Int64
+	latest   atomic.Int64
+	gate     chan struct{}
+	loading  chan struct{}
+}
+
+func newCountingRepository() *countingRepository {
+	return &countingRepository{rates: make(map[string]entity.CurrencyInfo)}
+}
+
+func (r *countingRepository) Save(ctx context.Context, currencyInfo *entity.CurrencyInfo) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rates[currencyInfo.GetEntityID()] = *currencyInfo
+	return nil
+}
+
+func (r *countingRepository) Insert(ctx context.Context, currencyInfo *entity.CurrencyInfo) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.rates[currencyInfo.GetEntityID()]; ok {
+		return false, nil
+	}
+	r.rates[currencyInfo.GetEntityID()] = *currencyInfo
+	return true, nil
+}
+
+func (r *countingRepository) FindAll(ctx context.Context) ([]*entity.CurrencyInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := make([]*entity.CurrencyInfo, 0, len(r.rates))
+	for _, rate := range r.rates {
+		rate := rate
+		result = append(result, &rate)
+	}
+	return result, nil
+}
+
+func (r *countingRepository) Find(ctx context.Context, code string, codeIn string) ([]*entity.CurrencyInfo, error) {
+	r.find.Add(1)
+	all, _ := r.FindAll(ctx)
+	result := make([]*entity.CurrencyInfo, 0)
+	for _, rate := range all {
+		if rate.Code == code && rate.CodeIn == codeIn {
+			result = append(result, rate)
+		}
+	}
+	return result, nil
+}
+
+func (r *countingRepository) FindByID(ctx context.Context, id string) (*entity.CurrencyInfo, error) {
+	r.findByID.Add(1)
+	r.mu.Lock()
+	rate, ok := r.rates[id]
+	r.mu.Unlock()
+	if r.loading != nil {
+		r.loading <- struct{}{}
+	}
+	if r.gate != nil {
+		select {
+		case <-r.gate:
+		case <-ctx.Done():
+			return nil, entity.MapDeadlineError("find by id", ctx.Err())
+		}
+	}
+	if !ok {
+		return nil, entity.ErrExchangeRateNotFound
+	}
+	return &rate, nil
+}
+
+func (r *countingRepository) FindLatest(ctx context.Context, code string, codeIn string) (*entity.CurrencyInfo, error) {
+	r.latest.Add(1)
+	rates, _ := r.Find(ctx, code, codeIn)
+	r.find.Add(-1)
+	var latest *entity.CurrencyInfo
+	for _, rate := range rates {
+		if latest == nil || rate.Timestamp > latest.Timestamp {
+			latest = rate
+		}
+	}
+	if latest == nil {
+		return nil, entity.ErrExchangeRateNotFound
+	}
+	return latest, nil
+}
+
+func (r *countingRepository) FindRange(ctx context.Context, code string, codeIn string, from time.Time, to time.Time, page entity.Page) ([]*entity.CurrencyInfo, error) {
+	return r.Find(ctx, code, codeIn)
+}
+
+func (r *countingRepository) CountByPair(ctx context.Context, code string, codeIn string) (int, error) {
+	rates, err := r.Find(ctx, code, codeIn)
+	return len(rates), err
+}
+
+func (r *countingRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.rates[id]; !ok {
+		return entity.ErrExchangeRateNotFound
+	}
+	delete(r.rates, id)
+	return nil
+}
+
+type CachedExchangeRateRepositoryTestSuite struct {
+	suite.Suite
+	inner      *countingRepository
+	repository *ExchangeRateRepository
+	now        time.Time
+}
+
+func TestCachedExchangeRateRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(CachedExchangeRateRepositoryTestSuite))
+}
+
+func (suite *CachedExchangeRateRepositoryTestSuite) SetupTest() {
+	suite.inner = newCountingRepository()
+	repository, err := NewExchangeRateRepository(suite.inner, Options{TTL: time.Minute, MaxEntries: 3})
+	suite.Require().NoError(err)
+	suite.now = time.Unix(1626889200, 0)
+	repository.cache.now = func() time.Time { return suite.now }
+	suite.repository = repository
+}
+
+// save stores a quote of the pair through the cache and returns it.
+func (suite *CachedExchangeRateRepositoryTestSuite) save(code, codeIn, bid, timestamp string) *entity.CurrencyInfo {
+	currencyInfo, err := entity.NewExchangeRate(code, codeIn, code+"/"+codeIn, bid, bid, "0.05", "0.01", bid, bid, timestamp, "2021-07-21 00:00:00")
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.repository.Save(context.Background(), currencyInfo))
+	return currencyInfo
+}
+
+func (suite *CachedExchangeRateRepositoryTestSuite) TestInvalidOptions() {
+	for _, options := range []Options{{TTL: 0, MaxEntries: 1}, {TTL: time.Second, MaxEntries: 0}} {
+		_, err := NewExchangeRateRepository(suite.inner, options)
+		assert.True(suite.T(), errors.Is(err, ErrInvalidOptions), "unexpected error: %v", err)
+	}
+}
+
+func (suite *CachedExchangeRateRepositoryTestSuite) TestReadsAreCached() {
+	ctx := context.Background()
+	rate := suite.save("USD", "BRL", "5.45", "1626889200")
+
+	for i := 0; i < 3; i++ {
+		found, err := suite.repository.FindByID(ctx, rate.GetEntityID())
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), rate, found)
+		rates, err := suite.repository.Find(ctx, "USD", "BRL")
+		assert.NoError(suite.T(), err)
+		assert.Len(suite.T(), rates, 1)
+		latest, err := suite.repository.FindLatest(ctx, "USD", "BRL")
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), rate, latest)
+	}
+
+	assert.Equal(suite.T(), int64(1), suite.inner.findByID.Load())
+	assert.Equal(suite.T(), int64(1), suite.inner.find.Load())
+	assert.Equal(suite.T(), int64(1), suite.inner.latest.Load())
+	stats := suite.repository.Stats()
+	assert.Equal(suite.T(), uint64(6), stats.Hits)
+	assert.Equal(suite.T(), uint64(3), stats.Misses)
+	assert.Equal(suite.T(), 3, stats.Size)
+}
+
+func (suite *CachedExchangeRateRepositoryTestSuite) TestCallersCannotChangeCachedEntities() {
+	ctx := context.Background()
+	rate := suite.save("USD", "BRL", "5.45", "1626889200")
+
+	found, _ := suite.repository.FindByID(ctx, rate.GetEntityID())
+	found.Bid = entity.DecimalFromInt(1)
+	rates, _ := suite.repository.Find(ctx, "USD", "BRL")
+	rates[0].Bid = entity.DecimalFromInt(1)
+
+	found, _ = suite.repository.FindByID(ctx, rate.GetEntityID())
+	rates, _ = suite.repository.Find(ctx, "USD", "BRL")
+	assert.Equal(suite.T(), entity.MustParseDecimal("5.45"), found.Bid)
+	assert.Equal(suite.T(), entity.MustParseDecimal("5.45"), rates[0].Bid)
+}
+
+func (suite *CachedExchangeRateRepositoryTestSuite) TestEntriesExpire() {
+	ctx := context.Background()
+	rate := suite.save("USD", "BRL", "5.45", "1626889200")
+	_, _ = suite.repository.FindByID(ctx, rate.GetEntityID())
+
+	suite.now = suite.now.Add(59 * time.Second)
+	_, _ = suite.repository.FindByID(ctx, rate.GetEntityID())
+	assert.Equal(suite.T(), int64(1), suite.inner.findByID.Load())
+
+	suite.now = suite.now.Add(time.Second)
+	_, _ = suite.repository.FindByID(ctx, rate.GetEntityID())
+	assert.Equal(suite.T(), int64(2), suite.inner.findByID.Load())
+	assert.Equal(suite.T(), uint64(1), suite.repository.Stats().Expirations)
+}
+
+func (suite *CachedExchangeRateRepositoryTestSuite) TestSizeIsBounded() {
+	ctx := context.Background()
+	rates := []*entity.CurrencyInfo{
+		suite.save("USD", "BRL", "5.45", "1626889200"),
+		suite.save("EUR", "BRL", "6.45", "1626889200"),
+		suite.save("GBP", "BRL", "7.45", "1626889200"),
+		suite.save("JPY", "BRL", "0.05", "1626889200"),
+	}
+	for _, rate := range rates {
+		_, _ = suite.repository.FindByID(ctx, rate.GetEntityID())
+	}
+
+	stats := suite.repository.Stats()
+	assert.Equal(suite.T(), 3, stats.Size)
+	assert.Equal(suite.T(), uint64(1), stats.Evictions)
+
+	// The least recently used entry, the first one, was evicted.
+	_, _ = suite.repository.FindByID(ctx, rates[3].GetEntityID())
+	assert.Equal(suite.T(), int64(4), suite.inner.findByID.Load())
+	_, _ = suite.repository.FindByID(ctx, rates[0].GetEntityID())
+	assert.Equal(suite.T(), int64(5), suite.inner.findByID.Load())
+}
+
+func (suite *CachedExchangeRateRepositoryTestSuite) TestSaveInvalidates() {
+	ctx := context.Background()
+	rate := suite.save("USD", "BRL", "5.45", "1626889200")
+	_, _ = suite.repository.FindByID(ctx, rate.GetEntityID())
+	_, _ = suite.repository.Find(ctx, "USD", "BRL")
+	_, _ = suite.repository.FindLatest(ctx, "USD", "BRL")
+
+	newer := suite.save("USD", "BRL", "5.55", "1626889260")
+
+	assert.Equal(suite.T(), 1, suite.repository.Stats().Size, "only the read of the other ID is kept")
+	rates, err := suite.repository.Find(ctx, "USD", "BRL")
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), rates, 2)
+	latest, err := suite.repository.FindLatest(ctx, "USD", "BRL")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), newer, latest)
+}
+
+func (suite *CachedExchangeRateRepositoryTestSuite) TestInsertInvalidates() {
+	ctx := context.Background()
+	rate := suite.save("USD", "BRL", "5.45", "1626889200")
+	_, _ = suite.repository.Find(ctx, "USD", "BRL")
+	newer := *rate
+	newer.ID = "newer"
+
+	inserted, err := suite.repository.Insert(ctx, &newer)
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), inserted)
+	inserted, err = suite.repository.Insert(ctx, &newer)
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), inserted)
+
+	rates, err := suite.repository.Find(ctx, "USD", "BRL")
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), rates, 2)
+}
+
+func (suite *CachedExchangeRateRepositoryTestSuite) TestSaveMovingPairsInvalidatesBothPairs() {
+	ctx := context.Background()
+	rate := suite.save("USD", "BRL", "5.45", "1626889200")
+	_, _ = suite.repository.Find(ctx, "USD", "BRL")
+
+	moved := *rate
+	moved.Code = "EUR"
+	assert.NoError(suite.T(), suite.repository.Save(ctx, &moved))
+
+	rates, err := suite.repository.Find(ctx, "USD", "BRL")
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), rates)
+}
+
+func (suite *CachedExchangeRateRepositoryTestSuite) TestDeleteInvalidates() {
+	ctx := context.Background()
+	rate := suite.save("USD", "BRL", "5.45", "1626889200")
+	_, _ = suite.repository.FindByID(ctx, rate.GetEntityID())
+	_, _ = suite.repository.Find(ctx, "USD", "BRL")
+	_, _ = suite.repository.FindLatest(ctx, "USD", "BRL")
+
+	assert.NoError(suite.T(), suite.repository.Delete(ctx, rate.GetEntityID()))
+
+	_, err := suite.repository.FindByID(ctx, rate.GetEntityID())
+	assert.ErrorIs(suite.T(), err, entity.ErrExchangeRateNotFound)
+	_, err = suite.repository.FindLatest(ctx, "USD", "BRL")
+	assert.ErrorIs(suite.T(), err, entity.ErrExchangeRateNotFound)
+	rates, _ := suite.repository.Find(ctx, "USD", "BRL")
+	assert.Empty(suite.T(), rates)
+}
+
+func (suite *CachedExchangeRateRepositoryTestSuite) TestErrorsAreNotCached() {
+	ctx := context.Background()
+	_, err := suite.repository.FindByID(ctx, "missing")
+	assert.ErrorIs(suite.T(), err, entity.ErrExchangeRateNotFound)
+	_, err = suite.repository.FindByID(ctx, "missing")
+	assert.ErrorIs(suite.T(), err, entity.ErrExchangeRateNotFound)
+
+	assert.Equal(suite.T(), int64(2), suite.inner.findByID.Load())
+	assert.Equal(suite.T(), 0, suite.repository.Stats().Size)
+}
+
+func (suite *CachedExchangeRateRepositoryTestSuite) TestConcurrentMissesShareOneLoad() {
+	ctx := context.Background()
+	rate := suite.save("USD", "BRL", "5.45", "1626889200")
+	suite.inner.gate = make(chan struct{})
+	suite.inner.loading = make(chan struct{}, 1)
+
+	var wg sync.WaitGroup
+	results := make([]*entity.CurrencyInfo, 8)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		results[0], _ = suite.repository.FindByID(ctx, rate.GetEntityID())
+	}()
+	<-suite.inner.loading
+	for i := 1; i < len(results); i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = suite.repository.FindByID(ctx, rate.GetEntityID())
+		}(i)
+	}
+	assert.Eventually(suite.T(), func() bool {
+		suite.repository.flights.mu.Lock()
+		defer suite.repository.flights.mu.Unlock()
+		for _, f := range suite.repository.flights.flights {
+			return f.dups == len(results)-1
+		}
+		return false
+	}, time.Second, time.Millisecond)
+	close(suite.inner.gate)
+	wg.Wait()
+
+	assert.Equal(suite.T(), int64(1), suite.inner.findByID.Load())
+	assert.Equal(suite.T(), uint64(len(results)-1), suite.repository.Stats().SharedLoads)
+	for _, result := range results {
+		assert.Equal(suite.T(), rate, result)
+	}
+}
+
+func (suite *CachedExchangeRateRepositoryTestSuite) TestLoadRacingSaveIsNotCached() {
+	ctx := context.Background()
+	rate := suite.save("USD", "BRL", "5.45", "1626889200")
+	suite.inner.gate = make(chan struct{})
+	suite.inner.loading = make(chan struct{}, 1)
+
+	done := make(chan *entity.CurrencyInfo)
+	go func() {
+		found, _ := suite.repository.FindByID(ctx, rate.GetEntityID())
+		done <- found
+	}()
+	<-suite.inner.loading
+	// The load has read the old entity but not returned it yet when the save lands.
+	updated := *rate
+	updated.Bid = entity.MustParseDecimal("5.55")
+	assert.NoError(suite.T(), suite.repository.Save(ctx, &updated))
+	close(suite.inner.gate)
+	<-done
+	suite.inner.loading = nil
+
+	found, err := suite.repository.FindByID(ctx, rate.GetEntityID())
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), entity.MustParseDecimal("5.55"), found.Bid)
+	assert.Equal(suite.T(), int64(2), suite.inner.findByID.Load(), "the racing load must not have been cached")
+}
+
+func (suite *CachedExchangeRateRepositoryTestSuite) TestWaitingCallerKeepsItsOwnDeadline() {
+	rate := suite.save("USD", "BRL", "5.45", "1626889200")
+	suite.inner.gate = make(chan struct{})
+	suite.inner.loading = make(chan struct{}, 1)
+	defer close(suite.inner.gate)
+
+	go func() {
+		_, _ = suite.repository.FindByID(context.Background(), rate.GetEntityID())
+	}()
+	<-suite.inner.loading
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := suite.repository.FindByID(ctx, rate.GetEntityID())
+
+	var timeoutErr *entity.RepositoryTimeoutError
+	assert.True(suite.T(), errors.As(err, &timeoutErr), "unexpected error: %v", err)
+}
+
+func (suite *CachedExchangeRateRepositoryTestSuite) TestFindCandlesAggregatesFindRange() {
+	suite.save("USD", "BRL", "5.40", "1626889200")
+	suite.save("USD", "BRL", "5.60", "1626889230")
+
+	candles, err := suite.repository.FindCandles(context.Background(), "USD", "BRL", entity.CandleInterval1m, time.Time{}, time.Time{})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []entity.Candle{{Start: 1626889200, Open: entity.MustParseDecimal("5.40"), High: entity.MustParseDecimal("5.60"), Low: entity.MustParseDecimal("5.40"), Close: entity.MustParseDecimal("5.60"), Count: 2}}, candles)
+}
+
+func TestWrapKeepsUnitOfWork(t *testing.T) {
+	plain, err := Wrap(newCountingRepository(), DefaultOptions())
+	assert.NoError(t, err)
+	_, ok := plain.(entity.UnitOfWork)
+	assert.False(t, ok)
+
+	transactional, err := Wrap(&transactionalRepository{countingRepository: newCountingRepository()}, DefaultOptions())
+	assert.NoError(t, err)
+	_, ok = transactional.(entity.UnitOfWork)
+	assert.True(t, ok)
+}
+
+// transactionalRepository adds a Do that discards the writes of a failed unit of work to countingRepository.
+type transactionalRepository struct {
+	*countingRepository
+}
+
+func (r *transactionalRepository) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	r.mu.Lock()
+	saved := make(map[string]entity.CurrencyInfo, len(r.rates))
+	for id, rate := range r.rates {
+		saved[id] = rate
+	}
+	r.mu.Unlock()
+	err := fn(ctx)
+	if err != nil {
+		r.mu.Lock()
+		r.rates = saved
+		r.mu.Unlock()
+	}
+	return err
+}
+
+func TestUnitOfWorkBypassesAndInvalidatesTheCache(t *testing.T) {
+	ctx := context.Background()
+	inner := &transactionalRepository{countingRepository: newCountingRepository()}
+	repository, err := NewTransactionalExchangeRateRepository(inner, DefaultOptions())
+	assert.NoError(t, err)
+	rate, err := entity.NewExchangeRate("USD", "BRL", "USD/BRL", "5.5", "5.4", "0.05", "0.01", "5.45", "5.46", "1626889200", "2021-07-21 00:00:00")
+	assert.NoError(t, err)
+	assert.NoError(t, repository.Save(ctx, rate))
+	_, _ = repository.FindByID(ctx, rate.GetEntityID())
+
+	updated := *rate
+	updated.Bid = entity.MustParseDecimal("5.55")
+	err = repository.Do(ctx, func(ctx context.Context) error {
+		if err := repository.Save(ctx, &updated); err != nil {
+			return err
+		}
+		found, err := repository.FindByID(ctx, rate.GetEntityID())
+		assert.NoError(t, err)
+		assert.Equal(t, entity.MustParseDecimal("5.55"), found.Bid, "reads in the unit of work see its writes")
+		// A reader outside the unit of work caches the value being replaced.
+		_, _ = repository.FindByID(context.Background(), rate.GetEntityID())
+		return errors.New("rollback")
+	})
+	assert.Error(t, err)
+
+	found, err := repository.FindByID(ctx, rate.GetEntityID())
+	assert.NoError(t, err)
+	assert.Equal(t, entity.MustParseDecimal("5.45"), found.Bid)
+	assert.Equal(t, int64(4), inner.findByID.Load(), "the read cached during the unit of work was dropped once it ended")
+}
@@ -0,0 +1,64 @@
+package cachedrepository
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// errLoadPanicked is returned to the callers sharing a load that panicked. The panic itself reaches the caller that ran it.
+var errLoadPanicked = errors.New("cached repository: shared load panicked")
+
+// flightKey identifies a load: concurrent misses of the same read in the same cache generation share it.
+// A load started before an invalidation is not joined by the reads that follow the invalidation.
+type flightKey struct {
+	key        cacheKey
+	generation uint64
+}
+
+// flight is a load in progress. done is closed once value and err are set.
+type flight struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+	// dups counts the callers waiting for the load of another one.
+	dups int
+}
+
+// flightGroup de-duplicates concurrent loads of the same key, like golang.org/x/sync/singleflight.
+type flightGroup struct {
+	mu      sync.Mutex
+	flights map[flightKey]*flight
+}
+
+// do runs load once for concurrent callers of the same key and returns its result to all of them.
+// shared reports whether the result came from the load of another caller. A caller waiting for the load
+// of another one stops waiting when its own ctx is done and returns ctx.Err().
+func (g *flightGroup) do(ctx context.Context, key flightKey, load func() (interface{}, error)) (value interface{}, err error, shared bool) {
+	g.mu.Lock()
+	if g.flights == nil {
+		g.flights = make(map[flightKey]*flight)
+	}
+	if f, ok := g.flights[key]; ok {
+		f.dups++
+		g.mu.Unlock()
+		select {
+		case <-f.done:
+			return f.value, f.err, true
+		case <-ctx.Done():
+			return nil, ctx.Err(), true
+		}
+	}
+	f := &flight{done: make(chan struct{}), err: errLoadPanicked}
+	g.flights[key] = f
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.flights, key)
+		g.mu.Unlock()
+		close(f.done)
+	}()
+	f.value, f.err = load()
+	return f.value, f.err, false
+}
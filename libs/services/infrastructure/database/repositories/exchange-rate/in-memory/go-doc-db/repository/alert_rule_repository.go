@@ -0,0 +1,143 @@
+package godocdbrepository
+
+import (
+	"context"
+	"errors"
+	"libs/resources/database/in-memory/go-doc-db-client/client"
+	"libs/resources/database/in-memory/go-doc-db/database"
+	entity "libs/services/entities/exchange-rate/entity"
+	"log"
+	"slices"
+	"sync"
+)
+
+var (
+	alertRuleCollectionName = "alert-rules"
+)
+
+// AlertRuleRepository stores alert rule entities using the in-memory database client.
+type AlertRuleRepository struct {
+	database       string
+	client         *client.Client
+	collectionName string
+	initOnce       sync.Once
+	initErr        error
+}
+
+// NewAlertRuleRepository creates and returns a new AlertRuleRepository instance.
+func NewAlertRuleRepository(
+	database string,
+	client *client.Client,
+) *AlertRuleRepository {
+	return &AlertRuleRepository{
+		database:       database,
+		client:         client,
+		collectionName: alertRuleCollectionName,
+	}
+}
+
+// init creates the collection the first time the repository is used, unless another repository already did.
+func (r *AlertRuleRepository) init() error {
+	r.initOnce.Do(func() {
+		if slices.Contains(r.client.ListCollections(), r.collectionName) {
+			return
+		}
+		err := r.client.CreateCollection(r.collectionName)
+		if err != nil && !slices.Contains(r.client.ListCollections(), r.collectionName) {
+			log.Printf("Error creating collection: %v", err)
+			r.initErr = err
+		}
+	})
+	return r.initErr
+}
+
+// SaveAlertRule inserts the alert rule into the collection, replacing the rule with the same ID.
+func (r *AlertRuleRepository) SaveAlertRule(ctx context.Context, rule *entity.AlertRule) error {
+	log.Printf("Saving alert rule to collection: %v", r.collectionName)
+	if err := checkContext(ctx, "save alert rule"); err != nil {
+		return err
+	}
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+	if err := r.init(); err != nil {
+		return err
+	}
+	if err := r.client.DeleteOne(r.collectionName, rule.ID); err != nil && !errors.Is(err, database.ErrDocumentNotFound) {
+		log.Printf("Error replacing alert rule: %v", err)
+		return err
+	}
+	if err := r.client.InsertOne(r.collectionName, rule.ToMap()); err != nil {
+		log.Printf("Error saving alert rule: %v", err)
+		return err
+	}
+	return nil
+}
+
+// FindAlertRules retrieves the alert rules of the pair from the collection.
+func (r *AlertRuleRepository) FindAlertRules(ctx context.Context, code string, codeIn string) ([]*entity.AlertRule, error) {
+	log.Printf("Finding alert rules by pair from collection: %v", r.collectionName)
+	if err := checkContext(ctx, "find alert rules"); err != nil {
+		return nil, err
+	}
+	if err := r.init(); err != nil {
+		return nil, err
+	}
+	documents, err := r.client.Find(r.collectionName, pairFilter(code, codeIn))
+	if err != nil {
+		log.Printf("Error finding alert rules: %v", err)
+		return nil, err
+	}
+	return mapAlertRules(documents)
+}
+
+// FindAllAlertRules retrieves all alert rules from the collection.
+func (r *AlertRuleRepository) FindAllAlertRules(ctx context.Context) ([]*entity.AlertRule, error) {
+	log.Printf("Finding all alert rules from collection: %v", r.collectionName)
+	if err := checkContext(ctx, "find all alert rules"); err != nil {
+		return nil, err
+	}
+	if err := r.init(); err != nil {
+		return nil, err
+	}
+	documents, err := r.client.FindAll(r.collectionName)
+	if err != nil {
+		log.Printf("Error finding all alert rules: %v", err)
+		return nil, err
+	}
+	return mapAlertRules(documents)
+}
+
+// DeleteAlertRule removes the alert rule with the ID from the collection.
+// It returns entity.ErrAlertRuleNotFound when the rule does not exist.
+func (r *AlertRuleRepository) DeleteAlertRule(ctx context.Context, id string) error {
+	log.Printf("Deleting alert rule by ID from collection: %v", r.collectionName)
+	if err := checkContext(ctx, "delete alert rule"); err != nil {
+		return err
+	}
+	if err := r.init(); err != nil {
+		return err
+	}
+	err := r.client.DeleteOne(r.collectionName, id)
+	if errors.Is(err, database.ErrDocumentNotFound) {
+		return entity.ErrAlertRuleNotFound
+	}
+	if err != nil {
+		log.Printf("Error deleting alert rule: %v", err)
+	}
+	return err
+}
+
+// mapAlertRules maps documents to alert rule entities.
+func mapAlertRules(documents []map[string]interface{}) ([]*entity.AlertRule, error) {
+	rules := make([]*entity.AlertRule, len(documents))
+	for i, document := range documents {
+		rule, err := entity.MapToAlertRule(document)
+		if err != nil {
+			log.Printf("Error mapping document to alert rule: %v", err)
+			return nil, err
+		}
+		rules[i] = rule
+	}
+	return rules, nil
+}
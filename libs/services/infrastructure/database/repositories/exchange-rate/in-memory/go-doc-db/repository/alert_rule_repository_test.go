@@ -0,0 +1,78 @@
+package godocdbrepository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"libs/resources/database/in-memory/go-doc-db-client/client"
+	"libs/resources/database/in-memory/go-doc-db/database"
+	entity "libs/services/entities/exchange-rate/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type GoDocDBAlertRuleRepositoryTestSuite struct {
+	suite.Suite
+	client     *client.Client
+	repository *AlertRuleRepository
+	above      *entity.AlertRule
+	moves      *entity.AlertRule
+}
+
+func TestGoDocDBAlertRuleRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(GoDocDBAlertRuleRepositoryTestSuite))
+}
+
+func (suite *GoDocDBAlertRuleRepositoryTestSuite) SetupTest() {
+	var err error
+	suite.client = client.NewClient(database.NewInMemoryDocBD("test-database"))
+	suite.repository = NewAlertRuleRepository("test-database", suite.client)
+	suite.above, err = entity.NewThresholdAlertRule("USD", "BRL", entity.AlertAbove, entity.MustParseDecimal("5.5"))
+	suite.Require().NoError(err)
+	suite.moves, err = entity.NewPercentChangeAlertRule("EUR", "BRL", 2, time.Hour)
+	suite.Require().NoError(err)
+}
+
+func (suite *GoDocDBAlertRuleRepositoryTestSuite) TestSaveAndFind() {
+	ctx := context.Background()
+	assert.NoError(suite.T(), suite.repository.SaveAlertRule(ctx, suite.above))
+	assert.NoError(suite.T(), suite.repository.SaveAlertRule(ctx, suite.moves))
+	assert.NoError(suite.T(), suite.repository.SaveAlertRule(ctx, suite.above), "saving a rule again replaces it")
+
+	rules, err := suite.repository.FindAlertRules(ctx, "USD", "BRL")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []*entity.AlertRule{suite.above}, rules)
+
+	all, err := suite.repository.FindAllAlertRules(ctx)
+	assert.NoError(suite.T(), err)
+	assert.ElementsMatch(suite.T(), []*entity.AlertRule{suite.above, suite.moves}, all)
+}
+
+func (suite *GoDocDBAlertRuleRepositoryTestSuite) TestDelete() {
+	ctx := context.Background()
+	assert.NoError(suite.T(), suite.repository.SaveAlertRule(ctx, suite.above))
+
+	assert.NoError(suite.T(), suite.repository.DeleteAlertRule(ctx, suite.above.ID))
+	assert.Equal(suite.T(), entity.ErrAlertRuleNotFound, suite.repository.DeleteAlertRule(ctx, suite.above.ID))
+	rules, err := suite.repository.FindAllAlertRules(ctx)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), rules)
+}
+
+func (suite *GoDocDBAlertRuleRepositoryTestSuite) TestSharesTheCollectionBetweenRepositories() {
+	ctx := context.Background()
+	assert.NoError(suite.T(), suite.repository.SaveAlertRule(ctx, suite.above))
+
+	rules, err := NewAlertRuleRepository("test-database", suite.client).FindAllAlertRules(ctx)
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), rules, 1)
+}
+
+func (suite *GoDocDBAlertRuleRepositoryTestSuite) TestRejectsInvalidRules() {
+	err := suite.repository.SaveAlertRule(context.Background(), &entity.AlertRule{ID: "1", Code: "USD", CodeIn: "BRL", Kind: entity.AlertAbove})
+
+	assert.ErrorIs(suite.T(), err, entity.ErrInvalidAlertRule)
+}
@@ -0,0 +1,21 @@
+package godocdbrepository
+
+import (
+	"testing"
+
+	"libs/resources/database/in-memory/go-doc-db-client/client"
+	"libs/resources/database/in-memory/go-doc-db/database"
+	entity "libs/services/entities/exchange-rate/entity"
+	"libs/services/entities/exchange-rate/entity/repositorytest"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func TestGoDocDBExchangeRateRepositoryConformance(t *testing.T) {
+	suite.Run(t, &repositorytest.ExchangeRateRepositorySuite{
+		NewRepository: func() entity.ExchangeRateRepositoryInterface {
+			db := database.NewInMemoryDocBD("test-database")
+			return NewExchangeRateRepository("test-database", client.NewClient(db))
+		},
+	})
+}
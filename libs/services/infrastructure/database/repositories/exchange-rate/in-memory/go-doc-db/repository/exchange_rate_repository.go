@@ -1,9 +1,16 @@
 package godocdbrepository
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"libs/resources/database/in-memory/go-doc-db-client/client"
+	"libs/resources/database/in-memory/go-doc-db/database"
 	entity "libs/services/entities/exchange-rate/entity"
 	"log"
+	"slices"
+	"sync"
+	"time"
 )
 
 var (
@@ -11,11 +18,13 @@ var (
 )
 
 // ExchangeRateRepository handles the CRUD operations for exchange rate entities using the in-memory database client.
+// It is safe for concurrent use.
 type ExchangeRateRepository struct {
-	database          string
-	client            *client.Client
-	collectionName    string
-	collectionCreated bool
+	database       string
+	client         *client.Client
+	collectionName string
+	initOnce       sync.Once
+	initErr        error
 }
 
 // NewExchangeRateRepository creates and returns a new ExchangeRateRepository instance.
@@ -24,57 +33,69 @@ func NewExchangeRateRepository(
 	client *client.Client,
 ) *ExchangeRateRepository {
 	return &ExchangeRateRepository{
-		database:          database,
-		client:            client,
-		collectionName:    collectionName,
-		collectionCreated: false,
+		database:       database,
+		client:         client,
+		collectionName: collectionName,
 	}
 }
 
-// init initializes the repository by ensuring the collection exists.
-func (r *ExchangeRateRepository) init() {
-	if err := r.createCollectionIfNotExists(); err != nil {
-		log.Fatal(err)
-	}
-}
-
-// createCollectionIfNotExists checks if the collection is already created, if not then creates it.
-func (r *ExchangeRateRepository) createCollectionIfNotExists() error {
-	if !r.collectionCreated {
+// init creates the collection the first time the repository is used, unless another repository already did.
+func (r *ExchangeRateRepository) init() error {
+	r.initOnce.Do(func() {
+		if slices.Contains(r.client.ListCollections(), r.collectionName) {
+			return
+		}
 		err := r.client.CreateCollection(r.collectionName)
-		if err != nil {
+		if err != nil && !slices.Contains(r.client.ListCollections(), r.collectionName) {
 			log.Printf("Error creating collection: %v", err)
-			return err
+			r.initErr = err
 		}
-		r.collectionCreated = true
-		return nil
-	}
-	return nil
+	})
+	return r.initErr
 }
 
-// Save saves the given currency info entity into the collection.
-func (r *ExchangeRateRepository) Save(currencyInfo *entity.CurrencyInfo) error {
+// Save saves the given currency info entity into the collection, unless it is already stored.
+func (r *ExchangeRateRepository) Save(ctx context.Context, currencyInfo *entity.CurrencyInfo) error {
+	_, err := r.Insert(ctx, currencyInfo)
+	return err
+}
+
+// Insert saves the given currency info entity into the collection unless a document with its ID exists,
+// and reports whether it saved it.
+func (r *ExchangeRateRepository) Insert(ctx context.Context, currencyInfo *entity.CurrencyInfo) (bool, error) {
 	log.Printf("Saving exchange rate to collection: %v", r.collectionName)
-	r.init()
-	currencyInfoMap := currencyInfo.ToMap()
-	entityID := currencyInfo.GetEntityID()
-	_, err := r.FindByID(entityID)
-	if err == nil {
-		log.Printf("Exchange rate already exists: %v", entityID)
-		return nil
+	if err := checkContext(ctx, "save"); err != nil {
+		return false, err
 	}
-	err = r.client.InsertOne(r.collectionName, currencyInfoMap)
+	if err := r.init(); err != nil {
+		return false, err
+	}
+	entityID := currencyInfo.GetEntityID()
+	err := r.client.InsertOne(r.collectionName, currencyInfo.ToMap())
 	if err != nil {
+		// The collection checks the ID and inserts under its lock, so a failed insert of a stored ID means it existed.
+		if _, findErr := r.FindByID(ctx, entityID); findErr == nil {
+			log.Printf("Exchange rate already exists: %v", entityID)
+			return false, nil
+		}
 		log.Printf("Error saving exchange rate: %v", err)
-		return err
+		return false, err
 	}
-	return nil
+	if j := journalFromContext(ctx); j != nil {
+		j.inserted(entityID)
+	}
+	return true, nil
 }
 
 // FindAll retrieves all exchange rate entities from the collection.
-func (r *ExchangeRateRepository) FindAll() ([]*entity.CurrencyInfo, error) {
+func (r *ExchangeRateRepository) FindAll(ctx context.Context) ([]*entity.CurrencyInfo, error) {
 	log.Printf("Finding all exchange rates from collection: %v", r.collectionName)
-	r.init()
+	if err := checkContext(ctx, "find all"); err != nil {
+		return nil, err
+	}
+	if err := r.init(); err != nil {
+		return nil, err
+	}
 	documents, err := r.client.FindAll(r.collectionName)
 	if err != nil {
 		log.Printf("Error finding all exchange rates: %v", err)
@@ -93,13 +114,18 @@ func (r *ExchangeRateRepository) FindAll() ([]*entity.CurrencyInfo, error) {
 }
 
 // FindByID retrieves a single exchange rate entity by its ID from the collection.
-func (r *ExchangeRateRepository) FindByID(id string) (*entity.CurrencyInfo, error) {
+func (r *ExchangeRateRepository) FindByID(ctx context.Context, id string) (*entity.CurrencyInfo, error) {
 	log.Printf("Finding exchange rate by ID from collection: %v", r.collectionName)
-	r.init()
+	if err := checkContext(ctx, "find by id"); err != nil {
+		return nil, err
+	}
+	if err := r.init(); err != nil {
+		return nil, err
+	}
 	document, err := r.client.FindOne(r.collectionName, id)
 	if err != nil {
 		log.Printf("Error finding exchange rate by ID: %v", err)
-		return nil, err
+		return nil, mapNotFound(err)
 	}
 	result, err := entity.MapToCurrencyInfoEntity(document)
 	if err != nil {
@@ -110,9 +136,14 @@ func (r *ExchangeRateRepository) FindByID(id string) (*entity.CurrencyInfo, erro
 }
 
 // Find retrieves exchange rate entities by their code and codeIn from the collection.
-func (r *ExchangeRateRepository) Find(code string, codeIn string) ([]*entity.CurrencyInfo, error) {
+func (r *ExchangeRateRepository) Find(ctx context.Context, code string, codeIn string) ([]*entity.CurrencyInfo, error) {
 	log.Printf("Finding exchange rate by code from collection: %v", r.collectionName)
-	r.init()
+	if err := checkContext(ctx, "find"); err != nil {
+		return nil, err
+	}
+	if err := r.init(); err != nil {
+		return nil, err
+	}
 	queryFilter := map[string]interface{}{
 		"code":   code,
 		"codeIn": codeIn,
@@ -136,14 +167,169 @@ func (r *ExchangeRateRepository) Find(code string, codeIn string) ([]*entity.Cur
 	return currencyInfos, nil
 }
 
+// FindLatest retrieves the exchange rate entity of the pair with the highest timestamp from the collection.
+// It returns entity.ErrExchangeRateNotFound when the pair has no quote.
+func (r *ExchangeRateRepository) FindLatest(ctx context.Context, code string, codeIn string) (*entity.CurrencyInfo, error) {
+	log.Printf("Finding latest exchange rate from collection: %v", r.collectionName)
+	if err := checkContext(ctx, "find latest"); err != nil {
+		return nil, err
+	}
+	if err := r.init(); err != nil {
+		return nil, err
+	}
+	currencyInfos, err := r.findWithOptions(pairFilter(code, codeIn), database.FindOptions{
+		Sort:  historySort(entity.SortDescending),
+		Limit: 1,
+	})
+	if err != nil {
+		log.Printf("Error finding latest exchange rate: %v", err)
+		return nil, err
+	}
+	if len(currencyInfos) == 0 {
+		return nil, entity.ErrExchangeRateNotFound
+	}
+	return currencyInfos[0], nil
+}
+
+// FindRange retrieves the exchange rate entities of the pair with from <= timestamp < to from the collection,
+// ordered by timestamp and paginated by page.
+func (r *ExchangeRateRepository) FindRange(ctx context.Context, code string, codeIn string, from time.Time, to time.Time, page entity.Page) ([]*entity.CurrencyInfo, error) {
+	log.Printf("Finding exchange rate history from collection: %v", r.collectionName)
+	if err := entity.ValidateHistoryQuery(from, to, page); err != nil {
+		return nil, err
+	}
+	if err := checkContext(ctx, "find range"); err != nil {
+		return nil, err
+	}
+	if err := r.init(); err != nil {
+		return nil, err
+	}
+	min, max := entity.TimestampBounds(from, to)
+	queryFilter := pairFilter(code, codeIn)
+	queryFilter["timestamp"] = map[string]interface{}{
+		database.OpGte: min,
+		database.OpLt:  max,
+	}
+	currencyInfos, err := r.findWithOptions(queryFilter, database.FindOptions{
+		Sort:  historySort(page.Order),
+		Skip:  page.Offset,
+		Limit: page.Limit,
+	})
+	if err != nil {
+		log.Printf("Error finding exchange rate history: %v", err)
+		return nil, err
+	}
+	return currencyInfos, nil
+}
+
+// CountByPair returns the number of exchange rate entities of the pair in the collection.
+func (r *ExchangeRateRepository) CountByPair(ctx context.Context, code string, codeIn string) (int, error) {
+	log.Printf("Counting exchange rates from collection: %v", r.collectionName)
+	if err := checkContext(ctx, "count by pair"); err != nil {
+		return 0, err
+	}
+	if err := r.init(); err != nil {
+		return 0, err
+	}
+	documents, err := r.client.Find(r.collectionName, pairFilter(code, codeIn))
+	if err != nil {
+		log.Printf("Error counting exchange rates: %v", err)
+		return 0, err
+	}
+	return len(documents), nil
+}
+
+// FindCandles aggregates the exchange rate entities of the pair with from <= timestamp < to into candles.
+// The in-memory database has no grouping, so the quotes of the range are aggregated by entity.AggregateCandles.
+func (r *ExchangeRateRepository) FindCandles(ctx context.Context, code string, codeIn string, interval entity.CandleInterval, from time.Time, to time.Time) ([]entity.Candle, error) {
+	if !interval.Valid() {
+		return nil, fmt.Errorf("%w: %s", entity.ErrInvalidCandleInterval, interval)
+	}
+	quotes, err := r.FindRange(ctx, code, codeIn, from, to, entity.Page{})
+	if err != nil {
+		return nil, err
+	}
+	return entity.AggregateCandles(quotes, interval), nil
+}
+
+// findWithOptions runs a query over the collection and maps the documents to entities.
+func (r *ExchangeRateRepository) findWithOptions(queryFilter map[string]interface{}, options database.FindOptions) ([]*entity.CurrencyInfo, error) {
+	documents, err := r.client.FindWithOptions(r.collectionName, queryFilter, options)
+	if err != nil {
+		return nil, err
+	}
+	currencyInfos := make([]*entity.CurrencyInfo, len(documents))
+	for i, document := range documents {
+		result, err := entity.MapToCurrencyInfoEntity(document)
+		if err != nil {
+			log.Printf("Error mapping document to entity: %v", err)
+			return nil, err
+		}
+		currencyInfos[i] = result
+	}
+	return currencyInfos, nil
+}
+
+// pairFilter returns a query matching the documents of a currency pair.
+func pairFilter(code string, codeIn string) map[string]interface{} {
+	return map[string]interface{}{
+		"code":   code,
+		"codeIn": codeIn,
+	}
+}
+
+// historySort orders documents by timestamp, breaking ties by ID so pages are stable.
+func historySort(order entity.SortOrder) []database.SortField {
+	if order == entity.SortDescending {
+		return []database.SortField{database.Desc("timestamp"), database.Desc("_id")}
+	}
+	return []database.SortField{database.Asc("timestamp"), database.Asc("_id")}
+}
+
 // Delete removes a single exchange rate entity by its ID from the collection.
-func (r *ExchangeRateRepository) Delete(id string) error {
+func (r *ExchangeRateRepository) Delete(ctx context.Context, id string) error {
 	log.Printf("Deleting exchange rate by ID from collection: %v", r.collectionName)
-	r.init()
+	if err := checkContext(ctx, "delete"); err != nil {
+		return err
+	}
+	if err := r.init(); err != nil {
+		return err
+	}
+	j := journalFromContext(ctx)
+	var document map[string]interface{}
+	if j != nil {
+		found, err := r.client.FindOne(r.collectionName, id)
+		if err != nil {
+			log.Printf("Error deleting exchange rate by ID: %v", err)
+			return mapNotFound(err)
+		}
+		document = found
+	}
 	err := r.client.DeleteOne(r.collectionName, id)
 	if err != nil {
 		log.Printf("Error deleting exchange rate by ID: %v", err)
-		return err
+		return mapNotFound(err)
+	}
+	if j != nil {
+		j.deleted(id, document)
+	}
+	return nil
+}
+
+// mapNotFound translates the document not found error of the database into entity.ErrExchangeRateNotFound.
+func mapNotFound(err error) error {
+	if errors.Is(err, database.ErrDocumentNotFound) {
+		return entity.ErrExchangeRateNotFound
+	}
+	return err
+}
+
+// checkContext logs and returns an error when ctx is done before an operation starts.
+// An expired deadline is returned as an *entity.RepositoryTimeoutError.
+func checkContext(ctx context.Context, operation string) error {
+	if err := ctx.Err(); err != nil {
+		log.Printf("Exchange rate %s not started: %v", operation, err)
+		return entity.MapDeadlineError(operation, err)
 	}
 	return nil
 }
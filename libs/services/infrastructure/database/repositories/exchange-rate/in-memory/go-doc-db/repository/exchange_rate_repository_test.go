@@ -1,6 +1,12 @@
 package godocdbrepository
 
 import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"sync"
 	"testing"
 
 	"libs/resources/database/in-memory/go-doc-db-client/client"
@@ -72,7 +78,6 @@ func (suite *GoDocDBExchangeRateRepositoryTestSuite) TestNewGoDocDBExchangeRateR
 	assert.Equal(suite.T(), testCase.database, repository.database)
 	assert.Equal(suite.T(), testCase.client, repository.client)
 	assert.Equal(suite.T(), testCase.collectionName, repository.collectionName)
-	assert.False(suite.T(), repository.collectionCreated)
 }
 
 func (suite *GoDocDBExchangeRateRepositoryTestSuite) TestInit() {
@@ -81,38 +86,55 @@ func (suite *GoDocDBExchangeRateRepositoryTestSuite) TestInit() {
 		suite.client,
 	)
 
-	repository.init()
-	assert.Equal(suite.T(), true, repository.collectionCreated)
+	err := repository.init()
+	assert.Nil(suite.T(), err)
 	assert.Equal(suite.T(), []string{suite.collectionName}, suite.client.ListCollections())
-}
-
-func (suite *GoDocDBExchangeRateRepositoryTestSuite) TestCreateCollectionIfNotExists() {
-	repository := NewExchangeRateRepository(
-		suite.databaseName,
-		suite.client,
-	)
 
-	err := repository.createCollectionIfNotExists()
+	err = repository.init()
 	assert.Nil(suite.T(), err)
-	assert.Equal(suite.T(), true, repository.collectionCreated)
 	assert.Equal(suite.T(), []string{suite.collectionName}, suite.client.ListCollections())
 }
 
-func (suite *GoDocDBExchangeRateRepositoryTestSuite) TestCreateCollectionIfNotExistsWhenCollectionAlreadyExists() {
+func (suite *GoDocDBExchangeRateRepositoryTestSuite) TestInitWhenCollectionAlreadyExists() {
+	err := suite.client.CreateCollection(suite.collectionName)
+	assert.Nil(suite.T(), err)
 	repository := NewExchangeRateRepository(
 		suite.databaseName,
 		suite.client,
 	)
 
-	err := repository.createCollectionIfNotExists()
+	err = repository.init()
 	assert.Nil(suite.T(), err)
-	assert.Equal(suite.T(), true, repository.collectionCreated)
 	assert.Equal(suite.T(), []string{suite.collectionName}, suite.client.ListCollections())
+}
 
-	err = repository.createCollectionIfNotExists()
-	assert.Nil(suite.T(), err)
-	assert.Equal(suite.T(), true, repository.collectionCreated)
+func (suite *GoDocDBExchangeRateRepositoryTestSuite) TestConcurrentFirstUse() {
+	// A logger writing somewhere synchronizes the saves, which would hide their races from the race detector.
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+	repositories := []*ExchangeRateRepository{
+		NewExchangeRateRepository(suite.databaseName, suite.client),
+		NewExchangeRateRepository(suite.databaseName, suite.client),
+	}
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(repository *ExchangeRateRepository) {
+			defer wg.Done()
+			errs <- repository.Save(context.Background(), suite.currencyInfoData)
+		}(repositories[i%len(repositories)])
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.Nil(suite.T(), err)
+	}
 	assert.Equal(suite.T(), []string{suite.collectionName}, suite.client.ListCollections())
+	found, err := repositories[0].FindAll(context.Background())
+	assert.Nil(suite.T(), err)
+	assert.Len(suite.T(), found, 1)
 }
 
 func (suite *GoDocDBExchangeRateRepositoryTestSuite) TestSave() {
@@ -121,10 +143,9 @@ func (suite *GoDocDBExchangeRateRepositoryTestSuite) TestSave() {
 		suite.client,
 	)
 
-	repository.init()
-	assert.Equal(suite.T(), true, repository.collectionCreated)
+	assert.Nil(suite.T(), repository.init())
 
-	err := repository.Save(suite.currencyInfoData)
+	err := repository.Save(context.Background(), suite.currencyInfoData)
 	assert.Nil(suite.T(), err)
 
 	results, err := suite.client.FindAll(suite.collectionName)
@@ -138,7 +159,7 @@ func (suite *GoDocDBExchangeRateRepositoryTestSuite) TestSaveWhenCollectionNotCr
 		suite.client,
 	)
 
-	err := repository.Save(suite.currencyInfoData)
+	err := repository.Save(context.Background(), suite.currencyInfoData)
 	assert.Nil(suite.T(), err)
 
 	results, err := suite.client.FindAll(suite.collectionName)
@@ -152,17 +173,16 @@ func (suite *GoDocDBExchangeRateRepositoryTestSuite) TestSaveWhenAlreadyexists()
 		suite.client,
 	)
 
-	repository.init()
-	assert.Equal(suite.T(), true, repository.collectionCreated)
+	assert.Nil(suite.T(), repository.init())
 
-	err := repository.Save(suite.currencyInfoData)
+	err := repository.Save(context.Background(), suite.currencyInfoData)
 	assert.Nil(suite.T(), err)
 
 	results, err := suite.client.FindAll(suite.collectionName)
 	assert.Nil(suite.T(), err)
 	assert.Equal(suite.T(), 1, len(results))
 
-	err = repository.Save(suite.currencyInfoData)
+	err = repository.Save(context.Background(), suite.currencyInfoData)
 	assert.Nil(suite.T(), err)
 
 	results, err = suite.client.FindAll(suite.collectionName)
@@ -170,19 +190,45 @@ func (suite *GoDocDBExchangeRateRepositoryTestSuite) TestSaveWhenAlreadyexists()
 	assert.Equal(suite.T(), 1, len(results))
 }
 
+func (suite *GoDocDBExchangeRateRepositoryTestSuite) TestConcurrentInsertsReportOneInsert() {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+	repository := NewExchangeRateRepository(suite.databaseName, suite.client)
+	var wg sync.WaitGroup
+	inserted := make(chan bool, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, err := repository.Insert(context.Background(), suite.currencyInfoData)
+			assert.Nil(suite.T(), err)
+			inserted <- ok
+		}()
+	}
+	wg.Wait()
+	close(inserted)
+
+	count := 0
+	for ok := range inserted {
+		if ok {
+			count++
+		}
+	}
+	assert.Equal(suite.T(), 1, count)
+}
+
 func (suite *GoDocDBExchangeRateRepositoryTestSuite) TestFindAll() {
 	repository := NewExchangeRateRepository(
 		suite.databaseName,
 		suite.client,
 	)
 
-	repository.init()
-	assert.Equal(suite.T(), true, repository.collectionCreated)
+	assert.Nil(suite.T(), repository.init())
 
-	err := repository.Save(suite.currencyInfoData)
+	err := repository.Save(context.Background(), suite.currencyInfoData)
 	assert.Nil(suite.T(), err)
 
-	results, err := repository.FindAll()
+	results, err := repository.FindAll(context.Background())
 	assert.Nil(suite.T(), err)
 	assert.Equal(suite.T(), 1, len(results))
 
@@ -206,13 +252,12 @@ func (suite *GoDocDBExchangeRateRepositoryTestSuite) TestFindByID() {
 		suite.client,
 	)
 
-	repository.init()
-	assert.Equal(suite.T(), true, repository.collectionCreated)
+	assert.Nil(suite.T(), repository.init())
 
-	err := repository.Save(suite.currencyInfoData)
+	err := repository.Save(context.Background(), suite.currencyInfoData)
 	assert.Nil(suite.T(), err)
 
-	result, err := repository.FindByID(suite.currencyInfoData.ID)
+	result, err := repository.FindByID(context.Background(), suite.currencyInfoData.ID)
 	assert.Nil(suite.T(), err)
 
 	assert.Equal(suite.T(), suite.currencyInfoData.ID, result.ID)
@@ -235,13 +280,12 @@ func (suite *GoDocDBExchangeRateRepositoryTestSuite) TestFind() {
 		suite.client,
 	)
 
-	repository.init()
-	assert.Equal(suite.T(), true, repository.collectionCreated)
+	assert.Nil(suite.T(), repository.init())
 
-	err := repository.Save(suite.currencyInfoData)
+	err := repository.Save(context.Background(), suite.currencyInfoData)
 	assert.Nil(suite.T(), err)
 
-	results, err := repository.Find(suite.currencyInfoData.Code, suite.currencyInfoData.CodeIn)
+	results, err := repository.Find(context.Background(), suite.currencyInfoData.Code, suite.currencyInfoData.CodeIn)
 	assert.Nil(suite.T(), err)
 	assert.Equal(suite.T(), 1, len(results))
 
@@ -258,3 +302,57 @@ func (suite *GoDocDBExchangeRateRepositoryTestSuite) TestFind() {
 	// assert.Equal(suite.T(), suite.currencyInfoData.Timestamp, results[0].Timestamp)
 	// assert.Equal(suite.T(), suite.currencyInfoData.CreateDate, results[0].CreateDate)
 }
+
+func (suite *GoDocDBExchangeRateRepositoryTestSuite) TestDoUndoesSaveThenDeleteInReverseOrder() {
+	repository := NewExchangeRateRepository(suite.databaseName, suite.client)
+	errFailed := errors.New("failed")
+	id := suite.currencyInfoData.GetEntityID()
+
+	err := repository.Do(context.Background(), func(ctx context.Context) error {
+		assert.Nil(suite.T(), repository.Save(ctx, suite.currencyInfoData))
+		assert.Nil(suite.T(), repository.Delete(ctx, id))
+		return errFailed
+	})
+	assert.ErrorIs(suite.T(), err, errFailed)
+
+	_, err = repository.FindByID(context.Background(), id)
+	assert.ErrorIs(suite.T(), err, entity.ErrExchangeRateNotFound, "the rate did not exist before the unit of work")
+}
+
+func (suite *GoDocDBExchangeRateRepositoryTestSuite) TestDoUndoesDeleteThenSaveInReverseOrder() {
+	repository := NewExchangeRateRepository(suite.databaseName, suite.client)
+	assert.Nil(suite.T(), repository.Save(context.Background(), suite.currencyInfoData))
+	errFailed := errors.New("failed")
+	id := suite.currencyInfoData.GetEntityID()
+
+	err := repository.Do(context.Background(), func(ctx context.Context) error {
+		assert.Nil(suite.T(), repository.Delete(ctx, id))
+		assert.Nil(suite.T(), repository.Save(ctx, suite.currencyInfoData))
+		return errFailed
+	})
+	assert.ErrorIs(suite.T(), err, errFailed)
+
+	found, err := repository.FindByID(context.Background(), id)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), suite.currencyInfoData.Bid, found.Bid)
+}
+
+func (suite *GoDocDBExchangeRateRepositoryTestSuite) TestRangeQueriesOnStoredPrices() {
+	repository := NewExchangeRateRepository(suite.databaseName, suite.client)
+	cheaper, err := entity.NewExchangeRate("USD", "BRL", "Dollar", "5.1", "5.0", "0.01", "0.01", "5.05", "5.06", "1626889260", "2021-07-21 00:01:00")
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), repository.Save(context.Background(), suite.currencyInfoData))
+	assert.Nil(suite.T(), repository.Save(context.Background(), cheaper))
+
+	documents, err := suite.client.Find(suite.collectionName, map[string]interface{}{"bid": map[string]interface{}{"$gt": 5}})
+	assert.Nil(suite.T(), err)
+	assert.Len(suite.T(), documents, 2)
+
+	filter, err := database.ParseQuery("bid > 5.2")
+	assert.Nil(suite.T(), err)
+	documents, err = suite.client.Find(suite.collectionName, filter)
+	assert.Nil(suite.T(), err)
+	if assert.Len(suite.T(), documents, 1) {
+		assert.Equal(suite.T(), suite.currencyInfoData.GetEntityID(), documents[0]["_id"])
+	}
+}
@@ -0,0 +1,76 @@
+package godocdbrepository
+
+import (
+	"context"
+	"log"
+)
+
+// journalContextKey is the context key holding the journal of the current unit of work.
+type journalContextKey struct{}
+
+// journal records the writes of a unit of work, in the order they were made, so they can be undone.
+// The in-memory database has no transactions, so a failed unit of work is rolled back by compensating writes.
+type journal struct {
+	entries []journalEntry
+}
+
+// journalEntry is a single write of a unit of work: the insert of id, or the delete of document when it is set.
+type journalEntry struct {
+	id       string
+	document map[string]interface{}
+}
+
+// inserted records that the document with the given id was inserted.
+func (j *journal) inserted(id string) {
+	j.entries = append(j.entries, journalEntry{id: id})
+}
+
+// deleted records that document was deleted.
+func (j *journal) deleted(id string, document map[string]interface{}) {
+	j.entries = append(j.entries, journalEntry{id: id, document: document})
+}
+
+// journalFromContext returns the journal of the unit of work carried by ctx, if any.
+func journalFromContext(ctx context.Context) *journal {
+	j, _ := ctx.Value(journalContextKey{}).(*journal)
+	return j
+}
+
+// Do runs fn as a unit of work. When fn returns an error, the documents saved with the context passed to fn
+// are removed and the ones deleted are restored. Other writers are not isolated from the intermediate state.
+// A nested Do joins the outer unit of work.
+func (r *ExchangeRateRepository) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if journalFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+	if err := checkContext(ctx, "transaction"); err != nil {
+		return err
+	}
+	if err := r.init(); err != nil {
+		return err
+	}
+	j := &journal{}
+	err := fn(context.WithValue(ctx, journalContextKey{}, j))
+	if err != nil {
+		log.Printf("Rolling back exchange rate unit of work: %v", err)
+		r.undo(j)
+	}
+	return err
+}
+
+// undo reverts the writes recorded in the journal, newest first, so a save followed by a delete of the same
+// document leaves it absent.
+func (r *ExchangeRateRepository) undo(j *journal) {
+	for i := len(j.entries) - 1; i >= 0; i-- {
+		entry := j.entries[i]
+		if entry.document == nil {
+			if err := r.client.DeleteOne(r.collectionName, entry.id); err != nil {
+				log.Printf("Error undoing exchange rate save %v: %v", entry.id, err)
+			}
+			continue
+		}
+		if err := r.client.InsertOne(r.collectionName, entry.document); err != nil {
+			log.Printf("Error undoing exchange rate delete %v: %v", entry.id, err)
+		}
+	}
+}
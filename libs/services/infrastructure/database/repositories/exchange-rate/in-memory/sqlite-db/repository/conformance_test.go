@@ -0,0 +1,29 @@
+package sqliterepository
+
+import (
+	"context"
+	"testing"
+
+	"libs/resources/database/in-memory/sqlite-client/client"
+	entity "libs/services/entities/exchange-rate/entity"
+	"libs/services/entities/exchange-rate/entity/repositorytest"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestSQLiteExchangeRateRepositoryConformance(t *testing.T) {
+	var cl *client.Client
+	suite.Run(t, &repositorytest.ExchangeRateRepositorySuite{
+		NewRepository: func() entity.ExchangeRateRepositoryInterface {
+			var err error
+			cl, err = client.NewClient(":memory:")
+			require.NoError(t, err)
+			require.NoError(t, Migrate(context.Background(), cl))
+			return NewExchangeRateRepository(":memory:", cl)
+		},
+		Cleanup: func() {
+			cl.Close()
+		},
+	})
+}
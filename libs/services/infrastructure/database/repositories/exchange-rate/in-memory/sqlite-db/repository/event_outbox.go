@@ -0,0 +1,65 @@
+package sqliterepository
+
+import (
+	"context"
+	entity "libs/services/entities/exchange-rate/entity"
+	"log"
+	"strings"
+)
+
+// AppendEvents inserts the events into the event_outbox table, ignoring those already stored.
+// Called with the context of Do, the events are committed with the exchange rates of the transaction.
+func (r *ExchangeRateRepository) AppendEvents(ctx context.Context, events ...entity.OutboxEvent) error {
+	for _, event := range events {
+		err := r.client.ExecContext(ctx, "INSERT INTO event_outbox (id, name, event_key, payload) VALUES (?, ?, ?, ?) ON CONFLICT (id) DO NOTHING", event.ID, event.Name, event.Key, string(event.Payload))
+		if err != nil {
+			log.Printf("Error appending outbox event: %v", err)
+			return mapError("append events", err)
+		}
+	}
+	return nil
+}
+
+// PendingEvents retrieves up to limit events from the event_outbox table in the order they were appended.
+func (r *ExchangeRateRepository) PendingEvents(ctx context.Context, limit int) ([]entity.OutboxEvent, error) {
+	rows, err := r.client.QueryContext(ctx, "SELECT id, name, event_key, payload FROM event_outbox ORDER BY seq LIMIT ?", limit)
+	if err != nil {
+		log.Printf("Error finding pending outbox events: %v", err)
+		return nil, mapError("pending events", err)
+	}
+	defer rows.Close()
+
+	events := make([]entity.OutboxEvent, 0)
+	for rows.Next() {
+		var event entity.OutboxEvent
+		var payload string
+		if err := rows.Scan(&event.ID, &event.Name, &event.Key, &payload); err != nil {
+			log.Printf("Error scanning outbox event: %v", err)
+			return nil, mapError("pending events", err)
+		}
+		event.Payload = []byte(payload)
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Error iterating outbox events: %v", err)
+		return nil, mapError("pending events", err)
+	}
+	return events, nil
+}
+
+// DeleteEvents removes the events with the IDs from the event_outbox table.
+func (r *ExchangeRateRepository) DeleteEvents(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
+	if err := r.client.ExecContext(ctx, "DELETE FROM event_outbox WHERE id IN ("+placeholders+")", args...); err != nil {
+		log.Printf("Error deleting outbox events: %v", err)
+		return mapError("delete events", err)
+	}
+	return nil
+}
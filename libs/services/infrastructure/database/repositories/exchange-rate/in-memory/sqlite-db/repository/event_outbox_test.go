@@ -0,0 +1,132 @@
+package sqliterepository
+
+import (
+	"context"
+	"errors"
+	"libs/resources/database/in-memory/sqlite-client/client"
+	entity "libs/services/entities/exchange-rate/entity"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type SQLiteDBEventOutboxTestSuite struct {
+	suite.Suite
+	client       *client.Client
+	repository   *ExchangeRateRepository
+	exchangeRate *entity.CurrencyInfo
+}
+
+func TestSQLiteDBEventOutboxTestSuite(t *testing.T) {
+	suite.Run(t, new(SQLiteDBEventOutboxTestSuite))
+}
+
+func (suite *SQLiteDBEventOutboxTestSuite) SetupTest() {
+	var err error
+	suite.client, err = client.NewClient(":memory:")
+	suite.Require().NoError(err)
+	suite.Require().NoError(Migrate(context.Background(), suite.client))
+	suite.repository = NewExchangeRateRepository(":memory:", suite.client)
+	suite.exchangeRate, err = entity.NewExchangeRate("USD", "BRL", "Dollar", "5.5", "5.4", "0.05", "0.01", "5.45", "5.46", "1626889200", "2021-07-21 17:40:00")
+	suite.Require().NoError(err)
+}
+
+func (suite *SQLiteDBEventOutboxTestSuite) TearDownTest() {
+	suite.client.Close()
+}
+
+// savedEvent returns the outbox event of an ExchangeRateSaved event of a quote of the pair, quoted and saved seconds
+// after the exchange rate of the suite.
+func (suite *SQLiteDBEventOutboxTestSuite) savedEvent(seconds int) entity.OutboxEvent {
+	exchangeRate, err := entity.NewExchangeRate("USD", "BRL", "Dollar", "5.5", "5.4", "0.05", "0.01", "5.45", "5.46", strconv.Itoa(1626889200+seconds), "2021-07-21 17:40:00")
+	suite.Require().NoError(err)
+	return suite.outboxEvent(exchangeRate, time.Unix(1626889200+int64(seconds), 0).UTC())
+}
+
+// outboxEvent returns the outbox event of an ExchangeRateSaved event of the exchange rate, saved at occurredAt.
+func (suite *SQLiteDBEventOutboxTestSuite) outboxEvent(exchangeRate *entity.CurrencyInfo, occurredAt time.Time) entity.OutboxEvent {
+	event, err := entity.NewOutboxEvent(entity.ExchangeRateSaved{
+		Pair:         "USD-BRL",
+		ExchangeRate: exchangeRate,
+		OccurredAt:   occurredAt,
+	})
+	suite.Require().NoError(err)
+	return event
+}
+
+func (suite *SQLiteDBEventOutboxTestSuite) TestAppendsEventsInOrderOnce() {
+	ctx := context.Background()
+	first, second, third := suite.savedEvent(2), suite.savedEvent(1), suite.savedEvent(3)
+
+	assert.NoError(suite.T(), suite.repository.AppendEvents(ctx, first, second))
+	assert.NoError(suite.T(), suite.repository.AppendEvents(ctx, first, third), "an event already stored is ignored")
+
+	events, err := suite.repository.PendingEvents(ctx, 10)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []entity.OutboxEvent{first, second, third}, events)
+
+	events, err = suite.repository.PendingEvents(ctx, 2)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []entity.OutboxEvent{first, second}, events)
+}
+
+func (suite *SQLiteDBEventOutboxTestSuite) TestSameQuoteSavedTwiceIsStoredOnce() {
+	ctx := context.Background()
+	first := suite.outboxEvent(suite.exchangeRate, time.Unix(1626889260, 0).UTC())
+	again := suite.outboxEvent(suite.exchangeRate, time.Unix(1626889320, 0).UTC())
+
+	assert.NoError(suite.T(), suite.repository.AppendEvents(ctx, first))
+	assert.NoError(suite.T(), suite.repository.AppendEvents(ctx, again))
+
+	events, err := suite.repository.PendingEvents(ctx, 10)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []entity.OutboxEvent{first}, events)
+}
+
+func (suite *SQLiteDBEventOutboxTestSuite) TestDeleteEvents() {
+	ctx := context.Background()
+	first, second := suite.savedEvent(1), suite.savedEvent(2)
+	assert.NoError(suite.T(), suite.repository.AppendEvents(ctx, first, second))
+
+	assert.NoError(suite.T(), suite.repository.DeleteEvents(ctx, first.ID))
+	assert.NoError(suite.T(), suite.repository.DeleteEvents(ctx))
+
+	events, err := suite.repository.PendingEvents(ctx, 10)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []entity.OutboxEvent{second}, events)
+}
+
+func (suite *SQLiteDBEventOutboxTestSuite) TestEventsAreCommittedWithTheExchangeRates() {
+	ctx := context.Background()
+	failure := errors.New("crash before commit")
+
+	err := suite.repository.Do(ctx, func(ctx context.Context) error {
+		if err := suite.repository.Save(ctx, suite.exchangeRate); err != nil {
+			return err
+		}
+		if err := suite.repository.AppendEvents(ctx, suite.savedEvent(0)); err != nil {
+			return err
+		}
+		return failure
+	})
+	assert.ErrorIs(suite.T(), err, failure)
+	events, err := suite.repository.PendingEvents(ctx, 10)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), events, "a rolled back transaction leaves no event")
+
+	err = suite.repository.Do(ctx, func(ctx context.Context) error {
+		if err := suite.repository.Save(ctx, suite.exchangeRate); err != nil {
+			return err
+		}
+		return suite.repository.AppendEvents(ctx, suite.savedEvent(0))
+	})
+	assert.NoError(suite.T(), err)
+	events, err = suite.repository.PendingEvents(ctx, 10)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), events, 1)
+	_, err = suite.repository.FindByID(ctx, suite.exchangeRate.GetEntityID())
+	assert.NoError(suite.T(), err)
+}
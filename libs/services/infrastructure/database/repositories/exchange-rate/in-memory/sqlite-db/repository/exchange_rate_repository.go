@@ -1,22 +1,42 @@
 package sqliterepository
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"libs/resources/database/in-memory/sqlite-client/client"
+	"libs/resources/database/sql-dialect/dialect"
 	entity "libs/services/entities/exchange-rate/entity"
 	"log"
+	"strings"
+	"time"
 )
 
 var (
 	schemaName = "currencyInfo"
 )
 
+// columns lists the columns of exchange_rates, in the order scanned by scanCurrencyInfo.
+var columns = []string{"id", "code", "codeIn", "name", "high", "low", "varBid", "pctChange", "bid", "ask", "timestamp", "create_date"}
+
+var (
+	// selectColumns lists the columns read by the queries of the repository.
+	selectColumns = strings.Join(columns, ", ")
+	// upsertQuery inserts a row, or updates the row with the same id.
+	upsertQuery = dialect.Upsert("exchange_rates", "id", columns...)
+	// insertQuery inserts a row unless one has the same id, returning the id of the row it inserted.
+	insertQuery = dialect.InsertIfAbsent("exchange_rates", "id", columns...)
+)
+
+// ExchangeRateRepository handles the CRUD operations for exchange rate entities using the SQLite client.
+// The schema must be migrated with Migrate before the repository is used.
 type ExchangeRateRepository struct {
 	database string
 	client   *client.Client
-	// collectionName    string
-	// collectionCreated bool
 }
 
+// NewExchangeRateRepository creates and returns a new ExchangeRateRepository instance.
 func NewExchangeRateRepository(
 	database string,
 	client *client.Client,
@@ -24,53 +44,261 @@ func NewExchangeRateRepository(
 	return &ExchangeRateRepository{
 		database: database,
 		client:   client,
-		// collectionName:    collectionName,
-		// collectionCreated: false,
-	}
-}
-
-// CreateTable creates the exchange_rates table if it doesn't exist.
-func (r *ExchangeRateRepository) createTable() error {
-	createTableQuery := `
-    CREATE TABLE IF NOT EXISTS exchange_rates (
-        id TEXT PRIMARY KEY,
-        code TEXT,
-        codeIn TEXT,
-        name TEXT,
-        high REAL,
-        low REAL,
-        varBid REAL,
-        pctChange REAL,
-        bid REAL,
-        ask REAL,
-        timestamp INTEGER,
-        create_date DATETIME
-    )`
-	return r.client.Exec(createTableQuery)
-}
-
-func (r *ExchangeRateRepository) Save(currencyInfo *entity.CurrencyInfo) error {
-	if err := r.createTable(); err != nil {
-		log.Printf("Error creating table: %v", err)
-		return err
-	}
-
-	insertQuery := `
-    INSERT INTO exchange_rates (id, code, codeIn, name, high, low, varBid, pctChange, bid, ask, timestamp, create_date)
-    VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-    ON CONFLICT(id) DO UPDATE SET
-        code = excluded.code,
-        codeIn = excluded.codeIn,
-        name = excluded.name,
-        high = excluded.high,
-        low = excluded.low,
-        varBid = excluded.varBid,
-        pctChange = excluded.pctChange,
-        bid = excluded.bid,
-        ask = excluded.ask,
-        timestamp = excluded.timestamp,
-        create_date = excluded.create_date
+	}
+}
+
+// Do runs fn in a transaction. Repository calls made with the context passed to fn are committed together,
+// or rolled back together when fn returns an error. A transaction that fails on a busy database is retried.
+func (r *ExchangeRateRepository) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	err := r.client.WithTx(ctx, func(tx *client.Tx) error {
+		return fn(tx.Context())
+	})
+	if err != nil {
+		log.Printf("Error in exchange rate transaction: %v", err)
+		return entity.MapDeadlineError("transaction", err)
+	}
+	return nil
+}
+
+// Save inserts the given currency info entity, or updates it if a row with the same ID exists.
+func (r *ExchangeRateRepository) Save(ctx context.Context, currencyInfo *entity.CurrencyInfo) error {
+	err := r.client.ExecContext(ctx, upsertQuery, currencyInfo.GetEntityID(), currencyInfo.Code, currencyInfo.CodeIn, currencyInfo.Name, currencyInfo.High.Units(), currencyInfo.Low.Units(), currencyInfo.VarBid.Units(), currencyInfo.PctChange, currencyInfo.Bid.Units(), currencyInfo.Ask.Units(), currencyInfo.Timestamp, currencyInfo.CreateDate)
+	if err != nil {
+		log.Printf("Error saving exchange rate: %v", err)
+		return mapError("save", err)
+	}
+	return nil
+}
+
+// Insert inserts the given currency info entity unless a row with the same ID exists, and reports whether it did.
+func (r *ExchangeRateRepository) Insert(ctx context.Context, currencyInfo *entity.CurrencyInfo) (bool, error) {
+	var id string
+	err := r.client.QueryRowContext(ctx, insertQuery, currencyInfo.GetEntityID(), currencyInfo.Code, currencyInfo.CodeIn, currencyInfo.Name, currencyInfo.High.Units(), currencyInfo.Low.Units(), currencyInfo.VarBid.Units(), currencyInfo.PctChange, currencyInfo.Bid.Units(), currencyInfo.Ask.Units(), currencyInfo.Timestamp, currencyInfo.CreateDate).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		log.Printf("Error inserting exchange rate: %v", err)
+		return false, mapError("insert", err)
+	}
+	return true, nil
+}
+
+// FindAll retrieves all exchange rate entities from the table.
+func (r *ExchangeRateRepository) FindAll(ctx context.Context) ([]*entity.CurrencyInfo, error) {
+	log.Printf("Finding all exchange rates from table: exchange_rates")
+	return r.query(ctx, "find all", "SELECT "+selectColumns+" FROM exchange_rates")
+}
+
+// Find retrieves exchange rate entities by their code and codeIn from the table.
+func (r *ExchangeRateRepository) Find(ctx context.Context, code string, codeIn string) ([]*entity.CurrencyInfo, error) {
+	log.Printf("Finding exchange rate by code from table: exchange_rates")
+	return r.query(ctx, "find", "SELECT "+selectColumns+" FROM exchange_rates WHERE code = ? AND codeIn = ?", code, codeIn)
+}
+
+// FindByID retrieves a single exchange rate entity by its ID from the table.
+// It returns entity.ErrExchangeRateNotFound when no row has the given ID.
+func (r *ExchangeRateRepository) FindByID(ctx context.Context, id string) (*entity.CurrencyInfo, error) {
+	log.Printf("Finding exchange rate by ID from table: exchange_rates")
+	row := r.client.QueryRowContext(ctx, "SELECT "+selectColumns+" FROM exchange_rates WHERE id = ?", id)
+	currencyInfo, err := scanCurrencyInfo(row)
+	if err != nil {
+		log.Printf("Error finding exchange rate by ID: %v", err)
+		return nil, mapError("find by id", err)
+	}
+	return currencyInfo, nil
+}
+
+// FindLatest retrieves the exchange rate entity of the pair with the highest timestamp from the table.
+// It returns entity.ErrExchangeRateNotFound when the pair has no quote.
+func (r *ExchangeRateRepository) FindLatest(ctx context.Context, code string, codeIn string) (*entity.CurrencyInfo, error) {
+	log.Printf("Finding latest exchange rate from table: exchange_rates")
+	row := r.client.QueryRowContext(ctx, "SELECT "+selectColumns+" FROM exchange_rates WHERE code = ? AND codeIn = ? ORDER BY timestamp DESC, id DESC LIMIT 1", code, codeIn)
+	currencyInfo, err := scanCurrencyInfo(row)
+	if err != nil {
+		log.Printf("Error finding latest exchange rate: %v", err)
+		return nil, mapError("find latest", err)
+	}
+	return currencyInfo, nil
+}
+
+// FindRange retrieves the exchange rate entities of the pair with from <= timestamp < to from the table,
+// ordered by timestamp and paginated by page.
+func (r *ExchangeRateRepository) FindRange(ctx context.Context, code string, codeIn string, from time.Time, to time.Time, page entity.Page) ([]*entity.CurrencyInfo, error) {
+	log.Printf("Finding exchange rate history from table: exchange_rates")
+	if err := entity.ValidateHistoryQuery(from, to, page); err != nil {
+		return nil, err
+	}
+	min, max := entity.TimestampBounds(from, to)
+	order := "ASC"
+	if page.Order == entity.SortDescending {
+		order = "DESC"
+	}
+	var limit interface{} = page.Limit
+	if page.Limit == 0 {
+		limit = dialect.SQLite.NoLimit()
+	}
+	return r.query(ctx, "find range",
+		"SELECT "+selectColumns+" FROM exchange_rates WHERE code = ? AND codeIn = ? AND timestamp >= ? AND timestamp < ? ORDER BY timestamp "+order+", id "+order+" LIMIT ? OFFSET ?",
+		code, codeIn, min, max, limit, page.Offset,
+	)
+}
+
+// CountByPair returns the number of exchange rate entities of the pair in the table.
+func (r *ExchangeRateRepository) CountByPair(ctx context.Context, code string, codeIn string) (int, error) {
+	log.Printf("Counting exchange rates from table: exchange_rates")
+	var count int
+	err := r.client.QueryRowContext(ctx, "SELECT COUNT(*) FROM exchange_rates WHERE code = ? AND codeIn = ?", code, codeIn).Scan(&count)
+	if err != nil {
+		log.Printf("Error counting exchange rates: %v", err)
+		return 0, mapError("count by pair", err)
+	}
+	return count, nil
+}
+
+// candlesQuery groups the quotes of a pair into buckets of interval seconds. The window functions pick the bid of
+// the first and last quote of each bucket, ordered by timestamp and id like entity.AggregateCandles.
+const candlesQuery = `
+    SELECT bucket, MIN(open), MAX(bid), MIN(bid), MIN(close), COUNT(*)
+    FROM (
+        SELECT
+            timestamp - timestamp % ? AS bucket,
+            bid,
+            FIRST_VALUE(bid) OVER (PARTITION BY timestamp - timestamp % ? ORDER BY timestamp ASC, id ASC) AS open,
+            FIRST_VALUE(bid) OVER (PARTITION BY timestamp - timestamp % ? ORDER BY timestamp DESC, id DESC) AS close
+        FROM exchange_rates
+        WHERE code = ? AND codeIn = ? AND timestamp >= ? AND timestamp < ?
+    )
+    GROUP BY bucket
+    ORDER BY bucket
     `
-	err := r.client.Exec(insertQuery, currencyInfo.GetEntityID(), currencyInfo.Code, currencyInfo.CodeIn, currencyInfo.Name, currencyInfo.High, currencyInfo.Low, currencyInfo.VarBid, currencyInfo.PctChange, currencyInfo.Bid, currencyInfo.Ask, currencyInfo.Timestamp, currencyInfo.CreateDate)
-	return err
+
+// FindCandles aggregates the exchange rate entities of the pair with from <= timestamp < to into candles with GROUP BY.
+func (r *ExchangeRateRepository) FindCandles(ctx context.Context, code string, codeIn string, interval entity.CandleInterval, from time.Time, to time.Time) ([]entity.Candle, error) {
+	log.Printf("Finding exchange rate candles from table: exchange_rates")
+	if !interval.Valid() {
+		return nil, fmt.Errorf("%w: %s", entity.ErrInvalidCandleInterval, interval)
+	}
+	if err := entity.ValidateHistoryQuery(from, to, entity.Page{}); err != nil {
+		return nil, err
+	}
+	min, max := entity.TimestampBounds(from, to)
+	seconds := interval.Seconds()
+	rows, err := r.client.QueryContext(ctx, candlesQuery, seconds, seconds, seconds, code, codeIn, min, max)
+	if err != nil {
+		log.Printf("Error querying exchange rate candles: %v", err)
+		return nil, mapError("find candles", err)
+	}
+	defer rows.Close()
+
+	candles := make([]entity.Candle, 0)
+	for rows.Next() {
+		var candle entity.Candle
+		if err := rows.Scan(&candle.Start, units{&candle.Open}, units{&candle.High}, units{&candle.Low}, units{&candle.Close}, &candle.Count); err != nil {
+			log.Printf("Error scanning exchange rate candle: %v", err)
+			return nil, mapError("find candles", err)
+		}
+		candles = append(candles, candle)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Error iterating exchange rate candles: %v", err)
+		return nil, mapError("find candles", err)
+	}
+	return candles, nil
+}
+
+// Delete removes a single exchange rate entity by its ID from the table.
+// It returns entity.ErrExchangeRateNotFound when no row has the given ID.
+func (r *ExchangeRateRepository) Delete(ctx context.Context, id string) error {
+	log.Printf("Deleting exchange rate by ID from table: exchange_rates")
+	var deletedID string
+	err := r.client.QueryRowContext(ctx, "DELETE FROM exchange_rates WHERE id = ? RETURNING id", id).Scan(&deletedID)
+	if err != nil {
+		log.Printf("Error deleting exchange rate by ID: %v", err)
+		return mapError("delete", err)
+	}
+	return nil
+}
+
+// query runs a select over exchange_rates and scans every returned row.
+func (r *ExchangeRateRepository) query(ctx context.Context, operation string, query string, args ...interface{}) ([]*entity.CurrencyInfo, error) {
+	rows, err := r.client.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("Error querying exchange rates: %v", err)
+		return nil, mapError(operation, err)
+	}
+	defer rows.Close()
+
+	currencyInfos := make([]*entity.CurrencyInfo, 0)
+	for rows.Next() {
+		currencyInfo, err := scanCurrencyInfo(rows)
+		if err != nil {
+			log.Printf("Error scanning exchange rate: %v", err)
+			return nil, mapError(operation, err)
+		}
+		currencyInfos = append(currencyInfos, currencyInfo)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Error iterating exchange rates: %v", err)
+		return nil, mapError(operation, err)
+	}
+	return currencyInfos, nil
+}
+
+// scanner is implemented by both *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanCurrencyInfo scans a row holding selectColumns into a CurrencyInfo.
+// create_date is declared as DATETIME, so the driver returns it as a time.Time.
+// The prices are stored as INTEGER counts of 10^-8 units, so they are read back exactly.
+func scanCurrencyInfo(row scanner) (*entity.CurrencyInfo, error) {
+	var currencyInfo entity.CurrencyInfo
+	err := row.Scan(
+		&currencyInfo.ID,
+		&currencyInfo.Code,
+		&currencyInfo.CodeIn,
+		&currencyInfo.Name,
+		units{&currencyInfo.High},
+		units{&currencyInfo.Low},
+		units{&currencyInfo.VarBid},
+		&currencyInfo.PctChange,
+		units{&currencyInfo.Bid},
+		units{&currencyInfo.Ask},
+		&currencyInfo.Timestamp,
+		&currencyInfo.CreateDate,
+	)
+	if err != nil {
+		return nil, err
+	}
+	currencyInfo.CreateDate = currencyInfo.CreateDate.UTC()
+	return &currencyInfo, nil
+}
+
+// units scans an INTEGER column holding a count of 10^-8 units, as written by Save, into an entity.Decimal.
+// NULL reads as 0.
+type units struct {
+	decimal *entity.Decimal
+}
+
+// Scan implements sql.Scanner.
+func (u units) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*u.decimal = entity.Decimal{}
+	case int64:
+		*u.decimal = entity.DecimalFromUnits(v)
+	default:
+		return fmt.Errorf("%w: cannot scan %T as price units", entity.ErrInvalidDecimal, value)
+	}
+	return nil
+}
+
+// mapError translates sql.ErrNoRows into entity.ErrExchangeRateNotFound and expired deadlines into an *entity.RepositoryTimeoutError.
+func mapError(operation string, err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.ErrExchangeRateNotFound
+	}
+	return entity.MapDeadlineError(operation, err)
 }
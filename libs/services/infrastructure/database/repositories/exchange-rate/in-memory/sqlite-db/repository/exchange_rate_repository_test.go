@@ -1,8 +1,10 @@
 package sqliterepository
 
 import (
+	"context"
 	"libs/resources/database/in-memory/sqlite-client/client"
 	entity "libs/services/entities/exchange-rate/entity"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -26,28 +28,72 @@ func (suite *SQLiteDBExchangeRateRepositoryTestSuite) SetupTest() {
 
 	suite.client = cl
 	suite.repository = NewExchangeRateRepository(databasePath, cl)
+	assert.NoError(suite.T(), Migrate(context.Background(), cl))
 }
 
 func (suite *SQLiteDBExchangeRateRepositoryTestSuite) TearDownTest() {
 	suite.client.Close()
 }
 
-func (suite *SQLiteDBExchangeRateRepositoryTestSuite) TestCreateTable() {
-	err := suite.repository.createTable()
-	assert.NoError(suite.T(), err)
-
+func (suite *SQLiteDBExchangeRateRepositoryTestSuite) TestMigrate() {
 	query := "SELECT name FROM sqlite_master WHERE type='table' AND name='exchange_rates'"
 	row := suite.client.QueryRow(query)
 
 	var tableName string
-	err = row.Scan(&tableName)
+	err := row.Scan(&tableName)
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), "exchange_rates", tableName)
+
+	var version int64
+	err = suite.client.QueryRow("SELECT MAX(version) FROM schema_migrations").Scan(&version)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(4), version)
+	assert.NoError(suite.T(), Migrate(context.Background(), suite.client), "applied migrations are skipped")
 }
-func (suite *SQLiteDBExchangeRateRepositoryTestSuite) TestSave() {
-	err := suite.repository.createTable()
+
+func (suite *SQLiteDBExchangeRateRepositoryTestSuite) TestMigrateCanceled() {
+	cl, err := client.NewClient(":memory:")
+	assert.NoError(suite.T(), err)
+	defer cl.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(suite.T(), Migrate(ctx, cl), context.Canceled)
+}
+
+func (suite *SQLiteDBExchangeRateRepositoryTestSuite) TestMigrateConvertsRealPrices() {
+	// The database starts with the baseline schema of migrations 0001 and 0002, which stored prices as REAL.
+	cl, err := client.NewClient(":memory:")
+	assert.NoError(suite.T(), err)
+	defer cl.Close()
+	migrations, err := Migrations()
 	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), cl.Migrate(migrations[:2]))
+	err = cl.Exec(
+		"INSERT INTO exchange_rates (id, code, codeIn, name, high, low, varBid, pctChange, bid, ask, timestamp, create_date) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		"83da6030-ab1f-5b6c-8b07-7bac10f85dbc", "USD", "BRL", "Dollar", 5.5, 5.4, 0.0563, 0.01, 5.4563, 5.4564, 1626889200, "2021-07-21 00:00:00",
+	)
+	assert.NoError(suite.T(), err)
+
+	assert.NoError(suite.T(), Migrate(context.Background(), cl))
 
+	var bidType, askType string
+	var bid, ask, high, low, varBid int64
+	err = cl.QueryRow("SELECT typeof(bid), typeof(ask), bid, ask, high, low, varBid FROM exchange_rates").Scan(&bidType, &askType, &bid, &ask, &high, &low, &varBid)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "integer", bidType)
+	assert.Equal(suite.T(), "integer", askType)
+	assert.Equal(suite.T(), []int64{545630000, 545640000, 550000000, 540000000, 5630000}, []int64{bid, ask, high, low, varBid})
+
+	currencyInfo, err := NewExchangeRateRepository(":memory:", cl).FindByID(context.Background(), "83da6030-ab1f-5b6c-8b07-7bac10f85dbc")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), entity.MustParseDecimal("5.4563"), currencyInfo.Bid)
+	assert.Equal(suite.T(), entity.MustParseDecimal("5.4564"), currencyInfo.Ask)
+	assert.Equal(suite.T(), entity.MustParseDecimal("0.0563"), currencyInfo.VarBid)
+	assert.Equal(suite.T(), entity.MustParseDecimal("5.5"), currencyInfo.High)
+}
+
+func (suite *SQLiteDBExchangeRateRepositoryTestSuite) TestSave() {
 	code := "USD"
 	codeIn := "BRL"
 	name := "Dollar"
@@ -74,7 +120,7 @@ func (suite *SQLiteDBExchangeRateRepositoryTestSuite) TestSave() {
 		createDate,
 	)
 
-	err = suite.repository.Save(currencyInfo)
+	err := suite.repository.Save(context.Background(), currencyInfo)
 	assert.NoError(suite.T(), err)
 
 	query := "SELECT id, code, codeIn, name, high, low, varBid, pctChange, bid, ask, timestamp, create_date FROM exchange_rates WHERE id = ?"
@@ -86,15 +132,74 @@ func (suite *SQLiteDBExchangeRateRepositoryTestSuite) TestSave() {
 		&retrievedCurrencyInfo.Code,
 		&retrievedCurrencyInfo.CodeIn,
 		&retrievedCurrencyInfo.Name,
-		&retrievedCurrencyInfo.High,
-		&retrievedCurrencyInfo.Low,
-		&retrievedCurrencyInfo.VarBid,
+		units{&retrievedCurrencyInfo.High},
+		units{&retrievedCurrencyInfo.Low},
+		units{&retrievedCurrencyInfo.VarBid},
 		&retrievedCurrencyInfo.PctChange,
-		&retrievedCurrencyInfo.Bid,
-		&retrievedCurrencyInfo.Ask,
+		units{&retrievedCurrencyInfo.Bid},
+		units{&retrievedCurrencyInfo.Ask},
 		&retrievedCurrencyInfo.Timestamp,
 		&retrievedCurrencyInfo.CreateDate,
 	)
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), currencyInfo, &retrievedCurrencyInfo)
 }
+
+func (suite *SQLiteDBExchangeRateRepositoryTestSuite) TestInsertReportsWhetherItStored() {
+	ctx := context.Background()
+	currencyInfo, err := entity.NewExchangeRate("USD", "BRL", "Dollar", "5.5", "5.4", "5.45", "0.01", "5.45", "5.46", "1626889200", "2021-07-21 00:00:00")
+	assert.NoError(suite.T(), err)
+
+	inserted, err := suite.repository.Insert(ctx, currencyInfo)
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), inserted)
+
+	err = suite.repository.Do(ctx, func(ctx context.Context) error {
+		inserted, err = suite.repository.Insert(ctx, currencyInfo)
+		return err
+	})
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), inserted, "the quote is already stored")
+	count, err := suite.repository.CountByPair(ctx, "USD", "BRL")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, count)
+}
+
+// benchmarkSave saves the same quote repeatedly through a client caching size prepared statements.
+func benchmarkSave(b *testing.B, size int) {
+	options := client.DefaultOptions()
+	options.StatementCacheSize = size
+	options.Synchronous = client.SynchronousOff
+	cl, err := client.NewClientWithOptions(filepath.Join(b.TempDir(), "bench.db"), options)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer cl.Close()
+	if err := Migrate(context.Background(), cl); err != nil {
+		b.Fatal(err)
+	}
+	repository := NewExchangeRateRepository("bench.db", cl)
+	currencyInfo, err := entity.NewExchangeRate("USD", "BRL", "Dollar", "5.5", "5.4", "5.45", "0.01", "5.45", "5.46", "1626889200", "2021-07-21 00:00:00")
+	if err != nil {
+		b.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := repository.Save(ctx, currencyInfo); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := repository.Save(ctx, currencyInfo); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSaveCachedStatements(b *testing.B) {
+	benchmarkSave(b, client.DefaultStatementCacheSize)
+}
+
+func BenchmarkSaveUncachedStatements(b *testing.B) {
+	benchmarkSave(b, 0)
+}
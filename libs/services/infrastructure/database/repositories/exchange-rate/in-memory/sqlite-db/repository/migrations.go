@@ -0,0 +1,30 @@
+package sqliterepository
+
+import (
+	"context"
+	"embed"
+	"io/fs"
+	"libs/resources/database/in-memory/sqlite-client/client"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migrations returns the versioned schema migrations of the exchange_rates and event_outbox tables.
+func Migrations() ([]client.Migration, error) {
+	migrationsDir, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return client.LoadMigrations(migrationsDir)
+}
+
+// Migrate applies the pending schema migrations. Call it once at startup, before the repository is used,
+// and stop if it fails.
+func Migrate(ctx context.Context, c *client.Client) error {
+	migrations, err := Migrations()
+	if err != nil {
+		return err
+	}
+	return c.MigrateContext(ctx, migrations)
+}
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id TEXT PRIMARY KEY,
+    code TEXT,
+    codeIn TEXT,
+    name TEXT,
+    high REAL,
+    low REAL,
+    varBid REAL,
+    pctChange REAL,
+    bid REAL,
+    ask REAL,
+    timestamp INTEGER,
+    create_date DATETIME
+);
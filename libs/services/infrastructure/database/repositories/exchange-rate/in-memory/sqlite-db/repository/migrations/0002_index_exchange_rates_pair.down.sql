@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_exchange_rates_pair_timestamp;
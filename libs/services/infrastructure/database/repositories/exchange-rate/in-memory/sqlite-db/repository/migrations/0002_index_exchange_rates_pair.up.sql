@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_exchange_rates_pair_timestamp ON exchange_rates (code, codeIn, timestamp);
@@ -0,0 +1,32 @@
+CREATE TABLE exchange_rates_real (
+    id TEXT PRIMARY KEY,
+    code TEXT,
+    codeIn TEXT,
+    name TEXT,
+    high REAL,
+    low REAL,
+    varBid REAL,
+    pctChange REAL,
+    bid REAL,
+    ask REAL,
+    timestamp INTEGER,
+    create_date DATETIME
+);
+INSERT INTO exchange_rates_real
+    SELECT
+        id,
+        code,
+        codeIn,
+        name,
+        high / 100000000.0,
+        low / 100000000.0,
+        varBid / 100000000.0,
+        pctChange,
+        bid / 100000000.0,
+        ask / 100000000.0,
+        timestamp,
+        create_date
+    FROM exchange_rates;
+DROP TABLE exchange_rates;
+ALTER TABLE exchange_rates_real RENAME TO exchange_rates;
+CREATE INDEX IF NOT EXISTS idx_exchange_rates_pair_timestamp ON exchange_rates (code, codeIn, timestamp);
@@ -0,0 +1,32 @@
+CREATE TABLE exchange_rates_units (
+    id TEXT PRIMARY KEY,
+    code TEXT,
+    codeIn TEXT,
+    name TEXT,
+    high INTEGER,
+    low INTEGER,
+    varBid INTEGER,
+    pctChange REAL,
+    bid INTEGER,
+    ask INTEGER,
+    timestamp INTEGER,
+    create_date DATETIME
+);
+INSERT INTO exchange_rates_units
+    SELECT
+        id,
+        code,
+        codeIn,
+        name,
+        CAST(ROUND(high * 100000000) AS INTEGER),
+        CAST(ROUND(low * 100000000) AS INTEGER),
+        CAST(ROUND(varBid * 100000000) AS INTEGER),
+        pctChange,
+        CAST(ROUND(bid * 100000000) AS INTEGER),
+        CAST(ROUND(ask * 100000000) AS INTEGER),
+        timestamp,
+        create_date
+    FROM exchange_rates;
+DROP TABLE exchange_rates;
+ALTER TABLE exchange_rates_units RENAME TO exchange_rates;
+CREATE INDEX IF NOT EXISTS idx_exchange_rates_pair_timestamp ON exchange_rates (code, codeIn, timestamp);
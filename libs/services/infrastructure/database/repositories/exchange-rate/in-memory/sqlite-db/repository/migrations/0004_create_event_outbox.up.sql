@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS event_outbox (
+    seq INTEGER PRIMARY KEY AUTOINCREMENT,
+    id TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    event_key TEXT NOT NULL,
+    payload TEXT NOT NULL
+);
@@ -0,0 +1,3 @@
+module libs/services/infrastructure/database/repositories/exchange-rate/postgres-db
+
+go 1.22
@@ -0,0 +1,24 @@
+package postgresrepository
+
+import (
+	"database/sql"
+	"testing"
+
+	entity "libs/services/entities/exchange-rate/entity"
+	"libs/services/entities/exchange-rate/entity/repositorytest"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func TestPostgresExchangeRateRepositoryConformance(t *testing.T) {
+	var db *sql.DB
+	suite.Run(t, &repositorytest.ExchangeRateRepositorySuite{
+		NewRepository: func() entity.ExchangeRateRepositoryInterface {
+			db = openStandIn(t)
+			return NewExchangeRateRepository("test", db)
+		},
+		Cleanup: func() {
+			db.Close()
+		},
+	})
+}
@@ -0,0 +1,391 @@
+package postgresrepository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"libs/resources/database/sql-dialect/dialect"
+	entity "libs/services/entities/exchange-rate/entity"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// columns lists the columns of exchange_rates, in the order scanned by scanCurrencyInfo.
+var columns = []string{"id", "code", "code_in", "name", "high", "low", "var_bid", "pct_change", "bid", "ask", "quoted_at", "create_date"}
+
+var (
+	selectColumns   = strings.Join(columns, ", ")
+	upsertQuery     = rebind(dialect.Upsert("exchange_rates", "id", columns...))
+	insertQuery     = rebind(dialect.InsertIfAbsent("exchange_rates", "id", columns...))
+	findAllQuery    = rebind("SELECT " + selectColumns + " FROM exchange_rates")
+	findQuery       = rebind("SELECT " + selectColumns + " FROM exchange_rates WHERE code = ? AND code_in = ?")
+	findByIDQuery   = rebind("SELECT " + selectColumns + " FROM exchange_rates WHERE id = ?")
+	findLatestQuery = rebind("SELECT " + selectColumns + " FROM exchange_rates WHERE code = ? AND code_in = ? ORDER BY quoted_at DESC, id DESC LIMIT 1")
+	countQuery      = rebind("SELECT COUNT(*) FROM exchange_rates WHERE code = ? AND code_in = ?")
+	deleteQuery     = rebind("DELETE FROM exchange_rates WHERE id = ? RETURNING id")
+)
+
+// rebind rewrites the ? placeholders of query into the $n placeholders of PostgreSQL.
+func rebind(query string) string {
+	return dialect.Rebind(dialect.Postgres, query)
+}
+
+// ExchangeRateRepository handles the CRUD operations for exchange rate entities on a PostgreSQL database.
+type ExchangeRateRepository struct {
+	database    string
+	db          *sql.DB
+	migrateOnce sync.Once
+	migrateErr  error
+}
+
+// NewExchangeRateRepository creates and returns a new ExchangeRateRepository instance.
+// db is opened by the caller with a PostgreSQL driver, for example sql.Open("pgx", dsn).
+func NewExchangeRateRepository(
+	database string,
+	db *sql.DB,
+) *ExchangeRateRepository {
+	return &ExchangeRateRepository{
+		database: database,
+		db:       db,
+	}
+}
+
+// migrate creates the schema the first time the repository is used.
+// Later calls return the result of the first run.
+func (r *ExchangeRateRepository) migrate(ctx context.Context) error {
+	r.migrateOnce.Do(func() {
+		r.migrateErr = Migrate(context.WithoutCancel(ctx), r.db)
+	})
+	return r.migrateErr
+}
+
+// txKey is the context key of the transaction opened by Do on db.
+type txKey struct {
+	db *sql.DB
+}
+
+// executor is implemented by both *sql.DB and *sql.Tx.
+type executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// executor returns the transaction carried by ctx, or the database when there is none.
+func (r *ExchangeRateRepository) executor(ctx context.Context) executor {
+	if tx, ok := ctx.Value(txKey{r.db}).(*sql.Tx); ok {
+		return tx
+	}
+	return r.db
+}
+
+// Do runs fn in a transaction. Repository calls made with the context passed to fn are committed together,
+// or rolled back together when fn returns an error. A Do inside another one joins the outer transaction.
+func (r *ExchangeRateRepository) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := r.migrate(ctx); err != nil {
+		log.Printf("Error migrating schema: %v", err)
+		return err
+	}
+	if _, ok := ctx.Value(txKey{r.db}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error beginning exchange rate transaction: %v", err)
+		return entity.MapDeadlineError("transaction", err)
+	}
+	if err := fn(context.WithValue(ctx, txKey{r.db}, tx)); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && !errors.Is(rollbackErr, sql.ErrTxDone) {
+			log.Printf("Error rolling back exchange rate transaction: %v", rollbackErr)
+		}
+		log.Printf("Error in exchange rate transaction: %v", err)
+		return entity.MapDeadlineError("transaction", err)
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing exchange rate transaction: %v", err)
+		return entity.MapDeadlineError("transaction", err)
+	}
+	return nil
+}
+
+// Save inserts the given currency info entity, or updates it if a row with the same ID exists.
+func (r *ExchangeRateRepository) Save(ctx context.Context, currencyInfo *entity.CurrencyInfo) error {
+	if err := r.migrate(ctx); err != nil {
+		log.Printf("Error migrating schema: %v", err)
+		return err
+	}
+	_, err := r.executor(ctx).ExecContext(ctx, upsertQuery,
+		currencyInfo.GetEntityID(),
+		currencyInfo.Code,
+		currencyInfo.CodeIn,
+		currencyInfo.Name,
+		currencyInfo.High,
+		currencyInfo.Low,
+		currencyInfo.VarBid,
+		currencyInfo.PctChange,
+		currencyInfo.Bid,
+		currencyInfo.Ask,
+		time.Unix(currencyInfo.Timestamp, 0).UTC(),
+		currencyInfo.CreateDate.UTC(),
+	)
+	if err != nil {
+		log.Printf("Error saving exchange rate: %v", err)
+		return mapError("save", err)
+	}
+	return nil
+}
+
+// Insert inserts the given currency info entity unless a row with the same ID exists, and reports whether it did.
+func (r *ExchangeRateRepository) Insert(ctx context.Context, currencyInfo *entity.CurrencyInfo) (bool, error) {
+	if err := r.migrate(ctx); err != nil {
+		log.Printf("Error migrating schema: %v", err)
+		return false, err
+	}
+	var id string
+	err := r.executor(ctx).QueryRowContext(ctx, insertQuery,
+		currencyInfo.GetEntityID(),
+		currencyInfo.Code,
+		currencyInfo.CodeIn,
+		currencyInfo.Name,
+		currencyInfo.High,
+		currencyInfo.Low,
+		currencyInfo.VarBid,
+		currencyInfo.PctChange,
+		currencyInfo.Bid,
+		currencyInfo.Ask,
+		time.Unix(currencyInfo.Timestamp, 0).UTC(),
+		currencyInfo.CreateDate.UTC(),
+	).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		log.Printf("Error inserting exchange rate: %v", err)
+		return false, mapError("insert", err)
+	}
+	return true, nil
+}
+
+// FindAll retrieves all exchange rate entities from the table.
+func (r *ExchangeRateRepository) FindAll(ctx context.Context) ([]*entity.CurrencyInfo, error) {
+	log.Printf("Finding all exchange rates from table: exchange_rates")
+	if err := r.migrate(ctx); err != nil {
+		log.Printf("Error migrating schema: %v", err)
+		return nil, err
+	}
+	return r.query(ctx, "find all", findAllQuery)
+}
+
+// Find retrieves exchange rate entities by their code and codeIn from the table.
+func (r *ExchangeRateRepository) Find(ctx context.Context, code string, codeIn string) ([]*entity.CurrencyInfo, error) {
+	log.Printf("Finding exchange rate by code from table: exchange_rates")
+	if err := r.migrate(ctx); err != nil {
+		log.Printf("Error migrating schema: %v", err)
+		return nil, err
+	}
+	return r.query(ctx, "find", findQuery, code, codeIn)
+}
+
+// FindByID retrieves a single exchange rate entity by its ID from the table.
+// It returns entity.ErrExchangeRateNotFound when no row has the given ID.
+func (r *ExchangeRateRepository) FindByID(ctx context.Context, id string) (*entity.CurrencyInfo, error) {
+	log.Printf("Finding exchange rate by ID from table: exchange_rates")
+	if err := r.migrate(ctx); err != nil {
+		log.Printf("Error migrating schema: %v", err)
+		return nil, err
+	}
+	currencyInfo, err := scanCurrencyInfo(r.executor(ctx).QueryRowContext(ctx, findByIDQuery, id))
+	if err != nil {
+		log.Printf("Error finding exchange rate by ID: %v", err)
+		return nil, mapError("find by id", err)
+	}
+	return currencyInfo, nil
+}
+
+// FindLatest retrieves the exchange rate entity of the pair with the most recent quote from the table.
+// It returns entity.ErrExchangeRateNotFound when the pair has no quote.
+func (r *ExchangeRateRepository) FindLatest(ctx context.Context, code string, codeIn string) (*entity.CurrencyInfo, error) {
+	log.Printf("Finding latest exchange rate from table: exchange_rates")
+	if err := r.migrate(ctx); err != nil {
+		log.Printf("Error migrating schema: %v", err)
+		return nil, err
+	}
+	currencyInfo, err := scanCurrencyInfo(r.executor(ctx).QueryRowContext(ctx, findLatestQuery, code, codeIn))
+	if err != nil {
+		log.Printf("Error finding latest exchange rate: %v", err)
+		return nil, mapError("find latest", err)
+	}
+	return currencyInfo, nil
+}
+
+// FindRange retrieves the exchange rate entities of the pair quoted from <= quoted_at < to from the table,
+// ordered by quoted_at and paginated by page. A zero from or to leaves that side of the range open.
+func (r *ExchangeRateRepository) FindRange(ctx context.Context, code string, codeIn string, from time.Time, to time.Time, page entity.Page) ([]*entity.CurrencyInfo, error) {
+	log.Printf("Finding exchange rate history from table: exchange_rates")
+	if err := entity.ValidateHistoryQuery(from, to, page); err != nil {
+		return nil, err
+	}
+	if err := r.migrate(ctx); err != nil {
+		log.Printf("Error migrating schema: %v", err)
+		return nil, err
+	}
+	min, max := entity.TimestampBounds(from, to)
+	query := "SELECT " + selectColumns + " FROM exchange_rates WHERE code = ? AND code_in = ?"
+	args := []interface{}{code, codeIn}
+	// Open sides are left out: timestamptz cannot hold the bounds TimestampBounds returns for them.
+	if !from.IsZero() {
+		query += " AND quoted_at >= ?"
+		args = append(args, time.Unix(min, 0).UTC())
+	}
+	if !to.IsZero() {
+		query += " AND quoted_at < ?"
+		args = append(args, time.Unix(max, 0).UTC())
+	}
+	order := "ASC"
+	if page.Order == entity.SortDescending {
+		order = "DESC"
+	}
+	var limit interface{} = page.Limit
+	if page.Limit == 0 {
+		limit = dialect.Postgres.NoLimit()
+	}
+	query += " ORDER BY quoted_at " + order + ", id " + order + " LIMIT ? OFFSET ?"
+	args = append(args, limit, page.Offset)
+	return r.query(ctx, "find range", rebind(query), args...)
+}
+
+// CountByPair returns the number of exchange rate entities of the pair in the table.
+func (r *ExchangeRateRepository) CountByPair(ctx context.Context, code string, codeIn string) (int, error) {
+	log.Printf("Counting exchange rates from table: exchange_rates")
+	if err := r.migrate(ctx); err != nil {
+		log.Printf("Error migrating schema: %v", err)
+		return 0, err
+	}
+	var count int
+	err := r.executor(ctx).QueryRowContext(ctx, countQuery, code, codeIn).Scan(&count)
+	if err != nil {
+		log.Printf("Error counting exchange rates: %v", err)
+		return 0, mapError("count by pair", err)
+	}
+	return count, nil
+}
+
+// Delete removes a single exchange rate entity by its ID from the table.
+// It returns entity.ErrExchangeRateNotFound when no row has the given ID.
+func (r *ExchangeRateRepository) Delete(ctx context.Context, id string) error {
+	log.Printf("Deleting exchange rate by ID from table: exchange_rates")
+	if err := r.migrate(ctx); err != nil {
+		log.Printf("Error migrating schema: %v", err)
+		return err
+	}
+	var deletedID string
+	err := r.executor(ctx).QueryRowContext(ctx, deleteQuery, id).Scan(&deletedID)
+	if err != nil {
+		log.Printf("Error deleting exchange rate by ID: %v", err)
+		return mapError("delete", err)
+	}
+	return nil
+}
+
+// query runs a select over exchange_rates and scans every returned row.
+func (r *ExchangeRateRepository) query(ctx context.Context, operation string, query string, args ...interface{}) ([]*entity.CurrencyInfo, error) {
+	rows, err := r.executor(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("Error querying exchange rates: %v", err)
+		return nil, mapError(operation, err)
+	}
+	defer rows.Close()
+
+	currencyInfos := make([]*entity.CurrencyInfo, 0)
+	for rows.Next() {
+		currencyInfo, err := scanCurrencyInfo(rows)
+		if err != nil {
+			log.Printf("Error scanning exchange rate: %v", err)
+			return nil, mapError(operation, err)
+		}
+		currencyInfos = append(currencyInfos, currencyInfo)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Error iterating exchange rates: %v", err)
+		return nil, mapError(operation, err)
+	}
+	return currencyInfos, nil
+}
+
+// scanner is implemented by both *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanCurrencyInfo scans a row holding selectColumns into a CurrencyInfo.
+func scanCurrencyInfo(row scanner) (*entity.CurrencyInfo, error) {
+	var currencyInfo entity.CurrencyInfo
+	var quotedAt, createDate timestamptz
+	err := row.Scan(
+		&currencyInfo.ID,
+		&currencyInfo.Code,
+		&currencyInfo.CodeIn,
+		&currencyInfo.Name,
+		&currencyInfo.High,
+		&currencyInfo.Low,
+		&currencyInfo.VarBid,
+		&currencyInfo.PctChange,
+		&currencyInfo.Bid,
+		&currencyInfo.Ask,
+		&quotedAt,
+		&createDate,
+	)
+	if err != nil {
+		return nil, err
+	}
+	currencyInfo.Timestamp = quotedAt.Unix()
+	currencyInfo.CreateDate = createDate.UTC()
+	return &currencyInfo, nil
+}
+
+// timestamptzLayouts are the text forms of a timestamptz: the PostgreSQL output format and RFC 3339.
+var timestamptzLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999-07",
+	time.RFC3339Nano,
+}
+
+// timestamptz scans a timestamptz column. PostgreSQL drivers return a time.Time;
+// drivers reading the column as text return the PostgreSQL output format.
+type timestamptz struct {
+	time.Time
+}
+
+// Scan implements sql.Scanner.
+func (t *timestamptz) Scan(value interface{}) error {
+	var text string
+	switch v := value.(type) {
+	case time.Time:
+		t.Time = v
+		return nil
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into a timestamptz", value)
+	}
+	for _, layout := range timestamptzLayouts {
+		if parsed, err := time.Parse(layout, text); err == nil {
+			t.Time = parsed
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot parse %q as a timestamptz", text)
+}
+
+// mapError translates sql.ErrNoRows into entity.ErrExchangeRateNotFound and expired deadlines into an *entity.RepositoryTimeoutError.
+func mapError(operation string, err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.ErrExchangeRateNotFound
+	}
+	return entity.MapDeadlineError(operation, err)
+}
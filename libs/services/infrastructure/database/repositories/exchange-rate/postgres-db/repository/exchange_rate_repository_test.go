@@ -0,0 +1,129 @@
+package postgresrepository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	entity "libs/services/entities/exchange-rate/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type PostgresExchangeRateRepositoryTestSuite struct {
+	suite.Suite
+	db         *sql.DB
+	repository *ExchangeRateRepository
+}
+
+func TestPostgresExchangeRateRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(PostgresExchangeRateRepositoryTestSuite))
+}
+
+func (suite *PostgresExchangeRateRepositoryTestSuite) SetupTest() {
+	suite.db = openStandIn(suite.T())
+	suite.repository = NewExchangeRateRepository("test", suite.db)
+}
+
+func (suite *PostgresExchangeRateRepositoryTestSuite) TearDownTest() {
+	suite.db.Close()
+}
+
+func (suite *PostgresExchangeRateRepositoryTestSuite) TestMigrateIsIdempotent() {
+	ctx := context.Background()
+	assert.NoError(suite.T(), Migrate(ctx, suite.db))
+	assert.NoError(suite.T(), Migrate(ctx, suite.db))
+
+	var count int
+	err := suite.db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name IN ('exchange_rates', 'idx_exchange_rates_pair_quoted_at')").Scan(&count)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, count, "the table and its index")
+	assert.Len(suite.T(), Schema(), 2)
+}
+
+func (suite *PostgresExchangeRateRepositoryTestSuite) TestQueriesUsePostgresPlaceholders() {
+	for _, query := range []string{upsertQuery, insertQuery, findQuery, findByIDQuery, findLatestQuery, countQuery, deleteQuery} {
+		assert.NotContains(suite.T(), query, "?")
+		assert.Contains(suite.T(), query, "$1")
+	}
+	assert.True(suite.T(), strings.HasPrefix(upsertQuery, "INSERT INTO exchange_rates"))
+	assert.Contains(suite.T(), upsertQuery, "$12) ON CONFLICT(id) DO UPDATE SET")
+}
+
+func (suite *PostgresExchangeRateRepositoryTestSuite) TestSaveStoresTimestamptz() {
+	currencyInfo, err := entity.NewExchangeRate("USD", "BRL", "Dollar", "5.5", "5.4", "0.05", "0.01", "5.45", "5.46", "1626889200", "2021-07-21 00:00:00")
+	suite.Require().NoError(err)
+
+	assert.NoError(suite.T(), suite.repository.Save(context.Background(), currencyInfo))
+
+	var quotedAt timestamptz
+	err = suite.db.QueryRow("SELECT quoted_at FROM exchange_rates WHERE id = $1", currencyInfo.GetEntityID()).Scan(&quotedAt)
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), time.Unix(1626889200, 0).Equal(quotedAt.Time), "unexpected quoted_at: %v", quotedAt.Time)
+}
+
+func (suite *PostgresExchangeRateRepositoryTestSuite) TestInsertReportsWhetherItStored() {
+	ctx := context.Background()
+	currencyInfo, err := entity.NewExchangeRate("USD", "BRL", "Dollar", "5.5", "5.4", "0.05", "0.01", "5.45", "5.46", "1626889200", "2021-07-21 00:00:00")
+	suite.Require().NoError(err)
+
+	inserted, err := suite.repository.Insert(ctx, currencyInfo)
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), inserted)
+
+	inserted, err = suite.repository.Insert(ctx, currencyInfo)
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), inserted, "the quote is already stored")
+}
+
+func (suite *PostgresExchangeRateRepositoryTestSuite) TestSaveKeepsExactPrices() {
+	ctx := context.Background()
+	currencyInfo, err := entity.NewExchangeRate("USD", "BRL", "Dollar", "5.4571", "5.4402", "0.0063", "0.12", "5.4563", "5.45640001", "1626889200", "2021-07-21 00:00:00")
+	suite.Require().NoError(err)
+
+	assert.NoError(suite.T(), suite.repository.Save(ctx, currencyInfo))
+
+	found, err := suite.repository.FindByID(ctx, currencyInfo.GetEntityID())
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), entity.MustParseDecimal("5.4563"), found.Bid)
+	assert.Equal(suite.T(), entity.MustParseDecimal("5.45640001"), found.Ask)
+	assert.Equal(suite.T(), entity.MustParseDecimal("0.0063"), found.VarBid)
+}
+
+func (suite *PostgresExchangeRateRepositoryTestSuite) TestNestedDoJoinsTransaction() {
+	ctx := context.Background()
+	currencyInfo, err := entity.NewExchangeRate("USD", "BRL", "Dollar", "5.5", "5.4", "0.05", "0.01", "5.45", "5.46", "1626889200", "2021-07-21 00:00:00")
+	suite.Require().NoError(err)
+
+	err = suite.repository.Do(ctx, func(ctx context.Context) error {
+		return suite.repository.Do(ctx, func(ctx context.Context) error {
+			return suite.repository.Save(ctx, currencyInfo)
+		})
+	})
+
+	assert.NoError(suite.T(), err)
+	count, err := suite.repository.CountByPair(ctx, "USD", "BRL")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, count)
+}
+
+func TestTimestamptzScan(t *testing.T) {
+	expected := time.Date(2021, 7, 21, 17, 40, 0, 0, time.UTC)
+	for _, value := range []interface{}{
+		expected,
+		"2021-07-21 17:40:00+00:00",
+		"2021-07-21 14:40:00-03",
+		[]byte("2021-07-21T17:40:00Z"),
+	} {
+		var scanned timestamptz
+		assert.NoError(t, scanned.Scan(value))
+		assert.True(t, expected.Equal(scanned.Time), "unexpected time for %v: %v", value, scanned.Time)
+	}
+
+	var scanned timestamptz
+	assert.Error(t, scanned.Scan("yesterday"))
+	assert.Error(t, scanned.Scan(int64(1626889200)))
+}
@@ -0,0 +1,32 @@
+package postgresrepository
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"strings"
+)
+
+//go:embed schema.sql
+var schema string
+
+// Schema returns the statements creating the exchange_rates table and its indexes. They are idempotent.
+func Schema() []string {
+	statements := make([]string, 0)
+	for _, statement := range strings.Split(schema, ";") {
+		if statement = strings.TrimSpace(statement); statement != "" {
+			statements = append(statements, statement)
+		}
+	}
+	return statements
+}
+
+// Migrate creates the exchange_rates table and its indexes when they do not exist. Call it once at startup.
+func Migrate(ctx context.Context, db *sql.DB) error {
+	for _, statement := range Schema() {
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
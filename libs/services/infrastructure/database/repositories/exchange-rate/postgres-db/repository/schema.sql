@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id TEXT PRIMARY KEY,
+    code TEXT NOT NULL,
+    code_in TEXT NOT NULL,
+    name TEXT NOT NULL,
+    high NUMERIC(20, 8) NOT NULL,
+    low NUMERIC(20, 8) NOT NULL,
+    var_bid NUMERIC(20, 8) NOT NULL,
+    pct_change DOUBLE PRECISION NOT NULL,
+    bid NUMERIC(20, 8) NOT NULL,
+    ask NUMERIC(20, 8) NOT NULL,
+    quoted_at TIMESTAMPTZ NOT NULL,
+    create_date TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_exchange_rates_pair_quoted_at ON exchange_rates (code, code_in, quoted_at);
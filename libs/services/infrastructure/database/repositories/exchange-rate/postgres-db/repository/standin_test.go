@@ -0,0 +1,21 @@
+package postgresrepository
+
+import (
+	"database/sql"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+)
+
+// openStandIn opens an in-memory SQLite database standing in for PostgreSQL, so the tests need no server.
+// SQLite accepts the $n placeholders, ON CONFLICT, RETURNING and the column types of schema.sql, so the
+// repository runs its PostgreSQL queries unchanged. It returns timestamptz columns as text, which
+// exercises the text path of timestamptz.Scan.
+func openStandIn(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	// Every connection to :memory: opens a new database.
+	db.SetMaxOpenConns(1)
+	return db
+}
@@ -0,0 +1,3 @@
+module libs/services/infrastructure/providers/exchange-rate
+
+go 1.22
@@ -0,0 +1,67 @@
+package exchangerateprovider
+
+import (
+	"context"
+	"libs/external-clients/economia-awesome-api/client"
+	entity "libs/services/entities/exchange-rate/entity"
+	"strings"
+)
+
+// AwesomeAPIProviderName is the Name of AwesomeAPIProvider.
+const AwesomeAPIProviderName = "economia-awesome-api"
+
+// AwesomeAPIProvider adapts the Economia Awesome API client to entity.ExchangeRateProvider.
+// All pairs are fetched in one request.
+type AwesomeAPIProvider struct {
+	client *client.Client
+}
+
+// NewAwesomeAPIProvider creates and returns a new AwesomeAPIProvider instance.
+func NewAwesomeAPIProvider(client *client.Client) *AwesomeAPIProvider {
+	return &AwesomeAPIProvider{client: client}
+}
+
+// Name returns AwesomeAPIProviderName.
+func (p *AwesomeAPIProvider) Name() string {
+	return AwesomeAPIProviderName
+}
+
+// GetExchangeRates fetches the pairs, such as "USD-BRL,EUR-BRL", and returns the quotes of the requested pairs.
+func (p *AwesomeAPIProvider) GetExchangeRates(ctx context.Context, pairs []entity.CurrencyPair) ([]entity.RawQuote, error) {
+	requested := make(map[string]bool, len(pairs))
+	names := make([]string, 0, len(pairs))
+	for _, pair := range pairs {
+		if !requested[pair.String()] {
+			requested[pair.String()] = true
+			names = append(names, pair.String())
+		}
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	result, err := p.client.GetExchangeRateContext(ctx, strings.Join(names, ","))
+	if err != nil {
+		return nil, err
+	}
+	quotes := make([]entity.RawQuote, 0, len(result))
+	for _, rate := range result {
+		if !requested[strings.ToUpper(rate.Code+"-"+rate.CodeIn)] {
+			continue
+		}
+		quotes = append(quotes, entity.RawQuote{
+			Code:       rate.Code,
+			CodeIn:     rate.CodeIn,
+			Name:       rate.Name,
+			High:       rate.High,
+			Low:        rate.Low,
+			VarBid:     rate.VarBid,
+			PctChange:  rate.PctChange,
+			Bid:        rate.Bid,
+			Ask:        rate.Ask,
+			Timestamp:  rate.Timestamp,
+			CreateDate: rate.CreateDate,
+		})
+	}
+	return quotes, nil
+}
@@ -0,0 +1,100 @@
+package exchangerateprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"libs/external-clients/ecb-reference-rates/client"
+	entity "libs/services/entities/exchange-rate/entity"
+	"log"
+	"strconv"
+	"time"
+)
+
+// ECBProviderName is the Name of ECBProvider.
+const ECBProviderName = "ecb"
+
+// ErrNoReferenceRates is returned when the ECB document holds no reference day.
+var ErrNoReferenceRates = errors.New("ecb reference rates have no day")
+
+// ecbBaseCurrency is the currency the ECB reference rates are quoted against.
+const ecbBaseCurrency = "EUR"
+
+// ECBProvider adapts the daily euro reference rates of the European Central Bank to entity.ExchangeRateProvider.
+// Pairs without the euro are crossed through it. Reference rates are mid rates fixed once a day, so the quotes have
+// the same high, low, bid and ask, no variation, and the Timestamp of the start of the reference day, UTC.
+type ECBProvider struct {
+	client *client.Client
+}
+
+// NewECBProvider creates and returns a new ECBProvider instance.
+func NewECBProvider(client *client.Client) *ECBProvider {
+	return &ECBProvider{client: client}
+}
+
+// Name returns ECBProviderName.
+func (p *ECBProvider) Name() string {
+	return ECBProviderName
+}
+
+// GetExchangeRates fetches the reference rates of the last working day and returns the quotes of the requested pairs
+// whose currencies the ECB publishes.
+func (p *ECBProvider) GetExchangeRates(ctx context.Context, pairs []entity.CurrencyPair) ([]entity.RawQuote, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	envelope, err := p.client.GetDailyRatesContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(envelope.Days) == 0 {
+		return nil, ErrNoReferenceRates
+	}
+	day := envelope.Days[0]
+	referenceDay, err := time.Parse("2006-01-02", day.Time)
+	if err != nil {
+		return nil, fmt.Errorf("ecb reference rates have an invalid day: %w", err)
+	}
+
+	euros := map[string]entity.Decimal{ecbBaseCurrency: entity.DecimalFromInt(1)}
+	for _, rate := range day.Rates {
+		value, err := entity.ParseDecimal(rate.Rate)
+		if err != nil || value.Sign() <= 0 {
+			log.Printf("Ignoring ECB reference rate %s=%q", rate.Currency, rate.Rate)
+			continue
+		}
+		euros[rate.Currency] = value
+	}
+
+	timestamp := strconv.FormatInt(referenceDay.Unix(), 10)
+	createDate := referenceDay.Format("2006-01-02 15:04:05")
+	quotes := make([]entity.RawQuote, 0, len(pairs))
+	for _, pair := range pairs {
+		base, ok := euros[pair.Code()]
+		if !ok {
+			continue
+		}
+		quote, ok := euros[pair.CodeIn()]
+		if !ok {
+			continue
+		}
+		price, err := quote.Div(base, entity.RoundHalfEven)
+		if err != nil {
+			return nil, err
+		}
+		quotes = append(quotes, entity.RawQuote{
+			Code:       pair.Code(),
+			CodeIn:     pair.CodeIn(),
+			Name:       pair.Base().Name(entity.LocaleEnglish) + "/" + pair.Quote().Name(entity.LocaleEnglish),
+			High:       price.String(),
+			Low:        price.String(),
+			VarBid:     "0",
+			PctChange:  "0",
+			Bid:        price.String(),
+			Ask:        price.String(),
+			Timestamp:  timestamp,
+			CreateDate: createDate,
+		})
+	}
+	return quotes, nil
+}
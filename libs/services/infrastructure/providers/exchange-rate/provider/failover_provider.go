@@ -0,0 +1,244 @@
+package exchangerateprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	entity "libs/services/entities/exchange-rate/entity"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNoProviders is returned by a FailoverProvider without providers.
+	ErrNoProviders = errors.New("no exchange rate providers")
+	// ErrAllProvidersFailed is returned, joined with the error of each provider, when every provider fails.
+	ErrAllProvidersFailed = errors.New("every exchange rate provider failed")
+	// ErrQuorumNotReached is returned when providers answered but not enough of them agree on any requested pair.
+	ErrQuorumNotReached = errors.New("exchange rate providers do not agree")
+)
+
+const (
+	// DefaultFailureThreshold is the number of consecutive failures after which a provider is unhealthy.
+	DefaultFailureThreshold = 3
+	// DefaultCooldown is the time an unhealthy provider is skipped before it is tried again.
+	DefaultCooldown = 30 * time.Second
+)
+
+// DefaultTolerance is the relative difference between bids under which providers agree on a quote: 0.5%.
+var DefaultTolerance = entity.MustParseDecimal("0.005")
+
+// FailoverOptions configures a FailoverProvider. Zero fields take their default.
+type FailoverOptions struct {
+	// FailureThreshold is the number of consecutive failures after which a provider is unhealthy,
+	// DefaultFailureThreshold by default.
+	FailureThreshold int
+	// Cooldown is the time an unhealthy provider is skipped before it is tried again, DefaultCooldown by default.
+	Cooldown time.Duration
+	// Quorum is the number of providers that must agree on the quote of a pair, 1 by default.
+	Quorum int
+	// Tolerance is the relative difference between bids under which two quotes agree, DefaultTolerance by default.
+	Tolerance entity.Decimal
+}
+
+// ProviderHealth reports the health of a provider of a FailoverProvider.
+type ProviderHealth struct {
+	Name                string    `json:"name"`
+	Healthy             bool      `json:"healthy"`
+	ConsecutiveFailures int       `json:"consecutiveFailures"`
+	LastError           string    `json:"lastError,omitempty"`
+	LastSuccess         time.Time `json:"lastSuccess,omitempty"`
+	// RetryAt is when an unhealthy provider is tried again.
+	RetryAt time.Time `json:"retryAt,omitempty"`
+}
+
+// FailoverProvider is an entity.ExchangeRateProvider that asks its providers in priority order.
+// A pair the first provider does not quote is asked to the next one, until Quorum providers quote every pair.
+// A provider failing FailureThreshold times in a row is unhealthy and only tried again after Cooldown, unless
+// every provider is unhealthy. With a Quorum above 1, the quote of the first provider is returned only when Quorum
+// providers agree on it within Tolerance; pairs without a quorum are left out.
+type FailoverProvider struct {
+	providers []entity.ExchangeRateProvider
+	options   FailoverOptions
+	mu        sync.Mutex
+	health    []ProviderHealth
+	now       func() time.Time
+}
+
+// NewFailoverProvider creates and returns a new FailoverProvider asking providers in the given order.
+func NewFailoverProvider(options FailoverOptions, providers ...entity.ExchangeRateProvider) *FailoverProvider {
+	if options.FailureThreshold <= 0 {
+		options.FailureThreshold = DefaultFailureThreshold
+	}
+	if options.Cooldown <= 0 {
+		options.Cooldown = DefaultCooldown
+	}
+	if options.Quorum <= 0 {
+		options.Quorum = 1
+	}
+	if options.Tolerance.Sign() <= 0 {
+		options.Tolerance = DefaultTolerance
+	}
+	health := make([]ProviderHealth, len(providers))
+	for i, provider := range providers {
+		health[i] = ProviderHealth{Name: provider.Name(), Healthy: true}
+	}
+	return &FailoverProvider{
+		providers: providers,
+		options:   options,
+		health:    health,
+		now:       time.Now,
+	}
+}
+
+// Name returns the names of the providers, such as "failover(economia-awesome-api,ecb)".
+func (p *FailoverProvider) Name() string {
+	names := make([]string, len(p.providers))
+	for i, provider := range p.providers {
+		names[i] = provider.Name()
+	}
+	return "failover(" + strings.Join(names, ",") + ")"
+}
+
+// Health returns the health of each provider, in priority order.
+func (p *FailoverProvider) Health() []ProviderHealth {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	health := make([]ProviderHealth, len(p.health))
+	copy(health, p.health)
+	return health
+}
+
+// GetExchangeRates asks the providers for the pairs until Quorum of them quote each pair.
+// It returns ErrAllProvidersFailed when every provider asked fails, and ErrQuorumNotReached when providers
+// answered but no pair reached the quorum.
+func (p *FailoverProvider) GetExchangeRates(ctx context.Context, pairs []entity.CurrencyPair) ([]entity.RawQuote, error) {
+	if len(p.providers) == 0 {
+		return nil, ErrNoProviders
+	}
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+
+	quotesByPair := make(map[string][]entity.RawQuote, len(pairs))
+	var errs []error
+	answered := false
+	for _, i := range p.order() {
+		missing := p.missing(pairs, quotesByPair)
+		if len(missing) == 0 {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		provider := p.providers[i]
+		quotes, err := provider.GetExchangeRates(ctx, missing)
+		if err != nil {
+			log.Printf("Exchange rate provider %s failed: %v", provider.Name(), err)
+			p.recordFailure(i, err)
+			errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+			continue
+		}
+		p.recordSuccess(i)
+		answered = true
+		for _, quote := range quotes {
+			key := strings.ToUpper(quote.Code + "-" + quote.CodeIn)
+			quotesByPair[key] = append(quotesByPair[key], quote)
+		}
+	}
+	if !answered {
+		return nil, errors.Join(append([]error{ErrAllProvidersFailed}, errs...)...)
+	}
+
+	result := make([]entity.RawQuote, 0, len(pairs))
+	disagreed := false
+	for _, pair := range pairs {
+		quotes := quotesByPair[pair.String()]
+		if len(quotes) == 0 {
+			continue
+		}
+		if p.agreeing(quotes) < p.options.Quorum {
+			log.Printf("Exchange rate providers do not agree on %s", pair)
+			disagreed = true
+			continue
+		}
+		result = append(result, quotes[0])
+		delete(quotesByPair, pair.String())
+	}
+	if len(result) == 0 && disagreed {
+		return nil, ErrQuorumNotReached
+	}
+	return result, nil
+}
+
+// order returns the indexes of the providers to ask: the healthy ones in priority order, then the unhealthy ones
+// when none is healthy.
+func (p *FailoverProvider) order() []int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	var healthy, unhealthy []int
+	for i, health := range p.health {
+		if health.Healthy || !now.Before(health.RetryAt) {
+			healthy = append(healthy, i)
+		} else {
+			unhealthy = append(unhealthy, i)
+		}
+	}
+	if len(healthy) == 0 {
+		return unhealthy
+	}
+	return healthy
+}
+
+// missing returns the pairs quoted by fewer than Quorum providers.
+func (p *FailoverProvider) missing(pairs []entity.CurrencyPair, quotesByPair map[string][]entity.RawQuote) []entity.CurrencyPair {
+	var missing []entity.CurrencyPair
+	seen := make(map[string]bool, len(pairs))
+	for _, pair := range pairs {
+		if !seen[pair.String()] && len(quotesByPair[pair.String()]) < p.options.Quorum {
+			missing = append(missing, pair)
+		}
+		seen[pair.String()] = true
+	}
+	return missing
+}
+
+// agreeing returns the number of quotes whose bid is within Tolerance of the bid of the first quote, including it.
+func (p *FailoverProvider) agreeing(quotes []entity.RawQuote) int {
+	reference, err := entity.ParseDecimal(quotes[0].Bid)
+	if err != nil {
+		return 0
+	}
+	allowed := reference.Abs().Mul(p.options.Tolerance, entity.RoundHalfEven)
+	agreeing := 0
+	for _, quote := range quotes {
+		bid, err := entity.ParseDecimal(quote.Bid)
+		if err == nil && bid.Sub(reference).Abs().Cmp(allowed) <= 0 {
+			agreeing++
+		}
+	}
+	return agreeing
+}
+
+// recordSuccess marks the provider healthy.
+func (p *FailoverProvider) recordSuccess(i int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.health[i] = ProviderHealth{Name: p.health[i].Name, Healthy: true, LastSuccess: p.now()}
+}
+
+// recordFailure counts a failure of the provider and marks it unhealthy for Cooldown once it reaches FailureThreshold.
+func (p *FailoverProvider) recordFailure(i int, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	health := &p.health[i]
+	health.ConsecutiveFailures++
+	health.LastError = err.Error()
+	if health.ConsecutiveFailures >= p.options.FailureThreshold {
+		health.Healthy = false
+		health.RetryAt = p.now().Add(p.options.Cooldown)
+	}
+}
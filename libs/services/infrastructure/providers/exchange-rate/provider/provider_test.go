@@ -0,0 +1,256 @@
+package exchangerateprovider
+
+import (
+	"context"
+	"errors"
+	ecbClient "libs/external-clients/ecb-reference-rates/client"
+	awesomeClient "libs/external-clients/economia-awesome-api/client"
+	entity "libs/services/entities/exchange-rate/entity"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+const awesomeResponse = `{
+	"USDBRL": {"code": "USD", "codein": "BRL", "name": "Dólar Americano/Real Brasileiro", "high": "5.5", "low": "5.4",
+		"varBid": "0.05", "pctChange": "0.01", "bid": "5.45", "ask": "5.46", "timestamp": "1626889200", "create_date": "2021-07-21 17:40:00"},
+	"EURBRL": {"code": "EUR", "codein": "BRL", "name": "Euro/Real Brasileiro", "high": "6.1", "low": "6.0",
+		"varBid": "0.05", "pctChange": "0.01", "bid": "6.01", "ask": "6.02", "timestamp": "1626889200", "create_date": "2021-07-21 17:40:00"}
+}`
+
+const ecbResponse = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<gesmes:Sender><gesmes:name>European Central Bank</gesmes:name></gesmes:Sender>
+	<Cube>
+		<Cube time="2021-07-21">
+			<Cube currency="USD" rate="1.18"/>
+			<Cube currency="BRL" rate="6.136"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+// standIn serves body with the content type, or 503 while failing is set.
+type standIn struct {
+	server   *httptest.Server
+	failing  bool
+	requests []string
+}
+
+func newStandIn(contentType, body string) *standIn {
+	s := &standIn{}
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests = append(s.requests, r.URL.Path)
+		if s.failing {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Write([]byte(body))
+	}))
+	return s
+}
+
+// stubProvider returns quotes, or err, and counts its calls.
+type stubProvider struct {
+	name   string
+	quotes []entity.RawQuote
+	err    error
+	calls  int
+}
+
+func (p *stubProvider) Name() string {
+	return p.name
+}
+
+func (p *stubProvider) GetExchangeRates(ctx context.Context, pairs []entity.CurrencyPair) ([]entity.RawQuote, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	var quotes []entity.RawQuote
+	for _, quote := range p.quotes {
+		for _, pair := range pairs {
+			if quote.Code == pair.Code() && quote.CodeIn == pair.CodeIn() {
+				quotes = append(quotes, quote)
+			}
+		}
+	}
+	return quotes, nil
+}
+
+type ProviderTestSuite struct {
+	suite.Suite
+	awesome         *standIn
+	ecb             *standIn
+	awesomeProvider *AwesomeAPIProvider
+	ecbProvider     *ECBProvider
+}
+
+func TestProviderTestSuite(t *testing.T) {
+	suite.Run(t, new(ProviderTestSuite))
+}
+
+func (suite *ProviderTestSuite) SetupTest() {
+	suite.awesome = newStandIn("application/json", awesomeResponse)
+	suite.ecb = newStandIn("text/xml", ecbResponse)
+	awesome := awesomeClient.NewClient()
+	awesome.SetBaseURL(suite.awesome.server.URL)
+	ecb := ecbClient.NewClient()
+	ecb.SetBaseURL(suite.ecb.server.URL)
+	suite.awesomeProvider = NewAwesomeAPIProvider(awesome)
+	suite.ecbProvider = NewECBProvider(ecb)
+}
+
+func (suite *ProviderTestSuite) TearDownTest() {
+	suite.awesome.server.Close()
+	suite.ecb.server.Close()
+}
+
+func (suite *ProviderTestSuite) pairs(names ...string) []entity.CurrencyPair {
+	pairs := make([]entity.CurrencyPair, len(names))
+	for i, name := range names {
+		pair, err := entity.ParseCurrencyPair(name)
+		suite.Require().NoError(err)
+		pairs[i] = pair
+	}
+	return pairs
+}
+
+// bids returns the bid of each quote by pair.
+func bids(quotes []entity.RawQuote) map[string]string {
+	result := make(map[string]string, len(quotes))
+	for _, quote := range quotes {
+		result[quote.Code+"-"+quote.CodeIn] = quote.Bid
+	}
+	return result
+}
+
+func (suite *ProviderTestSuite) TestAwesomeAPIProviderFetchesPairsInOneRequest() {
+	quotes, err := suite.awesomeProvider.GetExchangeRates(context.Background(), suite.pairs("USD-BRL", "EUR-BRL", "USD-BRL"))
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"/json/last/USD-BRL,EUR-BRL"}, suite.awesome.requests)
+	assert.Equal(suite.T(), map[string]string{"USD-BRL": "5.45", "EUR-BRL": "6.01"}, bids(quotes))
+	for _, quote := range quotes {
+		_, err := quote.ExchangeRate()
+		assert.NoError(suite.T(), err)
+	}
+}
+
+func (suite *ProviderTestSuite) TestAwesomeAPIProviderLeavesOutPairsNotRequested() {
+	quotes, err := suite.awesomeProvider.GetExchangeRates(context.Background(), suite.pairs("USD-BRL"))
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), map[string]string{"USD-BRL": "5.45"}, bids(quotes))
+}
+
+func (suite *ProviderTestSuite) TestECBProviderCrossesThroughEuro() {
+	quotes, err := suite.ecbProvider.GetExchangeRates(context.Background(), suite.pairs("EUR-BRL", "USD-BRL", "BRL-EUR", "GBP-BRL"))
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"/stats/eurofxref/eurofxref-daily.xml"}, suite.ecb.requests)
+	assert.Equal(suite.T(), map[string]string{"EUR-BRL": "6.136", "USD-BRL": "5.2", "BRL-EUR": "0.16297262"}, bids(quotes))
+
+	rate, err := quotes[1].ExchangeRate()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "US Dollar/Brazilian Real", rate.Name)
+	assert.Equal(suite.T(), rate.Bid, rate.Ask)
+	assert.Equal(suite.T(), time.Date(2021, 7, 21, 0, 0, 0, 0, time.UTC).Unix(), rate.Timestamp)
+}
+
+func (suite *ProviderTestSuite) TestFailoverUsesNextProviderWhenFirstFails() {
+	suite.awesome.failing = true
+	provider := NewFailoverProvider(FailoverOptions{}, suite.awesomeProvider, suite.ecbProvider)
+
+	quotes, err := provider.GetExchangeRates(context.Background(), suite.pairs("USD-BRL"))
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), map[string]string{"USD-BRL": "5.2"}, bids(quotes))
+	health := provider.Health()
+	assert.Equal(suite.T(), 1, health[0].ConsecutiveFailures)
+	assert.Contains(suite.T(), health[0].LastError, "503")
+	assert.True(suite.T(), health[0].Healthy, "one failure is below the threshold")
+	assert.False(suite.T(), health[1].LastSuccess.IsZero())
+}
+
+func (suite *ProviderTestSuite) TestFailoverAsksNextProviderForMissingPairs() {
+	provider := NewFailoverProvider(FailoverOptions{}, suite.awesomeProvider, suite.ecbProvider)
+
+	quotes, err := provider.GetExchangeRates(context.Background(), suite.pairs("USD-BRL", "BRL-EUR"))
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), map[string]string{"USD-BRL": "5.45", "BRL-EUR": "0.16297262"}, bids(quotes))
+}
+
+func (suite *ProviderTestSuite) TestFailoverSkipsUnhealthyProviderUntilCooldown() {
+	suite.awesome.failing = true
+	provider := NewFailoverProvider(FailoverOptions{FailureThreshold: 2, Cooldown: time.Minute}, suite.awesomeProvider, suite.ecbProvider)
+	now := time.Unix(1626889200, 0)
+	provider.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		_, err := provider.GetExchangeRates(context.Background(), suite.pairs("USD-BRL"))
+		assert.NoError(suite.T(), err)
+	}
+	assert.Len(suite.T(), suite.awesome.requests, 2, "skipped once unhealthy")
+	health := provider.Health()
+	assert.False(suite.T(), health[0].Healthy)
+	assert.Equal(suite.T(), now.Add(time.Minute), health[0].RetryAt)
+
+	suite.awesome.failing = false
+	now = now.Add(time.Minute)
+	quotes, err := provider.GetExchangeRates(context.Background(), suite.pairs("USD-BRL"))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), map[string]string{"USD-BRL": "5.45"}, bids(quotes))
+	assert.True(suite.T(), provider.Health()[0].Healthy)
+	assert.Equal(suite.T(), 0, provider.Health()[0].ConsecutiveFailures)
+}
+
+func (suite *ProviderTestSuite) TestFailoverTriesUnhealthyProvidersWhenAllAreUnhealthy() {
+	suite.awesome.failing = true
+	suite.ecb.failing = true
+	provider := NewFailoverProvider(FailoverOptions{FailureThreshold: 1}, suite.awesomeProvider, suite.ecbProvider)
+
+	_, err := provider.GetExchangeRates(context.Background(), suite.pairs("USD-BRL"))
+	assert.True(suite.T(), errors.Is(err, ErrAllProvidersFailed), "unexpected error: %v", err)
+	_, err = provider.GetExchangeRates(context.Background(), suite.pairs("USD-BRL"))
+	assert.True(suite.T(), errors.Is(err, ErrAllProvidersFailed), "unexpected error: %v", err)
+
+	assert.Len(suite.T(), suite.awesome.requests, 2)
+	assert.Len(suite.T(), suite.ecb.requests, 2)
+}
+
+func (suite *ProviderTestSuite) TestFailoverQuorum() {
+	agreeing := &stubProvider{name: "agreeing", quotes: []entity.RawQuote{
+		{Code: "USD", CodeIn: "BRL", Bid: "5.46"},
+		{Code: "EUR", CodeIn: "BRL", Bid: "6.50"},
+	}}
+	provider := NewFailoverProvider(FailoverOptions{Quorum: 2, Tolerance: entity.MustParseDecimal("0.01")}, suite.awesomeProvider, agreeing)
+
+	quotes, err := provider.GetExchangeRates(context.Background(), suite.pairs("USD-BRL", "EUR-BRL"))
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), map[string]string{"USD-BRL": "5.45"}, bids(quotes), "EUR-BRL differs by more than 1%")
+
+	_, err = provider.GetExchangeRates(context.Background(), suite.pairs("EUR-BRL"))
+	assert.Equal(suite.T(), ErrQuorumNotReached, err)
+}
+
+func (suite *ProviderTestSuite) TestFailoverStopsOnceEveryPairIsQuoted() {
+	second := &stubProvider{name: "second"}
+	provider := NewFailoverProvider(FailoverOptions{}, suite.awesomeProvider, second)
+
+	_, err := provider.GetExchangeRates(context.Background(), suite.pairs("USD-BRL"))
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, second.calls)
+	assert.Equal(suite.T(), "failover(economia-awesome-api,second)", provider.Name())
+
+	_, err = NewFailoverProvider(FailoverOptions{}).GetExchangeRates(context.Background(), suite.pairs("USD-BRL"))
+	assert.Equal(suite.T(), ErrNoProviders, err)
+}
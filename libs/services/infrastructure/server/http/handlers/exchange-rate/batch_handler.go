@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	usecase "libs/services/usecases/exchange-rate/usecases"
+	"net/http"
+	"strings"
+)
+
+// ListExchangeRates handles HTTP GET requests to /cotacoes/batch.
+// It expects the "pairs" query parameter, a comma-separated list of pairs such as "USD-BRL,EUR-BRL", fetched in one
+// call to the exchange rate provider. Pairs that fail are listed in the "failures" of the body; the request only fails
+// when the provider fails, with 502.
+func (h *WebServiceExchangeRateHandler) ListExchangeRates(w http.ResponseWriter, r *http.Request) {
+	var pairs []string
+	for _, pair := range strings.Split(r.URL.Query().Get("pairs"), ",") {
+		if pair = strings.TrimSpace(pair); pair != "" {
+			pairs = append(pairs, pair)
+		}
+	}
+	if len(pairs) == 0 {
+		http.Error(w, errors.New("missing required query parameter 'pairs'").Error(), http.StatusBadRequest)
+		return
+	}
+
+	getExchangeRates := usecase.NewGetExchangeRatesBatchUseCase(h.ExchangeRateRepository)
+	if h.ExchangeRateProvider != nil {
+		getExchangeRates.SetProvider(h.ExchangeRateProvider)
+	}
+	if h.AlertEvaluator != nil {
+		getExchangeRates.SetAlertEvaluator(h.AlertEvaluator)
+	}
+	if h.EventPublisher != nil {
+		getExchangeRates.SetEventPublisher(h.EventPublisher)
+	}
+
+	exchangeRates, err := getExchangeRates.Execute(r.Context(), pairs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(exchangeRates)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
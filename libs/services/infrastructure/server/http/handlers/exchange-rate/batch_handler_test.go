@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"libs/resources/database/in-memory/go-doc-db-client/client"
+	"libs/resources/database/in-memory/go-doc-db/database"
+	outputDTO "libs/services/acl/dtos/exchange-rate/output"
+	entity "libs/services/entities/exchange-rate/entity"
+	repository "libs/services/infrastructure/database/repositories/exchange-rate/in-memory/go-doc-db/repository"
+	usecase "libs/services/usecases/exchange-rate/usecases"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// fakeProvider answers every request with the same quotes, or fails with err.
+type fakeProvider struct {
+	quotes []entity.RawQuote
+	err    error
+}
+
+func (p *fakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *fakeProvider) GetExchangeRates(ctx context.Context, pairs []entity.CurrencyPair) ([]entity.RawQuote, error) {
+	return p.quotes, p.err
+}
+
+type BatchHandlerTestSuite struct {
+	suite.Suite
+	provider *fakeProvider
+	handler  *WebServiceExchangeRateHandler
+}
+
+func TestBatchHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(BatchHandlerTestSuite))
+}
+
+func (suite *BatchHandlerTestSuite) SetupTest() {
+	suite.provider = &fakeProvider{quotes: []entity.RawQuote{
+		{Code: "USD", CodeIn: "BRL", Name: "Dollar", High: "5.5", Low: "5.4", VarBid: "0.05", PctChange: "0.01",
+			Bid: "5.45", Ask: "5.46", Timestamp: "1626889200", CreateDate: "2021-07-21 00:00:00"},
+	}}
+	exchangeRateRepository := repository.NewExchangeRateRepository("test", client.NewClient(database.NewInMemoryDocBD("test")))
+	suite.handler = NewWebServiceExchangeRateHandler(exchangeRateRepository)
+	suite.handler.ExchangeRateProvider = suite.provider
+}
+
+func (suite *BatchHandlerTestSuite) get(target string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	suite.handler.ListExchangeRates(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+	return recorder
+}
+
+func (suite *BatchHandlerTestSuite) TestListExchangeRatesReportsFailures() {
+	recorder := suite.get("/cotacoes/batch?pairs=USD-BRL,%20EUR-BRL")
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	var exchangeRates outputDTO.BatchExchangeRatesDTO
+	assert.NoError(suite.T(), json.NewDecoder(recorder.Body).Decode(&exchangeRates))
+	assert.Contains(suite.T(), exchangeRates.Rates, "USD-BRL")
+	assert.Equal(suite.T(), map[string]string{"EUR-BRL": usecase.ErrPairNotQuoted.Error()}, exchangeRates.Failures)
+}
+
+func (suite *BatchHandlerTestSuite) TestListExchangeRatesErrors() {
+	assert.Equal(suite.T(), http.StatusBadRequest, suite.get("/cotacoes/batch").Code)
+	assert.Equal(suite.T(), http.StatusBadRequest, suite.get("/cotacoes/batch?pairs=,").Code)
+
+	suite.provider.err = errors.New("upstream unavailable")
+	assert.Equal(suite.T(), http.StatusBadGateway, suite.get("/cotacoes/batch?pairs=USD-BRL").Code)
+}
+
+func (suite *BatchHandlerTestSuite) TestListExchangeRatesEvaluatesAlertsAndPublishesEvents() {
+	evaluator := &recordingEvaluator{}
+	publisher := &recordingPublisher{}
+	suite.handler.AlertEvaluator = evaluator
+	suite.handler.EventPublisher = publisher
+
+	assert.Equal(suite.T(), http.StatusOK, suite.get("/cotacoes/batch?pairs=USD-BRL").Code)
+
+	assert.Len(suite.T(), evaluator.evaluated, 1)
+	assert.Equal(suite.T(), []string{entity.ExchangeRateFetchedEvent, entity.ExchangeRateSavedEvent}, publisher.names)
+}
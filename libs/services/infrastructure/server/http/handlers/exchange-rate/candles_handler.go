@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	entity "libs/services/entities/exchange-rate/entity"
+	usecase "libs/services/usecases/exchange-rate/usecases"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// DefaultCandleInterval is the interval of ListCandles when the request has no "interval" query parameter.
+const DefaultCandleInterval = "1h"
+
+// ListCandles handles HTTP GET requests to /cotacoes/{code}-{codein}/candles.
+// It accepts the "interval" (1m, 5m, 1h or 1d, defaulting to 1h), "from" and "to" query parameters;
+// from and to are Unix seconds or RFC 3339 times and leave the range open when missing.
+func (h *WebServiceExchangeRateHandler) ListCandles(w http.ResponseWriter, r *http.Request) {
+	code := chi.URLParam(r, "code")
+	codeIn := chi.URLParam(r, "codein")
+	if _, err := entity.NewCurrencyPair(code, codeIn); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, "Invalid currency pair", err)
+		return
+	}
+	interval := r.URL.Query().Get("interval")
+	if interval == "" {
+		interval = DefaultCandleInterval
+	}
+	from, err := parseTimeParameter(r, "from")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	to, err := parseTimeParameter(r, "to")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	getCandles := usecase.NewGetCandlesUseCase(h.ExchangeRateRepository)
+
+	candles, err := getCandles.Execute(r.Context(), code, codeIn, interval, from, to)
+	if err != nil {
+		var timeoutErr *entity.RepositoryTimeoutError
+		switch {
+		case errors.Is(err, usecase.ErrCurrencyCodesRequired),
+			errors.Is(err, entity.ErrInvalidCurrencyPair),
+			errors.Is(err, entity.ErrInvalidCandleInterval),
+			errors.Is(err, entity.ErrInvalidHistoryQuery):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.As(err, &timeoutErr):
+			http.Error(w, err.Error(), http.StatusGatewayTimeout)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(candles)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// parseTimeParameter reads a query parameter holding Unix seconds or an RFC 3339 time. A missing parameter is the zero time.
+func parseTimeParameter(r *http.Request, name string) (time.Time, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid query parameter '%s': expected Unix seconds or an RFC 3339 time", name)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"libs/resources/database/in-memory/go-doc-db-client/client"
+	"libs/resources/database/in-memory/go-doc-db/database"
+	outputDTO "libs/services/acl/dtos/exchange-rate/output"
+	entity "libs/services/entities/exchange-rate/entity"
+	repository "libs/services/infrastructure/database/repositories/exchange-rate/in-memory/go-doc-db/repository"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type CandlesHandlerTestSuite struct {
+	suite.Suite
+	router *chi.Mux
+}
+
+func TestCandlesHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(CandlesHandlerTestSuite))
+}
+
+func (suite *CandlesHandlerTestSuite) SetupTest() {
+	exchangeRateRepository := repository.NewExchangeRateRepository("test", client.NewClient(database.NewInMemoryDocBD("test")))
+	for i, bid := range []string{"5.40", "5.60"} {
+		rate, err := entity.NewExchangeRate("USD", "BRL", "USD/BRL", "5.6", "5.4", "0.05", "0.01", bid, "5.61", []string{"1626889200", "1626889230"}[i], "2021-07-21 00:00:00")
+		suite.Require().NoError(err)
+		suite.Require().NoError(exchangeRateRepository.Save(context.Background(), rate))
+	}
+	handler := NewWebServiceExchangeRateHandler(exchangeRateRepository)
+	suite.router = chi.NewRouter()
+	suite.router.Get("/cotacoes/{code}-{codein}/candles", handler.ListCandles)
+}
+
+func (suite *CandlesHandlerTestSuite) get(target string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	suite.router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+	return recorder
+}
+
+func (suite *CandlesHandlerTestSuite) TestListCandles() {
+	recorder := suite.get("/cotacoes/USD-BRL/candles?interval=1m&from=1626889200&to=2021-07-21T18:00:00Z")
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Contains(suite.T(), recorder.Body.String(), `"open":5.4,"high":5.6,"low":5.4,"close":5.6`, "prices are JSON numbers")
+	var candles outputDTO.CandlesDTO
+	assert.NoError(suite.T(), json.NewDecoder(recorder.Body).Decode(&candles))
+	assert.Equal(suite.T(), outputDTO.CandlesDTO{
+		Code:     "USD",
+		CodeIn:   "BRL",
+		Interval: "1m",
+		Candles:  []outputDTO.CandleDTO{{Timestamp: 1626889200, Open: entity.MustParseDecimal("5.40"), High: entity.MustParseDecimal("5.60"), Low: entity.MustParseDecimal("5.40"), Close: entity.MustParseDecimal("5.60"), Count: 2}},
+	}, candles)
+}
+
+func (suite *CandlesHandlerTestSuite) TestListCandlesDefaultsToHourly() {
+	recorder := suite.get("/cotacoes/usd-brl/candles")
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	var candles outputDTO.CandlesDTO
+	assert.NoError(suite.T(), json.NewDecoder(recorder.Body).Decode(&candles))
+	assert.Equal(suite.T(), "1h", candles.Interval)
+	assert.Len(suite.T(), candles.Candles, 1)
+}
+
+func (suite *CandlesHandlerTestSuite) TestListCandlesBadRequest() {
+	for _, target := range []string{
+		"/cotacoes/USD-BRL/candles?interval=2m",
+		"/cotacoes/USD-XYZ/candles",
+		"/cotacoes/BRL-BRL/candles",
+		"/cotacoes/USD-BRL/candles?from=yesterday",
+		"/cotacoes/USD-BRL/candles?from=1626889300&to=1626889200",
+	} {
+		recorder := suite.get(target)
+		assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code, target)
+	}
+}
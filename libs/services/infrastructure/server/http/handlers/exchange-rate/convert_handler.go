@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	entity "libs/services/entities/exchange-rate/entity"
+	usecase "libs/services/usecases/exchange-rate/usecases"
+	"net/http"
+)
+
+// Convert handles HTTP GET requests to /convert.
+// It expects the "from" and "to" codes of two different ISO 4217 currencies and the "amount" to convert,
+// a decimal number such as 100 or 12.50.
+func (h *WebServiceExchangeRateHandler) Convert(w http.ResponseWriter, r *http.Request) {
+	from := r.URL.Query().Get("from")
+	to := r.URL.Query().Get("to")
+	if from == "" || to == "" {
+		http.Error(w, errors.New("missing required query parameters 'from' and 'to'").Error(), http.StatusBadRequest)
+		return
+	}
+	if _, err := entity.NewCurrencyPair(from, to); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, "Invalid currency pair", err)
+		return
+	}
+	amount, err := entity.ParseDecimal(r.URL.Query().Get("amount"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid query parameter 'amount': %v", err), http.StatusBadRequest)
+		return
+	}
+
+	convertCurrency := usecase.NewConvertCurrencyUseCase(h.ExchangeRateRepository, h.ExchangeRateFetcher)
+
+	conversion, err := convertCurrency.Execute(r.Context(), from, to, amount)
+	if err != nil {
+		var timeoutErr *entity.RepositoryTimeoutError
+		switch {
+		case errors.Is(err, usecase.ErrCurrencyCodesRequired),
+			errors.Is(err, entity.ErrInvalidCurrencyPair),
+			errors.Is(err, usecase.ErrInvalidAmount),
+			errors.Is(err, entity.ErrDecimalOverflow):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, usecase.ErrConversionPathNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.As(err, &timeoutErr):
+			http.Error(w, err.Error(), http.StatusGatewayTimeout)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(conversion)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"libs/resources/database/in-memory/go-doc-db-client/client"
+	"libs/resources/database/in-memory/go-doc-db/database"
+	outputDTO "libs/services/acl/dtos/exchange-rate/output"
+	entity "libs/services/entities/exchange-rate/entity"
+	repository "libs/services/infrastructure/database/repositories/exchange-rate/in-memory/go-doc-db/repository"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ConvertHandlerTestSuite struct {
+	suite.Suite
+	handler *WebServiceExchangeRateHandler
+}
+
+func TestConvertHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(ConvertHandlerTestSuite))
+}
+
+func (suite *ConvertHandlerTestSuite) SetupTest() {
+	exchangeRateRepository := repository.NewExchangeRateRepository("test", client.NewClient(database.NewInMemoryDocBD("test")))
+	for _, quote := range []struct{ code, bid, ask string }{{"USD", "5.00", "5.10"}, {"EUR", "6.00", "6.10"}} {
+		rate, err := entity.NewExchangeRate(quote.code, "BRL", quote.code+"/BRL", quote.ask, quote.bid, "0.05", "0.01", quote.bid, quote.ask, "1626889200", "2021-07-21 00:00:00")
+		suite.Require().NoError(err)
+		suite.Require().NoError(exchangeRateRepository.Save(context.Background(), rate))
+	}
+	suite.handler = NewWebServiceExchangeRateHandler(exchangeRateRepository)
+	suite.handler.ExchangeRateFetcher = nil
+}
+
+func (suite *ConvertHandlerTestSuite) get(target string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	suite.handler.Convert(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+	return recorder
+}
+
+func (suite *ConvertHandlerTestSuite) TestConvert() {
+	recorder := suite.get("/convert?from=eur&to=usd&amount=100")
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Contains(suite.T(), recorder.Body.String(), `"result":117.64705882`, "amounts are JSON numbers")
+	var conversion outputDTO.ConversionDTO
+	assert.NoError(suite.T(), json.NewDecoder(recorder.Body).Decode(&conversion))
+	assert.Equal(suite.T(), []string{"EUR", "BRL", "USD"}, conversion.Path)
+	if assert.Len(suite.T(), conversion.Quotes, 2) {
+		assert.Equal(suite.T(), "bid", conversion.Quotes[0].Side)
+		assert.Equal(suite.T(), "ask", conversion.Quotes[1].Side)
+		assert.Equal(suite.T(), int64(1626889200), conversion.Quotes[1].Timestamp)
+	}
+}
+
+func (suite *ConvertHandlerTestSuite) TestConvertErrors() {
+	for target, status := range map[string]int{
+		"/convert?to=USD&amount=1":            http.StatusBadRequest,
+		"/convert?from=EUR&to=USD":            http.StatusBadRequest,
+		"/convert?from=EUR&to=USD&amount=abc": http.StatusBadRequest,
+		"/convert?from=EUR&to=USD&amount=-5":  http.StatusBadRequest,
+		"/convert?from=EUR&to=XYZ&amount=1":   http.StatusBadRequest,
+		"/convert?from=EUR&to=eur&amount=1":   http.StatusBadRequest,
+		"/convert?from=EUR&to=JPY&amount=1":   http.StatusNotFound,
+	} {
+		recorder := suite.get(target)
+		assert.Equal(suite.T(), status, recorder.Code, target)
+	}
+}
@@ -3,14 +3,35 @@ package handlers
 import (
 	"encoding/json"
 	"errors"
+	outputDTO "libs/services/acl/dtos/exchange-rate/output"
 	entity "libs/services/entities/exchange-rate/entity"
 	usecase "libs/services/usecases/exchange-rate/usecases"
 	"net/http"
+	"strconv"
+	"time"
 )
 
+// SourceHeader is the response header telling whether the exchange rates were fetched from the provider or served
+// from the repository, outputDTO.SourceProvider or outputDTO.SourceCache. Cached rates also set the Age header.
+const SourceHeader = "X-Exchange-Rate-Source"
+
 // WebServiceExchangeRateHandler handles HTTP requests for exchange rate operations.
 type WebServiceExchangeRateHandler struct {
 	ExchangeRateRepository entity.ExchangeRateRepositoryInterface
+	// ExchangeRateFetcher fetches the rates Convert does not find in the repository. A nil fetcher only uses the stored rates.
+	ExchangeRateFetcher usecase.ExchangeRateFetcher
+	// ExchangeRateProvider fetches the rates of ListCurrentExchangeRate and ListExchangeRates.
+	// A nil provider uses usecase.NewDefaultExchangeRateProvider.
+	ExchangeRateProvider entity.ExchangeRateProvider
+	// MaxStaleAge is the age under which ListCurrentExchangeRate serves a stored rate when the provider fails.
+	// Zero uses usecase.DefaultMaxStaleAge and a negative age disables the fallback.
+	MaxStaleAge time.Duration
+	// AlertEvaluator evaluates the alert rules of the rates ListCurrentExchangeRate and ListExchangeRates save.
+	// A nil evaluator raises no alert.
+	AlertEvaluator usecase.AlertEvaluator
+	// EventPublisher publishes the domain events of ListCurrentExchangeRate and ListExchangeRates. A nil publisher
+	// publishes none.
+	EventPublisher usecase.EventPublisher
 }
 
 // NewWebServiceExchangeRateHandler creates and returns a new WebServiceExchangeRateHandler instance.
@@ -19,11 +40,14 @@ func NewWebServiceExchangeRateHandler(
 ) *WebServiceExchangeRateHandler {
 	return &WebServiceExchangeRateHandler{
 		ExchangeRateRepository: exchangeRateRepository,
+		ExchangeRateFetcher:    usecase.NewGetExchangeRateUseCase(exchangeRateRepository),
 	}
 }
 
 // ListCurrentExchangeRate handles HTTP GET requests to list the current exchange rate.
-// It expects "code" and "code_in" query parameters, defaulting to "USD" and "BRL" respectively if not provided.
+// It expects "code" and "code_in" query parameters forming an entity.CurrencyPair, and answers 400 otherwise.
+// Quotes the provider returns that fail entity validation answer 502 with a problem details body listing the field errors.
+// When the provider fails, a stored rate younger than MaxStaleAge is served with the SourceHeader and Age headers.
 func (h *WebServiceExchangeRateHandler) ListCurrentExchangeRate(w http.ResponseWriter, r *http.Request) {
 	code := r.URL.Query().Get("code")
 	codeIn := r.URL.Query().Get("code_in")
@@ -33,17 +57,61 @@ func (h *WebServiceExchangeRateHandler) ListCurrentExchangeRate(w http.ResponseW
 		http.Error(w, errors.New("missing required query parameters 'code' and 'code_in").Error(), http.StatusBadRequest)
 		return
 	}
+	if _, err := entity.NewCurrencyPair(code, codeIn); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, "Invalid currency pair", err)
+		return
+	}
 
 	getExchangeRate := usecase.NewGetExchangeRateUseCase(h.ExchangeRateRepository)
+	if h.ExchangeRateProvider != nil {
+		getExchangeRate.SetProvider(h.ExchangeRateProvider)
+	}
+	if h.MaxStaleAge != 0 {
+		getExchangeRate.SetMaxStaleAge(h.MaxStaleAge)
+	}
+	if h.AlertEvaluator != nil {
+		getExchangeRate.SetAlertEvaluator(h.AlertEvaluator)
+	}
+	if h.EventPublisher != nil {
+		getExchangeRate.SetEventPublisher(h.EventPublisher)
+	}
 
-	exchangeRate, err := getExchangeRate.Execute(code, codeIn)
+	exchangeRate, err := getExchangeRate.Execute(r.Context(), code, codeIn)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		var timeoutErr *entity.RepositoryTimeoutError
+		switch {
+		case errors.Is(err, entity.ErrValidation):
+			writeProblem(w, r, http.StatusBadGateway, "Invalid exchange rate from the quote API", err)
+		case errors.As(err, &timeoutErr):
+			http.Error(w, err.Error(), http.StatusGatewayTimeout)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
 		return
 	}
+	writeSourceHeaders(w, exchangeRate)
 	err = json.NewEncoder(w).Encode(exchangeRate)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 }
+
+// writeSourceHeaders sets the SourceHeader of the exchange rates and, when any is cached, the Age of the oldest in seconds.
+func writeSourceHeaders(w http.ResponseWriter, exchangeRates outputDTO.ExchangeRatesDTO) {
+	if len(exchangeRates) == 0 {
+		return
+	}
+	source := outputDTO.SourceProvider
+	var age int64
+	for _, exchangeRate := range exchangeRates {
+		if exchangeRate.Source == outputDTO.SourceCache {
+			source = outputDTO.SourceCache
+			age = max(age, exchangeRate.AgeSeconds)
+		}
+	}
+	w.Header().Set(SourceHeader, source)
+	if source == outputDTO.SourceCache {
+		w.Header().Set("Age", strconv.FormatInt(age, 10))
+	}
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"libs/resources/database/in-memory/go-doc-db-client/client"
+	"libs/resources/database/in-memory/go-doc-db/database"
+	outputDTO "libs/services/acl/dtos/exchange-rate/output"
+	entity "libs/services/entities/exchange-rate/entity"
+	repository "libs/services/infrastructure/database/repositories/exchange-rate/in-memory/go-doc-db/repository"
+	goeventbus "libs/shared/go-eventbus"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ExchangeRateHandlerTestSuite struct {
+	suite.Suite
+	handler *WebServiceExchangeRateHandler
+}
+
+func TestExchangeRateHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(ExchangeRateHandlerTestSuite))
+}
+
+func (suite *ExchangeRateHandlerTestSuite) SetupTest() {
+	exchangeRateRepository := repository.NewExchangeRateRepository("test", client.NewClient(database.NewInMemoryDocBD("test")))
+	suite.handler = NewWebServiceExchangeRateHandler(exchangeRateRepository)
+}
+
+func (suite *ExchangeRateHandlerTestSuite) TestListCurrentExchangeRateBadRequest() {
+	for _, target := range []string{
+		"/cotacoes?code=USD",
+		"/cotacoes?code=USD&code_in=XYZ",
+		"/cotacoes?code=BTC&code_in=BRL",
+		"/cotacoes?code=usd&code_in=USD",
+	} {
+		recorder := httptest.NewRecorder()
+		suite.handler.ListCurrentExchangeRate(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code, target)
+	}
+
+	recorder := httptest.NewRecorder()
+	suite.handler.ListCurrentExchangeRate(recorder, httptest.NewRequest(http.MethodGet, "/cotacoes?code=USD&code_in=XYZ", nil))
+	assert.Equal(suite.T(), "application/problem+json", recorder.Header().Get("Content-Type"))
+	assert.Contains(suite.T(), recorder.Body.String(), `"title":"Invalid currency pair"`)
+}
+
+func (suite *ExchangeRateHandlerTestSuite) TestListCurrentExchangeRateServesStoredRateWhenProviderFails() {
+	timestamp := strconv.FormatInt(time.Now().Add(-2*time.Minute).Unix(), 10)
+	stored, err := entity.NewExchangeRate("USD", "BRL", "Dollar", "5.5", "5.4", "0.05", "0.01", "5.45", "5.46", timestamp, "2021-07-21 00:00:00")
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.handler.ExchangeRateRepository.Save(context.Background(), stored))
+	suite.handler.ExchangeRateProvider = &fakeProvider{err: errors.New("upstream timeout")}
+
+	recorder := httptest.NewRecorder()
+	suite.handler.ListCurrentExchangeRate(recorder, httptest.NewRequest(http.MethodGet, "/cotacoes?code=USD&code_in=BRL", nil))
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Equal(suite.T(), outputDTO.SourceCache, recorder.Header().Get(SourceHeader))
+	age, err := strconv.Atoi(recorder.Header().Get("Age"))
+	assert.NoError(suite.T(), err)
+	assert.InDelta(suite.T(), 120, age, 5)
+	var exchangeRates outputDTO.ExchangeRatesDTO
+	assert.NoError(suite.T(), json.NewDecoder(recorder.Body).Decode(&exchangeRates))
+	assert.Equal(suite.T(), outputDTO.SourceCache, exchangeRates["USD-BRL"].Source)
+	assert.Equal(suite.T(), int64(age), exchangeRates["USD-BRL"].AgeSeconds)
+
+	suite.handler.MaxStaleAge = time.Minute
+	recorder = httptest.NewRecorder()
+	suite.handler.ListCurrentExchangeRate(recorder, httptest.NewRequest(http.MethodGet, "/cotacoes?code=USD&code_in=BRL", nil))
+	assert.Equal(suite.T(), http.StatusInternalServerError, recorder.Code)
+	assert.Empty(suite.T(), recorder.Header().Get(SourceHeader))
+}
+
+func (suite *ExchangeRateHandlerTestSuite) TestListCurrentExchangeRateMarksFetchedRates() {
+	suite.handler.ExchangeRateProvider = &fakeProvider{quotes: []entity.RawQuote{
+		{Code: "USD", CodeIn: "BRL", Name: "Dollar", High: "5.5", Low: "5.4", VarBid: "0.05", PctChange: "0.01",
+			Bid: "5.45", Ask: "5.46", Timestamp: "1626889200", CreateDate: "2021-07-21 00:00:00"},
+	}}
+
+	recorder := httptest.NewRecorder()
+	suite.handler.ListCurrentExchangeRate(recorder, httptest.NewRequest(http.MethodGet, "/cotacoes?code=USD&code_in=BRL", nil))
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Equal(suite.T(), outputDTO.SourceProvider, recorder.Header().Get(SourceHeader))
+	assert.Empty(suite.T(), recorder.Header().Get("Age"))
+}
+
+// recordingEvaluator records the exchange rates it evaluates.
+type recordingEvaluator struct {
+	evaluated []*entity.CurrencyInfo
+}
+
+func (e *recordingEvaluator) Execute(ctx context.Context, quote *entity.CurrencyInfo) ([]entity.Alert, error) {
+	e.evaluated = append(e.evaluated, quote)
+	return nil, nil
+}
+
+func (suite *ExchangeRateHandlerTestSuite) TestListCurrentExchangeRateEvaluatesAlerts() {
+	suite.handler.ExchangeRateProvider = &fakeProvider{quotes: []entity.RawQuote{
+		{Code: "USD", CodeIn: "BRL", Name: "Dollar", High: "5.5", Low: "5.4", VarBid: "0.05", PctChange: "0.01",
+			Bid: "5.45", Ask: "5.46", Timestamp: "1626889200", CreateDate: "2021-07-21 00:00:00"},
+	}}
+	evaluator := &recordingEvaluator{}
+	suite.handler.AlertEvaluator = evaluator
+
+	recorder := httptest.NewRecorder()
+	suite.handler.ListCurrentExchangeRate(recorder, httptest.NewRequest(http.MethodGet, "/cotacoes?code=USD&code_in=BRL", nil))
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Len(suite.T(), evaluator.evaluated, 1)
+}
+
+// recordingPublisher records the names of the events it publishes.
+type recordingPublisher struct {
+	names []string
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event goeventbus.Event) error {
+	p.names = append(p.names, event.EventName())
+	return nil
+}
+
+func (suite *ExchangeRateHandlerTestSuite) TestListCurrentExchangeRatePublishesEvents() {
+	suite.handler.ExchangeRateProvider = &fakeProvider{quotes: []entity.RawQuote{
+		{Code: "USD", CodeIn: "BRL", Name: "Dollar", High: "5.5", Low: "5.4", VarBid: "0.05", PctChange: "0.01",
+			Bid: "5.45", Ask: "5.46", Timestamp: "1626889200", CreateDate: "2021-07-21 00:00:00"},
+	}}
+	publisher := &recordingPublisher{}
+	suite.handler.EventPublisher = publisher
+
+	recorder := httptest.NewRecorder()
+	suite.handler.ListCurrentExchangeRate(recorder, httptest.NewRequest(http.MethodGet, "/cotacoes?code=USD&code_in=BRL", nil))
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Equal(suite.T(), []string{entity.ExchangeRateFetchedEvent, entity.ExchangeRateSavedEvent}, publisher.names)
+}
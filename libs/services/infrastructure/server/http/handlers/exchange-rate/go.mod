@@ -1,3 +1,5 @@
 module libs/services/infrastructure/server/http/handlers/exchange-rate
 
 go 1.22
+
+require github.com/go-chi/chi/v5 v5.0.12
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	entity "libs/services/entities/exchange-rate/entity"
+	"net/http"
+)
+
+// problemContentType is the media type of an RFC 9457 problem details response.
+const problemContentType = "application/problem+json"
+
+// Problem is an RFC 9457 problem details response.
+type Problem struct {
+	Type     string              `json:"type"`
+	Title    string              `json:"title"`
+	Status   int                 `json:"status"`
+	Detail   string              `json:"detail"`
+	Instance string              `json:"instance,omitempty"`
+	Errors   []entity.FieldError `json:"errors,omitempty"`
+}
+
+// writeProblem answers with the problem details of err. The field errors of an *entity.ValidationError are listed in Errors.
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, title string, err error) {
+	problem := Problem{
+		Type:     "about:blank",
+		Title:    title,
+		Status:   status,
+		Detail:   err.Error(),
+		Instance: r.URL.Path,
+	}
+	var validationErr *entity.ValidationError
+	if errors.As(err, &validationErr) {
+		problem.Errors = validationErr.Errors
+	}
+	w.Header().Set("Content-Type", problemContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(problem)
+}
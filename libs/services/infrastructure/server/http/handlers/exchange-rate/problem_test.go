@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"encoding/json"
+	entity "libs/services/entities/exchange-rate/entity"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteProblemListsFieldErrors(t *testing.T) {
+	_, err := entity.NewExchangeRate("USD", "BRL", "Dollar", "5.5", "5.4", "0.05", "0.01", "5.45", "5.40", "1626889200", "2021-07-21 00:00:00")
+	require.Error(t, err)
+
+	recorder := httptest.NewRecorder()
+	writeProblem(recorder, httptest.NewRequest(http.MethodGet, "/cotacoes", nil), http.StatusBadGateway, "Invalid exchange rate from the quote API", err)
+
+	assert.Equal(t, http.StatusBadGateway, recorder.Code)
+	assert.Equal(t, "application/problem+json", recorder.Header().Get("Content-Type"))
+	var problem map[string]interface{}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+	assert.Equal(t, "about:blank", problem["type"])
+	assert.Equal(t, float64(http.StatusBadGateway), problem["status"])
+	assert.Equal(t, "/cotacoes", problem["instance"])
+	assert.Equal(t, []interface{}{map[string]interface{}{
+		"field":   "ask",
+		"value":   "5.40",
+		"rule":    entity.RuleAskNotBelowBid,
+		"message": "must not be less than bid 5.45",
+	}}, problem["errors"])
+}
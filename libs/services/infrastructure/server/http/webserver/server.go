@@ -1,6 +1,8 @@
 package webserver
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"time"
 
@@ -10,15 +12,18 @@ import (
 
 // Server represents an HTTP server with a router and address.
 type Server struct {
-	router *chi.Mux
-	addr   string
+	router     *chi.Mux
+	addr       string
+	httpServer *http.Server
 }
 
 // NewWebServer creates and returns a new Server instance with the specified address.
 func NewWebServer(addr string) *Server {
+	router := chi.NewRouter()
 	return &Server{
-		router: chi.NewRouter(),
-		addr:   addr,
+		router:     router,
+		addr:       addr,
+		httpServer: &http.Server{Addr: addr, Handler: router},
 	}
 }
 
@@ -56,7 +61,16 @@ func (s *Server) RegisterRouteGroup(prefix string, routes func(r chi.Router)) {
 	s.router.Route(prefix, routes)
 }
 
-// Start runs the web server on the specified address.
+// Start runs the web server on the specified address. It returns nil once Shutdown stops it.
 func (s *Server) Start() error {
-	return http.ListenAndServe(s.addr, s.router)
+	err := s.httpServer.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Shutdown stops accepting connections and waits until the running requests finish or ctx is done.
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.httpServer.Shutdown(ctx)
 }
@@ -1,9 +1,11 @@
 package webserver
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
@@ -74,6 +76,23 @@ func (suite *HTTPServerTestSuite) TestServerStart() {
 	// defer suite.server.Stop()
 }
 
+func (suite *HTTPServerTestSuite) TestServerShutdown() {
+	server := NewWebServer("127.0.0.1:0")
+	started := make(chan error, 1)
+	go func() {
+		started <- server.Start()
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	assert.NoError(suite.T(), server.Shutdown(context.Background()))
+	select {
+	case err := <-started:
+		assert.NoError(suite.T(), err, "a server stopped by Shutdown returns nil")
+	case <-time.After(time.Second):
+		suite.T().Fatal("Start did not return after Shutdown")
+	}
+}
+
 func (suite *HTTPServerTestSuite) TestStartHandler() {
 	suite.server.RegisterRoute("GET", "/test", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -0,0 +1,274 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	outputDTO "libs/services/acl/dtos/exchange-rate/output"
+	entity "libs/services/entities/exchange-rate/entity"
+	"log"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrInvalidAmount is returned when the amount to convert is not a positive number.
+	ErrInvalidAmount = errors.New("amount must be a positive number")
+	// ErrConversionPathNotFound is returned when no chain of exchange rates connects the two currencies.
+	ErrConversionPathNotFound = errors.New("no exchange rates connect the currencies")
+)
+
+const (
+	// SideBid marks a conversion leg that sells the base currency of the quote, at its bid.
+	SideBid = "bid"
+	// SideAsk marks a conversion leg that buys the base currency of the quote, at its ask.
+	SideAsk = "ask"
+)
+
+// DefaultPivotCurrencies are the currencies through which ConvertCurrencyUseCase fetches rates when the stored
+// ones do not connect the requested currencies.
+var DefaultPivotCurrencies = []string{"BRL", "USD", "EUR"}
+
+// ExchangeRateFetcher fetches the current exchange rates of a pair and saves them to the repository.
+// GetExchangeRateUseCase implements it.
+type ExchangeRateFetcher interface {
+	Execute(ctx context.Context, code, codeIn string) (outputDTO.ExchangeRatesDTO, error)
+}
+
+// ConvertCurrencyUseCase represents a use case for converting an amount between two currencies with the latest exchange rates.
+type ConvertCurrencyUseCase struct {
+	repository entity.ExchangeRateRepositoryInterface
+	fetcher    ExchangeRateFetcher
+	pivots     []string
+}
+
+// NewConvertCurrencyUseCase creates and returns a new ConvertCurrencyUseCase instance.
+// fetcher is used when the stored rates do not connect the currencies; a nil fetcher only uses the stored rates.
+func NewConvertCurrencyUseCase(
+	repository entity.ExchangeRateRepositoryInterface,
+	fetcher ExchangeRateFetcher,
+) *ConvertCurrencyUseCase {
+	return &ConvertCurrencyUseCase{
+		repository: repository,
+		fetcher:    fetcher,
+		pivots:     DefaultPivotCurrencies,
+	}
+}
+
+// SetPivotCurrencies changes the currencies through which rates are fetched when no direct pair is available.
+func (u *ConvertCurrencyUseCase) SetPivotCurrencies(pivots ...string) {
+	u.pivots = pivots
+}
+
+// Execute converts amount from one currency to another with the latest stored quote of each pair.
+// The currencies must form an entity.CurrencyPair, so converting a currency to itself is an error.
+// When no pair quotes both currencies, the rate is crossed through other currencies, using the path with the fewest quotes.
+// Each leg trades at the side a customer gets: selling the base currency of a quote at its bid, buying it at its ask.
+// When the stored quotes do not connect the currencies, the direct pair and the legs through the pivot currencies
+// are fetched first. Results are rounded half to even to entity.DecimalScale places after each leg.
+func (u *ConvertCurrencyUseCase) Execute(ctx context.Context, from, to string, amount entity.Decimal) (outputDTO.ConversionDTO, error) {
+	if from == "" || to == "" {
+		return outputDTO.ConversionDTO{}, ErrCurrencyCodesRequired
+	}
+	if amount.Sign() <= 0 {
+		return outputDTO.ConversionDTO{}, ErrInvalidAmount
+	}
+	pair, err := entity.NewCurrencyPair(from, to)
+	if err != nil {
+		return outputDTO.ConversionDTO{}, err
+	}
+	from, to = pair.Code(), pair.CodeIn()
+	log.Printf("Converting %s %s to %s", amount, from, to)
+
+	output := outputDTO.ConversionDTO{
+		From:   from,
+		To:     to,
+		Amount: amount,
+		Result: amount,
+		Rate:   entity.DecimalFromInt(1),
+		Path:   []string{from},
+		Quotes: make([]outputDTO.ConversionQuoteDTO, 0),
+	}
+	legs, err := u.findPath(ctx, from, to)
+	if err != nil {
+		return outputDTO.ConversionDTO{}, err
+	}
+	if legs == nil && u.fetcher != nil {
+		if err := u.fetch(ctx, from, to); err != nil {
+			return outputDTO.ConversionDTO{}, err
+		}
+		if legs, err = u.findPath(ctx, from, to); err != nil {
+			return outputDTO.ConversionDTO{}, err
+		}
+	}
+	if legs == nil {
+		return outputDTO.ConversionDTO{}, fmt.Errorf("%w: %s to %s", ErrConversionPathNotFound, from, to)
+	}
+
+	for _, leg := range legs {
+		if output.Result, err = leg.apply(output.Result); err != nil {
+			return outputDTO.ConversionDTO{}, err
+		}
+		if output.Rate, err = leg.apply(output.Rate); err != nil {
+			return outputDTO.ConversionDTO{}, err
+		}
+		output.Path = append(output.Path, leg.to)
+		output.Quotes = append(output.Quotes, outputDTO.ConversionQuoteDTO{
+			ID:        leg.quote.GetEntityID(),
+			Code:      leg.quote.Code,
+			CodeIn:    leg.quote.CodeIn,
+			Side:      leg.side,
+			Price:     leg.price(),
+			Timestamp: leg.quote.Timestamp,
+		})
+	}
+	return output, nil
+}
+
+// findPath returns the legs of the shortest conversion over the latest stored quotes, or nil when there is none.
+func (u *ConvertCurrencyUseCase) findPath(ctx context.Context, from, to string) ([]conversionLeg, error) {
+	quotes, err := u.repository.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return shortestConversion(latestQuotes(quotes), from, to), nil
+}
+
+// fetch asks the fetcher for the direct pair and, when it fails, for the legs of both currencies through each pivot
+// until both legs of one pivot are fetched.
+// Failed fetches are logged and skipped: the pair may not be quoted by the API. It only fails when ctx is done.
+func (u *ConvertCurrencyUseCase) fetch(ctx context.Context, from, to string) error {
+	_, err := u.fetcher.Execute(ctx, from, to)
+	if err == nil {
+		return nil
+	}
+	log.Printf("Fetching %s/%s failed: %v", from, to, err)
+	for _, pivot := range u.pivots {
+		if pivot == from || pivot == to {
+			continue
+		}
+		fetched := 0
+		for _, code := range []string{from, to} {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if _, err := u.fetcher.Execute(ctx, code, pivot); err != nil {
+				log.Printf("Fetching %s/%s failed: %v", code, pivot, err)
+				continue
+			}
+			fetched++
+		}
+		if fetched == 2 {
+			return nil
+		}
+	}
+	return ctx.Err()
+}
+
+// conversionLeg converts from one currency to another with a single quote.
+type conversionLeg struct {
+	from  string
+	to    string
+	side  string
+	quote *entity.CurrencyInfo
+}
+
+// price returns the price of the quote used by the leg.
+func (l conversionLeg) price() entity.Decimal {
+	if l.side == SideBid {
+		return l.quote.Bid
+	}
+	return l.quote.Ask
+}
+
+// apply converts value along the leg: times the bid when selling the base currency, divided by the ask when buying it.
+func (l conversionLeg) apply(value entity.Decimal) (result entity.Decimal, err error) {
+	if l.side == SideAsk {
+		return value.Div(l.quote.Ask, entity.RoundHalfEven)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			if overflow, ok := r.(error); ok && errors.Is(overflow, entity.ErrDecimalOverflow) {
+				err = overflow
+				return
+			}
+			panic(r)
+		}
+	}()
+	return value.Mul(l.quote.Bid, entity.RoundHalfEven), nil
+}
+
+// latestQuotes keeps the quote with the highest timestamp of each pair, ties broken by ID.
+func latestQuotes(quotes []*entity.CurrencyInfo) []*entity.CurrencyInfo {
+	latest := make(map[string]*entity.CurrencyInfo)
+	for _, quote := range quotes {
+		key := GenerateExchangeRateSearchKey(quote.Code, quote.CodeIn)
+		current, ok := latest[key]
+		if !ok || quote.Timestamp > current.Timestamp ||
+			(quote.Timestamp == current.Timestamp && quote.GetEntityID() > current.GetEntityID()) {
+			latest[key] = quote
+		}
+	}
+	result := make([]*entity.CurrencyInfo, 0, len(latest))
+	for _, quote := range latest {
+		result = append(result, quote)
+	}
+	return result
+}
+
+// shortestConversion finds the conversion from one currency to another with the fewest legs, breadth first.
+// A quote of A/B is a leg from A to B at its bid and a leg from B to A at its ask. Legs are tried in the order
+// of their target currency, bid first, so the result does not depend on the order of quotes. It returns nil
+// when the currencies are not connected.
+func shortestConversion(quotes []*entity.CurrencyInfo, from, to string) []conversionLeg {
+	edges := make(map[string][]conversionLeg)
+	for _, quote := range quotes {
+		code, codeIn := strings.ToUpper(quote.Code), strings.ToUpper(quote.CodeIn)
+		if code == codeIn {
+			continue
+		}
+		if quote.Bid.Sign() > 0 {
+			edges[code] = append(edges[code], conversionLeg{from: code, to: codeIn, side: SideBid, quote: quote})
+		}
+		if quote.Ask.Sign() > 0 {
+			edges[codeIn] = append(edges[codeIn], conversionLeg{from: codeIn, to: code, side: SideAsk, quote: quote})
+		}
+	}
+	for _, legs := range edges {
+		sort.Slice(legs, func(i, j int) bool {
+			if legs[i].to != legs[j].to {
+				return legs[i].to < legs[j].to
+			}
+			return legs[i].side == SideBid && legs[j].side != SideBid
+		})
+	}
+
+	previous := map[string]conversionLeg{from: {}}
+	queue := []string{from}
+	for len(queue) > 0 {
+		currency := queue[0]
+		queue = queue[1:]
+		for _, leg := range edges[currency] {
+			if _, seen := previous[leg.to]; seen {
+				continue
+			}
+			previous[leg.to] = leg
+			if leg.to == to {
+				return walkBack(previous, from, to)
+			}
+			queue = append(queue, leg.to)
+		}
+	}
+	return nil
+}
+
+// walkBack returns the legs leading from one currency to another, as recorded by shortestConversion.
+func walkBack(previous map[string]conversionLeg, from, to string) []conversionLeg {
+	var legs []conversionLeg
+	for currency := to; currency != from; {
+		leg := previous[currency]
+		legs = append([]conversionLeg{leg}, legs...)
+		currency = leg.from
+	}
+	return legs
+}
@@ -0,0 +1,198 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	outputDTO "libs/services/acl/dtos/exchange-rate/output"
+	entity "libs/services/entities/exchange-rate/entity"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// fakeFetcher saves the quotes it knows to the repository and records the pairs it was asked for.
+type fakeFetcher struct {
+	repository *fakeRepository
+	quotes     map[string]*entity.CurrencyInfo
+	requested  []string
+}
+
+func (f *fakeFetcher) Execute(ctx context.Context, code, codeIn string) (outputDTO.ExchangeRatesDTO, error) {
+	key := GenerateExchangeRateSearchKey(code, codeIn)
+	f.requested = append(f.requested, key)
+	quote, ok := f.quotes[key]
+	if !ok {
+		return nil, errors.New("pair not quoted")
+	}
+	return outputDTO.ExchangeRatesDTO{}, f.repository.Save(ctx, quote)
+}
+
+type ConvertCurrencyUseCaseTestSuite struct {
+	suite.Suite
+	repository *fakeRepository
+	fetcher    *fakeFetcher
+	useCase    *ConvertCurrencyUseCase
+}
+
+func TestConvertCurrencyUseCaseTestSuite(t *testing.T) {
+	suite.Run(t, new(ConvertCurrencyUseCaseTestSuite))
+}
+
+func (suite *ConvertCurrencyUseCaseTestSuite) SetupTest() {
+	suite.repository = &fakeRepository{}
+	suite.fetcher = &fakeFetcher{repository: suite.repository, quotes: map[string]*entity.CurrencyInfo{}}
+	suite.useCase = NewConvertCurrencyUseCase(suite.repository, suite.fetcher)
+}
+
+func (suite *ConvertCurrencyUseCaseTestSuite) quote(code, codeIn, bid, ask, timestamp string) *entity.CurrencyInfo {
+	rate, err := entity.NewExchangeRate(code, codeIn, code+"/"+codeIn, ask, bid, "0.01", "0.1", bid, ask, timestamp, "2021-07-21 00:00:00")
+	suite.Require().NoError(err)
+	return rate
+}
+
+func (suite *ConvertCurrencyUseCaseTestSuite) store(quotes ...*entity.CurrencyInfo) {
+	for _, quote := range quotes {
+		suite.Require().NoError(suite.repository.Save(context.Background(), quote))
+	}
+}
+
+func (suite *ConvertCurrencyUseCaseTestSuite) TestExecuteDirectPairSellsAtBid() {
+	quote := suite.quote("USD", "BRL", "5.40", "5.50", "1626889200")
+	suite.store(quote)
+
+	result, err := suite.useCase.Execute(context.Background(), "usd", "brl", entity.MustParseDecimal("100"))
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), entity.MustParseDecimal("540"), result.Result)
+	assert.Equal(suite.T(), entity.MustParseDecimal("5.4"), result.Rate)
+	assert.Equal(suite.T(), []string{"USD", "BRL"}, result.Path)
+	assert.Equal(suite.T(), []outputDTO.ConversionQuoteDTO{{
+		ID:        quote.GetEntityID(),
+		Code:      "USD",
+		CodeIn:    "BRL",
+		Side:      SideBid,
+		Price:     entity.MustParseDecimal("5.40"),
+		Timestamp: 1626889200,
+	}}, result.Quotes)
+	assert.Empty(suite.T(), suite.fetcher.requested)
+}
+
+func (suite *ConvertCurrencyUseCaseTestSuite) TestExecuteInversePairBuysAtAsk() {
+	suite.store(suite.quote("USD", "BRL", "5.40", "5.50", "1626889200"))
+
+	result, err := suite.useCase.Execute(context.Background(), "BRL", "USD", entity.MustParseDecimal("550"))
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), entity.MustParseDecimal("100"), result.Result)
+	assert.Equal(suite.T(), entity.MustParseDecimal("0.18181818"), result.Rate)
+	assert.Equal(suite.T(), SideAsk, result.Quotes[0].Side)
+	assert.Equal(suite.T(), entity.MustParseDecimal("5.50"), result.Quotes[0].Price)
+}
+
+func (suite *ConvertCurrencyUseCaseTestSuite) TestExecuteCrossesThroughPivot() {
+	suite.store(
+		suite.quote("EUR", "BRL", "6.00", "6.10", "1626889200"),
+		suite.quote("USD", "BRL", "5.00", "5.10", "1626889260"),
+	)
+
+	result, err := suite.useCase.Execute(context.Background(), "EUR", "USD", entity.MustParseDecimal("100"))
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"EUR", "BRL", "USD"}, result.Path)
+	// 100 EUR sold at 6.00 is 600 BRL, which buys 600 / 5.10 USD.
+	assert.Equal(suite.T(), entity.MustParseDecimal("117.64705882"), result.Result)
+	assert.Equal(suite.T(), entity.MustParseDecimal("1.17647059"), result.Rate)
+	if assert.Len(suite.T(), result.Quotes, 2) {
+		assert.Equal(suite.T(), "EUR", result.Quotes[0].Code)
+		assert.Equal(suite.T(), SideBid, result.Quotes[0].Side)
+		assert.Equal(suite.T(), int64(1626889200), result.Quotes[0].Timestamp)
+		assert.Equal(suite.T(), "USD", result.Quotes[1].Code)
+		assert.Equal(suite.T(), SideAsk, result.Quotes[1].Side)
+		assert.Equal(suite.T(), int64(1626889260), result.Quotes[1].Timestamp)
+	}
+}
+
+func (suite *ConvertCurrencyUseCaseTestSuite) TestExecutePrefersShortestPath() {
+	suite.store(
+		suite.quote("EUR", "BRL", "6.00", "6.10", "1626889200"),
+		suite.quote("BRL", "JPY", "27.00", "27.50", "1626889200"),
+		suite.quote("JPY", "USD", "0.0090", "0.0091", "1626889200"),
+		suite.quote("EUR", "USD", "1.10", "1.11", "1626889200"),
+	)
+
+	result, err := suite.useCase.Execute(context.Background(), "EUR", "USD", entity.MustParseDecimal("10"))
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"EUR", "USD"}, result.Path)
+	assert.Equal(suite.T(), entity.MustParseDecimal("11"), result.Result)
+}
+
+func (suite *ConvertCurrencyUseCaseTestSuite) TestExecuteUsesLatestQuote() {
+	suite.store(
+		suite.quote("USD", "BRL", "5.60", "5.70", "1626889260"),
+		suite.quote("USD", "BRL", "5.40", "5.50", "1626889200"),
+	)
+
+	result, err := suite.useCase.Execute(context.Background(), "USD", "BRL", entity.MustParseDecimal("1"))
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), entity.MustParseDecimal("5.6"), result.Result)
+	assert.Equal(suite.T(), int64(1626889260), result.Quotes[0].Timestamp)
+}
+
+func (suite *ConvertCurrencyUseCaseTestSuite) TestExecuteFetchesDirectPair() {
+	suite.fetcher.quotes["USD-BRL"] = suite.quote("USD", "BRL", "5.40", "5.50", "1626889200")
+
+	result, err := suite.useCase.Execute(context.Background(), "USD", "BRL", entity.MustParseDecimal("2"))
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), entity.MustParseDecimal("10.8"), result.Result)
+	assert.Equal(suite.T(), []string{"USD-BRL"}, suite.fetcher.requested)
+}
+
+func (suite *ConvertCurrencyUseCaseTestSuite) TestExecuteFetchesLegsThroughPivots() {
+	suite.fetcher.quotes["EUR-BRL"] = suite.quote("EUR", "BRL", "6.00", "6.10", "1626889200")
+	suite.fetcher.quotes["GBP-BRL"] = suite.quote("GBP", "BRL", "7.00", "7.10", "1626889200")
+
+	result, err := suite.useCase.Execute(context.Background(), "EUR", "GBP", entity.MustParseDecimal("71"))
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"EUR", "BRL", "GBP"}, result.Path)
+	assert.Equal(suite.T(), entity.MustParseDecimal("60"), result.Result)
+	assert.Equal(suite.T(), []string{"EUR-GBP", "EUR-BRL", "GBP-BRL"}, suite.fetcher.requested)
+}
+
+func (suite *ConvertCurrencyUseCaseTestSuite) TestExecuteWithoutPath() {
+	suite.store(suite.quote("USD", "BRL", "5.40", "5.50", "1626889200"))
+
+	_, err := suite.useCase.Execute(context.Background(), "EUR", "JPY", entity.MustParseDecimal("1"))
+	assert.True(suite.T(), errors.Is(err, ErrConversionPathNotFound), "unexpected error: %v", err)
+
+	storedOnly := NewConvertCurrencyUseCase(suite.repository, nil)
+	_, err = storedOnly.Execute(context.Background(), "EUR", "USD", entity.MustParseDecimal("1"))
+	assert.True(suite.T(), errors.Is(err, ErrConversionPathNotFound), "unexpected error: %v", err)
+}
+
+func (suite *ConvertCurrencyUseCaseTestSuite) TestExecuteValidatesInput() {
+	_, err := suite.useCase.Execute(context.Background(), "", "BRL", entity.MustParseDecimal("1"))
+	assert.Equal(suite.T(), ErrCurrencyCodesRequired, err)
+
+	for _, codes := range [][2]string{{"brl", "BRL"}, {"XYZ", "BRL"}} {
+		_, err = suite.useCase.Execute(context.Background(), codes[0], codes[1], entity.MustParseDecimal("1"))
+		assert.True(suite.T(), errors.Is(err, entity.ErrInvalidCurrencyPair), "%v: unexpected error: %v", codes, err)
+	}
+	assert.Empty(suite.T(), suite.fetcher.requested)
+
+	for _, amount := range []string{"0", "-1"} {
+		_, err = suite.useCase.Execute(context.Background(), "USD", "BRL", entity.MustParseDecimal(amount))
+		assert.Equal(suite.T(), ErrInvalidAmount, err)
+	}
+}
+
+func (suite *ConvertCurrencyUseCaseTestSuite) TestExecuteReportsOverflow() {
+	suite.store(suite.quote("USD", "BRL", "5.40", "5.50", "1626889200"))
+
+	_, err := suite.useCase.Execute(context.Background(), "USD", "BRL", entity.MustParseDecimal("90000000000"))
+	assert.True(suite.T(), errors.Is(err, entity.ErrDecimalOverflow), "unexpected error: %v", err)
+}
@@ -0,0 +1,102 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	entity "libs/services/entities/exchange-rate/entity"
+	"log"
+	"time"
+)
+
+// AlertEvaluator evaluates the alert rules of a saved exchange rate, such as EvaluateAlertsUseCase.
+type AlertEvaluator interface {
+	Execute(ctx context.Context, quote *entity.CurrencyInfo) ([]entity.Alert, error)
+}
+
+// EvaluateAlertsUseCase represents a use case for raising the alerts of the rules of a pair when a quote is saved.
+type EvaluateAlertsUseCase struct {
+	rules      entity.AlertRuleRepositoryInterface
+	repository entity.ExchangeRateRepositoryInterface
+	sinks      []entity.AlertSink
+}
+
+// NewEvaluateAlertsUseCase creates and returns a new EvaluateAlertsUseCase instance notifying the sinks.
+func NewEvaluateAlertsUseCase(
+	rules entity.AlertRuleRepositoryInterface,
+	repository entity.ExchangeRateRepositoryInterface,
+	sinks ...entity.AlertSink,
+) *EvaluateAlertsUseCase {
+	return &EvaluateAlertsUseCase{
+		rules:      rules,
+		repository: repository,
+		sinks:      sinks,
+	}
+}
+
+// Execute evaluates the alert rules of the pair of quote, which must already be saved, and returns the alerts raised.
+// A rule raises an alert when it holds for quote but did not for the previous stored quote of the pair.
+// Every alert is sent to every sink; the errors of the sinks are joined and returned along with the alerts.
+func (u *EvaluateAlertsUseCase) Execute(ctx context.Context, quote *entity.CurrencyInfo) ([]entity.Alert, error) {
+	rules, err := u.rules.FindAlertRules(ctx, quote.Code, quote.CodeIn)
+	if err != nil || len(rules) == 0 {
+		return nil, err
+	}
+	previous, err := u.quoteBefore(ctx, quote)
+	if err != nil {
+		return nil, err
+	}
+
+	var alerts []entity.Alert
+	var errs []error
+	for _, rule := range rules {
+		baseline, err := u.baseline(ctx, rule, quote)
+		if err != nil {
+			return alerts, err
+		}
+		if !rule.Holds(quote, baseline) {
+			continue
+		}
+		if previous != nil {
+			previousBaseline, err := u.baseline(ctx, rule, previous)
+			if err != nil {
+				return alerts, err
+			}
+			if rule.Holds(previous, previousBaseline) {
+				continue
+			}
+		}
+		alert := entity.NewAlert(*rule, quote, baseline)
+		log.Printf("Alert raised: %s", alert.Message)
+		alerts = append(alerts, alert)
+		for _, sink := range u.sinks {
+			if err := sink.Notify(ctx, alert); err != nil {
+				errs = append(errs, fmt.Errorf("notifying alert of rule %s: %w", rule.ID, err))
+			}
+		}
+	}
+	return alerts, errors.Join(errs...)
+}
+
+// quoteBefore returns the latest stored quote of the pair older than quote, or nil when there is none.
+func (u *EvaluateAlertsUseCase) quoteBefore(ctx context.Context, quote *entity.CurrencyInfo) (*entity.CurrencyInfo, error) {
+	return u.first(ctx, quote, time.Time{}, entity.SortDescending)
+}
+
+// baseline returns the oldest stored quote within the window of the rule before quote, or nil when the rule has no
+// window or the window holds no quote.
+func (u *EvaluateAlertsUseCase) baseline(ctx context.Context, rule *entity.AlertRule, quote *entity.CurrencyInfo) (*entity.CurrencyInfo, error) {
+	if rule.Kind != entity.AlertPercentChange || rule.Window == 0 {
+		return nil, nil
+	}
+	return u.first(ctx, quote, time.Unix(quote.Timestamp, 0).Add(-rule.Window), entity.SortAscending)
+}
+
+// first returns the first stored quote of the pair of quote, in order, with from <= timestamp < quote.Timestamp.
+func (u *EvaluateAlertsUseCase) first(ctx context.Context, quote *entity.CurrencyInfo, from time.Time, order entity.SortOrder) (*entity.CurrencyInfo, error) {
+	quotes, err := u.repository.FindRange(ctx, quote.Code, quote.CodeIn, from, time.Unix(quote.Timestamp, 0), entity.Page{Limit: 1, Order: order})
+	if err != nil || len(quotes) == 0 {
+		return nil, err
+	}
+	return quotes[0], nil
+}
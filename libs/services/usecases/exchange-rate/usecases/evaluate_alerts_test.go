@@ -0,0 +1,179 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	entity "libs/services/entities/exchange-rate/entity"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// fakeAlertRuleRepository returns the rules of every pair.
+type fakeAlertRuleRepository struct {
+	rules []*entity.AlertRule
+}
+
+func (r *fakeAlertRuleRepository) SaveAlertRule(ctx context.Context, rule *entity.AlertRule) error {
+	r.rules = append(r.rules, rule)
+	return nil
+}
+
+func (r *fakeAlertRuleRepository) FindAlertRules(ctx context.Context, code string, codeIn string) ([]*entity.AlertRule, error) {
+	var rules []*entity.AlertRule
+	for _, rule := range r.rules {
+		if rule.Code == code && rule.CodeIn == codeIn {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+func (r *fakeAlertRuleRepository) FindAllAlertRules(ctx context.Context) ([]*entity.AlertRule, error) {
+	return r.rules, nil
+}
+
+func (r *fakeAlertRuleRepository) DeleteAlertRule(ctx context.Context, id string) error {
+	return nil
+}
+
+// recordingSink records the alerts it is notified of and fails with err.
+type recordingSink struct {
+	alerts []entity.Alert
+	err    error
+}
+
+func (s *recordingSink) Notify(ctx context.Context, alert entity.Alert) error {
+	s.alerts = append(s.alerts, alert)
+	return s.err
+}
+
+type EvaluateAlertsUseCaseTestSuite struct {
+	suite.Suite
+	repository *fakeRepository
+	rules      *fakeAlertRuleRepository
+	sink       *recordingSink
+	useCase    *EvaluateAlertsUseCase
+}
+
+func TestEvaluateAlertsUseCaseTestSuite(t *testing.T) {
+	suite.Run(t, new(EvaluateAlertsUseCaseTestSuite))
+}
+
+func (suite *EvaluateAlertsUseCaseTestSuite) SetupTest() {
+	suite.repository = &fakeRepository{}
+	suite.rules = &fakeAlertRuleRepository{}
+	suite.sink = &recordingSink{}
+	suite.useCase = NewEvaluateAlertsUseCase(suite.rules, suite.repository, suite.sink)
+}
+
+// save stores a USD-BRL quote with the bid, minutes after 2021-07-21 17:40:00 UTC, and returns it.
+func (suite *EvaluateAlertsUseCaseTestSuite) save(minutes int, bid string) *entity.CurrencyInfo {
+	timestamp := strconv.FormatInt(1626889200+int64(minutes)*60, 10)
+	quote, err := entity.NewExchangeRate("USD", "BRL", "Dollar", bid, bid, "0", "0", bid, bid, timestamp, "2021-07-21 17:40:00")
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.repository.Save(context.Background(), quote))
+	return quote
+}
+
+func (suite *EvaluateAlertsUseCaseTestSuite) rule(rule *entity.AlertRule, err error) {
+	suite.Require().NoError(err)
+	suite.rules.rules = append(suite.rules.rules, rule)
+}
+
+func (suite *EvaluateAlertsUseCaseTestSuite) TestRaisesThresholdAlertsWhenCrossed() {
+	suite.rule(entity.NewThresholdAlertRule("USD", "BRL", entity.AlertAbove, entity.MustParseDecimal("5.5")))
+	ctx := context.Background()
+
+	alerts, err := suite.useCase.Execute(ctx, suite.save(0, "5.4"))
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), alerts)
+
+	alerts, err = suite.useCase.Execute(ctx, suite.save(1, "5.6"))
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), alerts, 1)
+	assert.Equal(suite.T(), "USD-BRL above 5.5: bid 5.6", alerts[0].Message)
+
+	alerts, err = suite.useCase.Execute(ctx, suite.save(2, "5.7"))
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), alerts, "the level is still crossed")
+	assert.Len(suite.T(), suite.sink.alerts, 1)
+}
+
+func (suite *EvaluateAlertsUseCaseTestSuite) TestRaisesAlertsForTheFirstQuote() {
+	suite.rule(entity.NewThresholdAlertRule("USD", "BRL", entity.AlertBelow, entity.MustParseDecimal("5.5")))
+
+	alerts, err := suite.useCase.Execute(context.Background(), suite.save(0, "5.4"))
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), alerts, 1)
+}
+
+func (suite *EvaluateAlertsUseCaseTestSuite) TestRaisesPercentChangeAlertsOverTheWindow() {
+	suite.rule(entity.NewPercentChangeAlertRule("USD", "BRL", 2, 30*time.Minute))
+	ctx := context.Background()
+	suite.save(0, "5.00")
+	suite.save(20, "5.05")
+
+	alerts, err := suite.useCase.Execute(ctx, suite.save(25, "5.10"))
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), alerts, 1)
+	assert.InDelta(suite.T(), 2, alerts[0].Change, 1e-9)
+
+	alerts, err = suite.useCase.Execute(ctx, suite.save(40, "5.10"))
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), alerts, "the bid barely moved since the quote 20 minutes before")
+}
+
+func (suite *EvaluateAlertsUseCaseTestSuite) TestIgnoresOtherPairs() {
+	suite.rule(entity.NewThresholdAlertRule("EUR", "BRL", entity.AlertAbove, entity.MustParseDecimal("1")))
+
+	alerts, err := suite.useCase.Execute(context.Background(), suite.save(0, "5.4"))
+
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), alerts)
+}
+
+func (suite *EvaluateAlertsUseCaseTestSuite) TestNotifiesEverySinkAndJoinsTheirErrors() {
+	failing := &recordingSink{err: errors.New("sink down")}
+	suite.useCase = NewEvaluateAlertsUseCase(suite.rules, suite.repository, failing, suite.sink)
+	suite.rule(entity.NewThresholdAlertRule("USD", "BRL", entity.AlertAbove, entity.MustParseDecimal("5.5")))
+
+	alerts, err := suite.useCase.Execute(context.Background(), suite.save(0, "5.6"))
+
+	assert.ErrorIs(suite.T(), err, failing.err)
+	assert.Len(suite.T(), alerts, 1)
+	assert.Len(suite.T(), failing.alerts, 1)
+	assert.Len(suite.T(), suite.sink.alerts, 1)
+}
+
+func (suite *EvaluateAlertsUseCaseTestSuite) TestGetExchangeRateUseCaseEvaluatesNewQuotesOnce() {
+	suite.rule(entity.NewThresholdAlertRule("USD", "BRL", entity.AlertAbove, entity.MustParseDecimal("5.4")))
+	fetcher := NewGetExchangeRateUseCase(suite.repository)
+	fetcher.SetProvider(&fakeProvider{quotes: map[string]entity.RawQuote{"USD-BRL": rawQuote("USD", "BRL", "5.45", "5.46")}})
+	fetcher.SetAlertEvaluator(suite.useCase)
+
+	for i := 0; i < 2; i++ {
+		_, err := fetcher.Execute(context.Background(), "USD", "BRL")
+		assert.NoError(suite.T(), err)
+	}
+
+	assert.Len(suite.T(), suite.sink.alerts, 1)
+}
+
+func (suite *EvaluateAlertsUseCaseTestSuite) TestGetExchangeRateUseCaseIgnoresAlertErrors() {
+	suite.rule(entity.NewThresholdAlertRule("USD", "BRL", entity.AlertAbove, entity.MustParseDecimal("5.4")))
+	suite.sink.err = errors.New("sink down")
+	fetcher := NewGetExchangeRateUseCase(suite.repository)
+	fetcher.SetProvider(&fakeProvider{quotes: map[string]entity.RawQuote{"USD-BRL": rawQuote("USD", "BRL", "5.45", "5.46")}})
+	fetcher.SetAlertEvaluator(suite.useCase)
+
+	output, err := fetcher.Execute(context.Background(), "USD", "BRL")
+
+	assert.NoError(suite.T(), err)
+	assert.Contains(suite.T(), output, "USD-BRL")
+	assert.Len(suite.T(), suite.sink.alerts, 1)
+}
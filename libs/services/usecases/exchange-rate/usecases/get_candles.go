@@ -0,0 +1,75 @@
+package usecases
+
+import (
+	"context"
+	outputDTO "libs/services/acl/dtos/exchange-rate/output"
+	entity "libs/services/entities/exchange-rate/entity"
+	"log"
+	"time"
+)
+
+// GetCandlesUseCase represents a use case for aggregating stored exchange rates into OHLC candles.
+type GetCandlesUseCase struct {
+	repository entity.ExchangeRateRepositoryInterface
+}
+
+// NewGetCandlesUseCase creates and returns a new GetCandlesUseCase instance.
+func NewGetCandlesUseCase(
+	repository entity.ExchangeRateRepositoryInterface,
+) *GetCandlesUseCase {
+	return &GetCandlesUseCase{
+		repository: repository,
+	}
+}
+
+// Execute returns the candles of the bid of the pair over the quotes with from <= timestamp < to, oldest first.
+// interval is one of 1m, 5m, 1h or 1d, and a zero from or to leaves that side of the range open.
+// Repositories implementing entity.CandleRepository aggregate in storage; the quotes of the others are aggregated in memory.
+func (u *GetCandlesUseCase) Execute(ctx context.Context, code, codeIn, interval string, from, to time.Time) (outputDTO.CandlesDTO, error) {
+	if code == "" || codeIn == "" {
+		return outputDTO.CandlesDTO{}, ErrCurrencyCodesRequired
+	}
+	candleInterval, err := entity.ParseCandleInterval(interval)
+	if err != nil {
+		return outputDTO.CandlesDTO{}, err
+	}
+	if err := entity.ValidateHistoryQuery(from, to, entity.Page{}); err != nil {
+		return outputDTO.CandlesDTO{}, err
+	}
+	pair, err := entity.NewCurrencyPair(code, codeIn)
+	if err != nil {
+		return outputDTO.CandlesDTO{}, err
+	}
+	code, codeIn = pair.Code(), pair.CodeIn()
+	log.Printf("Getting %s candles for %s/%s", candleInterval, code, codeIn)
+
+	var candles []entity.Candle
+	if candleRepository, ok := u.repository.(entity.CandleRepository); ok {
+		candles, err = candleRepository.FindCandles(ctx, code, codeIn, candleInterval, from, to)
+	} else {
+		var quotes []*entity.CurrencyInfo
+		quotes, err = u.repository.FindRange(ctx, code, codeIn, from, to, entity.Page{})
+		candles = entity.AggregateCandles(quotes, candleInterval)
+	}
+	if err != nil {
+		return outputDTO.CandlesDTO{}, err
+	}
+
+	output := outputDTO.CandlesDTO{
+		Code:     code,
+		CodeIn:   codeIn,
+		Interval: candleInterval.String(),
+		Candles:  make([]outputDTO.CandleDTO, len(candles)),
+	}
+	for i, candle := range candles {
+		output.Candles[i] = outputDTO.CandleDTO{
+			Timestamp: candle.Start,
+			Open:      candle.Open,
+			High:      candle.High,
+			Low:       candle.Low,
+			Close:     candle.Close,
+			Count:     candle.Count,
+		}
+	}
+	return output, nil
+}
@@ -0,0 +1,97 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	outputDTO "libs/services/acl/dtos/exchange-rate/output"
+	entity "libs/services/entities/exchange-rate/entity"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// fakeCandleRepository aggregates candles in "storage" and records the arguments it received.
+type fakeCandleRepository struct {
+	fakeRepository
+	interval entity.CandleInterval
+}
+
+func (r *fakeCandleRepository) FindCandles(ctx context.Context, code string, codeIn string, interval entity.CandleInterval, from time.Time, to time.Time) ([]entity.Candle, error) {
+	r.interval = interval
+	return []entity.Candle{{Start: 1626825600, Open: entity.MustParseDecimal("1"), High: entity.MustParseDecimal("2"), Low: entity.MustParseDecimal("1"), Close: entity.MustParseDecimal("2"), Count: 2}}, nil
+}
+
+type GetCandlesUseCaseTestSuite struct {
+	suite.Suite
+	repository *fakeRepository
+	useCase    *GetCandlesUseCase
+}
+
+func TestGetCandlesUseCaseTestSuite(t *testing.T) {
+	suite.Run(t, new(GetCandlesUseCaseTestSuite))
+}
+
+func (suite *GetCandlesUseCaseTestSuite) SetupTest() {
+	suite.repository = &fakeRepository{}
+	suite.useCase = NewGetCandlesUseCase(suite.repository)
+	timestamps := []int{1626889200, 1626889230, 1626889270, 1626889290}
+	for i, bid := range []string{"5.40", "5.60", "5.50", "5.30"} {
+		timestamp := strconv.Itoa(timestamps[i])
+		rate, err := entity.NewExchangeRate("USD", "BRL", "USD/BRL", "5.6", "5.3", "0.05", "0.01", bid, "5.61", timestamp, "2021-07-21 00:00:00")
+		suite.Require().NoError(err)
+		suite.Require().NoError(suite.repository.Save(context.Background(), rate))
+	}
+}
+
+func (suite *GetCandlesUseCaseTestSuite) TestExecuteAggregatesInMemory() {
+	result, err := suite.useCase.Execute(context.Background(), "usd", "brl", "1m", time.Time{}, time.Time{})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), outputDTO.CandlesDTO{
+		Code:     "USD",
+		CodeIn:   "BRL",
+		Interval: "1m",
+		Candles: []outputDTO.CandleDTO{
+			{Timestamp: 1626889200, Open: entity.MustParseDecimal("5.40"), High: entity.MustParseDecimal("5.60"), Low: entity.MustParseDecimal("5.40"), Close: entity.MustParseDecimal("5.60"), Count: 2},
+			{Timestamp: 1626889260, Open: entity.MustParseDecimal("5.50"), High: entity.MustParseDecimal("5.50"), Low: entity.MustParseDecimal("5.30"), Close: entity.MustParseDecimal("5.30"), Count: 2},
+		},
+	}, result)
+}
+
+func (suite *GetCandlesUseCaseTestSuite) TestExecuteFiltersTheRange() {
+	result, err := suite.useCase.Execute(context.Background(), "USD", "BRL", "1h", time.Unix(1626889240, 0), time.Unix(1626889300, 0))
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []outputDTO.CandleDTO{{Timestamp: 1626886800, Open: entity.MustParseDecimal("5.50"), High: entity.MustParseDecimal("5.50"), Low: entity.MustParseDecimal("5.30"), Close: entity.MustParseDecimal("5.30"), Count: 2}}, result.Candles)
+}
+
+func (suite *GetCandlesUseCaseTestSuite) TestExecuteUsesCandleRepository() {
+	repository := &fakeCandleRepository{}
+	useCase := NewGetCandlesUseCase(repository)
+
+	result, err := useCase.Execute(context.Background(), "USD", "BRL", "1d", time.Time{}, time.Time{})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), entity.CandleInterval1d, repository.interval)
+	assert.Equal(suite.T(), []outputDTO.CandleDTO{{Timestamp: 1626825600, Open: entity.MustParseDecimal("1"), High: entity.MustParseDecimal("2"), Low: entity.MustParseDecimal("1"), Close: entity.MustParseDecimal("2"), Count: 2}}, result.Candles)
+}
+
+func (suite *GetCandlesUseCaseTestSuite) TestExecuteInvalidArguments() {
+	_, err := suite.useCase.Execute(context.Background(), "", "BRL", "1m", time.Time{}, time.Time{})
+	assert.ErrorIs(suite.T(), err, ErrCurrencyCodesRequired)
+
+	_, err = suite.useCase.Execute(context.Background(), "USD", "XYZ", "1m", time.Time{}, time.Time{})
+	assert.True(suite.T(), errors.Is(err, entity.ErrUnknownCurrency), "unexpected error: %v", err)
+
+	_, err = suite.useCase.Execute(context.Background(), "USD", "usd", "1m", time.Time{}, time.Time{})
+	assert.True(suite.T(), errors.Is(err, entity.ErrIdenticalCurrencies), "unexpected error: %v", err)
+
+	_, err = suite.useCase.Execute(context.Background(), "USD", "BRL", "2m", time.Time{}, time.Time{})
+	assert.True(suite.T(), errors.Is(err, entity.ErrInvalidCandleInterval), "unexpected error: %v", err)
+
+	_, err = suite.useCase.Execute(context.Background(), "USD", "BRL", "1m", time.Unix(1626889300, 0), time.Unix(1626889200, 0))
+	assert.True(suite.T(), errors.Is(err, entity.ErrInvalidHistoryQuery), "unexpected error: %v", err)
+}
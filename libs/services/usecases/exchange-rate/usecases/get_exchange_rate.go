@@ -1,17 +1,37 @@
 package usecases
 
 import (
+	"context"
 	"errors"
 	"libs/external-clients/economia-awesome-api/client"
 	outputDTO "libs/services/acl/dtos/exchange-rate/output"
 	entity "libs/services/entities/exchange-rate/entity"
+	exchangerateprovider "libs/services/infrastructure/providers/exchange-rate/provider"
 	"log"
+	"time"
 )
 
+// ErrCurrencyCodesRequired is returned when a use case is called without both currency codes.
+var ErrCurrencyCodesRequired = errors.New("currency codes cannot be empty")
+
+const (
+	// DefaultSaveTimeout bounds the persistence of each exchange rate fetched by GetExchangeRateUseCase.
+	DefaultSaveTimeout = 10 * time.Millisecond
+	// DefaultMaxStaleAge is the age under which GetExchangeRateUseCase serves a stored exchange rate when the provider fails.
+	DefaultMaxStaleAge = 15 * time.Minute
+)
+
+// NewDefaultExchangeRateProvider returns the provider use cases fetch exchange rates with by default:
+// the Economia Awesome API.
+func NewDefaultExchangeRateProvider() entity.ExchangeRateProvider {
+	return exchangerateprovider.NewAwesomeAPIProvider(client.NewClient())
+}
+
 // GetExchangeRateUseCase represents a use case for fetching and saving exchange rates.
 type GetExchangeRateUseCase struct {
-	repository               entity.ExchangeRateRepositoryInterface
-	economiaAwesomeApiClient *client.Client
+	exchangeRateSaver
+	provider    entity.ExchangeRateProvider
+	maxStaleAge time.Duration
 }
 
 // NewGetExchangeRateUseCase creates and returns a new GetExchangeRateUseCase instance.
@@ -19,63 +39,130 @@ func NewGetExchangeRateUseCase(
 	repository entity.ExchangeRateRepositoryInterface,
 ) *GetExchangeRateUseCase {
 	return &GetExchangeRateUseCase{
-		repository:               repository,
-		economiaAwesomeApiClient: client.NewClient(),
+		exchangeRateSaver: newExchangeRateSaver(repository),
+		provider:          NewDefaultExchangeRateProvider(),
+		maxStaleAge:       DefaultMaxStaleAge,
 	}
 }
 
-// Execute fetches the exchange rate for the given currency codes, saves it to the repository, and returns the exchange rate data.
-func (u *GetExchangeRateUseCase) Execute(code, codeIn string) (outputDTO.ExchangeRatesDTO, error) {
+// SetProvider changes the provider the exchange rates are fetched with, NewDefaultExchangeRateProvider by default.
+func (u *GetExchangeRateUseCase) SetProvider(provider entity.ExchangeRateProvider) {
+	u.provider = provider
+}
+
+// SetSaveTimeout changes the time allowed to persist each exchange rate. Zero only applies the deadline of the caller.
+func (u *GetExchangeRateUseCase) SetSaveTimeout(timeout time.Duration) {
+	u.saveTimeout = timeout
+}
+
+// SetMaxStaleAge changes the age under which a stored exchange rate is served when the provider fails. Zero disables the fallback.
+func (u *GetExchangeRateUseCase) SetMaxStaleAge(maxAge time.Duration) {
+	u.maxStaleAge = maxAge
+}
+
+// SetAlertEvaluator evaluates the alert rules of each exchange rate saved for the first time, none by default.
+func (u *GetExchangeRateUseCase) SetAlertEvaluator(evaluator AlertEvaluator) {
+	u.alerts = evaluator
+}
+
+// SetEventPublisher publishes the domain events of the use case, none by default: entity.UpstreamFetchFailed when the
+// provider fails, entity.ExchangeRateFetched for each rate returned and entity.ExchangeRateSaved once a rate not
+// stored before is saved.
+// When the repository is an entity.EventOutbox, ExchangeRateSaved events are stored with the rates and relayed from
+// the outbox after saving.
+func (u *GetExchangeRateUseCase) SetEventPublisher(publisher EventPublisher) {
+	u.setEventPublisher(publisher)
+}
+
+// Execute fetches the exchange rate for the given currency codes, saves it to the repository, and returns the exchange rate data
+// keyed by pair, such as "USD-BRL".
+// When the provider fails, the latest stored exchange rate of the pair is returned instead if it is younger than the max stale age,
+// with the outputDTO.SourceCache source and its age; otherwise the error of the provider is returned.
+// The codes must form an entity.CurrencyPair; an invalid pair is returned as an error wrapping entity.ErrInvalidCurrencyPair.
+// All returned rates are saved atomically when the repository supports it. Each save is bounded by the save timeout; an overrun is returned as an *entity.RepositoryTimeoutError.
+// Once saved, the rates not stored before are passed to the alert evaluator, whose errors are logged without failing the call.
+// Domain events are published to the event publisher; their errors are logged without failing the call either.
+func (u *GetExchangeRateUseCase) Execute(ctx context.Context, code, codeIn string) (outputDTO.ExchangeRatesDTO, error) {
 	if code == "" || codeIn == "" {
-		return outputDTO.ExchangeRatesDTO{}, errors.New("currency codes cannot be empty")
+		return outputDTO.ExchangeRatesDTO{}, ErrCurrencyCodesRequired
 	}
-	log.Printf("Getting exchange rate from Economia Awesome API for %s/%s", code, codeIn)
-	searchKey := GenerateExchangeRateSearchKey(code, codeIn)
-	log.Printf("Search key: %s", searchKey)
+	pair, err := entity.NewCurrencyPair(code, codeIn)
+	if err != nil {
+		return outputDTO.ExchangeRatesDTO{}, err
+	}
+	log.Printf("Getting exchange rate from %s for %s", u.provider.Name(), pair)
 
-	awesomeAPIresult, err := u.economiaAwesomeApiClient.GetExchangeRate(searchKey)
-	log.Printf("API result: %v", awesomeAPIresult)
+	quotes, err := u.provider.GetExchangeRates(ctx, []entity.CurrencyPair{pair})
+	log.Printf("Provider result: %v", quotes)
 	if err != nil {
+		u.publish(ctx, entity.UpstreamFetchFailed{Pair: pair.String(), Provider: u.provider.Name(), Error: err.Error(), OccurredAt: u.now()})
+		if output, ok := u.staleFallback(ctx, pair, err); ok {
+			return output, nil
+		}
 		return outputDTO.ExchangeRatesDTO{}, err
 	}
 
 	output := make(outputDTO.ExchangeRatesDTO)
-	for _, rate := range awesomeAPIresult {
-		exchangeRate, err := entity.NewExchangeRate(
-			rate.Code,
-			rate.CodeIn,
-			rate.Name,
-			rate.High,
-			rate.Low,
-			rate.VarBid,
-			rate.PctChange,
-			rate.Bid,
-			rate.Ask,
-			rate.Timestamp,
-			rate.CreateDate,
-		)
+	exchangeRates := make([]*entity.CurrencyInfo, 0, len(quotes))
+	for _, quote := range quotes {
+		exchangeRate, err := quote.ExchangeRate()
 		if err != nil {
 			return outputDTO.ExchangeRatesDTO{}, err
 		}
-		err = u.repository.Save(exchangeRate)
-		if err != nil {
-			return outputDTO.ExchangeRatesDTO{}, err
-		}
-		exchangeRateOut := outputDTO.ExchangeRateDTO{
-			Code:       exchangeRate.Code,
-			CodeIn:     exchangeRate.CodeIn,
-			Name:       exchangeRate.Name,
-			High:       exchangeRate.High,
-			Low:        exchangeRate.Low,
-			VarBid:     exchangeRate.VarBid,
-			PctChange:  exchangeRate.PctChange,
-			Bid:        exchangeRate.Bid,
-			Ask:        exchangeRate.Ask,
-			Timestamp:  exchangeRate.Timestamp,
-			CreateDate: exchangeRate.CreateDate.Format("2006-01-02 15:04:05"),
-		}
-		output[searchKey] = exchangeRateOut
+		exchangeRates = append(exchangeRates, exchangeRate)
+		u.publish(ctx, entity.ExchangeRateFetched{Pair: GenerateExchangeRateSearchKey(exchangeRate.Code, exchangeRate.CodeIn), Provider: u.provider.Name(), ExchangeRate: exchangeRate, OccurredAt: u.now()})
+		output[GenerateExchangeRateSearchKey(exchangeRate.Code, exchangeRate.CodeIn)] = toExchangeRateDTO(exchangeRate)
 	}
 
+	fresh, err := u.saveAll(ctx, exchangeRates)
+	if err != nil {
+		return outputDTO.ExchangeRatesDTO{}, err
+	}
+	u.afterSave(ctx, fresh)
 	return output, nil
 }
+
+// staleFallback returns the latest stored exchange rate of the pair, marked as cached, when it is younger than the max stale age.
+func (u *GetExchangeRateUseCase) staleFallback(ctx context.Context, pair entity.CurrencyPair, providerErr error) (outputDTO.ExchangeRatesDTO, bool) {
+	if u.maxStaleAge <= 0 {
+		return nil, false
+	}
+	latest, err := u.repository.FindLatest(ctx, pair.Code(), pair.CodeIn())
+	if err != nil {
+		if !errors.Is(err, entity.ErrExchangeRateNotFound) {
+			log.Printf("Finding a stored exchange rate for %s failed: %v", pair, err)
+		}
+		return nil, false
+	}
+	age := u.now().Sub(time.Unix(latest.Timestamp, 0))
+	if age > u.maxStaleAge {
+		log.Printf("Stored exchange rate for %s is %s old, over the max stale age of %s", pair, age.Truncate(time.Second), u.maxStaleAge)
+		return nil, false
+	}
+	if age < 0 {
+		age = 0
+	}
+	log.Printf("Serving the stored exchange rate for %s, %s old: %v", pair, age.Truncate(time.Second), providerErr)
+	exchangeRate := toExchangeRateDTO(latest)
+	exchangeRate.Source = outputDTO.SourceCache
+	exchangeRate.AgeSeconds = int64(age / time.Second)
+	return outputDTO.ExchangeRatesDTO{pair.String(): exchangeRate}, true
+}
+
+// toExchangeRateDTO returns the output of an exchange rate.
+func toExchangeRateDTO(exchangeRate *entity.CurrencyInfo) outputDTO.ExchangeRateDTO {
+	return outputDTO.ExchangeRateDTO{
+		Code:       exchangeRate.Code,
+		CodeIn:     exchangeRate.CodeIn,
+		Name:       exchangeRate.Name,
+		High:       exchangeRate.High,
+		Low:        exchangeRate.Low,
+		VarBid:     exchangeRate.VarBid,
+		PctChange:  exchangeRate.PctChange,
+		Bid:        exchangeRate.Bid,
+		Ask:        exchangeRate.Ask,
+		Timestamp:  exchangeRate.Timestamp,
+		CreateDate: exchangeRate.CreateDate.Format("2006-01-02 15:04:05"),
+		Source:     outputDTO.SourceProvider,
+	}
+}
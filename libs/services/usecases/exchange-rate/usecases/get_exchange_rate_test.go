@@ -0,0 +1,305 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"libs/resources/database/in-memory/go-doc-db-client/client"
+	"libs/resources/database/in-memory/go-doc-db/database"
+	outputDTO "libs/services/acl/dtos/exchange-rate/output"
+	entity "libs/services/entities/exchange-rate/entity"
+	godocdbrepository "libs/services/infrastructure/database/repositories/exchange-rate/in-memory/go-doc-db/repository"
+	"log"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// fakeUnitKey is the context key holding the rates saved by the current unit of work of a fakeRepository.
+type fakeUnitKey struct{}
+
+// fakeRepository stores saved rates in memory and implements entity.UnitOfWork by discarding the saves of a failed unit.
+// It is safe for concurrent use.
+type fakeRepository struct {
+	mu      sync.Mutex
+	saved   []*entity.CurrencyInfo
+	failOn  string
+	usedUoW bool
+}
+
+func (r *fakeRepository) Save(ctx context.Context, currencyInfo *entity.CurrencyInfo) error {
+	_, err := r.Insert(ctx, currencyInfo)
+	return err
+}
+
+func (r *fakeRepository) Insert(ctx context.Context, currencyInfo *entity.CurrencyInfo) (bool, error) {
+	if currencyInfo.Code == r.failOn {
+		return false, errors.New("save failed")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, saved := range r.saved {
+		if saved.GetEntityID() == currencyInfo.GetEntityID() {
+			return false, nil
+		}
+	}
+	r.saved = append(r.saved, currencyInfo)
+	if unit, ok := ctx.Value(fakeUnitKey{}).(*[]*entity.CurrencyInfo); ok {
+		*unit = append(*unit, currencyInfo)
+	}
+	return true, nil
+}
+
+func (r *fakeRepository) FindAll(ctx context.Context) ([]*entity.CurrencyInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.saved, nil
+}
+
+func (r *fakeRepository) Find(ctx context.Context, code string, codeIn string) ([]*entity.CurrencyInfo, error) {
+	return nil, nil
+}
+
+func (r *fakeRepository) FindByID(ctx context.Context, id string) (*entity.CurrencyInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, currencyInfo := range r.saved {
+		if currencyInfo.GetEntityID() == id {
+			return currencyInfo, nil
+		}
+	}
+	return nil, entity.ErrExchangeRateNotFound
+}
+
+func (r *fakeRepository) Delete(ctx context.Context, id string) error {
+	return nil
+}
+
+func (r *fakeRepository) FindLatest(ctx context.Context, code string, codeIn string) (*entity.CurrencyInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var latest *entity.CurrencyInfo
+	for _, currencyInfo := range r.saved {
+		if currencyInfo.Code == code && currencyInfo.CodeIn == codeIn && (latest == nil || currencyInfo.Timestamp > latest.Timestamp) {
+			latest = currencyInfo
+		}
+	}
+	if latest == nil {
+		return nil, entity.ErrExchangeRateNotFound
+	}
+	return latest, nil
+}
+
+func (r *fakeRepository) FindRange(ctx context.Context, code string, codeIn string, from time.Time, to time.Time, page entity.Page) ([]*entity.CurrencyInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	min, max := entity.TimestampBounds(from, to)
+	var results []*entity.CurrencyInfo
+	for _, currencyInfo := range r.saved {
+		if currencyInfo.Code == code && currencyInfo.CodeIn == codeIn && currencyInfo.Timestamp >= min && currencyInfo.Timestamp < max {
+			results = append(results, currencyInfo)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if page.Order == entity.SortDescending {
+			return results[i].Timestamp > results[j].Timestamp
+		}
+		return results[i].Timestamp < results[j].Timestamp
+	})
+	if page.Limit > 0 && len(results) > page.Limit {
+		results = results[:page.Limit]
+	}
+	return results, nil
+}
+
+func (r *fakeRepository) CountByPair(ctx context.Context, code string, codeIn string) (int, error) {
+	return 0, nil
+}
+
+func (r *fakeRepository) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	r.mu.Lock()
+	r.usedUoW = true
+	r.mu.Unlock()
+	unit := &[]*entity.CurrencyInfo{}
+	if err := fn(context.WithValue(ctx, fakeUnitKey{}, unit)); err != nil {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.saved = slices.DeleteFunc(r.saved, func(saved *entity.CurrencyInfo) bool {
+			return slices.Contains(*unit, saved)
+		})
+		return err
+	}
+	return nil
+}
+
+type GetExchangeRateUseCaseTestSuite struct {
+	suite.Suite
+	repository *fakeRepository
+	useCase    *GetExchangeRateUseCase
+	rates      []*entity.CurrencyInfo
+}
+
+func TestGetExchangeRateUseCaseTestSuite(t *testing.T) {
+	suite.Run(t, new(GetExchangeRateUseCaseTestSuite))
+}
+
+func (suite *GetExchangeRateUseCaseTestSuite) SetupTest() {
+	suite.repository = &fakeRepository{}
+	suite.useCase = NewGetExchangeRateUseCase(suite.repository)
+	suite.rates = nil
+	for _, code := range []string{"USD", "EUR"} {
+		rate, err := entity.NewExchangeRate(code, "BRL", code+"/BRL", "5.5", "5.4", "0.05", "0.01", "5.45", "5.46", "1626889200", "2021-07-21 00:00:00")
+		assert.NoError(suite.T(), err)
+		suite.rates = append(suite.rates, rate)
+	}
+}
+
+func (suite *GetExchangeRateUseCaseTestSuite) TestSaveAllUsesUnitOfWork() {
+	_, err := suite.useCase.saveAll(context.Background(), suite.rates)
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), suite.repository.usedUoW)
+	assert.Equal(suite.T(), suite.rates, suite.repository.saved)
+}
+
+func (suite *GetExchangeRateUseCaseTestSuite) TestSaveAllIsAtomic() {
+	suite.repository.failOn = "EUR"
+	_, err := suite.useCase.saveAll(context.Background(), suite.rates)
+	assert.Error(suite.T(), err)
+	assert.Empty(suite.T(), suite.repository.saved)
+}
+
+func (suite *GetExchangeRateUseCaseTestSuite) TestExecuteRejectsInvalidPair() {
+	for _, codes := range [][2]string{{"XYZ", "BRL"}, {"BRL", "brl"}} {
+		_, err := suite.useCase.Execute(context.Background(), codes[0], codes[1])
+		assert.True(suite.T(), errors.Is(err, entity.ErrInvalidCurrencyPair), "%v: unexpected error: %v", codes, err)
+	}
+	assert.Empty(suite.T(), suite.repository.saved)
+}
+
+func (suite *GetExchangeRateUseCaseTestSuite) TestExecuteKeysRatesByPair() {
+	suite.useCase.SetProvider(&fakeProvider{quotes: map[string]entity.RawQuote{"USD-BRL": rawQuote("USD", "BRL", "5.45", "5.46")}})
+
+	output, err := suite.useCase.Execute(context.Background(), "usd", "brl")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), entity.MustParseDecimal("5.45"), output["USD-BRL"].Bid)
+	assert.Len(suite.T(), suite.repository.saved, 1)
+}
+
+func (suite *GetExchangeRateUseCaseTestSuite) TestExecuteMarksFetchedRatesWithTheirSource() {
+	suite.useCase.SetProvider(&fakeProvider{quotes: map[string]entity.RawQuote{"USD-BRL": rawQuote("USD", "BRL", "5.45", "5.46")}})
+
+	output, err := suite.useCase.Execute(context.Background(), "USD", "BRL")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), outputDTO.SourceProvider, output["USD-BRL"].Source)
+	assert.Zero(suite.T(), output["USD-BRL"].AgeSeconds)
+}
+
+func (suite *GetExchangeRateUseCaseTestSuite) TestExecuteConcurrentlyToGoDocDB() {
+	provider := &fakeProvider{quotes: map[string]entity.RawQuote{}}
+	for _, code := range []string{"USD", "EUR", "GBP"} {
+		provider.quotes[code+"-BRL"] = rawQuote(code, "BRL", "5.45", "5.46")
+	}
+	// A logger writing somewhere synchronizes the fetches, which would hide their races from the race detector.
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+	dbClient := client.NewClient(database.NewInMemoryDocBD("exchange-rate"))
+	repository := godocdbrepository.NewExchangeRateRepository("exchange-rate", dbClient)
+	useCase := NewGetExchangeRateUseCase(repository)
+	useCase.SetProvider(provider)
+
+	// Like the jobs of the poll scheduler and the requests of the server, several fetches of each pair run at once.
+	var wg sync.WaitGroup
+	errs := make(chan error, 12)
+	for i := 0; i < 12; i++ {
+		code := []string{"USD", "EUR", "GBP"}[i%3]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := useCase.Execute(context.Background(), code, "BRL"); err != nil {
+				errs <- fmt.Errorf("%s: %w", code, err)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(suite.T(), err)
+	}
+	saved, err := repository.FindAll(context.Background())
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), saved, 3)
+}
+
+func (suite *GetExchangeRateUseCaseTestSuite) TestConcurrentExecutesPublishSavedOnce() {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+	dbClient := client.NewClient(database.NewInMemoryDocBD("exchange-rate"))
+	useCase := NewGetExchangeRateUseCase(godocdbrepository.NewExchangeRateRepository("exchange-rate", dbClient))
+	useCase.SetProvider(&fakeProvider{quotes: map[string]entity.RawQuote{"USD-BRL": rawQuote("USD", "BRL", "5.45", "5.46")}})
+	publisher := &recordingPublisher{}
+	useCase.SetEventPublisher(publisher)
+
+	// Every fetch gets the same quote, so several of them may try to store it at once.
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := useCase.Execute(context.Background(), "USD", "BRL")
+			assert.NoError(suite.T(), err)
+		}()
+	}
+	wg.Wait()
+
+	saved := 0
+	for _, name := range publisher.names() {
+		if name == entity.ExchangeRateSavedEvent {
+			saved++
+		}
+	}
+	assert.Equal(suite.T(), 1, saved)
+}
+
+func (suite *GetExchangeRateUseCaseTestSuite) TestExecuteServesStoredRateWhenProviderFails() {
+	suite.repository.saved = suite.rates
+	suite.useCase.SetProvider(&fakeProvider{err: errors.New("upstream timeout")})
+	suite.useCase.now = func() time.Time { return time.Unix(suite.rates[0].Timestamp, 0).Add(90 * time.Second) }
+
+	output, err := suite.useCase.Execute(context.Background(), "USD", "BRL")
+
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), output, 1) {
+		assert.Equal(suite.T(), outputDTO.SourceCache, output["USD-BRL"].Source)
+		assert.Equal(suite.T(), int64(90), output["USD-BRL"].AgeSeconds)
+		assert.Equal(suite.T(), suite.rates[0].Bid, output["USD-BRL"].Bid)
+	}
+	assert.Len(suite.T(), suite.repository.saved, 2, "a cached rate is not saved again")
+}
+
+func (suite *GetExchangeRateUseCaseTestSuite) TestExecuteFailsWithoutFreshStoredRate() {
+	providerErr := errors.New("upstream timeout")
+	suite.useCase.SetProvider(&fakeProvider{err: providerErr})
+	suite.useCase.now = func() time.Time { return time.Unix(suite.rates[0].Timestamp, 0).Add(DefaultMaxStaleAge + time.Second) }
+
+	_, err := suite.useCase.Execute(context.Background(), "USD", "BRL")
+	assert.Equal(suite.T(), providerErr, err, "nothing stored")
+
+	suite.repository.saved = suite.rates
+	_, err = suite.useCase.Execute(context.Background(), "USD", "BRL")
+	assert.Equal(suite.T(), providerErr, err, "stored rate too old")
+
+	suite.useCase.now = func() time.Time { return time.Unix(suite.rates[0].Timestamp, 0) }
+	suite.useCase.SetMaxStaleAge(0)
+	_, err = suite.useCase.Execute(context.Background(), "USD", "BRL")
+	assert.Equal(suite.T(), providerErr, err, "fallback disabled")
+}
@@ -0,0 +1,186 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	outputDTO "libs/services/acl/dtos/exchange-rate/output"
+	entity "libs/services/entities/exchange-rate/entity"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrCurrencyPairsRequired is returned when a batch is executed without pairs.
+	ErrCurrencyPairsRequired = errors.New("currency pairs cannot be empty")
+	// ErrPairNotQuoted is reported for a requested pair the provider did not return.
+	ErrPairNotQuoted = errors.New("pair not returned by the exchange rate provider")
+)
+
+// DefaultBatchConcurrency bounds the exchange rates GetExchangeRatesBatchUseCase saves at the same time.
+const DefaultBatchConcurrency = 4
+
+// GetExchangeRatesBatchUseCase represents a use case for fetching the exchange rates of several pairs in one call
+// to the provider and saving them.
+type GetExchangeRatesBatchUseCase struct {
+	exchangeRateSaver
+	provider    entity.ExchangeRateProvider
+	concurrency int
+}
+
+// NewGetExchangeRatesBatchUseCase creates and returns a new GetExchangeRatesBatchUseCase instance.
+func NewGetExchangeRatesBatchUseCase(
+	repository entity.ExchangeRateRepositoryInterface,
+) *GetExchangeRatesBatchUseCase {
+	return &GetExchangeRatesBatchUseCase{
+		exchangeRateSaver: newExchangeRateSaver(repository),
+		provider:          NewDefaultExchangeRateProvider(),
+		concurrency:       DefaultBatchConcurrency,
+	}
+}
+
+// SetProvider changes the provider the exchange rates are fetched with, NewDefaultExchangeRateProvider by default.
+func (u *GetExchangeRatesBatchUseCase) SetProvider(provider entity.ExchangeRateProvider) {
+	u.provider = provider
+}
+
+// SetSaveTimeout changes the time allowed to persist each exchange rate. Zero only applies the deadline of the caller.
+func (u *GetExchangeRatesBatchUseCase) SetSaveTimeout(timeout time.Duration) {
+	u.saveTimeout = timeout
+}
+
+// SetAlertEvaluator evaluates the alert rules of each exchange rate saved for the first time, none by default.
+func (u *GetExchangeRatesBatchUseCase) SetAlertEvaluator(evaluator AlertEvaluator) {
+	u.alerts = evaluator
+}
+
+// SetEventPublisher publishes the domain events of the use case, none by default, like
+// GetExchangeRateUseCase.SetEventPublisher: entity.UpstreamFetchFailed for each pair when the provider fails,
+// entity.ExchangeRateFetched for each rate returned and entity.ExchangeRateSaved once a rate not stored before is saved.
+func (u *GetExchangeRatesBatchUseCase) SetEventPublisher(publisher EventPublisher) {
+	u.setEventPublisher(publisher)
+}
+
+// SetConcurrency changes the number of exchange rates saved at the same time. Values below 1 save one at a time.
+func (u *GetExchangeRatesBatchUseCase) SetConcurrency(concurrency int) {
+	u.concurrency = concurrency
+}
+
+// Execute fetches the exchange rates of pairs written as "USD-BRL" in one call to the provider, saves them concurrently
+// and returns them keyed by pair.
+// A pair that is not an entity.CurrencyPair, is not returned by the provider, fails entity validation or fails to save
+// is left out of Rates and reported in Failures, without failing the others. Duplicate pairs are fetched once.
+// The error is ErrCurrencyPairsRequired without pairs, or the error of the provider, which fails every pair.
+// Rates are saved independently: a batch is not atomic, even when the repository is an entity.UnitOfWork.
+// Once saved, the rates not stored before are passed to the alert evaluator and announced to the event publisher, as
+// GetExchangeRateUseCase does; their errors are logged without failing the call.
+func (u *GetExchangeRatesBatchUseCase) Execute(ctx context.Context, pairs []string) (outputDTO.BatchExchangeRatesDTO, error) {
+	if len(pairs) == 0 {
+		return outputDTO.BatchExchangeRatesDTO{}, ErrCurrencyPairsRequired
+	}
+	output := outputDTO.BatchExchangeRatesDTO{
+		Rates:    make(outputDTO.ExchangeRatesDTO),
+		Failures: make(map[string]string),
+	}
+	requested := make([]entity.CurrencyPair, 0, len(pairs))
+	seen := make(map[string]bool, len(pairs))
+	for _, raw := range pairs {
+		pair, err := entity.ParseCurrencyPair(strings.TrimSpace(raw))
+		if err != nil {
+			output.Failures[raw] = err.Error()
+			continue
+		}
+		if !seen[pair.String()] {
+			seen[pair.String()] = true
+			requested = append(requested, pair)
+		}
+	}
+	if len(requested) == 0 {
+		return output, nil
+	}
+
+	log.Printf("Getting exchange rates from %s for %d pairs", u.provider.Name(), len(requested))
+	quotes, err := u.provider.GetExchangeRates(ctx, requested)
+	if err != nil {
+		for _, pair := range requested {
+			output.Failures[pair.String()] = err.Error()
+			u.publish(ctx, entity.UpstreamFetchFailed{Pair: pair.String(), Provider: u.provider.Name(), Error: err.Error(), OccurredAt: u.now()})
+		}
+		return output, err
+	}
+
+	exchangeRates := make(map[string]*entity.CurrencyInfo, len(requested))
+	for _, quote := range quotes {
+		key := GenerateExchangeRateSearchKey(quote.Code, quote.CodeIn)
+		if !seen[key] {
+			log.Printf("Ignoring exchange rate %s that was not requested", key)
+			continue
+		}
+		exchangeRate, err := quote.ExchangeRate()
+		if err != nil {
+			output.Failures[key] = err.Error()
+			continue
+		}
+		exchangeRates[key] = exchangeRate
+		u.publish(ctx, entity.ExchangeRateFetched{Pair: key, Provider: u.provider.Name(), ExchangeRate: exchangeRate, OccurredAt: u.now()})
+	}
+	for _, pair := range requested {
+		key := pair.String()
+		if _, ok := exchangeRates[key]; !ok {
+			if _, failed := output.Failures[key]; !failed {
+				output.Failures[key] = ErrPairNotQuoted.Error()
+			}
+		}
+	}
+
+	fresh, failures := u.saveConcurrently(ctx, exchangeRates)
+	for key, err := range failures {
+		output.Failures[key] = err.Error()
+		delete(exchangeRates, key)
+	}
+	u.afterSave(ctx, fresh)
+	for key, exchangeRate := range exchangeRates {
+		output.Rates[key] = toExchangeRateDTO(exchangeRate)
+	}
+	return output, nil
+}
+
+// saveConcurrently saves the exchange rates with at most concurrency saves at a time. It returns the exchange rates
+// not stored before, sorted by pair, and the error of each pair that failed.
+func (u *GetExchangeRatesBatchUseCase) saveConcurrently(ctx context.Context, exchangeRates map[string]*entity.CurrencyInfo) ([]*entity.CurrencyInfo, map[string]error) {
+	concurrency := u.concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		fresh    []*entity.CurrencyInfo
+		failures = make(map[string]error)
+		slots    = make(chan struct{}, concurrency)
+	)
+	for key, exchangeRate := range exchangeRates {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(key string, exchangeRate *entity.CurrencyInfo) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			saved, err := u.saveAll(ctx, []*entity.CurrencyInfo{exchangeRate})
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failures[key] = fmt.Errorf("saving %s: %w", key, err)
+				return
+			}
+			fresh = append(fresh, saved...)
+		}(key, exchangeRate)
+	}
+	wg.Wait()
+	sort.Slice(fresh, func(i, j int) bool {
+		return GenerateExchangeRateSearchKey(fresh[i].Code, fresh[i].CodeIn) < GenerateExchangeRateSearchKey(fresh[j].Code, fresh[j].CodeIn)
+	})
+	return fresh, failures
+}
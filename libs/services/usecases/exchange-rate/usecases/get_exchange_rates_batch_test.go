@@ -0,0 +1,254 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"io"
+	"libs/resources/database/in-memory/go-doc-db-client/client"
+	"libs/resources/database/in-memory/go-doc-db/database"
+	entity "libs/services/entities/exchange-rate/entity"
+	godocdbrepository "libs/services/infrastructure/database/repositories/exchange-rate/in-memory/go-doc-db/repository"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// fakeProvider answers with the quotes it knows of the requested pairs, keyed by pair, and records each call
+// as the requested pairs separated by commas.
+type fakeProvider struct {
+	mu        sync.Mutex
+	quotes    map[string]entity.RawQuote
+	err       error
+	requested []string
+}
+
+func (p *fakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *fakeProvider) GetExchangeRates(ctx context.Context, pairs []entity.CurrencyPair) ([]entity.RawQuote, error) {
+	names := make([]string, len(pairs))
+	for i, pair := range pairs {
+		names[i] = pair.String()
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.requested = append(p.requested, strings.Join(names, ","))
+	if p.err != nil {
+		return nil, p.err
+	}
+	var quotes []entity.RawQuote
+	for _, name := range names {
+		if quote, ok := p.quotes[name]; ok {
+			quotes = append(quotes, quote)
+		}
+	}
+	return quotes, nil
+}
+
+// slowRepository delays each save and records the most saves in flight at once.
+type slowRepository struct {
+	*fakeRepository
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+}
+
+func (r *slowRepository) Insert(ctx context.Context, currencyInfo *entity.CurrencyInfo) (bool, error) {
+	r.mu.Lock()
+	r.inFlight++
+	if r.inFlight > r.maxInFlight {
+		r.maxInFlight = r.inFlight
+	}
+	r.mu.Unlock()
+	time.Sleep(5 * time.Millisecond)
+	r.mu.Lock()
+	r.inFlight--
+	r.mu.Unlock()
+	return r.fakeRepository.Insert(ctx, currencyInfo)
+}
+
+type GetExchangeRatesBatchUseCaseTestSuite struct {
+	suite.Suite
+	repository *fakeRepository
+	provider   *fakeProvider
+	useCase    *GetExchangeRatesBatchUseCase
+}
+
+func TestGetExchangeRatesBatchUseCaseTestSuite(t *testing.T) {
+	suite.Run(t, new(GetExchangeRatesBatchUseCaseTestSuite))
+}
+
+func (suite *GetExchangeRatesBatchUseCaseTestSuite) SetupTest() {
+	suite.repository = &fakeRepository{}
+	suite.provider = &fakeProvider{quotes: map[string]entity.RawQuote{}}
+	for _, code := range []string{"USD", "EUR", "GBP", "JPY", "CHF", "CAD"} {
+		suite.provider.quotes[code+"-BRL"] = rawQuote(code, "BRL", "5.45", "5.46")
+	}
+	suite.useCase = NewGetExchangeRatesBatchUseCase(suite.repository)
+	suite.useCase.SetProvider(suite.provider)
+	suite.useCase.SetSaveTimeout(0)
+}
+
+// rawQuote returns a quote of the pair as a provider returns it.
+func rawQuote(code, codeIn, bid, ask string) entity.RawQuote {
+	return entity.RawQuote{
+		Code:       code,
+		CodeIn:     codeIn,
+		Name:       code + "/" + codeIn,
+		High:       "5.5",
+		Low:        "5.4",
+		VarBid:     "0.05",
+		PctChange:  "0.01",
+		Bid:        bid,
+		Ask:        ask,
+		Timestamp:  "1626889200",
+		CreateDate: "2021-07-21 00:00:00",
+	}
+}
+
+// savedPairs returns the pairs saved to the repository, sorted.
+func (suite *GetExchangeRatesBatchUseCaseTestSuite) savedPairs() []string {
+	var pairs []string
+	for _, rate := range suite.repository.saved {
+		pairs = append(pairs, GenerateExchangeRateSearchKey(rate.Code, rate.CodeIn))
+	}
+	sort.Strings(pairs)
+	return pairs
+}
+
+func (suite *GetExchangeRatesBatchUseCaseTestSuite) TestExecuteFetchesPairsInOneCall() {
+	output, err := suite.useCase.Execute(context.Background(), []string{"usd-brl", "EUR-BRL", "USD-BRL"})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"USD-BRL,EUR-BRL"}, suite.provider.requested)
+	assert.Empty(suite.T(), output.Failures)
+	if assert.Len(suite.T(), output.Rates, 2) {
+		assert.Equal(suite.T(), "USD", output.Rates["USD-BRL"].Code)
+		assert.Equal(suite.T(), "EUR", output.Rates["EUR-BRL"].Code)
+		assert.Equal(suite.T(), entity.MustParseDecimal("5.45"), output.Rates["EUR-BRL"].Bid)
+	}
+	assert.Equal(suite.T(), []string{"EUR-BRL", "USD-BRL"}, suite.savedPairs())
+}
+
+func (suite *GetExchangeRatesBatchUseCaseTestSuite) TestExecuteReportsPartialFailures() {
+	suite.provider.quotes["EUR-BRL"] = rawQuote("EUR", "BRL", "5.45", "5.40")
+	delete(suite.provider.quotes, "GBP-BRL")
+	suite.repository.failOn = "JPY"
+
+	output, err := suite.useCase.Execute(context.Background(), []string{"USD-BRL", "EUR-BRL", "GBP-BRL", "JPY-BRL", "XYZ-BRL"})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"USD-BRL,EUR-BRL,GBP-BRL,JPY-BRL"}, suite.provider.requested)
+	assert.Len(suite.T(), output.Rates, 1)
+	assert.Contains(suite.T(), output.Rates, "USD-BRL")
+	assert.Len(suite.T(), output.Failures, 4)
+	assert.Contains(suite.T(), output.Failures["EUR-BRL"], "ask: must not be less than bid")
+	assert.Equal(suite.T(), ErrPairNotQuoted.Error(), output.Failures["GBP-BRL"])
+	assert.Equal(suite.T(), "saving JPY-BRL: save failed", output.Failures["JPY-BRL"])
+	assert.Contains(suite.T(), output.Failures["XYZ-BRL"], entity.ErrInvalidCurrencyPair.Error())
+	assert.Equal(suite.T(), []string{"USD-BRL"}, suite.savedPairs())
+}
+
+func (suite *GetExchangeRatesBatchUseCaseTestSuite) TestExecuteBoundsConcurrentSaves() {
+	repository := &slowRepository{fakeRepository: suite.repository}
+	useCase := NewGetExchangeRatesBatchUseCase(repository)
+	useCase.SetProvider(suite.provider)
+	useCase.SetSaveTimeout(0)
+	useCase.SetConcurrency(2)
+
+	output, err := useCase.Execute(context.Background(), []string{"USD-BRL", "EUR-BRL", "GBP-BRL", "JPY-BRL", "CHF-BRL", "CAD-BRL"})
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), output.Rates, 6)
+	assert.Len(suite.T(), suite.repository.saved, 6)
+	assert.Equal(suite.T(), 2, repository.maxInFlight)
+}
+
+func (suite *GetExchangeRatesBatchUseCaseTestSuite) TestExecuteSavesConcurrentlyToGoDocDB() {
+	pairs := []string{"USD-BRL", "EUR-BRL", "GBP-BRL", "JPY-BRL", "CHF-BRL", "CAD-BRL", "AUD-BRL", "ARS-BRL"}
+	for _, pair := range pairs {
+		code, codeIn, _ := strings.Cut(pair, "-")
+		suite.provider.quotes[pair] = rawQuote(code, codeIn, "5.45", "5.46")
+	}
+	// A logger writing somewhere synchronizes the saves, which would hide their races from the race detector.
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+	dbClient := client.NewClient(database.NewInMemoryDocBD("exchange-rate"))
+	repository := godocdbrepository.NewExchangeRateRepository("exchange-rate", dbClient)
+	useCase := NewGetExchangeRatesBatchUseCase(repository)
+	useCase.SetProvider(suite.provider)
+	useCase.SetConcurrency(len(pairs))
+
+	output, err := useCase.Execute(context.Background(), pairs)
+
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), output.Failures)
+	assert.Len(suite.T(), output.Rates, len(pairs))
+	saved, err := repository.FindAll(context.Background())
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), saved, len(pairs))
+}
+
+func (suite *GetExchangeRatesBatchUseCaseTestSuite) TestExecuteFailsEveryPairWhenTheAPIFails() {
+	suite.provider.err = errors.New("upstream unavailable")
+
+	output, err := suite.useCase.Execute(context.Background(), []string{"USD-BRL", "EUR-BRL"})
+
+	assert.Equal(suite.T(), suite.provider.err, err)
+	assert.Empty(suite.T(), output.Rates)
+	assert.Equal(suite.T(), map[string]string{"USD-BRL": "upstream unavailable", "EUR-BRL": "upstream unavailable"}, output.Failures)
+	assert.Empty(suite.T(), suite.repository.saved)
+}
+
+func (suite *GetExchangeRatesBatchUseCaseTestSuite) TestExecuteWithoutValidPairs() {
+	_, err := suite.useCase.Execute(context.Background(), nil)
+	assert.Equal(suite.T(), ErrCurrencyPairsRequired, err)
+
+	output, err := suite.useCase.Execute(context.Background(), []string{"BRL-BRL", "USDBRL"})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), output.Failures, 2)
+	assert.Empty(suite.T(), suite.provider.requested)
+}
+
+func (suite *GetExchangeRatesBatchUseCaseTestSuite) TestExecuteEvaluatesAndPublishesNewRatesOnce() {
+	rules := &fakeAlertRuleRepository{}
+	rule, err := entity.NewThresholdAlertRule("USD", "BRL", entity.AlertAbove, entity.MustParseDecimal("5.4"))
+	suite.Require().NoError(err)
+	suite.Require().NoError(rules.SaveAlertRule(context.Background(), rule))
+	sink := &recordingSink{}
+	publisher := &recordingPublisher{}
+	suite.useCase.SetAlertEvaluator(NewEvaluateAlertsUseCase(rules, suite.repository, sink))
+	suite.useCase.SetEventPublisher(publisher)
+
+	for i := 0; i < 2; i++ {
+		output, err := suite.useCase.Execute(context.Background(), []string{"USD-BRL", "EUR-BRL"})
+		assert.NoError(suite.T(), err)
+		assert.Len(suite.T(), output.Rates, 2)
+	}
+
+	assert.Len(suite.T(), sink.alerts, 1, "a quote fetched again is not evaluated twice")
+	assert.Equal(suite.T(), []string{
+		entity.ExchangeRateFetchedEvent, entity.ExchangeRateFetchedEvent,
+		entity.ExchangeRateSavedEvent, entity.ExchangeRateSavedEvent,
+		entity.ExchangeRateFetchedEvent, entity.ExchangeRateFetchedEvent,
+	}, publisher.names())
+}
+
+func (suite *GetExchangeRatesBatchUseCaseTestSuite) TestExecutePublishesUpstreamFailures() {
+	publisher := &recordingPublisher{}
+	suite.useCase.SetEventPublisher(publisher)
+	suite.provider.err = errors.New("upstream down")
+
+	_, err := suite.useCase.Execute(context.Background(), []string{"USD-BRL", "EUR-BRL"})
+
+	assert.Error(suite.T(), err)
+	assert.Equal(suite.T(), []string{entity.UpstreamFetchFailedEvent, entity.UpstreamFetchFailedEvent}, publisher.names())
+}
@@ -0,0 +1,79 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	entity "libs/services/entities/exchange-rate/entity"
+	goeventbus "libs/shared/go-eventbus"
+	"log"
+	"sync"
+)
+
+// DefaultRelayBatchSize is the number of outbox events RelayOutboxUseCase reads at a time.
+const DefaultRelayBatchSize = 100
+
+// EventPublisher publishes domain events, such as a *goeventbus.Bus.
+type EventPublisher interface {
+	Publish(ctx context.Context, event goeventbus.Event) error
+}
+
+// RelayOutboxUseCase represents a use case for publishing the events stored in an entity.EventOutbox.
+type RelayOutboxUseCase struct {
+	outbox    entity.EventOutbox
+	publisher EventPublisher
+	mu        sync.Mutex
+}
+
+// NewRelayOutboxUseCase creates and returns a new RelayOutboxUseCase instance.
+func NewRelayOutboxUseCase(
+	outbox entity.EventOutbox,
+	publisher EventPublisher,
+) *RelayOutboxUseCase {
+	return &RelayOutboxUseCase{
+		outbox:    outbox,
+		publisher: publisher,
+	}
+}
+
+// Execute publishes the pending events of the outbox in the order they were stored and removes them, until the outbox
+// is empty, and returns the number of events published. Events are delivered at least once: an event published
+// before the process stops, and not yet removed, is published again by the next run.
+// A run stops at the first event the publisher cannot deliver, such as on a closed bus, so the order is kept;
+// errors of the subscribers are logged and the event is removed. Runs of the same RelayOutboxUseCase are serialized.
+func (u *RelayOutboxUseCase) Execute(ctx context.Context) (int, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	published := 0
+	for {
+		events, err := u.outbox.PendingEvents(ctx, DefaultRelayBatchSize)
+		if err != nil || len(events) == 0 {
+			return published, err
+		}
+		ids := make([]string, 0, len(events))
+		var stopErr error
+		for _, outboxEvent := range events {
+			event, err := outboxEvent.Event()
+			if err != nil {
+				log.Printf("Dropping outbox event %s that cannot be decoded: %v", outboxEvent.ID, err)
+				ids = append(ids, outboxEvent.ID)
+				continue
+			}
+			if err := u.publisher.Publish(ctx, event); err != nil {
+				if errors.Is(err, goeventbus.ErrBusClosed) || ctx.Err() != nil {
+					stopErr = err
+					break
+				}
+				log.Printf("Subscribers of outbox event %s failed: %v", outboxEvent.ID, err)
+			}
+			ids = append(ids, outboxEvent.ID)
+			published++
+		}
+		if err := u.outbox.DeleteEvents(ctx, ids...); err != nil {
+			return published, err
+		}
+		if stopErr != nil {
+			return published, stopErr
+		}
+	}
+}
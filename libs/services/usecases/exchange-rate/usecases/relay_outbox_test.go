@@ -0,0 +1,225 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	entity "libs/services/entities/exchange-rate/entity"
+	goeventbus "libs/shared/go-eventbus"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// recordingPublisher records the events it publishes and fails with err. It is safe for concurrent use.
+type recordingPublisher struct {
+	mu     sync.Mutex
+	events []goeventbus.Event
+	err    error
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event goeventbus.Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if errors.Is(p.err, goeventbus.ErrBusClosed) {
+		return p.err
+	}
+	p.events = append(p.events, event)
+	return p.err
+}
+
+// names returns the names of the published events.
+func (p *recordingPublisher) names() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	names := make([]string, len(p.events))
+	for i, event := range p.events {
+		names[i] = event.EventName()
+	}
+	return names
+}
+
+// fakeOutboxRepository is a fakeRepository implementing entity.EventOutbox, whose unit of work also discards the
+// events of a failed unit.
+type fakeOutboxRepository struct {
+	*fakeRepository
+	outbox []entity.OutboxEvent
+}
+
+func (r *fakeOutboxRepository) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	before := len(r.outbox)
+	if err := r.fakeRepository.Do(ctx, fn); err != nil {
+		r.outbox = r.outbox[:before]
+		return err
+	}
+	return nil
+}
+
+func (r *fakeOutboxRepository) AppendEvents(ctx context.Context, events ...entity.OutboxEvent) error {
+	r.outbox = append(r.outbox, events...)
+	return nil
+}
+
+func (r *fakeOutboxRepository) PendingEvents(ctx context.Context, limit int) ([]entity.OutboxEvent, error) {
+	if len(r.outbox) < limit {
+		limit = len(r.outbox)
+	}
+	return append([]entity.OutboxEvent(nil), r.outbox[:limit]...), nil
+}
+
+func (r *fakeOutboxRepository) DeleteEvents(ctx context.Context, ids ...string) error {
+	deleted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		deleted[id] = true
+	}
+	kept := r.outbox[:0]
+	for _, event := range r.outbox {
+		if !deleted[event.ID] {
+			kept = append(kept, event)
+		}
+	}
+	r.outbox = kept
+	return nil
+}
+
+type DomainEventsTestSuite struct {
+	suite.Suite
+	repository *fakeOutboxRepository
+	publisher  *recordingPublisher
+	provider   *fakeProvider
+}
+
+func TestDomainEventsTestSuite(t *testing.T) {
+	suite.Run(t, new(DomainEventsTestSuite))
+}
+
+func (suite *DomainEventsTestSuite) SetupTest() {
+	suite.repository = &fakeOutboxRepository{fakeRepository: &fakeRepository{}}
+	suite.publisher = &recordingPublisher{}
+	suite.provider = &fakeProvider{quotes: map[string]entity.RawQuote{"USD-BRL": rawQuote("USD", "BRL", "5.45", "5.46")}}
+}
+
+// useCase returns a GetExchangeRateUseCase saving to repository and publishing to the publisher of the suite.
+func (suite *DomainEventsTestSuite) useCase(repository entity.ExchangeRateRepositoryInterface) *GetExchangeRateUseCase {
+	useCase := NewGetExchangeRateUseCase(repository)
+	useCase.SetProvider(suite.provider)
+	useCase.SetEventPublisher(suite.publisher)
+	useCase.now = func() time.Time { return time.Unix(1626889260, 0) }
+	return useCase
+}
+
+func (suite *DomainEventsTestSuite) TestExecutePublishesFetchedThenSaved() {
+	_, err := suite.useCase(suite.repository.fakeRepository).Execute(context.Background(), "USD", "BRL")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{entity.ExchangeRateFetchedEvent, entity.ExchangeRateSavedEvent}, suite.publisher.names())
+	fetched := suite.publisher.events[0].(entity.ExchangeRateFetched)
+	assert.Equal(suite.T(), "USD-BRL", fetched.EventKey())
+	assert.Equal(suite.T(), "fake", fetched.Provider)
+	assert.Equal(suite.T(), entity.MustParseDecimal("5.45"), fetched.ExchangeRate.Bid)
+	assert.Equal(suite.T(), time.Unix(1626889260, 0), fetched.OccurredAt)
+}
+
+func (suite *DomainEventsTestSuite) TestExecutePublishesSavedOnlyForNewRates() {
+	useCase := suite.useCase(suite.repository.fakeRepository)
+	_, err := useCase.Execute(context.Background(), "USD", "BRL")
+	assert.NoError(suite.T(), err)
+
+	_, err = useCase.Execute(context.Background(), "USD", "BRL")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{
+		entity.ExchangeRateFetchedEvent, entity.ExchangeRateSavedEvent,
+		entity.ExchangeRateFetchedEvent,
+	}, suite.publisher.names(), "a quote fetched again is not announced as saved twice")
+}
+
+func (suite *DomainEventsTestSuite) TestExecutePublishesUpstreamFailures() {
+	suite.provider.err = errors.New("upstream down")
+
+	_, err := suite.useCase(suite.repository.fakeRepository).Execute(context.Background(), "USD", "BRL")
+
+	assert.Error(suite.T(), err)
+	assert.Equal(suite.T(), []goeventbus.Event{entity.UpstreamFetchFailed{
+		Pair:       "USD-BRL",
+		Provider:   "fake",
+		Error:      "upstream down",
+		OccurredAt: time.Unix(1626889260, 0),
+	}}, suite.publisher.events)
+}
+
+func (suite *DomainEventsTestSuite) TestExecuteIgnoresPublishErrors() {
+	suite.publisher.err = errors.New("subscriber failed")
+
+	output, err := suite.useCase(suite.repository.fakeRepository).Execute(context.Background(), "USD", "BRL")
+
+	assert.NoError(suite.T(), err)
+	assert.Contains(suite.T(), output, "USD-BRL")
+}
+
+func (suite *DomainEventsTestSuite) TestExecuteRelaysSavedEventsThroughTheOutbox() {
+	_, err := suite.useCase(suite.repository).Execute(context.Background(), "USD", "BRL")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{entity.ExchangeRateFetchedEvent, entity.ExchangeRateSavedEvent}, suite.publisher.names())
+	assert.Empty(suite.T(), suite.repository.outbox, "relayed events are removed")
+}
+
+func (suite *DomainEventsTestSuite) TestExecuteStoresSavedEventsOnlyForNewRates() {
+	suite.publisher.err = goeventbus.ErrBusClosed
+	useCase := suite.useCase(suite.repository)
+	_, err := useCase.Execute(context.Background(), "USD", "BRL")
+	assert.NoError(suite.T(), err)
+
+	_, err = useCase.Execute(context.Background(), "USD", "BRL")
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), suite.repository.outbox, 1, "a quote fetched again adds no event to the outbox")
+}
+
+func (suite *DomainEventsTestSuite) TestExecuteKeepsSavedEventsWhenPublishingStops() {
+	suite.publisher.err = goeventbus.ErrBusClosed
+
+	_, err := suite.useCase(suite.repository).Execute(context.Background(), "USD", "BRL")
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), suite.repository.outbox, 1, "the event waits in the outbox for the next relay")
+
+	suite.publisher.err = nil
+	published, err := NewRelayOutboxUseCase(suite.repository, suite.publisher).Execute(context.Background())
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, published)
+	assert.Equal(suite.T(), []string{entity.ExchangeRateSavedEvent}, suite.publisher.names())
+	assert.Empty(suite.T(), suite.repository.outbox)
+}
+
+func (suite *DomainEventsTestSuite) TestExecuteDiscardsSavedEventsOfFailedSaves() {
+	suite.repository.failOn = "USD"
+
+	_, err := suite.useCase(suite.repository).Execute(context.Background(), "USD", "BRL")
+
+	assert.Error(suite.T(), err)
+	assert.Empty(suite.T(), suite.repository.outbox)
+	assert.Equal(suite.T(), []string{entity.ExchangeRateFetchedEvent}, suite.publisher.names())
+}
+
+func (suite *DomainEventsTestSuite) TestRelayPublishesPendingEventsInOrder() {
+	var want []goeventbus.Event
+	for i := 0; i < DefaultRelayBatchSize+5; i++ {
+		event := entity.UpstreamFetchFailed{Pair: "USD-BRL", Provider: "fake", Error: "timeout", OccurredAt: time.Unix(int64(i), 0).UTC()}
+		outboxEvent, err := entity.NewOutboxEvent(event)
+		suite.Require().NoError(err)
+		suite.repository.outbox = append(suite.repository.outbox, outboxEvent)
+		want = append(want, event)
+	}
+	suite.repository.outbox = append(suite.repository.outbox, entity.OutboxEvent{ID: "unknown", Name: "exchange_rate.deleted"})
+
+	published, err := NewRelayOutboxUseCase(suite.repository, suite.publisher).Execute(context.Background())
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), len(want), published)
+	assert.Equal(suite.T(), want, suite.publisher.events)
+	assert.Empty(suite.T(), suite.repository.outbox, "events that cannot be decoded are dropped")
+}
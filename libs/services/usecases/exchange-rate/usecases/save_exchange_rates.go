@@ -0,0 +1,149 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	entity "libs/services/entities/exchange-rate/entity"
+	goeventbus "libs/shared/go-eventbus"
+	"log"
+	"time"
+)
+
+// exchangeRateSaver is the save step shared by GetExchangeRateUseCase and GetExchangeRatesBatchUseCase. It persists
+// fetched exchange rates and, for the ones stored for the first time, publishes entity.ExchangeRateSaved and
+// evaluates the alert rules.
+type exchangeRateSaver struct {
+	repository  entity.ExchangeRateRepositoryInterface
+	saveTimeout time.Duration
+	now         func() time.Time
+	alerts      AlertEvaluator
+	events      EventPublisher
+	relay       *RelayOutboxUseCase
+}
+
+// newExchangeRateSaver returns a saver of repository with the default save timeout, no alert evaluator and no
+// event publisher.
+func newExchangeRateSaver(repository entity.ExchangeRateRepositoryInterface) exchangeRateSaver {
+	return exchangeRateSaver{
+		repository:  repository,
+		saveTimeout: DefaultSaveTimeout,
+		now:         time.Now,
+	}
+}
+
+// setEventPublisher publishes the events of the saver to publisher, relaying entity.ExchangeRateSaved from the
+// outbox when the repository is an entity.EventOutbox.
+func (s *exchangeRateSaver) setEventPublisher(publisher EventPublisher) {
+	s.events = publisher
+	s.relay = nil
+	if outbox, ok := s.repository.(entity.EventOutbox); ok && publisher != nil {
+		s.relay = NewRelayOutboxUseCase(outbox, publisher)
+	}
+}
+
+// saveAll persists every exchange rate and returns the ones not stored before, as reported by the inserts
+// themselves, so a quote saved by concurrent fetches is returned by only one of them. When the repository is an
+// entity.UnitOfWork, either all of them are saved or none is. With an outbox relay, the entity.ExchangeRateSaved
+// events of the fresh ones are appended to the outbox in the same unit of work.
+func (s *exchangeRateSaver) saveAll(ctx context.Context, exchangeRates []*entity.CurrencyInfo) ([]*entity.CurrencyInfo, error) {
+	var fresh []*entity.CurrencyInfo
+	persist := func(ctx context.Context) error {
+		// A retried unit of work inserts again, so only the last attempt decides which rates are fresh.
+		fresh = nil
+		for _, exchangeRate := range exchangeRates {
+			inserted, err := insertWithin(ctx, s.repository, s.saveTimeout, exchangeRate)
+			if err != nil {
+				return err
+			}
+			if inserted {
+				fresh = append(fresh, exchangeRate)
+			}
+		}
+		if s.relay == nil || len(fresh) == 0 {
+			return nil
+		}
+		events := make([]entity.OutboxEvent, 0, len(fresh))
+		for _, exchangeRate := range fresh {
+			event, err := entity.NewOutboxEvent(s.savedEvent(exchangeRate))
+			if err != nil {
+				return err
+			}
+			events = append(events, event)
+		}
+		return s.relay.outbox.AppendEvents(ctx, events...)
+	}
+	var err error
+	if uow, ok := s.repository.(entity.UnitOfWork); ok {
+		err = uow.Do(ctx, persist)
+	} else {
+		err = persist(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return fresh, nil
+}
+
+// afterSave publishes the entity.ExchangeRateSaved events of the exchange rates saved for the first time and passes
+// them to the alert evaluator.
+func (s *exchangeRateSaver) afterSave(ctx context.Context, fresh []*entity.CurrencyInfo) {
+	s.publishSaved(ctx, fresh)
+	s.evaluateAlerts(ctx, fresh)
+}
+
+// publish publishes the event when an event publisher is set, logging its error.
+func (s *exchangeRateSaver) publish(ctx context.Context, event goeventbus.Event) {
+	if s.events == nil {
+		return
+	}
+	if err := s.events.Publish(ctx, event); err != nil {
+		log.Printf("Publishing event %s for %s failed: %v", event.EventName(), event.EventKey(), err)
+	}
+}
+
+// publishSaved publishes an entity.ExchangeRateSaved event for each exchange rate saved for the first time, relaying
+// them from the outbox they were stored in when the repository is an entity.EventOutbox.
+func (s *exchangeRateSaver) publishSaved(ctx context.Context, exchangeRates []*entity.CurrencyInfo) {
+	if s.relay != nil {
+		if _, err := s.relay.Execute(ctx); err != nil {
+			log.Printf("Relaying outbox events failed: %v", err)
+		}
+		return
+	}
+	for _, exchangeRate := range exchangeRates {
+		s.publish(ctx, s.savedEvent(exchangeRate))
+	}
+}
+
+// savedEvent returns the entity.ExchangeRateSaved event of the exchange rate.
+func (s *exchangeRateSaver) savedEvent(exchangeRate *entity.CurrencyInfo) entity.ExchangeRateSaved {
+	return entity.ExchangeRateSaved{Pair: GenerateExchangeRateSearchKey(exchangeRate.Code, exchangeRate.CodeIn), ExchangeRate: exchangeRate, OccurredAt: s.now()}
+}
+
+// evaluateAlerts passes the saved exchange rates to the alert evaluator and logs its errors.
+func (s *exchangeRateSaver) evaluateAlerts(ctx context.Context, exchangeRates []*entity.CurrencyInfo) {
+	if s.alerts == nil {
+		return
+	}
+	for _, exchangeRate := range exchangeRates {
+		if _, err := s.alerts.Execute(ctx, exchangeRate); err != nil {
+			log.Printf("Evaluating alerts for exchange rate %s failed: %v", exchangeRate.GetEntityID(), err)
+		}
+	}
+}
+
+// insertWithin inserts the exchange rate into the repository within timeout and reports whether it was not stored
+// yet; zero only applies the deadline of ctx.
+func insertWithin(ctx context.Context, repository entity.ExchangeRateRepositoryInterface, timeout time.Duration, exchangeRate *entity.CurrencyInfo) (bool, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	inserted, err := repository.Insert(ctx, exchangeRate)
+	var timeoutErr *entity.RepositoryTimeoutError
+	if errors.As(err, &timeoutErr) {
+		log.Printf("Saving exchange rate %s exceeded its deadline: %v", exchangeRate.GetEntityID(), err)
+	}
+	return inserted, err
+}
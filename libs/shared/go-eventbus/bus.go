@@ -0,0 +1,258 @@
+package goeventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"sync"
+)
+
+var (
+	// ErrBusClosed is returned when events are published or subscribed to after Close.
+	ErrBusClosed = errors.New("event bus closed")
+	// ErrInvalidSubscription is returned when a subscription has no event name or handler, or no worker.
+	ErrInvalidSubscription = errors.New("invalid subscription")
+)
+
+const (
+	// AllEvents subscribes a handler to the events of every name.
+	AllEvents = "*"
+	// DefaultQueueSize is the number of events each worker of an asynchronous subscriber buffers.
+	DefaultQueueSize = 64
+)
+
+// Event is published on a Bus. Events with the same key, such as the currency pair of an exchange rate, are delivered
+// to each subscriber in the order they were published.
+type Event interface {
+	// EventName is the name subscribers select events by, such as "exchange_rate.saved".
+	EventName() string
+	// EventKey orders the delivery of events.
+	EventKey() string
+}
+
+// Handler handles an event delivered by a Bus.
+type Handler func(ctx context.Context, event Event) error
+
+// Bus delivers events to the handlers subscribed to their name within the process. Synchronous handlers run in the
+// goroutine of the publisher; asynchronous handlers run in worker goroutines of their own.
+type Bus struct {
+	mu      sync.RWMutex
+	sync    []subscription
+	async   []*asyncSubscriber
+	closed  bool
+	done    chan struct{}
+	sending sync.WaitGroup
+	keysMu  sync.Mutex
+	keys    map[string]*keyLock
+	wg      sync.WaitGroup
+}
+
+// keyLock serializes the publications of a key. refs counts the publishers holding or waiting for it, so it is
+// dropped once the key is idle.
+type keyLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// subscription is a handler subscribed to the events named name.
+type subscription struct {
+	name    string
+	handler Handler
+}
+
+// matches reports whether the subscription receives the events named name.
+func (s subscription) matches(name string) bool {
+	return s.name == AllEvents || s.name == name
+}
+
+// asyncSubscriber delivers events to its handler from workers, each key always on the same worker.
+type asyncSubscriber struct {
+	subscription
+	queues []chan delivery
+}
+
+// delivery is an event queued for an asynchronous subscriber with the context it is handled with.
+type delivery struct {
+	ctx   context.Context
+	event Event
+}
+
+// NewBus creates a bus without subscribers.
+func NewBus() *Bus {
+	return &Bus{
+		done: make(chan struct{}),
+		keys: make(map[string]*keyLock),
+	}
+}
+
+// Subscribe registers handler to be called by Publish, in the goroutine of the publisher, for the events named name
+// or for every event with AllEvents. Its error is returned by Publish.
+func (b *Bus) Subscribe(name string, handler Handler) error {
+	if name == "" || handler == nil {
+		return ErrInvalidSubscription
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrBusClosed
+	}
+	b.sync = append(b.sync, subscription{name: name, handler: handler})
+	return nil
+}
+
+// SubscribeAsync registers handler to be called in workers goroutines for the events named name or for every event
+// with AllEvents. The events of a key are always handled by the same worker, so they are handled in order, while
+// different keys are handled concurrently. Errors are logged.
+func (b *Bus) SubscribeAsync(name string, workers int, handler Handler) error {
+	if name == "" || handler == nil || workers <= 0 {
+		return ErrInvalidSubscription
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrBusClosed
+	}
+	subscriber := &asyncSubscriber{
+		subscription: subscription{name: name, handler: handler},
+		queues:       make([]chan delivery, workers),
+	}
+	for i := range subscriber.queues {
+		queue := make(chan delivery, DefaultQueueSize)
+		subscriber.queues[i] = queue
+		b.wg.Add(1)
+		go func() {
+			defer b.wg.Done()
+			for d := range queue {
+				if err := handle(d.ctx, subscriber.handler, d.event); err != nil {
+					log.Printf("Handling event %s for %s failed: %v", d.event.EventName(), d.event.EventKey(), err)
+				}
+			}
+		}()
+	}
+	b.async = append(b.async, subscriber)
+	return nil
+}
+
+// Publish delivers event to the synchronous subscribers, in the order they subscribed, then queues it for the
+// asynchronous subscribers, waiting for room in their queues until ctx is done. Asynchronous handlers get a context
+// with the values of ctx that is never canceled. The errors of the synchronous handlers are joined and returned.
+// Events with the same key are published one at a time, so a synchronous handler must not publish an event with the
+// key of the event it handles.
+func (b *Bus) Publish(ctx context.Context, event Event) error {
+	unlock := b.lockKey(event.EventKey())
+	defer unlock()
+
+	b.mu.RLock()
+	closed, subscriptions := b.closed, b.sync
+	b.mu.RUnlock()
+	if closed {
+		return ErrBusClosed
+	}
+
+	var errs []error
+	for _, s := range subscriptions {
+		if !s.matches(event.EventName()) {
+			continue
+		}
+		if err := handle(ctx, s.handler, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := b.enqueue(ctx, event); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// enqueue queues event on the worker of its key of each matching asynchronous subscriber. It waits for room without
+// holding the lock of the bus, and gives up with ErrBusClosed when the bus is closed meanwhile.
+func (b *Bus) enqueue(ctx context.Context, event Event) error {
+	b.mu.RLock()
+	if b.closed {
+		b.mu.RUnlock()
+		return ErrBusClosed
+	}
+	// Close waits for the senders before closing the queues, so none of them sends on a closed queue.
+	b.sending.Add(1)
+	defer b.sending.Done()
+	subscribers := b.async
+	b.mu.RUnlock()
+
+	d := delivery{ctx: context.WithoutCancel(ctx), event: event}
+	worker := keyHash(event.EventKey())
+	for _, subscriber := range subscribers {
+		if !subscriber.matches(event.EventName()) {
+			continue
+		}
+		select {
+		case subscriber.queues[worker%uint32(len(subscriber.queues))] <- d:
+		case <-ctx.Done():
+			return fmt.Errorf("queueing event %s: %w", event.EventName(), ctx.Err())
+		case <-b.done:
+			return fmt.Errorf("queueing event %s: %w", event.EventName(), ErrBusClosed)
+		}
+	}
+	return nil
+}
+
+// lockKey serializes the publications of the key and returns the function releasing it. The lock of a key is
+// dropped when its last publisher releases it.
+func (b *Bus) lockKey(key string) func() {
+	b.keysMu.Lock()
+	lock, ok := b.keys[key]
+	if !ok {
+		lock = &keyLock{}
+		b.keys[key] = lock
+	}
+	lock.refs++
+	b.keysMu.Unlock()
+	lock.mu.Lock()
+	return func() {
+		lock.mu.Unlock()
+		b.keysMu.Lock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(b.keys, key)
+		}
+		b.keysMu.Unlock()
+	}
+}
+
+// Close stops accepting events and subscriptions, then waits until the asynchronous subscribers handled the events
+// already queued. Publishers waiting for room in a full queue give up with ErrBusClosed.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	close(b.done)
+	b.mu.Unlock()
+	b.sending.Wait()
+	for _, subscriber := range b.async {
+		for _, queue := range subscriber.queues {
+			close(queue)
+		}
+	}
+	b.wg.Wait()
+}
+
+// handle calls handler, returning a panic as an error.
+func handle(ctx context.Context, handler Handler, event Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+	return handler(ctx, event)
+}
+
+// keyHash spreads the keys over the workers of asynchronous subscribers.
+func keyHash(key string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return h.Sum32()
+}
@@ -0,0 +1,238 @@
+package goeventbus
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// testEvent is an event named name with the key key and a sequence number.
+type testEvent struct {
+	name string
+	key  string
+	seq  int
+}
+
+func (e testEvent) EventName() string { return e.name }
+
+func (e testEvent) EventKey() string { return e.key }
+
+type BusTestSuite struct {
+	suite.Suite
+	bus *Bus
+}
+
+func TestBusTestSuite(t *testing.T) {
+	suite.Run(t, new(BusTestSuite))
+}
+
+func (suite *BusTestSuite) SetupTest() {
+	suite.bus = NewBus()
+}
+
+func (suite *BusTestSuite) TearDownTest() {
+	suite.bus.Close()
+}
+
+func (suite *BusTestSuite) TestSubscribeDeliversMatchingEventsInOrder() {
+	var received []string
+	record := func(prefix string) Handler {
+		return func(ctx context.Context, event Event) error {
+			received = append(received, prefix+event.EventName())
+			return nil
+		}
+	}
+	assert.NoError(suite.T(), suite.bus.Subscribe("saved", record("first:")))
+	assert.NoError(suite.T(), suite.bus.Subscribe(AllEvents, record("all:")))
+
+	assert.NoError(suite.T(), suite.bus.Publish(context.Background(), testEvent{name: "saved", key: "USD-BRL"}))
+	assert.NoError(suite.T(), suite.bus.Publish(context.Background(), testEvent{name: "fetched", key: "USD-BRL"}))
+
+	assert.Equal(suite.T(), []string{"first:saved", "all:saved", "all:fetched"}, received)
+}
+
+func (suite *BusTestSuite) TestPublishJoinsSynchronousErrorsAndRecoversPanics() {
+	failure := errors.New("handler failed")
+	called := false
+	suite.bus.Subscribe("saved", func(ctx context.Context, event Event) error { return failure })
+	suite.bus.Subscribe("saved", func(ctx context.Context, event Event) error { panic("boom") })
+	suite.bus.Subscribe("saved", func(ctx context.Context, event Event) error {
+		called = true
+		return nil
+	})
+
+	err := suite.bus.Publish(context.Background(), testEvent{name: "saved", key: "USD-BRL"})
+
+	assert.ErrorIs(suite.T(), err, failure)
+	assert.ErrorContains(suite.T(), err, "handler panicked: boom")
+	assert.True(suite.T(), called, "a failing handler does not stop the others")
+}
+
+func (suite *BusTestSuite) TestSubscribeAsyncDeliversEachKeyInOrder() {
+	var mu sync.Mutex
+	received := map[string][]int{}
+	err := suite.bus.SubscribeAsync(AllEvents, 4, func(ctx context.Context, event Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		received[event.EventKey()] = append(received[event.EventKey()], event.(testEvent).seq)
+		return nil
+	})
+	assert.NoError(suite.T(), err)
+
+	var want []int
+	for seq := 0; seq < 100; seq++ {
+		want = append(want, seq)
+		for _, key := range []string{"USD-BRL", "EUR-BRL", "GBP-BRL"} {
+			assert.NoError(suite.T(), suite.bus.Publish(context.Background(), testEvent{name: "saved", key: key, seq: seq}))
+		}
+	}
+	suite.bus.Close()
+
+	assert.Equal(suite.T(), map[string][]int{"USD-BRL": want, "EUR-BRL": want, "GBP-BRL": want}, received)
+}
+
+func (suite *BusTestSuite) TestSubscribeAsyncDoesNotBlockThePublisher() {
+	release := make(chan struct{})
+	handled := make(chan Event, 1)
+	suite.bus.SubscribeAsync("saved", 1, func(ctx context.Context, event Event) error {
+		<-release
+		handled <- event
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err := suite.bus.Publish(ctx, testEvent{name: "saved", key: "USD-BRL"})
+	cancel()
+	close(release)
+
+	assert.NoError(suite.T(), err)
+	select {
+	case event := <-handled:
+		assert.Equal(suite.T(), "USD-BRL", event.EventKey(), "the handler runs after the publisher context is canceled")
+	case <-time.After(time.Second):
+		suite.T().Fatal("the event was not handled")
+	}
+}
+
+func (suite *BusTestSuite) TestPublishWaitsForRoomUntilTheContextIsDone() {
+	release := make(chan struct{})
+	defer close(release)
+	suite.bus.SubscribeAsync("saved", 1, func(ctx context.Context, event Event) error {
+		<-release
+		return nil
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	var err error
+	for i := 0; i < DefaultQueueSize+2 && err == nil; i++ {
+		err = suite.bus.Publish(ctx, testEvent{name: "saved", key: "USD-BRL", seq: i})
+	}
+
+	assert.ErrorIs(suite.T(), err, context.DeadlineExceeded)
+}
+
+func (suite *BusTestSuite) TestPublishSerializesEventsOfAKey() {
+	var mu sync.Mutex
+	running, overlapped := 0, false
+	suite.bus.Subscribe("saved", func(ctx context.Context, event Event) error {
+		mu.Lock()
+		running++
+		overlapped = overlapped || running > 1
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			suite.bus.Publish(context.Background(), testEvent{name: "saved", key: "USD-BRL"})
+		}()
+	}
+	wg.Wait()
+
+	assert.False(suite.T(), overlapped)
+}
+
+func (suite *BusTestSuite) TestPublishForgetsIdleKeys() {
+	suite.bus.Subscribe("saved", func(ctx context.Context, event Event) error { return nil })
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			suite.bus.Publish(context.Background(), testEvent{name: "saved", key: []string{"USD-BRL", "EUR-BRL", "GBP-BRL"}[i%3], seq: i})
+		}(i)
+	}
+	wg.Wait()
+
+	suite.bus.keysMu.Lock()
+	defer suite.bus.keysMu.Unlock()
+	assert.Empty(suite.T(), suite.bus.keys)
+}
+
+func (suite *BusTestSuite) TestCloseReleasesPublishersWaitingForRoom() {
+	release := make(chan struct{})
+	suite.bus.SubscribeAsync("saved", 1, func(ctx context.Context, event Event) error {
+		<-release
+		return nil
+	})
+	// The worker holds the first event and its queue the next DefaultQueueSize.
+	for i := 0; i <= DefaultQueueSize; i++ {
+		suite.Require().NoError(suite.bus.Publish(context.Background(), testEvent{name: "saved", key: "USD-BRL", seq: i}))
+	}
+	published := make(chan error, 1)
+	go func() {
+		published <- suite.bus.Publish(context.Background(), testEvent{name: "saved", key: "USD-BRL"})
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	closed := make(chan struct{})
+	go func() {
+		suite.bus.Close()
+		close(closed)
+	}()
+
+	select {
+	case err := <-published:
+		assert.ErrorIs(suite.T(), err, ErrBusClosed)
+	case <-time.After(time.Second):
+		suite.T().Fatal("the publisher waiting for room was not released by Close")
+	}
+	close(release)
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		suite.T().Fatal("Close did not return once the queued events were handled")
+	}
+}
+
+func (suite *BusTestSuite) TestCloseRejectsEvents() {
+	suite.bus.Close()
+
+	assert.ErrorIs(suite.T(), suite.bus.Publish(context.Background(), testEvent{name: "saved", key: "USD-BRL"}), ErrBusClosed)
+	assert.ErrorIs(suite.T(), suite.bus.Subscribe("saved", func(ctx context.Context, event Event) error { return nil }), ErrBusClosed)
+}
+
+func (suite *BusTestSuite) TestRejectsInvalidSubscriptions() {
+	handler := func(ctx context.Context, event Event) error { return nil }
+	for name, err := range map[string]error{
+		"no name":    suite.bus.Subscribe("", handler),
+		"no handler": suite.bus.Subscribe("saved", nil),
+		"no worker":  suite.bus.SubscribeAsync("saved", 0, handler),
+	} {
+		assert.True(suite.T(), errors.Is(err, ErrInvalidSubscription), "%s: unexpected error: %v", name, err)
+	}
+}
@@ -0,0 +1,3 @@
+module libs/shared/go-eventbus
+
+go 1.22
@@ -151,7 +151,8 @@ func CreateRequest(
 }
 
 // SendRequest sends the given HTTP request using the provided client.
-// It waits for the response or times out after the specified duration. The response body is decoded into the result parameter.
+// It waits for the response or times out after the specified duration. The response body is decoded into the result parameter,
+// as XML when the response Content-Type is XML (such as "text/xml" or "application/xml") and as JSON otherwise.
 // Returns an error if the request fails, times out, or the response status is not 2xx.
 func SendRequest(
 	ctx context.Context,
@@ -188,9 +189,17 @@ func SendRequest(
 			return fmt.Errorf("HTTP request failed: %s", res.resp.Status)
 		}
 
-		if err := json.NewDecoder(res.resp.Body).Decode(result); err != nil {
+		if err := decodeBody(res.resp, result); err != nil {
 			return fmt.Errorf("failed to decode response body: %w", err)
 		}
 		return nil
 	}
 }
+
+// decodeBody decodes the response body into result as XML when the response Content-Type is XML, and as JSON otherwise.
+func decodeBody(resp *http.Response, result interface{}) error {
+	if strings.Contains(resp.Header.Get("Content-Type"), "xml") {
+		return xml.NewDecoder(resp.Body).Decode(result)
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
@@ -213,6 +213,28 @@ func (suite *RequestTestSuite) TestSendRequest_Success() {
 	assert.Equal(suite.T(), expectedResult.Message, result.Message)
 }
 
+type MockXMLResponse struct {
+	Message string `xml:"message"`
+}
+
+func (suite *RequestTestSuite) TestSendRequest_DecodesXML() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><response><message>success</message></response>`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	req, err := CreateRequest(ctx, server.URL, nil, nil, nil, map[string]string{"Accept": "text/xml"}, http.MethodGet)
+	assert.Nil(suite.T(), err)
+
+	var result MockXMLResponse
+	err = SendRequest(ctx, req, server.Client(), &result, 200*time.Millisecond)
+	assert.Nil(suite.T(), err)
+
+	assert.Equal(suite.T(), "success", result.Message)
+}
+
 func (suite *RequestTestSuite) TestSendRequest_Timeout() {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		time.Sleep(300 * time.Millisecond)
@@ -0,0 +1,3 @@
+module libs/shared/go-scheduler
+
+go 1.22
@@ -0,0 +1,224 @@
+package goscheduler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidSchedule is wrapped by the errors of Parse and ParseCron.
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+// Schedule computes the run times of a job.
+type Schedule interface {
+	// Next returns the first run time strictly after t, or the zero time when the schedule never runs again.
+	Next(t time.Time) time.Time
+	// String returns the spec of the schedule.
+	String() string
+}
+
+// Parse parses a cron expression or a descriptor: "@every 30s", "@hourly", "@daily" or "@midnight", "@weekly",
+// "@monthly" and "@yearly" or "@annually".
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		interval, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil || interval <= 0 {
+			return nil, fmt.Errorf("%w: %q needs a positive duration", ErrInvalidSchedule, spec)
+		}
+		return Every(interval), nil
+	}
+	switch spec {
+	case "@yearly", "@annually":
+		return ParseCron("0 0 1 1 *")
+	case "@monthly":
+		return ParseCron("0 0 1 * *")
+	case "@weekly":
+		return ParseCron("0 0 * * 0")
+	case "@daily", "@midnight":
+		return ParseCron("0 0 * * *")
+	case "@hourly":
+		return ParseCron("0 * * * *")
+	}
+	return ParseCron(spec)
+}
+
+// IntervalSchedule runs every Interval.
+type IntervalSchedule struct {
+	Interval time.Duration
+}
+
+// Every returns a Schedule running every interval, which must be positive.
+func Every(interval time.Duration) IntervalSchedule {
+	return IntervalSchedule{Interval: interval}
+}
+
+// Next returns t plus the interval.
+func (s IntervalSchedule) Next(t time.Time) time.Time {
+	return t.Add(s.Interval)
+}
+
+// String returns the schedule as "@every 30s".
+func (s IntervalSchedule) String() string {
+	return "@every " + s.Interval.String()
+}
+
+// CronSchedule runs at the minutes matching a five-field cron expression, in the location of the time given to Next.
+type CronSchedule struct {
+	spec                                  string
+	minute, hour, dayOfMonth, month, week uint64
+	// anyDay is set when the day of the month or of the week is "*": a day then has to match both fields, as in cron,
+	// rather than either of them.
+	anyDay bool
+}
+
+// cronField describes the values of a field of a cron expression.
+type cronField struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var (
+	minuteField     = cronField{name: "minute", min: 0, max: 59}
+	hourField       = cronField{name: "hour", min: 0, max: 23}
+	dayOfMonthField = cronField{name: "day of month", min: 1, max: 31}
+	monthField      = cronField{name: "month", min: 1, max: 12,
+		names: []string{"", "JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}}
+	// dayOfWeekField accepts 7 for Sunday, folded to 0.
+	dayOfWeekField = cronField{name: "day of week", min: 0, max: 7,
+		names: []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}}
+)
+
+// ParseCron parses a cron expression of five fields: minute, hour, day of month, month and day of week.
+// Each field is "*", a value, a range "1-5" or a list "1,15", optionally stepped as "*/15" or "1-30/5".
+// Months and days of the week also accept their three-letter English names.
+func ParseCron(spec string) (*CronSchedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: %q needs 5 fields, got %d", ErrInvalidSchedule, spec, len(fields))
+	}
+	schedule := &CronSchedule{
+		spec:   strings.Join(fields, " "),
+		anyDay: strings.HasPrefix(fields[2], "*") || strings.HasPrefix(fields[4], "*"),
+	}
+	var err error
+	for i, target := range []struct {
+		field cronField
+		bits  *uint64
+	}{
+		{minuteField, &schedule.minute},
+		{hourField, &schedule.hour},
+		{dayOfMonthField, &schedule.dayOfMonth},
+		{monthField, &schedule.month},
+		{dayOfWeekField, &schedule.week},
+	} {
+		if *target.bits, err = target.field.parse(fields[i]); err != nil {
+			return nil, fmt.Errorf("%w: %q: %v", ErrInvalidSchedule, spec, err)
+		}
+	}
+	if schedule.week&(1<<7) != 0 {
+		schedule.week = schedule.week&^(1<<7) | 1
+	}
+	return schedule, nil
+}
+
+// parse returns the values of the field as a bit set.
+func (f cronField) parse(expression string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(expression, ",") {
+		valueRange, stepText, stepped := strings.Cut(part, "/")
+		step := 1
+		if stepped {
+			var err error
+			if step, err = strconv.Atoi(stepText); err != nil || step <= 0 {
+				return 0, fmt.Errorf("%s: invalid step %q", f.name, stepText)
+			}
+		}
+		low, high := f.min, f.max
+		if valueRange != "*" {
+			lowText, highText, isRange := strings.Cut(valueRange, "-")
+			var err error
+			if low, err = f.value(lowText); err != nil {
+				return 0, err
+			}
+			high = low
+			if isRange {
+				if high, err = f.value(highText); err != nil {
+					return 0, err
+				}
+			} else if stepped {
+				high = f.max
+			}
+			if low > high {
+				return 0, fmt.Errorf("%s: range %q is reversed", f.name, valueRange)
+			}
+		}
+		for value := low; value <= high; value += step {
+			bits |= 1 << value
+		}
+	}
+	return bits, nil
+}
+
+// value parses a number or a name of the field.
+func (f cronField) value(text string) (int, error) {
+	for i, name := range f.names {
+		if name != "" && strings.EqualFold(text, name) {
+			return i, nil
+		}
+	}
+	value, err := strconv.Atoi(text)
+	if err != nil || value < f.min || value > f.max {
+		return 0, fmt.Errorf("%s: %q is not between %d and %d", f.name, text, f.min, f.max)
+	}
+	return value, nil
+}
+
+// cronSearchYears bounds the search of Next, so that expressions such as "0 0 30 2 *" never running end.
+const cronSearchYears = 5
+
+// Next returns the first minute strictly after t matching the expression, in the location of t.
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	location := t.Location()
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, location).Add(time.Minute)
+	yearLimit := t.Year() + cronSearchYears
+
+	for t.Year() <= yearLimit {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, location)
+			continue
+		}
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, location)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, location)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// matchesDay reports whether the day of t matches the day of the month and the day of the week.
+func (s *CronSchedule) matchesDay(t time.Time) bool {
+	dayOfMonth := s.dayOfMonth&(1<<uint(t.Day())) != 0
+	dayOfWeek := s.week&(1<<uint(t.Weekday())) != 0
+	if s.anyDay {
+		return dayOfMonth && dayOfWeek
+	}
+	return dayOfMonth || dayOfWeek
+}
+
+// String returns the cron expression.
+func (s *CronSchedule) String() string {
+	return s.spec
+}
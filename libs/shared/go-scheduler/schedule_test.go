@@ -0,0 +1,81 @@
+package goscheduler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ScheduleTestSuite struct {
+	suite.Suite
+	// from is Wednesday, 2021-07-21 17:40:30 UTC.
+	from time.Time
+}
+
+func TestScheduleTestSuite(t *testing.T) {
+	suite.Run(t, new(ScheduleTestSuite))
+}
+
+func (suite *ScheduleTestSuite) SetupTest() {
+	suite.from = time.Date(2021, 7, 21, 17, 40, 30, 0, time.UTC)
+}
+
+func (suite *ScheduleTestSuite) next(spec string) time.Time {
+	schedule, err := Parse(spec)
+	suite.Require().NoError(err, spec)
+	return schedule.Next(suite.from)
+}
+
+func (suite *ScheduleTestSuite) TestNext() {
+	for spec, expected := range map[string]time.Time{
+		"* * * * *":         time.Date(2021, 7, 21, 17, 41, 0, 0, time.UTC),
+		"*/15 * * * *":      time.Date(2021, 7, 21, 17, 45, 0, 0, time.UTC),
+		"5 9-17 * * *":      time.Date(2021, 7, 22, 9, 5, 0, 0, time.UTC),
+		"0,30 18 * * *":     time.Date(2021, 7, 21, 18, 0, 0, 0, time.UTC),
+		"0 10 * * MON-FRI":  time.Date(2021, 7, 22, 10, 0, 0, 0, time.UTC),
+		"0 10 * * 7":        time.Date(2021, 7, 25, 10, 0, 0, 0, time.UTC),
+		"0 0 1 jan *":       time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		"0 0 29 2 *":        time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+		"0 0 1 * 5":         time.Date(2021, 7, 23, 0, 0, 0, 0, time.UTC),
+		"10/20 17 21 7 *":   time.Date(2021, 7, 21, 17, 50, 0, 0, time.UTC),
+		"@hourly":           time.Date(2021, 7, 21, 18, 0, 0, 0, time.UTC),
+		"@daily":            time.Date(2021, 7, 22, 0, 0, 0, 0, time.UTC),
+		"@weekly":           time.Date(2021, 7, 25, 0, 0, 0, 0, time.UTC),
+		"@monthly":          time.Date(2021, 8, 1, 0, 0, 0, 0, time.UTC),
+		"@every 90s":        time.Date(2021, 7, 21, 17, 42, 0, 0, time.UTC),
+		" 0  12 * *   * ":   time.Date(2021, 7, 22, 12, 0, 0, 0, time.UTC),
+		"0 0 31 4,6,9,11 *": {},
+	} {
+		assert.Equal(suite.T(), expected, suite.next(spec), spec)
+	}
+}
+
+func (suite *ScheduleTestSuite) TestNextKeepsLocation() {
+	saoPaulo := time.FixedZone("BRT", -3*60*60)
+	schedule, err := ParseCron("0 10 * * *")
+	suite.Require().NoError(err)
+
+	next := schedule.Next(suite.from.In(saoPaulo))
+
+	assert.Equal(suite.T(), time.Date(2021, 7, 22, 10, 0, 0, 0, saoPaulo), next)
+}
+
+func (suite *ScheduleTestSuite) TestString() {
+	schedule, err := Parse(" */5  * * * * ")
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "*/5 * * * *", schedule.String())
+	assert.Equal(suite.T(), "@every 1m30s", Every(90*time.Second).String())
+}
+
+func (suite *ScheduleTestSuite) TestParseRejectsInvalidSpecs() {
+	for _, spec := range []string{
+		"", "* * * *", "* * * * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8",
+		"*/0 * * * *", "5-1 * * * *", "a * * * *", "* * * FOO *", "@every", "@every -1s", "@every soon", "@often",
+	} {
+		_, err := Parse(spec)
+		assert.True(suite.T(), errors.Is(err, ErrInvalidSchedule), "%q: unexpected error: %v", spec, err)
+	}
+}
@@ -0,0 +1,225 @@
+package goscheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidJob is returned when a job has no name, schedule or function, or a negative jitter or timeout.
+	ErrInvalidJob = errors.New("invalid job")
+	// ErrDuplicateJob is returned when a job has the name of a job already added.
+	ErrDuplicateJob = errors.New("job already added")
+	// ErrSchedulerStarted is returned when jobs are added to, or Run is called on, a running scheduler.
+	ErrSchedulerStarted = errors.New("scheduler already started")
+)
+
+// Job is a function run on a Schedule.
+type Job struct {
+	// Name identifies the job in the status of the scheduler.
+	Name     string
+	Schedule Schedule
+	// Jitter delays each run by a random duration below it, so jobs sharing a schedule do not all run at once.
+	Jitter time.Duration
+	// Timeout bounds each run when positive.
+	Timeout time.Duration
+	Run     func(ctx context.Context) error
+}
+
+// JobStatus reports the runs of a job. Times are zero until they happen.
+type JobStatus struct {
+	Name     string `json:"name"`
+	Schedule string `json:"schedule"`
+	Running  bool   `json:"running"`
+	Runs     int    `json:"runs"`
+	Failures int    `json:"failures"`
+	// Skipped counts the runs left out because the previous run had not finished.
+	Skipped      int       `json:"skipped"`
+	LastRun      time.Time `json:"lastRun"`
+	LastFinished time.Time `json:"lastFinished"`
+	LastError    string    `json:"lastError,omitempty"`
+	NextRun      time.Time `json:"nextRun"`
+}
+
+// Scheduler runs jobs on their schedules. A job never overlaps itself: a run due while the previous one is still
+// running is skipped.
+type Scheduler struct {
+	mu      sync.Mutex
+	jobs    []*scheduledJob
+	started bool
+	now     func() time.Time
+	jitter  func(max time.Duration) time.Duration
+}
+
+// scheduledJob is a job and its status.
+type scheduledJob struct {
+	job    Job
+	status JobStatus
+}
+
+// NewScheduler creates and returns a new Scheduler without jobs.
+func NewScheduler() *Scheduler {
+	return &Scheduler{
+		now: time.Now,
+		jitter: func(max time.Duration) time.Duration {
+			return time.Duration(rand.Int63n(int64(max)))
+		},
+	}
+}
+
+// Add adds a job. Jobs must be added before Run.
+func (s *Scheduler) Add(job Job) error {
+	if job.Name == "" || job.Schedule == nil || job.Run == nil || job.Jitter < 0 || job.Timeout < 0 {
+		return fmt.Errorf("%w: %q", ErrInvalidJob, job.Name)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return ErrSchedulerStarted
+	}
+	for _, scheduled := range s.jobs {
+		if scheduled.job.Name == job.Name {
+			return fmt.Errorf("%w: %q", ErrDuplicateJob, job.Name)
+		}
+	}
+	s.jobs = append(s.jobs, &scheduledJob{
+		job:    job,
+		status: JobStatus{Name: job.Name, Schedule: job.Schedule.String()},
+	})
+	return nil
+}
+
+// Run runs the jobs until ctx is done, then waits for the runs in progress, which see ctx done, to return.
+func (s *Scheduler) Run(ctx context.Context) error {
+	s.mu.Lock()
+	if s.started {
+		s.mu.Unlock()
+		return ErrSchedulerStarted
+	}
+	s.started = true
+	jobs := s.jobs
+	s.mu.Unlock()
+
+	var loops, runs sync.WaitGroup
+	for _, scheduled := range jobs {
+		loops.Add(1)
+		go func(scheduled *scheduledJob) {
+			defer loops.Done()
+			s.loop(ctx, scheduled, &runs)
+		}(scheduled)
+	}
+	loops.Wait()
+	runs.Wait()
+	return nil
+}
+
+// Status returns the status of each job, in the order they were added.
+func (s *Scheduler) Status() []JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := make([]JobStatus, len(s.jobs))
+	for i, scheduled := range s.jobs {
+		status[i] = scheduled.status
+	}
+	return status
+}
+
+// ServeHTTP writes the status of the jobs as JSON, so the Scheduler can be mounted on /jobs.
+func (s *Scheduler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(s.Status()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// loop waits for each run time of the job and starts the run, until ctx is done or the schedule ends.
+func (s *Scheduler) loop(ctx context.Context, scheduled *scheduledJob, runs *sync.WaitGroup) {
+	for {
+		next := s.next(scheduled)
+		if next.IsZero() {
+			log.Printf("Job %s has no next run", scheduled.job.Name)
+			return
+		}
+		timer := time.NewTimer(next.Sub(s.now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			s.mu.Lock()
+			scheduled.status.NextRun = time.Time{}
+			s.mu.Unlock()
+			return
+		case <-timer.C:
+		}
+		if ctx.Err() != nil {
+			continue
+		}
+
+		s.mu.Lock()
+		if scheduled.status.Running {
+			scheduled.status.Skipped++
+			s.mu.Unlock()
+			log.Printf("Skipping job %s: the previous run has not finished", scheduled.job.Name)
+			continue
+		}
+		scheduled.status.Running = true
+		scheduled.status.LastRun = s.now()
+		s.mu.Unlock()
+
+		runs.Add(1)
+		go func() {
+			defer runs.Done()
+			s.run(ctx, scheduled)
+		}()
+	}
+}
+
+// next computes the next run time of the job, with jitter, and records it in its status.
+func (s *Scheduler) next(scheduled *scheduledJob) time.Time {
+	next := scheduled.job.Schedule.Next(s.now())
+	if !next.IsZero() && scheduled.job.Jitter > 0 {
+		next = next.Add(s.jitter(scheduled.job.Jitter))
+	}
+	s.mu.Lock()
+	scheduled.status.NextRun = next
+	s.mu.Unlock()
+	return next
+}
+
+// run runs the job once and records the outcome. A panic of the job is recorded as its error.
+func (s *Scheduler) run(ctx context.Context, scheduled *scheduledJob) {
+	var err error
+	func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = fmt.Errorf("job panicked: %v", recovered)
+			}
+		}()
+		if scheduled.job.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, scheduled.job.Timeout)
+			defer cancel()
+		}
+		err = scheduled.job.Run(ctx)
+	}()
+	if err != nil {
+		log.Printf("Job %s failed: %v", scheduled.job.Name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	scheduled.status.Running = false
+	scheduled.status.Runs++
+	scheduled.status.LastFinished = s.now()
+	scheduled.status.LastError = ""
+	if err != nil {
+		scheduled.status.Failures++
+		scheduled.status.LastError = err.Error()
+	}
+}
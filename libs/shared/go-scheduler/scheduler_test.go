@@ -0,0 +1,197 @@
+package goscheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type SchedulerTestSuite struct {
+	suite.Suite
+	scheduler *Scheduler
+	ctx       context.Context
+	cancel    context.CancelFunc
+	done      chan error
+}
+
+func TestSchedulerTestSuite(t *testing.T) {
+	suite.Run(t, new(SchedulerTestSuite))
+}
+
+func (suite *SchedulerTestSuite) SetupTest() {
+	suite.scheduler = NewScheduler()
+	suite.ctx, suite.cancel = context.WithCancel(context.Background())
+	suite.done = make(chan error, 1)
+}
+
+func (suite *SchedulerTestSuite) TearDownTest() {
+	suite.cancel()
+}
+
+// start runs the scheduler in the background until the test cancels it.
+func (suite *SchedulerTestSuite) start() {
+	go func() {
+		suite.done <- suite.scheduler.Run(suite.ctx)
+	}()
+}
+
+// stop cancels the scheduler and waits for Run to return.
+func (suite *SchedulerTestSuite) stop() {
+	suite.cancel()
+	select {
+	case err := <-suite.done:
+		assert.NoError(suite.T(), err)
+	case <-time.After(time.Second):
+		suite.FailNow("scheduler did not stop")
+	}
+}
+
+func (suite *SchedulerTestSuite) TestRunsJobsOnTheirSchedule() {
+	var runs atomic.Int32
+	suite.Require().NoError(suite.scheduler.Add(Job{Name: "poll", Schedule: Every(5 * time.Millisecond), Run: func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	}}))
+	suite.Require().NoError(suite.scheduler.Add(Job{Name: "failing", Schedule: Every(5 * time.Millisecond), Run: func(ctx context.Context) error {
+		return errors.New("upstream unavailable")
+	}}))
+
+	suite.start()
+	assert.Eventually(suite.T(), func() bool { return runs.Load() >= 3 }, time.Second, time.Millisecond)
+	suite.stop()
+
+	status := suite.scheduler.Status()
+	assert.Equal(suite.T(), "poll", status[0].Name)
+	assert.Equal(suite.T(), "@every 5ms", status[0].Schedule)
+	assert.Equal(suite.T(), int(runs.Load()), status[0].Runs)
+	assert.Zero(suite.T(), status[0].Failures)
+	assert.False(suite.T(), status[0].LastRun.IsZero())
+	assert.False(suite.T(), status[0].LastFinished.Before(status[0].LastRun))
+	assert.True(suite.T(), status[0].NextRun.IsZero(), "a stopped scheduler has no next run")
+	assert.Equal(suite.T(), status[1].Runs, status[1].Failures)
+	assert.Equal(suite.T(), "upstream unavailable", status[1].LastError)
+}
+
+func (suite *SchedulerTestSuite) TestSkipsRunsWhileThePreviousOneRuns() {
+	release := make(chan struct{})
+	var runs atomic.Int32
+	suite.Require().NoError(suite.scheduler.Add(Job{Name: "slow", Schedule: Every(2 * time.Millisecond), Run: func(ctx context.Context) error {
+		runs.Add(1)
+		<-release
+		return nil
+	}}))
+
+	suite.start()
+	assert.Eventually(suite.T(), func() bool { return suite.scheduler.Status()[0].Skipped >= 3 }, time.Second, time.Millisecond)
+	status := suite.scheduler.Status()[0]
+	assert.True(suite.T(), status.Running)
+	assert.Equal(suite.T(), int32(1), runs.Load())
+	assert.False(suite.T(), status.NextRun.IsZero())
+
+	close(release)
+	suite.stop()
+	assert.False(suite.T(), suite.scheduler.Status()[0].Running)
+}
+
+func (suite *SchedulerTestSuite) TestStopWaitsForRunsInProgress() {
+	started := make(chan struct{})
+	var finished atomic.Bool
+	suite.Require().NoError(suite.scheduler.Add(Job{Name: "poll", Schedule: Every(time.Millisecond), Run: func(ctx context.Context) error {
+		if finished.Load() {
+			return nil
+		}
+		close(started)
+		<-ctx.Done()
+		time.Sleep(5 * time.Millisecond)
+		finished.Store(true)
+		return ctx.Err()
+	}}))
+
+	suite.start()
+	<-started
+	suite.stop()
+
+	assert.True(suite.T(), finished.Load(), "Run returned before the job")
+	assert.Equal(suite.T(), context.Canceled.Error(), suite.scheduler.Status()[0].LastError)
+}
+
+func (suite *SchedulerTestSuite) TestJitterAndTimeout() {
+	var jitters []time.Duration
+	suite.scheduler.jitter = func(max time.Duration) time.Duration {
+		jitters = append(jitters, max)
+		return max / 2
+	}
+	start := time.Date(2021, 7, 21, 17, 40, 0, 0, time.UTC)
+	suite.scheduler.now = func() time.Time { return start }
+	suite.Require().NoError(suite.scheduler.Add(Job{Name: "poll", Schedule: Every(time.Minute), Jitter: 10 * time.Second, Run: func(ctx context.Context) error {
+		return nil
+	}}))
+
+	next := suite.scheduler.next(suite.scheduler.jobs[0])
+
+	assert.Equal(suite.T(), start.Add(time.Minute+5*time.Second), next)
+	assert.Equal(suite.T(), next, suite.scheduler.Status()[0].NextRun)
+	assert.Equal(suite.T(), []time.Duration{10 * time.Second}, jitters)
+
+	timedOut := &scheduledJob{job: Job{Name: "slow", Timeout: time.Millisecond, Run: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}}
+	suite.scheduler.run(context.Background(), timedOut)
+	assert.Equal(suite.T(), context.DeadlineExceeded.Error(), timedOut.status.LastError)
+}
+
+func (suite *SchedulerTestSuite) TestRecordsPanicsAsFailures() {
+	panicking := &scheduledJob{job: Job{Name: "panicking", Run: func(ctx context.Context) error {
+		panic("boom")
+	}}}
+
+	suite.scheduler.run(context.Background(), panicking)
+
+	assert.Equal(suite.T(), 1, panicking.status.Failures)
+	assert.Equal(suite.T(), "job panicked: boom", panicking.status.LastError)
+}
+
+func (suite *SchedulerTestSuite) TestAddRejectsInvalidJobs() {
+	run := func(ctx context.Context) error { return nil }
+	for _, job := range []Job{
+		{Schedule: Every(time.Second), Run: run},
+		{Name: "no schedule", Run: run},
+		{Name: "no run", Schedule: Every(time.Second)},
+		{Name: "negative jitter", Schedule: Every(time.Second), Run: run, Jitter: -time.Second},
+	} {
+		assert.True(suite.T(), errors.Is(suite.scheduler.Add(job), ErrInvalidJob), job.Name)
+	}
+
+	assert.NoError(suite.T(), suite.scheduler.Add(Job{Name: "poll", Schedule: Every(time.Hour), Run: run}))
+	assert.True(suite.T(), errors.Is(suite.scheduler.Add(Job{Name: "poll", Schedule: Every(time.Hour), Run: run}), ErrDuplicateJob))
+
+	suite.start()
+	assert.Eventually(suite.T(), func() bool { return !suite.scheduler.Status()[0].NextRun.IsZero() }, time.Second, time.Millisecond)
+	assert.Equal(suite.T(), ErrSchedulerStarted, suite.scheduler.Add(Job{Name: "late", Schedule: Every(time.Hour), Run: run}))
+	assert.Equal(suite.T(), ErrSchedulerStarted, suite.scheduler.Run(suite.ctx))
+	suite.stop()
+}
+
+func (suite *SchedulerTestSuite) TestServeHTTP() {
+	suite.Require().NoError(suite.scheduler.Add(Job{Name: "poll USD-BRL", Schedule: Every(time.Minute), Run: func(ctx context.Context) error {
+		return nil
+	}}))
+
+	recorder := httptest.NewRecorder()
+	suite.scheduler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/jobs", nil))
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Equal(suite.T(), "application/json", recorder.Header().Get("Content-Type"))
+	var status []JobStatus
+	assert.NoError(suite.T(), json.NewDecoder(recorder.Body).Decode(&status))
+	assert.Equal(suite.T(), []JobStatus{{Name: "poll USD-BRL", Schedule: "@every 1m0s"}}, status)
+}
@@ -1,17 +1,49 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	ecbClient "libs/external-clients/ecb-reference-rates/client"
+	awesomeClient "libs/external-clients/economia-awesome-api/client"
 	inMemoryDBClient "libs/resources/database/in-memory/go-doc-db-client/client"
 	inMemoryDB "libs/resources/database/in-memory/go-doc-db/database"
+	inMemoryDBMetrics "libs/resources/database/in-memory/go-doc-db/metrics"
+	entity "libs/services/entities/exchange-rate/entity"
+	alertSink "libs/services/infrastructure/alert-sinks/exchange-rate/sink"
+	godocdbrepository "libs/services/infrastructure/database/repositories/exchange-rate/in-memory/go-doc-db/repository"
+	exchangeRateProvider "libs/services/infrastructure/providers/exchange-rate/provider"
 	webHandler "libs/services/infrastructure/server/http/handlers/exchange-rate"
 	"libs/services/infrastructure/server/http/webserver"
+	usecase "libs/services/usecases/exchange-rate/usecases"
+	goeventbus "libs/shared/go-eventbus"
+	goscheduler "libs/shared/go-scheduler"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 )
 
 var (
 	dbName        = "exchange-rate"
 	webserverPort = ":8080"
+	// pollJobsEnv names the environment variable overriding defaultPollJobs.
+	pollJobsEnv = "EXCHANGE_RATE_POLL_JOBS"
+	// defaultPollJobs lists the pairs polled in the background and their schedules, as "PAIR=schedule" separated by
+	// semicolons. A schedule is a cron expression or a descriptor such as "@every 1m".
+	defaultPollJobs = "USD-BRL=@every 1m;EUR-BRL=@every 1m;GBP-BRL=*/5 * * * *"
+	pollJitter      = 5 * time.Second
+	pollTimeout     = 30 * time.Second
+	// shutdownTimeout bounds the wait for the running requests when the server stops.
+	shutdownTimeout = 10 * time.Second
+	// alertRulesEnv names the environment variable listing the alert rules saved at startup, written like
+	// "USD-BRL above 5.5;USD-BRL below 5;EUR-BRL moves 2% in 1h" and separated by semicolons.
+	alertRulesEnv = "EXCHANGE_RATE_ALERT_RULES"
+	// alertWebhookEnv names the environment variable with the URL alerts are posted to, besides the log.
+	alertWebhookEnv = "EXCHANGE_RATE_ALERT_WEBHOOK"
 )
 
 func RegisterExchangeRateWebServerTransportRoutes(server *webserver.Server, webService *webHandler.WebServiceExchangeRateHandler) {
@@ -20,20 +52,181 @@ func RegisterExchangeRateWebServerTransportRoutes(server *webserver.Server, webS
 	})
 
 	server.RegisterRoute(http.MethodGet, "/cotacoes", webService.ListCurrentExchangeRate)
+	server.RegisterRoute(http.MethodGet, "/cotacoes/batch", webService.ListExchangeRates)
+	server.RegisterRoute(http.MethodGet, "/cotacoes/{code}-{codein}/candles", webService.ListCandles)
+	server.RegisterRoute(http.MethodGet, "/convert", webService.Convert)
+}
+
+// NewPollScheduler returns a scheduler fetching and saving the current rate of each pair of jobs with fetcher.
+// jobs is written like defaultPollJobs. The jobs run concurrently with each other and with the requests of the server,
+// so the repository of fetcher must be safe for concurrent use, as the go-doc-db repository is.
+func NewPollScheduler(fetcher usecase.ExchangeRateFetcher, jobs string) (*goscheduler.Scheduler, error) {
+	scheduler := goscheduler.NewScheduler()
+	for _, job := range strings.Split(jobs, ";") {
+		if strings.TrimSpace(job) == "" {
+			continue
+		}
+		pairSpec, scheduleSpec, _ := strings.Cut(job, "=")
+		pair, err := entity.ParseCurrencyPair(strings.TrimSpace(pairSpec))
+		if err != nil {
+			return nil, fmt.Errorf("poll job %q: %w", job, err)
+		}
+		schedule, err := goscheduler.Parse(scheduleSpec)
+		if err != nil {
+			return nil, fmt.Errorf("poll job %q: %w", job, err)
+		}
+		err = scheduler.Add(goscheduler.Job{
+			Name:     "poll " + pair.String(),
+			Schedule: schedule,
+			Jitter:   pollJitter,
+			Timeout:  pollTimeout,
+			Run: func(ctx context.Context) error {
+				_, err := fetcher.Execute(ctx, pair.Code(), pair.CodeIn())
+				return err
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("poll job %q: %w", job, err)
+		}
+	}
+	return scheduler, nil
+}
+
+// ParseAlertRules returns the alert rules of rules, written like the value of alertRulesEnv. A moves rule without
+// "in WINDOW" uses the daily change of each quote.
+func ParseAlertRules(rules string) ([]*entity.AlertRule, error) {
+	var alertRules []*entity.AlertRule
+	for _, spec := range strings.Split(rules, ";") {
+		fields := strings.Fields(spec)
+		if len(fields) == 0 {
+			continue
+		}
+		rule, err := parseAlertRule(fields)
+		if err != nil {
+			return nil, fmt.Errorf("alert rule %q: %w", strings.TrimSpace(spec), err)
+		}
+		alertRules = append(alertRules, rule)
+	}
+	return alertRules, nil
+}
+
+// parseAlertRule returns the alert rule of the fields of one entry of ParseAlertRules.
+func parseAlertRule(fields []string) (*entity.AlertRule, error) {
+	if len(fields) < 3 {
+		return nil, entity.ErrInvalidAlertRule
+	}
+	pair, err := entity.ParseCurrencyPair(fields[0])
+	if err != nil {
+		return nil, err
+	}
+	switch kind := entity.AlertKind(fields[1]); {
+	case (kind == entity.AlertAbove || kind == entity.AlertBelow) && len(fields) == 3:
+		level, err := entity.ParseDecimal(fields[2])
+		if err != nil {
+			return nil, err
+		}
+		return entity.NewThresholdAlertRule(pair.Code(), pair.CodeIn(), kind, level)
+	case fields[1] == "moves" && (len(fields) == 3 || len(fields) == 5 && fields[3] == "in"):
+		percent, err := strconv.ParseFloat(strings.TrimSuffix(fields[2], "%"), 64)
+		if err != nil {
+			return nil, err
+		}
+		var window time.Duration
+		if len(fields) == 5 {
+			if window, err = time.ParseDuration(fields[4]); err != nil {
+				return nil, err
+			}
+		}
+		return entity.NewPercentChangeAlertRule(pair.Code(), pair.CodeIn(), percent, window)
+	}
+	return nil, entity.ErrInvalidAlertRule
 }
 
 func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	db := inMemoryDB.NewInMemoryDocBD(dbName)
+	dbMetrics := inMemoryDBMetrics.NewCollector()
+	db.SetInstrumentation(dbMetrics)
 	dbClient := inMemoryDBClient.NewClient(db)
 
 	webserver := webserver.NewWebServer(webserverPort)
 	webserver.ConfigureDefaults()
 	webServiceExchangeRate := NewWebServiceExchangeRateHandler(dbClient, dbName)
+	provider := exchangeRateProvider.NewFailoverProvider(
+		exchangeRateProvider.FailoverOptions{},
+		exchangeRateProvider.NewAwesomeAPIProvider(awesomeClient.NewClient()),
+		exchangeRateProvider.NewECBProvider(ecbClient.NewClient()),
+	)
+	webServiceExchangeRate.ExchangeRateProvider = provider
+	fetcher := usecase.NewGetExchangeRateUseCase(webServiceExchangeRate.ExchangeRateRepository)
+	fetcher.SetProvider(provider)
+	alertRules := godocdbrepository.NewAlertRuleRepository(dbName, dbClient)
+	rules, err := ParseAlertRules(os.Getenv(alertRulesEnv))
+	if err != nil {
+		log.Fatalf("Failed to configure alert rules: %v", err)
+	}
+	for _, rule := range rules {
+		if err := alertRules.SaveAlertRule(ctx, rule); err != nil {
+			log.Fatalf("Failed to save alert rule %s: %v", rule, err)
+		}
+	}
+	alertSinks := []entity.AlertSink{alertSink.NewLogSink(nil)}
+	if webhook := os.Getenv(alertWebhookEnv); webhook != "" {
+		alertSinks = append(alertSinks, alertSink.NewWebhookSink(webhook))
+	}
+	alertEvaluator := usecase.NewEvaluateAlertsUseCase(alertRules, webServiceExchangeRate.ExchangeRateRepository, alertSinks...)
+	fetcher.SetAlertEvaluator(alertEvaluator)
+	webServiceExchangeRate.AlertEvaluator = alertEvaluator
+	// The go-doc-db repository has no outbox, so events are published directly and are lost when the process stops
+	// before they are handled.
+	events := goeventbus.NewBus()
+	err = events.SubscribeAsync(goeventbus.AllEvents, 1, func(ctx context.Context, event goeventbus.Event) error {
+		log.Printf("Event %s for %s", event.EventName(), event.EventKey())
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("Failed to subscribe to events: %v", err)
+	}
+	fetcher.SetEventPublisher(events)
+	webServiceExchangeRate.EventPublisher = events
+	webServiceExchangeRate.ExchangeRateFetcher = fetcher
+	pollJobs, ok := os.LookupEnv(pollJobsEnv)
+	if !ok {
+		pollJobs = defaultPollJobs
+	}
+	scheduler, err := NewPollScheduler(fetcher, pollJobs)
+	if err != nil {
+		log.Fatalf("Failed to configure poll jobs: %v", err)
+	}
 	webHealthz := NewHealthzHandler()
 	RegisterExchangeRateWebServerTransportRoutes(webserver, webServiceExchangeRate)
 	webserver.RegisterRoute(http.MethodGet, "/healthz", webHealthz.Healthz)
+	webserver.RegisterRoute(http.MethodGet, "/metrics", dbMetrics.ServeHTTP)
+	webserver.RegisterRoute(http.MethodGet, "/jobs", scheduler.ServeHTTP)
+
+	schedulerDone := make(chan struct{})
+	go func() {
+		defer close(schedulerDone)
+		if err := scheduler.Run(ctx); err != nil {
+			log.Printf("Scheduler stopped: %v", err)
+		}
+	}()
+	go func() {
+		if err := webserver.Start(); err != nil {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
 
-	if err := webserver.Start(); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	<-ctx.Done()
+	log.Printf("Shutting down, waiting for running requests and jobs")
+	// The requests and the jobs publish events, so both stop before the bus closes.
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := webserver.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Server shutdown: %v", err)
 	}
+	<-schedulerDone
+	events.Close()
 }